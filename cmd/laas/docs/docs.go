@@ -61,7 +61,7 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get all audit records from the server",
+                "description": "Get all audit records from the server. The audits of obligations which are not published\nare only listed to reviewers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,6 +103,198 @@ const docTemplate = `{
                 }
             }
         },
+        "/audits/archive": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get audit records which were moved to the archive. The audits of obligations which are not\npublished are only listed to reviewers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audits"
+                ],
+                "summary": "Get archived audit records",
+                "operationId": "GetArchivedAudits",
+                "parameters": [
+                    {
+                        "enum": [
+                            "obligation",
+                            "license"
+                        ],
+                        "type": "string",
+                        "description": "Type of the audited entity",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the audited entity",
+                        "name": "type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ArchivedAuditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid type id",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch archived audits",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move audits older than the cutoff, along with their change logs, into the archive.\nThe cutoff defaults to AUDIT_RETENTION_DAYS days before now. The audits are archived\nin batches, so a failed request may have archived some of them, which it reports.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audits"
+                ],
+                "summary": "Archive old audit records",
+                "operationId": "ArchiveAudits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Archive audits older than this RFC3339 timestamp",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the audits which would be archived",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditArchiveResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to archive audits",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/audits/by-user": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Count, for every user, the audits of obligations made by the user and give the time of\nthe last one, optionally within a range of time. Users without audits in the range are\nlisted with a count of 0. The users are listed by their count, the most active first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audits"
+                ],
+                "summary": "Count obligation audits per user",
+                "operationId": "GetAuditCountsByUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the audits at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the audits before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditUserActivityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid from or to value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to count audits",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
         "/audits/{audit_id}": {
             "get": {
                 "security": [
@@ -111,7 +303,7 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get a specific audit records by ID",
+                "description": "Get a specific audit records by ID. The audits of obligations which are not published\nare only found by reviewers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -162,7 +354,7 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get changelogs of an audit record",
+                "description": "Get changelogs of an audit record. The changelogs of obligations which are not published\nare only found by reviewers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -219,7 +411,7 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get a specific changelog of an audit record by its ID",
+                "description": "Get a specific changelog of an audit record by its ID. The changelogs of obligations which\nare not published are only found by reviewers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -269,9 +461,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/classification_rules": {
             "get": {
-                "description": "Check health of the service",
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the keyword rules used to suggest obligation classifications",
                 "consumes": [
                     "application/json"
                 ],
@@ -279,35 +477,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Obligations"
                 ],
-                "summary": "Check health",
-                "operationId": "getHealth",
+                "summary": "Get classification rules",
+                "operationId": "GetClassificationRules",
                 "responses": {
                     "200": {
-                        "description": "Heath is OK",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.LicenseError"
+                            "$ref": "#/definitions/models.ClassificationRuleResponse"
                         }
                     },
                     "500": {
-                        "description": "Connection to DB failed",
+                        "description": "Unable to fetch classification rules",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     }
                 }
-            }
-        },
-        "/licenses": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": [],
-                        "{}": []
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Filter licenses based on different parameters",
+                "description": "Create a keyword rule used to suggest obligation classifications. Only for admins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -315,45 +510,193 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Licenses"
+                    "Obligations"
                 ],
-                "summary": "Filter licenses",
-                "operationId": "FilterLicense",
+                "summary": "Create a classification rule",
+                "operationId": "CreateClassificationRule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "SPDX ID of the license",
-                        "name": "spdxid",
-                        "in": "query"
+                        "description": "Rule to create",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ClassificationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClassificationRuleResponse"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "License detector type",
-                        "name": "detector_type",
-                        "in": "query"
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
                     },
-                    {
-                        "type": "boolean",
-                        "description": "GPLv2 compatibility flag status of license",
-                        "name": "gplv2compatible",
-                        "in": "query"
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
                     },
-                    {
-                        "type": "boolean",
-                        "description": "GPLv3 compatibility flag status of license",
-                        "name": "gplv3compatible",
-                        "in": "query"
+                    "409": {
+                        "description": "Rule with same keyword exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
                     },
+                    "500": {
+                        "description": "Unable to create classification rule",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/classification_rules/{id}": {
+            "delete": {
+                "security": [
                     {
-                        "type": "boolean",
-                        "description": "Mary done flag status of license",
-                        "name": "marydone",
-                        "in": "query"
-                    },
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a keyword rule used to suggest obligation classifications. Only for admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Delete a classification rule",
+                "operationId": "DeleteClassificationRule",
+                "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "Active license only",
-                        "name": "active",
+                        "type": "integer",
+                        "description": "Id of the rule",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid rule id",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No rule with given id found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check health of the service",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Check health",
+                "operationId": "getHealth",
+                "responses": {
+                    "200": {
+                        "description": "Heath is OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Connection to DB failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/licenses": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Filter licenses based on different parameters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Licenses"
+                ],
+                "summary": "Filter licenses",
+                "operationId": "FilterLicense",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SPDX ID of the license",
+                        "name": "spdxid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "License detector type",
+                        "name": "detector_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "GPLv2 compatibility flag status of license",
+                        "name": "gplv2compatible",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "GPLv3 compatibility flag status of license",
+                        "name": "gplv3compatible",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Mary done flag status of license",
+                        "name": "marydone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Active license only",
+                        "name": "active",
                         "in": "query"
                     },
                     {
@@ -374,6 +717,12 @@ const docTemplate = `{
                         "name": "copyleft",
                         "in": "query"
                     },
+                    {
+                        "type": "boolean",
+                        "description": "Stub licenses auto created with obligations",
+                        "name": "autoCreated",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
@@ -589,6 +938,79 @@ const docTemplate = `{
                 }
             }
         },
+        "/licenses/obligations/export": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Export the union of the active obligations of the given licenses as a json or csv file,\nto be attached to an SBOM. Each obligation appears once, annotated with the shortnames\nof the given licenses it applies to. The obligations are streamed from a read only\nsnapshot, limited by EXPORT_STATEMENT_TIMEOUT_SECONDS. Exports are rate limited per user\nby EXPORT_RATE_LIMIT per minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Licenses"
+                ],
+                "summary": "Export the obligations of licenses",
+                "operationId": "ExportLicenseObligations",
+                "parameters": [
+                    {
+                        "description": "Shortnames of the licenses",
+                        "name": "licenses",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseObligationsExportInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Format of the file",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LicenseObligationExport"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request body or format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many export requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
         "/licenses/preview": {
             "get": {
                 "security": [
@@ -794,17 +1216,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/obligation_maps/license/{license}": {
-            "get": {
+        "/obligation_maps/import": {
+            "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": [],
-                        "{}": []
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get obligation maps for a given license shortname",
+                "description": "Map existing obligations to licenses by a list of topic and shortname pairs, sent as\njson or uploaded as the multipart form file \"file\", a csv file with the header\n\"topic,shortname\". Existing maps are left untouched, so importing the same maps twice\ncreates them once. Pairs whose topic or shortname is unknown are skipped and reported.\nThe maps are created in a single transaction.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -812,26 +1234,34 @@ const docTemplate = `{
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Get maps for a license",
-                "operationId": "GetObligationMapByLicense",
+                "summary": "Import obligation maps",
+                "operationId": "ImportObligationMaps",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Shortname of the license",
-                        "name": "license",
-                        "in": "path",
-                        "required": true
+                        "description": "Topic and shortname pairs",
+                        "name": "maps",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationMapImportInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ObligationMapResponse"
+                            "$ref": "#/definitions/models.ObligationMapImportResponse"
                         }
                     },
-                    "404": {
-                        "description": "No license with given shortname found or no map for",
+                    "400": {
+                        "description": "Invalid json body or csv file",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failure to insert new maps",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -839,7 +1269,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/obligation_maps/topic/{topic}": {
+        "/obligation_maps/license/{license}": {
             "get": {
                 "security": [
                     {
@@ -847,7 +1277,7 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get obligation maps for a given obligation topic",
+                "description": "Get obligation maps for a given license shortname",
                 "consumes": [
                     "application/json"
                 ],
@@ -857,15 +1287,26 @@ const docTemplate = `{
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Get maps for an obligation",
-                "operationId": "GetObligationMapByTopic",
+                "summary": "Get maps for a license",
+                "operationId": "GetObligationMapByLicense",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic of the obligation",
-                        "name": "topic",
+                        "description": "Shortname of the license",
+                        "name": "license",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -875,8 +1316,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ObligationMapResponse"
                         }
                     },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
                     "404": {
-                        "description": "No obligation with given topic found or no map for",
+                        "description": "No license with given shortname found or no map for",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -884,41 +1343,178 @@ const docTemplate = `{
                 }
             }
         },
-        "/obligation_maps/topic/{topic}/license": {
-            "put": {
+        "/obligation_maps/matrix": {
+            "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "ApiKeyAuth": [],
+                        "{}": []
                     }
                 ],
-                "description": "Replaces the license list of an obligation topic with the given list in the obligation map.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Export the obligation maps of the given licenses, or of all the active licenses, as a csv\nmatrix. Each row is a license, by shortname, and each column the topic of an active\nobligation mapped to any of the licenses. A cell is \"x\" if the obligation applies to the\nlicense, else it is empty. The rows are streamed one by one.",
                 "produces": [
-                    "application/json"
+                    "text/csv"
                 ],
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Change license list",
-                "operationId": "UpdateLicenseInObligationMap",
+                "summary": "Export obligation maps as a matrix",
+                "operationId": "ExportObligationMapMatrix",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic of the obligation",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
+                        "description": "Comma separated shortnames of the licenses, all the active licenses if not given",
+                        "name": "licenses",
+                        "in": "query"
                     },
                     {
-                        "description": "Shortnames of the licenses to be in map",
-                        "name": "shortnames",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LicenseShortnamesInput"
-                        }
+                        "enum": [
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Format of the matrix",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "csv matrix",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Licenses with given shortnames not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to export obligation maps",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligation_maps/topic/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get obligation maps for a given obligation topic",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get maps for an obligation",
+                "operationId": "GetObligationMapByTopic",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationMapResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found or no map for",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligation_maps/topic/{topic}/license": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the license list of an obligation topic with the given list in the obligation map.\nThe added and removed shortnames are recorded as an audit of the obligation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Change license list",
+                "operationId": "UpdateLicenseInObligationMap",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shortnames of the licenses to be in map",
+                        "name": "shortnames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseShortnamesInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -948,7 +1544,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add or remove licenses from obligation map for a given obligation topic",
+                "description": "Add or remove licenses from obligation map for a given obligation topic. The added and\nremoved shortnames are recorded as an audit of the obligation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1014,12 +1610,13 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get all active obligations from the service",
+                "description": "Get all active obligations from the service. With format=ndjson or Accept: application/x-ndjson,\nall the matching obligations are streamed as newline delimited json instead.\nThe filter expression joins conditions on topic, type, text, classification, comment,\nmodifications, active and text_updatable with AND, using the operators =, !=, eq, ne,\nin and like, e.g. \"type in (obligation,risk) AND topic like '%copyleft%'\".\nWith fields, only the given fields of the obligations are fetched and returned, e.g.\n\"topic,classification,active\", with the other fields left out of the obligations.\nWithout active, only the active obligations are listed, or all of them if\nOBLIGATION_ACTIVE_DEFAULT is all.\nWith expandLicenses, the licenses the obligations are mapped to are listed in their licenses.\nWith dedupeLicenses, the obligations only list the ids of their licenses in license_ids\nand every license is returned once in the included map of the response, keyed by id.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-ndjson"
                 ],
                 "tags": [
                     "Obligations"
@@ -1028,11 +1625,87 @@ const docTemplate = `{
                 "operationId": "GetAllObligation",
                 "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "Active obligation only",
+                        "enum": [
+                            "true",
+                            "false",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Active obligation only, considering the effective window, or all for both",
                         "name": "active",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Admin only, both active and inactive obligations, ignoring active",
+                        "name": "includeInactive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only obligations created at or after this RFC3339 timestamp",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only obligations created before this RFC3339 timestamp",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only obligations whose text is (not) updatable",
+                        "name": "textUpdatable",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only obligations with (without) a comment",
+                        "name": "hasComment",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "false for the normalized text instead of the text as sent",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. classification=red AND modifications=true. Not on comment while field encryption is enabled",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields of the obligations to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Expand the licenses of the obligations inline, not with fields or format=ndjson",
+                        "name": "expandLicenses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Expand the licenses once into included, referenced by id from the obligations",
+                        "name": "dedupeLicenses",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
@@ -1056,6 +1729,24 @@ const docTemplate = `{
                         "description": "Asc or desc ordering",
                         "name": "order_by",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "ndjson to stream all obligations one per line, without pagination",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "none for the obligations without the response envelope",
+                        "name": "X-Response-Envelope",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1065,6 +1756,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ObligationResponse"
                         }
                     },
+                    "400": {
+                        "description": "Invalid active, includeInactive, createdAfter, createdBefore, textUpdatable, hasComment, status, filter, fields, expandLicenses, dedupeLicenses or raw value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "includeInactive or status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "includeInactive by a non admin user, or status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
                     "404": {
                         "description": "No obligations in DB",
                         "schema": {
@@ -1079,7 +1788,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create an obligation and associate it with licenses",
+                "description": "Create an obligation and associate it with licenses. Without shortnames, a standalone\nobligation is created. Empty shortnames are skipped. Shortnames which do not match a\nlicense are rejected, unless createMissingLicenses is set. Then a stub license flagged\nas auto_created is created for each of them. With dryRun, only validate the obligation\nand report what would happen without creating it. Admins can backdate the obligation\nby a past created_at, which is audited. Users may create OBLIGATION_CREATION_QUOTA\nobligations per day, admins OBLIGATION_CREATION_QUOTA_ADMIN, where 0 is unlimited.\nWith expected_md5, the md5 of the canonical text must match it, else the text was altered\nin transit and the obligation is rejected.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1100,9 +1809,27 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ObligationPOSTRequestJSONSchema"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate without creating",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create stub licenses for unknown shortnames",
+                        "name": "createMissingLicenses",
+                        "in": "query"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Result of dry run",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationDryRunResponse"
+                        }
+                    },
                     "201": {
                         "description": "Created",
                         "schema": {
@@ -1110,13 +1837,31 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request body",
+                        "description": "Malformed request body, invalid query parameter or expected_md5 mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "403": {
+                        "description": "created_at by a non admin user",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
                     "409": {
-                        "description": "Obligation with same body exists",
+                        "description": "Obligation with same topic or text exists, whose id and topic are returned, with a diff if its text differs",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationConflictError"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid obligation, unknown or too many shortnames, or future created_at",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Daily obligation creation quota exceeded",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1126,104 +1871,97 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
+                    },
+                    "504": {
+                        "description": "Checking or creating the obligation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
                     }
                 }
             }
         },
-        "/obligations/export": {
-            "get": {
+        "/obligations/audits/batch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": [],
                         "{}": []
                     }
                 ],
-                "description": "Export all obligations as a json file",
+                "description": "Fetches the audits of the obligations with the given topics at once, grouped by topic in\nthe order of the topics, newest first within each topic. The pagination is over all the\naudits, so a topic may continue on the next page. Topics without a published obligation,\nor one of the requested status, are returned in unknown_topics.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Export all obligations as a json file",
-                "operationId": "ExportObligations",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Fetches audits of several obligations",
+                "operationId": "GetObligationAuditsBatch",
+                "parameters": [
+                    {
+                        "description": "Topics of the obligations",
+                        "name": "topics",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ObligationJSONFileFormat"
-                            }
+                            "$ref": "#/definitions/models.ObligationAuditBatchRequest"
                         }
                     },
-                    "500": {
-                        "description": "Failed to fetch obligations",
-                        "schema": {
-                            "$ref": "#/definitions/models.LicenseError"
-                        }
-                    }
-                }
-            }
-        },
-        "/obligations/import": {
-            "post": {
-                "security": [
                     {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Import obligations by uploading a json file",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Obligations"
-                ],
-                "summary": "Import obligations by uploading a json file",
-                "operationId": "ImportObligations",
-                "parameters": [
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
-                        "type": "file",
-                        "description": "obligations json file list",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/models.ImportObligationsResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.ObligationImportStatus"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/models.ObligationAuditBatchResponse"
                         }
                     },
                     "400": {
-                        "description": "input file must be present",
+                        "description": "Invalid request body, status value or too many topics",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Unable to fetch audits",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1231,7 +1969,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/obligations/preview": {
+        "/obligations/changes.atom": {
             "get": {
                 "security": [
                     {
@@ -1239,46 +1977,66 @@ const docTemplate = `{
                         "{}": []
                     }
                 ],
-                "description": "Get topic and type of all active obligations from the service",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the latest changes of the published obligations as an Atom feed to follow in a\nfeed reader. The updates and deactivations are taken from the audits, the creations from\nthe creation dates of the obligations. Each entry is titled by the topic of the obligation\nand summarizes the changed fields.",
                 "produces": [
-                    "application/json"
+                    "application/atom+xml"
                 ],
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Get topic and types of all active obligations",
-                "operationId": "GetAllObligationPreviews",
+                "summary": "Get the changes of obligations as an Atom feed",
+                "operationId": "GetObligationChangesFeed",
                 "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "Active obligation only",
-                        "name": "active",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "Only the changes after this RFC3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "green",
+                            "white",
+                            "yellow",
+                            "red"
+                        ],
+                        "type": "string",
+                        "description": "Only the changes of obligations of this classification",
+                        "name": "classification",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Atom feed",
                         "schema": {
-                            "$ref": "#/definitions/models.ObligationPreviewResponse"
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid since or classification value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
                         }
                     }
                 }
             }
         },
-        "/obligations/{topic}": {
-            "get": {
+        "/obligations/check-duplicates": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": [],
                         "{}": []
                     }
                 ],
-                "description": "Get an active based on given topic",
+                "description": "Check, for each given obligation, whether an obligation with the same topic or the same\ntext (compared by hash) already exists among the published obligations, or among those\nof the status reviewers ask for. Nothing is written.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1288,39 +2046,76 @@ const docTemplate = `{
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Get an obligation",
-                "operationId": "GetObligation",
+                "summary": "Check obligations for duplicates",
+                "operationId": "CheckObligationDuplicates",
                 "parameters": [
                     {
+                        "description": "Obligations to check",
+                        "name": "obligations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ObligationDuplicateCheckInput"
+                            }
+                        }
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
                         "type": "string",
-                        "description": "Topic of the obligation",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
+                        "description": "Reviewers only, check against obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ObligationResponse"
+                            "$ref": "#/definitions/models.ObligationDuplicateCheckResponse"
                         }
                     },
-                    "404": {
-                        "description": "No obligation with given topic found",
+                    "400": {
+                        "description": "Bad request body, too many obligations or invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to check obligations",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/obligations/classify": {
+            "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "ApiKeyAuth": [],
+                        "{}": []
                     }
                 ],
-                "description": "Deactivate an obligation",
+                "description": "Suggest a classification for an obligation text based on the classification rules.\nEach rule whose keyword appears in the text adds its weight to its classification, and\nthe classification with the highest score is suggested. Nothing is persisted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1330,36 +2125,49 @@ const docTemplate = `{
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Deactivate obligation",
-                "operationId": "DeleteObligation",
+                "summary": "Suggest a classification",
+                "operationId": "ClassifyObligation",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Topic of the obligation to be updated",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
+                        "description": "Obligation text",
+                        "name": "obligation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationClassifyInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationClassificationResponse"
+                        }
                     },
-                    "404": {
-                        "description": "No obligation with given topic found",
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch classification rules",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/obligations/deactivate-by-topics": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update an existing obligation record",
+                "description": "Deactivate the obligations of an explicit list of topics in one transaction. An audit is\nwritten for every deactivated obligation. The topics which were deactivated, which were\nalready inactive, which were not found and which are locked by another user, and so were\nleft active, are returned. At most 500 topics can be given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1369,23 +2177,16 @@ const docTemplate = `{
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Update obligation",
-                "operationId": "UpdateObligation",
+                "summary": "Deactivate obligations by topic",
+                "operationId": "DeactivateObligationsByTopics",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Topic of the obligation to be updated",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Obligation to be updated",
-                        "name": "obligation",
+                        "description": "Topics of the obligations to deactivate",
+                        "name": "deactivate",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ObligationPATCHRequestJSONSchema"
+                            "$ref": "#/definitions/models.ObligationDeactivateInput"
                         }
                     }
                 ],
@@ -1393,23 +2194,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ObligationResponse"
+                            "$ref": "#/definitions/models.ObligationDeactivateResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request body",
                         "schema": {
-                            "$ref": "#/definitions/models.LicenseError"
+                            "$ref": "#/definitions/models.ValidationError"
                         }
                     },
-                    "404": {
-                        "description": "No obligation with given topic found",
+                    "403": {
+                        "description": "User is not an admin",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
+                    "422": {
+                        "description": "Too many topics",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
                     "500": {
-                        "description": "Unable to update obligation",
+                        "description": "Unable to deactivate obligations",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1417,44 +2224,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/obligations/{topic}/audits": {
+        "/obligations/export": {
             "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": [],
-                        "{}": []
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches audits corresponding to an obligation",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Export all obligations as a json file, in an envelope with the schema version of the\nexport to be checked on import. The obligations are streamed from a read only\nsnapshot, limited by EXPORT_STATEMENT_TIMEOUT_SECONDS. Exports are rate limited per user\nby EXPORT_RATE_LIMIT per minute. The number of exported obligations is sent in the\nX-Total-Count header. The manifest at the end of the export holds the number of exported\nobligations and the sha256 over them, by which the import verifies the export. With\nschemaVersion 1, the obligations are exported as a bare array, without envelope and manifest.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Obligations"
                 ],
-                "summary": "Fetches audits corresponding to an obligation",
-                "operationId": "GetObligationAudits",
+                "summary": "Export all obligations as a json file",
+                "operationId": "ExportObligations",
                 "parameters": [
                     {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
                         "type": "string",
-                        "description": "Topic of the obligation for which audits need to be fetched",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            1,
+                            3
+                        ],
                         "type": "integer",
-                        "description": "Number of records per page",
-                        "name": "limit",
+                        "default": 3,
+                        "description": "Schema version of the export",
+                        "name": "schemaVersion",
                         "in": "query"
                     }
                 ],
@@ -1462,17 +2268,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AuditResponse"
+                            "$ref": "#/definitions/models.ObligationExportEnvelope"
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of exported obligations"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "No obligation with given topic found",
+                    "400": {
+                        "description": "Invalid status or schemaVersion value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many export requests",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
                     "500": {
-                        "description": "unable to find audits with such obligation topic",
+                        "description": "Failed to fetch obligations",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1480,15 +2304,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/search": {
-            "post": {
+        "/obligations/graph": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": [],
                         "{}": []
                     }
                 ],
-                "description": "Search licenses on different filters and algorithms",
+                "description": "Get the active obligations and the licenses as nodes and the obligation maps as edges,\noptionally scoped to a set of licenses or classifications",
                 "consumes": [
                     "application/json"
                 ],
@@ -1496,36 +2320,178 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Licenses"
+                    "Obligations"
                 ],
-                "summary": "Search licenses",
-                "operationId": "SearchInLicense",
+                "summary": "Get obligation graph",
+                "operationId": "GetObligationGraph",
                 "parameters": [
                     {
-                        "description": "Search criteria",
-                        "name": "search",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SearchLicense"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Licenses matched",
+                        "type": "string",
+                        "description": "Comma separated shortnames of the licenses",
+                        "name": "licenses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated classifications of the obligations",
+                        "name": "classification",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.LicenseResponse"
+                            "$ref": "#/definitions/models.ObligationGraphResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligation graph",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/id/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get an obligation by its id, which unlike its topic never changes. The text is translated\nand the text alone is returned with Accept: text/plain as for the lookup by topic.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get an obligation by id",
+                "operationId": "GetObligationById",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id of the obligation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "de",
+                        "description": "Language of the text",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "false for the normalized text instead of the text as sent",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the text",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "none for the obligations without the response envelope",
+                        "name": "X-Response-Envelope",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id, language, raw or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
                     "404": {
-                        "description": "Search algorithm doesn't exist",
+                        "description": "No published obligation with given id found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "406": {
+                        "description": "Accept allows neither json nor plain text",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch translation or log the access",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1533,14 +2499,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/obligations/id/{id}/audits": {
             "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "ApiKeyAuth": [],
+                        "{}": []
                     }
                 ],
-                "description": "Get all service users",
+                "description": "Fetches audits corresponding to an obligation by its id, which unlike its topic never changes,\nnewest first. The total number of audits and the links to the next and previous pages are in\nthe pagination meta.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1548,11 +2515,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Obligations"
                 ],
-                "summary": "Get users",
-                "operationId": "GetAllUsers",
+                "summary": "Fetches audits corresponding to an obligation by its id",
+                "operationId": "GetObligationAuditsById",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id of the obligation for which audits need to be fetched",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
@@ -1570,61 +2555,124 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.AuditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
                         }
                     },
                     "404": {
-                        "description": "Users not found",
+                        "description": "No published obligation with given id found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "unable to find audits with such obligation id",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/obligations/import": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new service user",
+                "description": "Import obligations by uploading a json file. An obligation with the same topic or text as an\nexisting one is handled by the strategy: skip leaves the existing obligation untouched,\noverwrite updates it unless its text is not updatable, and merge only fills its empty fields.\nAn existing obligation found by its text keeps its topic.\nWithout a strategy, existing obligations are skipped, where they used to be overwritten, so\nre-imports relying on that have to pass overwrite. An obligation failing to import is not\nchanged at all. Overwriting the text of an obligation requires a change_reason, which is\nstored on the audit of the update. Exports of older schema versions are migrated to the current one, exports of unknown\nversions are rejected. Exports with a manifest are rejected if the count or the sha256 of\ntheir obligations does not match it. Admins can backdate new obligations by a past created_at, which is\naudited. New obligations count against the daily obligation creation quota of the user,\nthose over it are reported with the status 429. Existing obligations locked by another user\nare neither overwritten nor merged and are reported with the status 423.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Obligations"
                 ],
-                "summary": "Create new user",
-                "operationId": "CreateUser",
+                "summary": "Import obligations by uploading a json file",
+                "operationId": "ImportObligations",
                 "parameters": [
                     {
-                        "description": "User to create",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserInput"
-                        }
+                        "type": "file",
+                        "description": "obligations json file list",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "overwrite",
+                            "merge"
+                        ],
+                        "type": "string",
+                        "default": "skip",
+                        "description": "Conflict resolution strategy",
+                        "name": "strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reason of the text changes of overwritten obligations",
+                        "name": "change_reason",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.ImportObligationsResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ObligationImportStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "input file must be present, invalid strategy, unsupported schema version or manifest mismatch",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
-                    "409": {
-                        "description": "User already exists",
+                    "403": {
+                        "description": "created_at by a non admin user",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
@@ -1632,14 +2680,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/obligations/length-distribution": {
             "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "ApiKeyAuth": [],
+                        "{}": []
                     }
                 ],
-                "description": "Get a single user by ID",
+                "description": "Count the obligations by the length of their text in buckets, e.g. to spot stub or\naccidentally huge obligations. The buckets are given by their ascending upper bounds,\nwith a last bucket for the longer texts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1647,62 +2696,5124 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Obligations"
                 ],
-                "summary": "Get a user",
-                "operationId": "GetUser",
+                "summary": "Get obligation text length distribution",
+                "operationId": "GetObligationLengthDistribution",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "default": "50,500,2000",
+                        "description": "Comma separated ascending upper bounds of the buckets",
+                        "name": "buckets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Active obligation only",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.ObligationLengthDistributionResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user id",
+                        "description": "Invalid buckets, active or status value",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "status without valid credentials",
                         "schema": {
                             "$ref": "#/definitions/models.LicenseError"
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "datatypes.JSONType-models_LicenseDBSchemaExtension": {
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligation lengths",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/lint": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Flag suspicious obligations for reviewers to fix: the topic is the md5 or the hash of the\ntext, the classification is empty, the text is shorter than minTextLength, the obligation\nis not mapped to any license, or it is inactive but mapped to active licenses. The\ntopics are listed by the check flagging them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Lint obligations",
+                "operationId": "GetObligationLint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Texts shorter than this many characters are flagged",
+                        "name": "minTextLength",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationLintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid minTextLength or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/mapping-health": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Classify the obligations as fully mapped to active licenses, mapped to some inactive\nlicenses, mapped to license ids which do not exist, or unmapped, with the topics of each\nclass",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get obligation mapping health",
+                "operationId": "GetObligationMappingHealth",
+                "parameters": [
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationMappingHealthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligation maps",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/preview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get topic and type of all active obligations from the service",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get topic and types of all active obligations",
+                "operationId": "GetAllObligationPreviews",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Active obligation only, considering the effective window",
+                        "name": "active",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid active or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/recent": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the published obligations changed since the given time, by their audits, with the\ntime of their last change and the fields changed since then. Each obligation is listed\nonce, the last changed first. since is either a duration before now, in days like 7d or\nas 12h or 30m, or an RFC3339 timestamp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get recently changed obligations",
+                "operationId": "GetRecentObligations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "7d",
+                        "description": "Duration before now or RFC3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationRecentChangesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid since value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/reclassify": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the classification of all the obligations matching the filter in one transaction,\ne.g. all red obligations without modifications to yellow. An audit is written for every\nreclassified obligation. With dryRun, only list the obligations which would be reclassified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Reclassify obligations",
+                "operationId": "ReclassifyObligations",
+                "parameters": [
+                    {
+                        "description": "Filter of the obligations and their new classification",
+                        "name": "reclassify",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationReclassifyInput"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only list the obligations which would be reclassified",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationReclassifyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, classification or dryRun value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to reclassify obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/resolve-expression": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Parse an SPDX license expression of license shortnames combined with AND, OR and\nparentheses, and resolve the active obligations of each license. The expression is\nreturned with the obligations of each license, along with the combined obligations of\nall the licenses of the expression.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Resolve the obligations of an SPDX license expression",
+                "operationId": "ResolveLicenseExpression",
+                "parameters": [
+                    {
+                        "description": "SPDX license expression",
+                        "name": "expression",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseExpressionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResolvedLicenseExpressionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request body or invalid expression",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/search": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Search obligations on different filters and algorithms. Each result has a highlight\nfield with a snippet of the searched field and the matches wrapped in \u003cmark\u003e tags.\nThe snippet is HTML-escaped, so it can be rendered as HTML.\nComments can not be searched while field encryption is enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Search obligations",
+                "operationId": "SearchInObligation",
+                "parameters": [
+                    {
+                        "description": "Search criteria",
+                        "name": "search",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchObligation"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Obligations matched",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or status, or comment search while field encryption is enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Search algorithm doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/tags/assign": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a tag to all the obligations with the given topics, or matching the given filter,\nin one transaction. An audit is written for every obligation which gets the tag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Assign a tag to obligations",
+                "operationId": "AssignObligationTag",
+                "parameters": [
+                    {
+                        "description": "Tag and the obligations to assign it to",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTagAssignInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTagAssignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to assign tag",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/tags/unassign": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a tag from all the obligations with the given topics, or matching the given filter,\nin one transaction. An audit is written for every obligation which loses the tag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Unassign a tag from obligations",
+                "operationId": "UnassignObligationTag",
+                "parameters": [
+                    {
+                        "description": "Tag and the obligations to unassign it from",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTagAssignInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTagAssignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to unassign tag",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/types": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the allowed values of the type of an obligation, configured by OBLIGATION_TYPES",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get obligation types",
+                "operationId": "GetObligationTypes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTypesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication needed",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the active obligations with the number of distinct active licenses mapped to them,\nmost used first, to prioritize the review of the most impactful obligations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get obligation usage",
+                "operationId": "GetObligationUsage",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, obligations of this review status instead of the published ones",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligation usage",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/validate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Validate, for each given obligation, that it could be created: the required fields, the\nclassification and type, that the topic is a slug of lowercase letters, digits and hyphens,\nthat the text has at least minTextLength characters, and that neither the topic nor the\ntext already exists, in the database or earlier in the batch, and that the text matches\nexpected_md5 if given. All the errors of each obligation are reported. Nothing is\nwritten, so no admin rights are needed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Validate obligations",
+                "operationId": "ValidateObligations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Texts shorter than this many characters are invalid",
+                        "name": "minTextLength",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Obligations to validate",
+                        "name": "obligations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ObligationPOSTRequestJSONSchema"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationValidationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request body, invalid minTextLength value or too many obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to validate obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/watched": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the obligations watched by the logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get watched obligations",
+                "operationId": "GetWatchedObligations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "false for the normalized text instead of the text as sent",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "none for the obligations without the response envelope",
+                        "name": "X-Response-Envelope",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid raw value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/watched/changes.atom": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the latest changes of the published obligations watched by the logged in user as\nan Atom feed, which notifies the user of the changes of those obligations in a feed\nreader. The entries are those of /obligations/changes.atom.",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get the changes of watched obligations as an Atom feed",
+                "operationId": "GetWatchedObligationChangesFeed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the changes after this RFC3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "green",
+                            "white",
+                            "yellow",
+                            "red"
+                        ],
+                        "type": "string",
+                        "description": "Only the changes of obligations of this classification",
+                        "name": "classification",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Atom feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid since or classification value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get an active based on given topic. The text is translated to the language requested\nby lang or Accept-Language when a translation exists, else the canonical text is returned.\nA former topic of a renamed obligation is redirected to its current topic. Reads of\nsensitive obligations are logged when OBLIGATION_READ_AUDIT_ENABLED is set. With\nAccept: text/plain, only the text of the obligation is returned. If there is no obligation\nwith the topic, the closest existing topics are suggested. The lock of an obligation\nunder review is returned with the obligation until it is released or expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get an obligation",
+                "operationId": "GetObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "de",
+                        "description": "Language of the text",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "false for the normalized text instead of the text as sent",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the text",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "none for the obligations without the response envelope",
+                        "name": "X-Response-Envelope",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "301": {
+                        "description": "Moved Permanently",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the obligation under its current topic"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid language, raw or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found, with the closest topics",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationNotFoundError"
+                        }
+                    },
+                    "406": {
+                        "description": "Accept allows neither json nor plain text",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "410": {
+                        "description": "Former topic of an obligation which is deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch translation or lock, or log the access",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deactivate an obligation. The deactivation is recorded as an audit of the obligation.\nThe deactivated obligation is returned, unless the client sent Prefer: return=minimal,\nwhich gets an empty 204 response instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Deactivate obligation",
+                "operationId": "DeleteObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation to be updated",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "return=minimal"
+                        ],
+                        "type": "string",
+                        "description": "return=minimal for a response without body",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to deactivate obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the status and headers of the obligation with the given topic as with the GET request,\nwithout the body, e.g. to check that the obligation exists.",
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Check an obligation",
+                "operationId": "HeadObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "301": {
+                        "description": "Moved Permanently",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the obligation under its current topic"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update an existing obligation record. The text can only be changed if text_updatable\nis true, where text_updatable of the request takes precedence over the stored one. So a\nrequest may unlock the text and change it, but not change it while locking it.\nChanging the text requires a change_reason, which is stored on the audit of the update.\nThe status can be set to DRAFT or IN_REVIEW, obligations are published with the publish endpoint.\nA renamed obligation keeps its former topic reserved, and GET requests for it are redirected.\nAn obligation locked by another user can not be updated until the lock is released or expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Update obligation",
+                "operationId": "UpdateObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation to be updated",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update if the obligation was not modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Obligation to be updated",
+                        "name": "obligation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationPATCHRequestJSONSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request body or header",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "403": {
+                        "description": "Userlevel of the user can not update a field or withdraw a published obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Topic is used or reserved by another obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Obligation was modified after If-Unmodified-Since",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid obligation fields",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "423": {
+                        "description": "Obligation is locked by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to update obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/access-log": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get who read a sensitive obligation and when, latest first. Reads are only logged\nwhen OBLIGATION_READ_AUDIT_ENABLED is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get access log of an obligation",
+                "operationId": "GetObligationAccessLog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationAccessLogResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication needed",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch access log",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/audit-report": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compile the full change history of an obligation, its audits and their changelogs\nincluding the archived ones, oldest first, into a report along with when and by whom it\nwas generated. The report is signed by an HMAC-SHA256, hex encoded, over its compact json\nencoding as returned in data, with the key AUDIT_REPORT_SIGNING_KEY. Recipients holding\nthe key can so verify that the report was not altered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get the signed audit report of an obligation",
+                "operationId": "GetObligationAuditReport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationAuditReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to compile the audit report",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "503": {
+                        "description": "AUDIT_REPORT_SIGNING_KEY is not set",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/audits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Fetches audits corresponding to an obligation, newest first. The total number of audits\nand the links to the next and previous pages are in the pagination meta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Fetches audits corresponding to an obligation",
+                "operationId": "GetObligationAudits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation for which audits need to be fetched",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "unable to find audits with such obligation topic",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/audits/summary": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the number of audits of an obligation, when and by whom it was last changed, and\nhow many times each field was changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get audit summary of an obligation",
+                "operationId": "GetObligationAuditSummary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationAuditSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to summarize audits",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/editable-fields": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get whether the user may update each field of the obligation, by json key, following\nthe field permissions of the userlevel of the user, text_updatable of the obligation,\nand that only reviewers may withdraw published obligations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get editable fields of an obligation",
+                "operationId": "GetObligationEditableFields",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationEditableFieldsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/find": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Find the occurrences of a phrase in the text of an obligation, compared case-insensitively.\nEach match has its character offset and length in the text, and a snippet of the text\naround it. Reads of sensitive obligations are logged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Find in an obligation",
+                "operationId": "FindInObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Phrase to find",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTextMatchesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing q or invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to log the access",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/lock": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lock an obligation under review, so that only the user can update it until the lock is\nreleased or expires after OBLIGATION_LOCK_TTL. Locking an obligation again renews the lock.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Lock an obligation",
+                "operationId": "LockObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationLockResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "423": {
+                        "description": "Obligation is locked by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to lock obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Release the lock of an obligation. Only the holder of the lock or an admin can release it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Unlock an obligation",
+                "operationId": "UnlockObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found or not locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "423": {
+                        "description": "Obligation is locked by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to unlock obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/notes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the review notes of an obligation, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get notes of an obligation",
+                "operationId": "GetObligationNotes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationNoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch notes",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a review note to an obligation as the logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Add a note to an obligation",
+                "operationId": "CreateObligationNote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note to add",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationNoteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationNoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to add note",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Publish an obligation, so it is listed to consumers. Only users whose userlevel is one of\nOBLIGATION_REVIEWER_USERLEVELS may publish. The publication is recorded as an audit of the obligation,\nalong with a snapshot of the published obligation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Publish obligation",
+                "operationId": "PublishObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation to be published",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Userlevel of the user can not publish obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Obligation is already published",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to publish obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the obligations whose texts are at least threshold similar to the text of the\ngiven obligation, most similar first. The similarity is computed on the trigrams of the\ntexts, by pg_trgm on Postgres. The defaults are set by OBLIGATION_SIMILARITY_THRESHOLD and\nOBLIGATION_SIMILARITY_MATCH_COUNT.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get similar obligations",
+                "operationId": "GetSimilarObligations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 0.95,
+                        "description": "Minimum similarity between 0 and 1",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Maximum number of matches",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationSimilarityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid threshold, count or status",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch obligations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/snapshot": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Take an immutable snapshot of the obligation as it is now, for point in time records.\nSnapshots are also taken on every publication of the obligation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Take a snapshot of an obligation",
+                "operationId": "CreateObligationSnapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationSnapshotResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to take snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/snapshots": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the snapshots of an obligation, newest first. Reads of the snapshots of sensitive\nobligations are logged as reads of the obligation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get snapshots of an obligation",
+                "operationId": "GetObligationSnapshots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationSnapshotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch snapshots",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/snapshots/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get a snapshot of an obligation by its id. Reads of the snapshots of sensitive obligations\nare logged as reads of the obligation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get a snapshot of an obligation",
+                "operationId": "GetObligationSnapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the snapshot",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationSnapshotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id or status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic or snapshot with given id found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/translations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Get the translated texts of an obligation in all available languages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Get translations of an obligation",
+                "operationId": "GetObligationTranslations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "IN_REVIEW",
+                            "PUBLISHED"
+                        ],
+                        "type": "string",
+                        "description": "Reviewers only, the obligation of this review status instead of the published one",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTranslationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status value",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "401": {
+                        "description": "status without valid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "403": {
+                        "description": "status by a non reviewer",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "No published obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to fetch translations",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/translations/{lang}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add or update the text of an obligation in the given language",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Add or update a translation of an obligation",
+                "operationId": "UpdateObligationTranslation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "de",
+                        "description": "Language tag of the translation",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translated text",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTranslationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ObligationTranslationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid language or request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to save translation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/obligations/{topic}/watch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Watch an obligation, so it is listed in the obligations watched by the user and its\nchanges are notified in the feed /obligations/watched/changes.atom",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Watch an obligation",
+                "operationId": "WatchObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to watch obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop watching an obligation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Obligations"
+                ],
+                "summary": "Unwatch an obligation",
+                "operationId": "UnwatchObligation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic of the obligation",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No obligation with given topic found or not watched",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Unable to unwatch obligation",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": [],
+                        "{}": []
+                    }
+                ],
+                "description": "Search licenses on different filters and algorithms",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Licenses"
+                ],
+                "summary": "Search licenses",
+                "operationId": "SearchInLicense",
+                "parameters": [
+                    {
+                        "description": "Search criteria",
+                        "name": "search",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchLicense"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Licenses matched",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Search algorithm doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all service users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get users",
+                "operationId": "GetAllUsers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Users not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new service user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create new user",
+                "operationId": "CreateUser",
+                "parameters": [
+                    {
+                        "description": "User to create",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a single user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a user",
+                "operationId": "GetUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.LicenseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "datatypes.JSONType-models_LicenseDBSchemaExtension": {
             "type": "object"
         },
-        "models.APICollection": {
+        "models.APICollection": {
+            "type": "object",
+            "properties": {
+                "authenticated": {
+                    "type": "object"
+                },
+                "unAuthenticated": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.APICollectionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.APICollection"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ArchivedAudit": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "type": "string",
+                    "example": "2024-12-01T18:10:25.00+05:30"
+                },
+                "change_logs": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "change_reason": {
+                    "type": "string",
+                    "example": "Aligned the text with the license"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 456
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "license"
+                    ],
+                    "example": "license"
+                },
+                "type_id": {
+                    "type": "integer",
+                    "example": 34
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "models.ArchivedAuditResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ArchivedAudit"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.Audit": {
+            "type": "object",
+            "properties": {
+                "change_reason": {
+                    "type": "string",
+                    "example": "Aligned the text with the license"
+                },
+                "entity": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 456
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "truncated": {
+                    "description": "changelogs over the maximum are summarized in one",
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "license"
+                    ],
+                    "example": "license"
+                },
+                "type_id": {
+                    "type": "integer",
+                    "example": 34
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "models.AuditArchiveResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.AuditArchiveResult"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.AuditArchiveResult": {
+            "type": "object",
+            "properties": {
+                "before": {
+                    "type": "string",
+                    "example": "2023-12-01T00:00:00Z"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "models.AuditResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Audit"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.AuditUserActivity": {
+            "type": "object",
+            "properties": {
+                "audits": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_activity_at": {
+                    "description": "null if the user has no audits",
+                    "type": "string",
+                    "example": "2024-05-01T10:00:00Z"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "username": {
+                    "type": "string",
+                    "example": "fossy"
+                }
+            }
+        },
+        "models.AuditUserActivityResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditUserActivity"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ChangeLog": {
+            "type": "object",
+            "properties": {
+                "audit_id": {
+                    "type": "integer",
+                    "example": 456
+                },
+                "field": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 789
+                },
+                "old_value": {
+                    "type": "string",
+                    "example": "Old license text"
+                },
+                "updated_value": {
+                    "type": "string",
+                    "example": "New license text"
+                }
+            }
+        },
+        "models.ChangeLogResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChangeLog"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ClassificationRule": {
+            "type": "object",
+            "required": [
+                "classification",
+                "keyword"
+            ],
+            "properties": {
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "red"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "keyword": {
+                    "type": "string",
+                    "example": "patent"
+                },
+                "weight": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "models.ClassificationRuleResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClassificationRule"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "topic"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "topic is required"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "models.ImportLicensesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "can be of type models.LicenseError or models.LicenseImportStatus",
+                    "type": "array",
+                    "items": {}
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ImportObligationsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "can be of type models.LicenseError or models.ObligationImportStatus",
+                    "type": "array",
+                    "items": {}
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.LicenseDB": {
+            "type": "object",
+            "required": [
+                "fullname",
+                "shortname",
+                "spdx_id",
+                "text"
+            ],
+            "properties": {
+                "FSFfree": {
+                    "type": "boolean"
+                },
+                "Fedora": {
+                    "type": "string"
+                },
+                "GPLv2compatible": {
+                    "type": "boolean"
+                },
+                "GPLv3compatible": {
+                    "type": "boolean"
+                },
+                "OSIapproved": {
+                    "type": "boolean"
+                },
+                "active": {
+                    "type": "boolean"
+                },
+                "add_date": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "auto_created": {
+                    "type": "boolean"
+                },
+                "copyleft": {
+                    "type": "boolean"
+                },
+                "detector_type": {
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "external_ref": {
+                    "$ref": "#/definitions/datatypes.JSONType-models_LicenseDBSchemaExtension"
+                },
+                "flag": {
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "fullname": {
+                    "type": "string",
+                    "example": "MIT License"
+                },
+                "marydone": {
+                    "type": "boolean"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "This license has been superseded."
+                },
+                "risk": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0
+                },
+                "shortname": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "spdx_id": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "MIT License Text here"
+                },
+                "text_updatable": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://opensource.org/licenses/MIT"
+                }
+            }
+        },
+        "models.LicenseError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid request body"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid request body"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/licenses"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T10:00:51+05:30"
+                }
+            }
+        },
+        "models.LicenseExpressionInput": {
+            "type": "object",
+            "required": [
+                "expression"
+            ],
+            "properties": {
+                "expression": {
+                    "type": "string",
+                    "example": "(MIT OR Apache-2.0) AND GPL-2.0-only"
+                }
+            }
+        },
+        "models.LicenseExpressionNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LicenseExpressionNode"
+                    }
+                },
+                "license": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "obligations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft"
+                    ]
+                },
+                "operator": {
+                    "type": "string",
+                    "enum": [
+                        "AND",
+                        "OR"
+                    ],
+                    "example": "AND"
+                }
+            }
+        },
+        "models.LicenseId": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "shortname": {
+                    "type": "string",
+                    "example": "MIT"
+                }
+            }
+        },
+        "models.LicenseImportStatus": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.LicenseId"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.LicenseMapShortnamesElement": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "shortname": {
+                    "type": "string",
+                    "example": "GPL-2.0-only"
+                }
+            }
+        },
+        "models.LicenseMapShortnamesInput": {
+            "type": "object",
+            "properties": {
+                "map": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LicenseMapShortnamesElement"
+                    }
+                }
+            }
+        },
+        "models.LicenseObligationExport": {
+            "type": "object",
+            "properties": {
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ]
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "This is a comment."
+                },
+                "modifications": {
+                    "type": "boolean"
+                },
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.LicenseObligationsExportInput": {
+            "type": "object",
+            "required": [
+                "shortnames"
+            ],
+            "properties": {
+                "shortnames": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "MIT"
+                    ]
+                }
+            }
+        },
+        "models.LicensePreviewResponse": {
+            "type": "object",
+            "properties": {
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.LicenseResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LicenseDB"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.LicenseShortnamesInput": {
+            "type": "object",
+            "properties": {
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                }
+            }
+        },
+        "models.LicenseUpdateJSONSchema": {
+            "type": "object",
+            "properties": {
+                "FSFfree": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "Fedora": {
+                    "type": "string",
+                    "example": "Fedora"
+                },
+                "GPLv2compatible": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "GPLv3compatible": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "OSIapproved": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "auto_created": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "copyleft": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "detector_type": {
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "external_ref": {
+                    "$ref": "#/definitions/datatypes.JSONType-models_LicenseDBSchemaExtension"
+                },
+                "flag": {
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "fullname": {
+                    "type": "string",
+                    "example": "MIT License"
+                },
+                "marydone": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "This license has been superseded."
+                },
+                "risk": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "source": {
+                    "type": "string",
+                    "example": "Source"
+                },
+                "spdx_id": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "MIT License Text here"
+                },
+                "text_updatable": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://opensource.org/licenses/MIT"
+                }
+            }
+        },
+        "models.Obligation": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "green"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "effective_until": {
+                    "type": "string",
+                    "example": "2024-12-31T23:59:59Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 147
+                },
+                "license_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "license_ids": {
+                    "description": "only set when the expanded licenses are deduplicated",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "licenses": {
+                    "description": "only set when the licenses are expanded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationLicense"
+                    }
+                },
+                "lock": {
+                    "description": "only set when a single obligation is read",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ObligationLock"
+                        }
+                    ]
+                },
+                "modifications": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "sensitive": {
+                    "description": "reads are logged when the read audit is enabled",
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "DRAFT",
+                        "IN_REVIEW",
+                        "PUBLISHED"
+                    ],
+                    "example": "PUBLISHED"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "text_updatable": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ],
+                    "example": "risk"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                }
+            }
+        },
+        "models.ObligationAccessLog": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "username": {
+                    "description": "empty for reads without authentication",
+                    "type": "string",
+                    "example": "fossy"
+                }
+            }
+        },
+        "models.ObligationAccessLogResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationAccessLog"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationAuditBatchRequest": {
+            "type": "object",
+            "required": [
+                "topics"
+            ],
+            "properties": {
+                "topics": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft",
+                        "patent-grant"
+                    ]
+                }
+            }
+        },
+        "models.ObligationAuditBatchResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationAudits"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "unknown_topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "no-such-topic"
+                    ]
+                }
+            }
+        },
+        "models.ObligationAuditReport": {
+            "type": "object",
+            "properties": {
+                "audits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationAuditReportEntry"
+                    }
+                },
+                "generated_at": {
+                    "type": "string",
+                    "example": "2024-12-01T18:10:25Z"
+                },
+                "generated_by": {
+                    "type": "string",
+                    "example": "fossy"
+                },
+                "obligation_id": {
+                    "type": "integer",
+                    "example": 147
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationAuditReportEntry": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "moved to the archive by the retention job",
+                    "type": "boolean",
+                    "example": false
+                },
+                "audit_id": {
+                    "type": "integer",
+                    "example": 456
+                },
+                "change_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChangeLog"
+                    }
+                },
+                "change_reason": {
+                    "type": "string",
+                    "example": "Aligned the text with the license"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "username": {
+                    "type": "string",
+                    "example": "fossy"
+                }
+            }
+        },
+        "models.ObligationAuditReportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationAuditReport"
+                },
+                "signature": {
+                    "$ref": "#/definitions/models.ReportSignature"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationAuditSummary": {
+            "type": "object",
+            "properties": {
+                "field_change_counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "last_changed_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "last_changed_by": {
+                    "type": "string",
+                    "example": "fossy"
+                },
+                "total_audits": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.ObligationAuditSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationAuditSummary"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationAudits": {
+            "type": "object",
+            "properties": {
+                "audits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Audit"
+                    }
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationClassification": {
+            "type": "object",
+            "properties": {
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "red"
+                },
+                "matched_keywords": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "patent"
+                    ]
+                },
+                "scores": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.ObligationClassificationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationClassification"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationClassifyInput": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                }
+            }
+        },
+        "models.ObligationConflictError": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "description": "only if the topic exists with a different text, which the user may read",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationFieldDiff"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Error: Obligation with topic 'copyleft' or Text 'Source cod'... already exists"
+                },
+                "existing": {
+                    "$ref": "#/definitions/models.ObligationConflictExisting"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "can not create obligation with same topic or text"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/obligations"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 409
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T10:00:51+05:30"
+                }
+            }
+        },
+        "models.ObligationConflictExisting": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationDeactivateInput": {
+            "type": "object",
+            "required": [
+                "topics"
+            ],
+            "properties": {
+                "change_reason": {
+                    "type": "string",
+                    "example": "Retired with the license cleanup"
+                },
+                "topics": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft",
+                        "patent-grant"
+                    ]
+                }
+            }
+        },
+        "models.ObligationDeactivateResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationDeactivateResult"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationDeactivateResult": {
+            "type": "object",
+            "properties": {
+                "already_inactive": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "patent-grant"
+                    ]
+                },
+                "deactivated": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft"
+                    ]
+                },
+                "locked": {
+                    "description": "locked by another user, left active",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "under-review"
+                    ]
+                },
+                "not_found": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "unknown-topic"
+                    ]
+                }
+            }
+        },
+        "models.ObligationDryRunResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Obligation"
+                    }
+                },
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "conflict",
+                        "unknown_shortnames"
+                    ],
+                    "example": "created"
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "unknown_shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only"
+                    ]
+                }
+            }
+        },
+        "models.ObligationDuplicateCheckInput": {
+            "type": "object",
+            "required": [
+                "text",
+                "topic"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationDuplicateCheckResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationDuplicateCheckResult"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationDuplicateCheckResult": {
+            "type": "object",
+            "properties": {
+                "existing_topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "text_exists": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "topic_exists": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ObligationEditableFieldsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    },
+                    "example": {
+                        "comment": true,
+                        "text": false
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationExportEnvelope": {
+            "type": "object",
+            "properties": {
+                "exported_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "manifest": {
+                    "$ref": "#/definitions/models.ObligationExportManifest"
+                },
+                "obligations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationJSONFileFormat"
+                    }
+                },
+                "schema_version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ObligationExportManifest": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "sha256": {
+                    "description": "hex encoded",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                }
+            }
+        },
+        "models.ObligationFieldDiff": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "Classification"
+                },
+                "new_value": {
+                    "type": "string",
+                    "example": "red"
+                },
+                "old_value": {
+                    "type": "string",
+                    "example": "green"
+                }
+            }
+        },
+        "models.ObligationGraph": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationGraphEdge"
+                    }
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationGraphNode"
+                    }
+                }
+            }
+        },
+        "models.ObligationGraphEdge": {
+            "type": "object",
+            "properties": {
+                "source": {
+                    "type": "string",
+                    "example": "obligation:147"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "license:123"
+                }
+            }
+        },
+        "models.ObligationGraphNode": {
+            "type": "object",
+            "properties": {
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "obligation:147"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "license"
+                    ],
+                    "example": "obligation"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.ObligationGraphResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationGraph"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationId": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationImportStatus": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "skipped",
+                        "overwritten",
+                        "merged"
+                    ],
+                    "example": "created"
+                },
+                "data": {
+                    "$ref": "#/definitions/models.ObligationId"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationJSONFileFormat": {
+            "type": "object",
+            "required": [
+                "active",
+                "classification",
+                "comment",
+                "modifications",
+                "shortnames",
+                "text",
+                "text_updatable",
+                "topic",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ]
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "This is a comment."
+                },
+                "created_at": {
+                    "description": "admin only, to keep the creation date of migrated obligations",
+                    "type": "string",
+                    "example": "2019-06-01T00:00:00Z"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "effective_until": {
+                    "type": "string",
+                    "example": "2024-12-31T23:59:59Z"
+                },
+                "modifications": {
+                    "type": "boolean"
+                },
+                "sensitive": {
+                    "type": "boolean"
+                },
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "text_updatable": {
+                    "type": "boolean"
+                },
+                "topic": {
+                    "description": "binding:\"required\" tag cannot be used as is works only for request body",
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.ObligationLengthBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "min": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "models.ObligationLengthDistributionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationLengthBucket"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationLicense": {
+            "type": "object",
+            "properties": {
+                "fullname": {
+                    "type": "string",
+                    "example": "MIT License"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "shortname": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "spdx_id": {
+                    "type": "string",
+                    "example": "MIT"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://opensource.org/licenses/MIT"
+                }
+            }
+        },
+        "models.ObligationLint": {
+            "type": "object",
+            "properties": {
+                "empty_classification": {
+                    "$ref": "#/definitions/models.ObligationLintCategory"
+                },
+                "inactive_mapped_to_active_licenses": {
+                    "$ref": "#/definitions/models.ObligationLintCategory"
+                },
+                "short_text": {
+                    "$ref": "#/definitions/models.ObligationLintCategory"
+                },
+                "topic_is_text_hash": {
+                    "$ref": "#/definitions/models.ObligationLintCategory"
+                },
+                "unmapped": {
+                    "$ref": "#/definitions/models.ObligationLintCategory"
+                }
+            }
+        },
+        "models.ObligationLintCategory": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft"
+                    ]
+                }
+            }
+        },
+        "models.ObligationLintResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationLint"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationLock": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:40:25.00+05:30"
+                },
+                "locked_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "models.ObligationLockResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationLock"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationMapImportInput": {
+            "type": "object",
+            "required": [
+                "maps"
+            ],
+            "properties": {
+                "maps": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationMapPair"
+                    }
+                }
+            }
+        },
+        "models.ObligationMapImportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ObligationMapImportResult"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationMapImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "existing": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "unresolved_shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Unknown-1.0"
+                    ]
+                },
+                "unresolved_topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "unknown-topic"
+                    ]
+                }
+            }
+        },
+        "models.ObligationMapPair": {
+            "type": "object",
+            "required": [
+                "shortname",
+                "topic"
+            ],
+            "properties": {
+                "shortname": {
+                    "type": "string",
+                    "example": "GPL-2.0-only"
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                }
+            }
+        },
+        "models.ObligationMapResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationMapUser"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationMapUser": {
+            "type": "object",
+            "properties": {
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ],
+                    "example": "obligation"
+                }
+            }
+        },
+        "models.ObligationMappingHealth": {
+            "type": "object",
+            "properties": {
+                "fully_mapped": {
+                    "$ref": "#/definitions/models.ObligationMappingHealthCategory"
+                },
+                "inactive_licenses": {
+                    "$ref": "#/definitions/models.ObligationMappingHealthCategory"
+                },
+                "missing_licenses": {
+                    "$ref": "#/definitions/models.ObligationMappingHealthCategory"
+                },
+                "unmapped": {
+                    "$ref": "#/definitions/models.ObligationMappingHealthCategory"
+                }
+            }
+        },
+        "models.ObligationMappingHealthCategory": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft"
+                    ]
+                }
+            }
+        },
+        "models.ObligationMappingHealthResponse": {
             "type": "object",
             "properties": {
-                "authenticated": {
-                    "type": "object"
+                "data": {
+                    "$ref": "#/definitions/models.ObligationMappingHealth"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationNotFoundError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "record not found"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "obligation with topic 'coplyeft' not found"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/obligations/coplyeft"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft"
+                    ]
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T10:00:51+05:30"
+                }
+            }
+        },
+        "models.ObligationNote": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Checked with legal, applies to binaries as well."
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "models.ObligationNoteInput": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Checked with legal, applies to binaries as well."
+                }
+            }
+        },
+        "models.ObligationNoteResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationNote"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationPATCHRequestJSONSchema": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "change_reason": {
+                    "type": "string",
+                    "example": "Aligned the text with the license"
+                },
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ]
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "This is a comment."
+                },
+                "effective_from": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "effective_until": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-12-31T23:59:59Z"
+                },
+                "modifications": {
+                    "type": "boolean"
+                },
+                "sensitive": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "publishing is done by a reviewer with the publish endpoint",
+                    "type": "string",
+                    "enum": [
+                        "DRAFT",
+                        "IN_REVIEW"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "text_updatable": {
+                    "type": "boolean"
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.ObligationPOSTRequestJSONSchema": {
+            "type": "object",
+            "required": [
+                "active",
+                "classification",
+                "comment",
+                "modifications",
+                "text",
+                "topic",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ]
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "admin only, to keep the creation date of migrated obligations",
+                    "type": "string",
+                    "example": "2019-06-01T00:00:00Z"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "effective_until": {
+                    "type": "string",
+                    "example": "2024-12-31T23:59:59Z"
+                },
+                "expected_md5": {
+                    "description": "md5 of the canonical text, verified by the server",
+                    "type": "string",
+                    "example": "5d41402abc4b2a76b9719d911017c592"
+                },
+                "modifications": {
+                    "type": "boolean"
+                },
+                "sensitive": {
+                    "type": "boolean"
+                },
+                "shortnames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only",
+                        "GPL-2.0-or-later"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Source code be made available when distributing the software."
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.ObligationPreview": {
+            "type": "object",
+            "properties": {
+                "topic": {
+                    "type": "string",
+                    "example": "Provide Copyright Notices"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
+                }
+            }
+        },
+        "models.ObligationPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationPreview"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationRecentChange": {
+            "type": "object",
+            "properties": {
+                "audits": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "changed_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Text",
+                        "Classification"
+                    ]
+                },
+                "last_changed_at": {
+                    "type": "string",
+                    "example": "2024-05-01T10:00:00Z"
                 },
-                "unAuthenticated": {
-                    "type": "object"
+                "obligation": {
+                    "$ref": "#/definitions/models.Obligation"
                 }
             }
         },
-        "models.APICollectionResponse": {
+        "models.ObligationRecentChangesResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.APICollection"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationRecentChange"
+                    }
+                },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
                 },
                 "status": {
                     "type": "integer",
@@ -1710,52 +7821,69 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Audit": {
+        "models.ObligationReclassifyFilter": {
             "type": "object",
             "properties": {
-                "entity": {
-                    "type": "object"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 456
+                "active": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "timestamp": {
+                "classification": {
                     "type": "string",
-                    "example": "2023-12-01T18:10:25.00+05:30"
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "red"
+                },
+                "modifications": {
+                    "type": "boolean",
+                    "example": false
                 },
                 "type": {
                     "type": "string",
                     "enum": [
                         "obligation",
-                        "license"
+                        "restriction",
+                        "risk",
+                        "right"
                     ],
-                    "example": "license"
-                },
-                "type_id": {
-                    "type": "integer",
-                    "example": 34
+                    "example": "risk"
+                }
+            }
+        },
+        "models.ObligationReclassifyInput": {
+            "type": "object",
+            "required": [
+                "classification"
+            ],
+            "properties": {
+                "change_reason": {
+                    "type": "string",
+                    "example": "Re-leveled the risk of unmodified red obligations"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "yellow"
                 },
-                "user_id": {
-                    "type": "integer",
-                    "example": 123
+                "filter": {
+                    "$ref": "#/definitions/models.ObligationReclassifyFilter"
                 }
             }
         },
-        "models.AuditResponse": {
+        "models.ObligationReclassifyResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Audit"
-                    }
-                },
-                "paginationmeta": {
-                    "$ref": "#/definitions/models.PaginationMeta"
+                    "$ref": "#/definitions/models.ObligationReclassifyResult"
                 },
                 "status": {
                     "type": "integer",
@@ -1763,38 +7891,47 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ChangeLog": {
+        "models.ObligationReclassifyResult": {
             "type": "object",
             "properties": {
-                "audit_id": {
-                    "type": "integer",
-                    "example": 456
-                },
-                "field": {
+                "classification": {
                     "type": "string",
-                    "example": "text"
+                    "example": "yellow"
                 },
-                "id": {
+                "count": {
                     "type": "integer",
-                    "example": 789
+                    "example": 2
                 },
-                "old_value": {
-                    "type": "string",
-                    "example": "Old license text"
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "updated_value": {
-                    "type": "string",
-                    "example": "New license text"
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "copyleft",
+                        "patent-grant"
+                    ]
                 }
             }
         },
-        "models.ChangeLogResponse": {
+        "models.ObligationResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ChangeLog"
+                        "$ref": "#/definitions/models.Obligation"
+                    }
+                },
+                "included": {
+                    "description": "the deduplicated licenses by id",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.ObligationLicense"
                     }
                 },
                 "paginationmeta": {
@@ -1806,27 +7943,17 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ImportLicensesResponse": {
+        "models.ObligationSearchResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "description": "can be of type models.LicenseError or models.LicenseImportStatus",
                     "type": "array",
-                    "items": {}
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationSearchResult"
+                    }
                 },
-                "status": {
-                    "type": "integer",
-                    "example": 200
-                }
-            }
-        },
-        "models.ImportObligationsResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "description": "can be of type models.LicenseError or models.ObligationImportStatus",
-                    "type": "array",
-                    "items": {}
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
                 },
                 "status": {
                     "type": "integer",
@@ -1834,195 +7961,195 @@ const docTemplate = `{
                 }
             }
         },
-        "models.LicenseDB": {
+        "models.ObligationSearchResult": {
             "type": "object",
-            "required": [
-                "fullname",
-                "shortname",
-                "spdx_id",
-                "text"
-            ],
             "properties": {
-                "FSFfree": {
-                    "type": "boolean"
-                },
-                "Fedora": {
-                    "type": "string"
-                },
-                "GPLv2compatible": {
-                    "type": "boolean"
-                },
-                "GPLv3compatible": {
+                "active": {
                     "type": "boolean"
                 },
-                "OSIapproved": {
-                    "type": "boolean"
+                "classification": {
+                    "type": "string",
+                    "enum": [
+                        "green",
+                        "white",
+                        "yellow",
+                        "red"
+                    ],
+                    "example": "green"
                 },
-                "active": {
-                    "type": "boolean"
+                "comment": {
+                    "type": "string"
                 },
-                "add_date": {
+                "created_at": {
                     "type": "string",
                     "example": "2023-12-01T18:10:25.00+05:30"
                 },
-                "copyleft": {
-                    "type": "boolean"
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
                 },
-                "detector_type": {
-                    "type": "integer",
-                    "maximum": 2,
-                    "minimum": 0,
-                    "example": 1
+                "effective_until": {
+                    "type": "string",
+                    "example": "2024-12-31T23:59:59Z"
                 },
-                "external_ref": {
-                    "$ref": "#/definitions/datatypes.JSONType-models_LicenseDBSchemaExtension"
+                "highlight": {
+                    "type": "string",
+                    "example": "... \u003cmark\u003esource\u003c/mark\u003e \u003cmark\u003ecode\u003c/mark\u003e be made available ..."
                 },
-                "flag": {
+                "id": {
                     "type": "integer",
-                    "maximum": 2,
-                    "minimum": 0,
-                    "example": 1
+                    "example": 147
                 },
-                "fullname": {
-                    "type": "string",
-                    "example": "MIT License"
+                "license_count": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "marydone": {
-                    "type": "boolean"
+                "license_ids": {
+                    "description": "only set when the expanded licenses are deduplicated",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "notes": {
-                    "type": "string",
-                    "example": "This license has been superseded."
+                "licenses": {
+                    "description": "only set when the licenses are expanded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationLicense"
+                    }
                 },
-                "risk": {
-                    "type": "integer",
-                    "maximum": 5,
-                    "minimum": 0
+                "lock": {
+                    "description": "only set when a single obligation is read",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ObligationLock"
+                        }
+                    ]
                 },
-                "shortname": {
-                    "type": "string",
-                    "example": "MIT"
+                "modifications": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "source": {
-                    "type": "string"
+                "sensitive": {
+                    "description": "reads are logged when the read audit is enabled",
+                    "type": "boolean"
                 },
-                "spdx_id": {
+                "status": {
                     "type": "string",
-                    "example": "MIT"
+                    "enum": [
+                        "DRAFT",
+                        "IN_REVIEW",
+                        "PUBLISHED"
+                    ],
+                    "example": "PUBLISHED"
                 },
                 "text": {
                     "type": "string",
-                    "example": "MIT License Text here"
+                    "example": "Source code be made available when distributing the software."
                 },
                 "text_updatable": {
-                    "type": "boolean"
-                },
-                "url": {
-                    "type": "string",
-                    "example": "https://opensource.org/licenses/MIT"
-                }
-            }
-        },
-        "models.LicenseError": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "invalid request body"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "invalid request body"
+                    "type": "boolean",
+                    "example": true
                 },
-                "path": {
+                "topic": {
                     "type": "string",
-                    "example": "/api/v1/licenses"
-                },
-                "status": {
-                    "type": "integer",
-                    "example": 400
+                    "example": "copyleft"
                 },
-                "timestamp": {
+                "type": {
                     "type": "string",
-                    "example": "2023-12-01T10:00:51+05:30"
-                }
-            }
-        },
-        "models.LicenseId": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer",
-                    "example": 31
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ],
+                    "example": "risk"
                 },
-                "shortname": {
+                "updated_at": {
                     "type": "string",
-                    "example": "MIT"
+                    "example": "2023-12-01T18:10:25.00+05:30"
                 }
             }
         },
-        "models.LicenseImportStatus": {
+        "models.ObligationSimilarity": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.LicenseId"
+                "score": {
+                    "type": "number",
+                    "example": 0.97
                 },
-                "status": {
-                    "type": "integer",
-                    "example": 200
-                }
-            }
-        },
-        "models.LicenseMapShortnamesElement": {
-            "type": "object",
-            "properties": {
-                "add": {
-                    "type": "boolean",
-                    "example": true
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
                 },
-                "shortname": {
+                "type": {
                     "type": "string",
-                    "example": "GPL-2.0-only"
+                    "enum": [
+                        "obligation",
+                        "restriction",
+                        "risk",
+                        "right"
+                    ]
                 }
             }
         },
-        "models.LicenseMapShortnamesInput": {
+        "models.ObligationSimilarityResponse": {
             "type": "object",
             "properties": {
-                "map": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.LicenseMapShortnamesElement"
+                        "$ref": "#/definitions/models.ObligationSimilarity"
                     }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
                 }
             }
         },
-        "models.LicensePreviewResponse": {
+        "models.ObligationSnapshot": {
             "type": "object",
             "properties": {
-                "shortnames": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "GPL-2.0-only",
-                        "GPL-2.0-or-later"
-                    ]
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-01T18:10:25.00+05:30"
                 },
-                "status": {
+                "id": {
                     "type": "integer",
-                    "example": 200
+                    "example": 7
+                },
+                "obligation": {
+                    "$ref": "#/definitions/models.Obligation"
+                },
+                "obligation_id": {
+                    "type": "integer",
+                    "example": 147
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "publish",
+                        "manual"
+                    ],
+                    "example": "publish"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
                 }
             }
         },
-        "models.LicenseResponse": {
+        "models.ObligationSnapshotResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.LicenseDB"
+                        "$ref": "#/definitions/models.ObligationSnapshot"
                     }
                 },
                 "paginationmeta": {
@@ -2034,112 +8161,62 @@ const docTemplate = `{
                 }
             }
         },
-        "models.LicenseShortnamesInput": {
+        "models.ObligationTagAssignInput": {
             "type": "object",
+            "required": [
+                "tag"
+            ],
             "properties": {
-                "shortnames": {
+                "filter": {
+                    "$ref": "#/definitions/models.ObligationTagFilter"
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "needs-review"
+                },
+                "topics": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "GPL-2.0-only",
-                        "GPL-2.0-or-later"
+                        "copyleft",
+                        "patent-grant"
                     ]
                 }
             }
         },
-        "models.LicenseUpdateJSONSchema": {
+        "models.ObligationTagAssignResponse": {
             "type": "object",
             "properties": {
-                "FSFfree": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "Fedora": {
-                    "type": "string",
-                    "example": "Fedora"
-                },
-                "GPLv2compatible": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "GPLv3compatible": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "OSIapproved": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "active": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "copyleft": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "detector_type": {
-                    "type": "integer",
-                    "maximum": 2,
-                    "minimum": 0,
-                    "example": 1
-                },
-                "external_ref": {
-                    "$ref": "#/definitions/datatypes.JSONType-models_LicenseDBSchemaExtension"
+                "data": {
+                    "$ref": "#/definitions/models.ObligationTagAssignResult"
                 },
-                "flag": {
+                "status": {
                     "type": "integer",
-                    "maximum": 2,
-                    "minimum": 0,
-                    "example": 1
-                },
-                "fullname": {
-                    "type": "string",
-                    "example": "MIT License"
-                },
-                "marydone": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "notes": {
-                    "type": "string",
-                    "example": "This license has been superseded."
-                },
-                "risk": {
+                    "example": 200
+                }
+            }
+        },
+        "models.ObligationTagAssignResult": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer",
-                    "maximum": 5,
-                    "minimum": 0,
-                    "example": 1
-                },
-                "source": {
-                    "type": "string",
-                    "example": "Source"
-                },
-                "spdx_id": {
-                    "type": "string",
-                    "example": "MIT"
-                },
-                "text": {
-                    "type": "string",
-                    "example": "MIT License Text here"
+                    "example": 12
                 },
-                "text_updatable": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "url": {
+                "tag": {
                     "type": "string",
-                    "example": "https://opensource.org/licenses/MIT"
+                    "example": "needs-review"
                 }
             }
         },
-        "models.Obligation": {
+        "models.ObligationTagFilter": {
             "type": "object",
             "properties": {
                 "active": {
-                    "type": "boolean"
+                    "type": "boolean",
+                    "example": true
                 },
                 "classification": {
                     "type": "string",
@@ -2151,29 +8228,6 @@ const docTemplate = `{
                     ],
                     "example": "green"
                 },
-                "comment": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 147
-                },
-                "modifications": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "text": {
-                    "type": "string",
-                    "example": "Source code be made available when distributing the software."
-                },
-                "text_updatable": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "topic": {
-                    "type": "string",
-                    "example": "copyleft"
-                },
                 "type": {
                     "type": "string",
                     "enum": [
@@ -2186,12 +8240,40 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ObligationId": {
+        "models.ObligationTextMatch": {
             "type": "object",
             "properties": {
-                "id": {
+                "length": {
                     "type": "integer",
-                    "example": 31
+                    "example": 11
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "...be made available when distributing..."
+                },
+                "snippet_offset": {
+                    "description": "offset of the match in the snippet",
+                    "type": "integer",
+                    "example": 18
+                }
+            }
+        },
+        "models.ObligationTextMatches": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ObligationTextMatch"
+                    }
+                },
+                "query": {
+                    "type": "string",
+                    "example": "distributing"
                 },
                 "topic": {
                     "type": "string",
@@ -2199,11 +8281,11 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ObligationImportStatus": {
+        "models.ObligationTextMatchesResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.ObligationId"
+                    "$ref": "#/definitions/models.ObligationTextMatches"
                 },
                 "status": {
                     "type": "integer",
@@ -2211,79 +8293,46 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ObligationJSONFileFormat": {
+        "models.ObligationTranslation": {
             "type": "object",
-            "required": [
-                "active",
-                "classification",
-                "comment",
-                "modifications",
-                "shortnames",
-                "text",
-                "text_updatable",
-                "topic",
-                "type"
-            ],
             "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "classification": {
-                    "type": "string",
-                    "enum": [
-                        "green",
-                        "white",
-                        "yellow",
-                        "red"
-                    ]
-                },
-                "comment": {
-                    "type": "string",
-                    "example": "This is a comment."
-                },
-                "modifications": {
-                    "type": "boolean"
-                },
-                "shortnames": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "GPL-2.0-only",
-                        "GPL-2.0-or-later"
-                    ]
-                },
-                "text": {
+                "created_at": {
                     "type": "string",
-                    "example": "Source code be made available when distributing the software."
+                    "example": "2023-12-01T18:10:25.00+05:30"
                 },
-                "text_updatable": {
-                    "type": "boolean"
+                "language": {
+                    "type": "string",
+                    "example": "de"
                 },
-                "topic": {
-                    "description": "binding:\"required\" tag cannot be used as is works only for request body",
+                "text": {
                     "type": "string",
-                    "example": "copyleft"
+                    "example": "Der Quellcode muss bei der Weitergabe der Software zur Verfügung gestellt werden."
                 },
-                "type": {
+                "updated_at": {
                     "type": "string",
-                    "enum": [
-                        "obligation",
-                        "restriction",
-                        "risk",
-                        "right"
-                    ]
+                    "example": "2023-12-01T18:10:25.00+05:30"
                 }
             }
         },
-        "models.ObligationMapResponse": {
+        "models.ObligationTranslationInput": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Der Quellcode muss bei der Weitergabe der Software zur Verfügung gestellt werden."
+                }
+            }
+        },
+        "models.ObligationTranslationResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ObligationMapUser"
+                        "$ref": "#/definitions/models.ObligationTranslation"
                     }
                 },
                 "paginationmeta": {
@@ -2295,92 +8344,33 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ObligationMapUser": {
+        "models.ObligationTypesResponse": {
             "type": "object",
             "properties": {
-                "shortnames": {
+                "data": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "GPL-2.0-only",
-                        "GPL-2.0-or-later"
-                    ]
-                },
-                "topic": {
-                    "type": "string",
-                    "example": "copyleft"
-                },
-                "type": {
-                    "type": "string",
-                    "enum": [
                         "obligation",
                         "restriction",
                         "risk",
                         "right"
-                    ],
-                    "example": "obligation"
-                }
-            }
-        },
-        "models.ObligationPATCHRequestJSONSchema": {
-            "type": "object",
-            "properties": {
-                "active": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "classification": {
-                    "type": "string",
-                    "enum": [
-                        "green",
-                        "white",
-                        "yellow",
-                        "red"
                     ]
                 },
-                "comment": {
-                    "type": "string",
-                    "example": "This is a comment."
-                },
-                "modifications": {
-                    "type": "boolean"
-                },
-                "text": {
-                    "type": "string",
-                    "example": "Source code be made available when distributing the software."
-                },
-                "text_updatable": {
-                    "type": "boolean"
-                },
-                "type": {
-                    "type": "string",
-                    "enum": [
-                        "obligation",
-                        "restriction",
-                        "risk",
-                        "right"
-                    ]
+                "status": {
+                    "type": "integer",
+                    "example": 200
                 }
             }
         },
-        "models.ObligationPOSTRequestJSONSchema": {
+        "models.ObligationUsage": {
             "type": "object",
-            "required": [
-                "active",
-                "classification",
-                "comment",
-                "modifications",
-                "shortnames",
-                "text",
-                "topic",
-                "type"
-            ],
             "properties": {
-                "active": {
-                    "type": "boolean",
-                    "example": true
+                "active_licenses": {
+                    "type": "integer",
+                    "example": 12
                 },
                 "classification": {
                     "type": "string",
@@ -2389,27 +8379,8 @@ const docTemplate = `{
                         "white",
                         "yellow",
                         "red"
-                    ]
-                },
-                "comment": {
-                    "type": "string"
-                },
-                "modifications": {
-                    "type": "boolean"
-                },
-                "shortnames": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "GPL-2.0-only",
-                        "GPL-2.0-or-later"
-                    ]
-                },
-                "text": {
-                    "type": "string",
-                    "example": "Source code be made available when distributing the software."
+                    ],
+                    "example": "green"
                 },
                 "topic": {
                     "type": "string",
@@ -2422,50 +8393,36 @@ const docTemplate = `{
                         "restriction",
                         "risk",
                         "right"
-                    ]
-                }
-            }
-        },
-        "models.ObligationPreview": {
-            "type": "object",
-            "properties": {
-                "topic": {
-                    "type": "string",
-                    "example": "Provide Copyright Notices"
-                },
-                "type": {
-                    "type": "string",
-                    "enum": [
-                        "obligation",
-                        "restriction",
-                        "risk",
-                        "right"
-                    ]
+                    ],
+                    "example": "risk"
                 }
             }
         },
-        "models.ObligationPreviewResponse": {
+        "models.ObligationUsageResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ObligationPreview"
+                        "$ref": "#/definitions/models.ObligationUsage"
                     }
                 },
+                "paginationmeta": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                },
                 "status": {
                     "type": "integer",
                     "example": 200
                 }
             }
         },
-        "models.ObligationResponse": {
+        "models.ObligationValidationResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Obligation"
+                        "$ref": "#/definitions/models.ObligationValidationResult"
                     }
                 },
                 "paginationmeta": {
@@ -2474,6 +8431,33 @@ const docTemplate = `{
                 "status": {
                     "type": "integer",
                     "example": 200
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "models.ObligationValidationResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "copyleft"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
@@ -2506,6 +8490,55 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ReportSignature": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string",
+                    "example": "HMAC-SHA256"
+                },
+                "value": {
+                    "description": "hex encoded",
+                    "type": "string",
+                    "example": "3f0b6c2e..."
+                }
+            }
+        },
+        "models.ResolvedLicenseExpression": {
+            "type": "object",
+            "properties": {
+                "expression": {
+                    "$ref": "#/definitions/models.LicenseExpressionNode"
+                },
+                "obligations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LicenseObligationExport"
+                    }
+                },
+                "unknown_licenses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GPL-2.0-only"
+                    ]
+                }
+            }
+        },
+        "models.ResolvedLicenseExpressionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ResolvedLicenseExpression"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
         "models.SearchLicense": {
             "type": "object",
             "required": [
@@ -2530,6 +8563,35 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SearchObligation": {
+            "type": "object",
+            "required": [
+                "field",
+                "search_term"
+            ],
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "enum": [
+                        "topic",
+                        "text",
+                        "comment"
+                    ],
+                    "example": "text"
+                },
+                "search": {
+                    "type": "string",
+                    "enum": [
+                        "fuzzy",
+                        "full_text_search"
+                    ]
+                },
+                "search_term": {
+                    "type": "string",
+                    "example": "source code"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "required": [
@@ -2607,6 +8669,37 @@ const docTemplate = `{
                     "example": 200
                 }
             }
+        },
+        "models.ValidationError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Key: 'ObligationPOSTRequestJSONSchema.Topic' Error:Field validation for 'Topic' failed on the 'required' tag"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid json body"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/obligations"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T10:00:51+05:30"
+                }
+            }
         }
     },
     "securityDefinitions": {
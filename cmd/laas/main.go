@@ -9,6 +9,8 @@ package main
 import (
 	"flag"
 	"log"
+	"net/http"
+	"os"
 
 	"github.com/joho/godotenv"
 
@@ -35,6 +37,16 @@ var (
 	datafile = flag.String("datafile", "licenseRef.json", "datafile path")
 	// auto-update the database
 	populatedb = flag.Bool("populatedb", false, "boolean variable to update database")
+	// list the pending database migrations without applying them
+	migrateDryRun = flag.Bool("migrate-dry-run", false, "list the pending database migrations and exit")
+	// apply the database migrations without serving the API
+	migrateOnly = flag.Bool("migrate-only", false, "apply the database migrations and exit")
+	// seed the database with licenses and obligations of a fixture
+	seed = flag.Bool("seed", false, "seed the database with the obligation fixture and exit")
+	// path of the fixture to seed, the bundled fixture is seeded if empty
+	seedFile = flag.String("seed-file", "", "fixture file path, defaults to the bundled fixture")
+	// delete the obligations before seeding
+	seedReset = flag.Bool("seed-reset", false, "delete all obligations before seeding")
 )
 
 func main() {
@@ -48,6 +60,18 @@ func main() {
 
 	db.Connect(dbhost, port, user, dbname, password)
 
+	if *migrateDryRun {
+		pending, err := db.PendingMigrations()
+		if err != nil {
+			log.Fatalf("Failed to list pending migrations: %v", err)
+		}
+		for _, migration := range pending {
+			log.Printf("Pending migration %d: %s", migration.Version, migration.Description)
+		}
+		log.Printf("%d pending migrations", len(pending))
+		return
+	}
+
 	if err := db.DB.AutoMigrate(&models.LicenseDB{}); err != nil {
 		log.Fatalf("Failed to automigrate database: %v", err)
 	}
@@ -64,6 +88,10 @@ func main() {
 		log.Fatalf("Failed to automigrate database: %v", err)
 	}
 
+	if err := db.DB.AutoMigrate(&models.ArchivedAudit{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
 	if err := db.DB.AutoMigrate(&models.Obligation{}); err != nil {
 		log.Fatalf("Failed to automigrate database: %v", err)
 	}
@@ -72,13 +100,79 @@ func main() {
 		log.Fatalf("Failed to automigrate database: %v", err)
 	}
 
+	if err := db.DB.AutoMigrate(&models.ObligationTranslation{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationNote{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationTag{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ClassificationRule{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationWatch{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationTopicRedirect{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationAccessLog{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationCreationCount{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationSnapshot{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.DB.AutoMigrate(&models.ObligationLock{}); err != nil {
+		log.Fatalf("Failed to automigrate database: %v", err)
+	}
+
+	if err := db.RunMigrations(); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	db.EncryptObligationComments()
+
+	if *migrateOnly {
+		return
+	}
+
+	if *seed {
+		fixture := api.DefaultObligationFixture
+		if *seedFile != "" {
+			if fixture, err = os.ReadFile(*seedFile); err != nil {
+				log.Fatalf("Unable to read fixture file: %v", err)
+			}
+		}
+		if err := api.SeedObligations(fixture, *seedReset); err != nil {
+			log.Fatalf("Failed to seed database: %v", err)
+		}
+		return
+	}
+
 	if *populatedb {
 		db.Populatedb(*datafile)
 	}
 
-	r := api.Router()
+	serverPort := os.Getenv("PORT")
+	if len(serverPort) == 0 {
+		serverPort = api.DEFAULT_PORT
+	}
 
-	if err := r.Run(); err != nil {
+	if err := http.ListenAndServe(":"+serverPort, api.Handler()); err != nil {
 		log.Fatalf("Error while running the server: %v", err)
 	}
 }
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// ObligationReadAuditEnabled reports whether the reads of sensitive obligations are
+// logged. By default it reads OBLIGATION_READ_AUDIT_ENABLED, which is off unless set
+// to true, and it can be replaced in tests.
+var ObligationReadAuditEnabled = func() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("OBLIGATION_READ_AUDIT_ENABLED"))
+	return err == nil && enabled
+}
+
+// logObligationAccess records the read of the obligation by the user of the request
+// if the obligation is sensitive and the read audit is enabled. HEAD requests are not
+// logged, as they do not get the obligation.
+func logObligationAccess(c *gin.Context, obligation *models.Obligation) error {
+	if !obligation.Sensitive || !ObligationReadAuditEnabled() || c.Request.Method == http.MethodHead {
+		return nil
+	}
+	entry := models.ObligationAccessLog{
+		ObligationPk: obligation.Id,
+		Topic:        obligation.Topic,
+		Username:     c.GetString("username"),
+		Timestamp:    time.Now(),
+	}
+	return db.DB.WithContext(c).Omit("Obligation").Create(&entry).Error
+}
+
+// GetObligationAccessLog retrieves the reads of an obligation
+//
+//	@Summary		Get access log of an obligation
+//	@Description	Get who read a sensitive obligation and when, latest first. Reads are only logged
+//	@Description	when OBLIGATION_READ_AUDIT_ENABLED is set.
+//	@Id				GetObligationAccessLog
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Success		200		{object}	models.ObligationAccessLogResponse
+//	@Failure		401		{object}	models.LicenseError	"Authentication needed"
+//	@Failure		403		{object}	models.LicenseError	"User is not an admin"
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch access log"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/access-log [get]
+func GetObligationAccessLog(c *gin.Context) {
+	var obligation models.Obligation
+	topic := c.Param("topic")
+	if err := db.DB.WithContext(c).Where(models.Obligation{Topic: topic}).Select("id").First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	var entries []models.ObligationAccessLog
+	query := db.DB.WithContext(c).Model(&models.ObligationAccessLog{}).
+		Where(models.ObligationAccessLog{ObligationPk: obligation.Id})
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationAccessLogResponse{})
+	if err := query.Order("timestamp DESC").Order("id DESC").Find(&entries).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch access log",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationAccessLogResponse{
+		Status: http.StatusOK,
+		Data:   entries,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(entries),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -13,9 +13,13 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"golang.org/x/exp/maps"
@@ -25,6 +29,7 @@ import (
 	"github.com/fossology/LicenseDb/pkg/db"
 	"github.com/fossology/LicenseDb/pkg/middleware"
 	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
 )
 
 // Router Get the gin router with all the routes defined
@@ -48,10 +53,74 @@ import (
 //	@description				Token from /login endpoint
 
 const (
-	DEFAULT_PORT                            = "8080"
-	DEFAULT_READ_API_AUTHENTICATION_ENABLED = false
+	DEFAULT_PORT                             = "8080"
+	DEFAULT_READ_API_AUTHENTICATION_ENABLED  = false
+	DEFAULT_AUDIT_RETENTION_DAYS             = 365
+	DEFAULT_MAX_AUDIT_CHANGELOGS             = 50
+	CHANGELOG_BATCH_SIZE                     = 100
+	AUDIT_ARCHIVE_BATCH_SIZE                 = 100
+	DEFAULT_MAX_OBLIGATION_SHORTNAMES        = 1000
+	MAX_DEACTIVATE_TOPICS                    = 500
+	OBLIGATION_MAP_BATCH_SIZE                = 100
+	MAX_DUPLICATE_CHECK_BATCH_SIZE           = 500
+	DEFAULT_EXPORT_RATE_LIMIT                = 10
+	DEFAULT_OBLIGATION_CREATION_QUOTA        = 100
+	DEFAULT_OBLIGATION_CREATION_QUOTA_ADMIN  = 0
+	DEFAULT_EXPORT_STATEMENT_TIMEOUT_SECONDS = 30
+	DEFAULT_REQUEST_TIMEOUT_SECONDS          = 30
+	DEFAULT_SIMILARITY_THRESHOLD             = 0.95
+	DEFAULT_SIMILARITY_MATCH_COUNT           = 5
+	SIMILARITY_BATCH_SIZE                    = 500
+	DEFAULT_LENGTH_BUCKETS                   = "50,500,2000"
+	MAX_LENGTH_BUCKETS                       = 20
+	DEFAULT_LINT_MIN_TEXT_LENGTH             = 20
+	API_V1_BASE_PATH                         = "/api/v1"
+	LICENSE_STUB_TEXT                        = "License text not yet available."
+	DEFAULT_COMPRESSION_ENABLED              = true
+	DEFAULT_COMPRESSION_MIN_SIZE             = 1024
+	TOPIC_SUGGESTION_COUNT                   = 3
+	MAX_TOPIC_SUGGESTION_CANDIDATES          = 5000
+	DEFAULT_RECENT_OBLIGATIONS_SINCE         = "7d"
+	DEFAULT_ERROR_VERBOSITY                  = "debug"
+	MAX_AUDIT_BATCH_TOPICS                   = 500
+	DEFAULT_OBLIGATION_ACTIVE                = "true"
+	OBLIGATION_ACTIVE_ALL                    = "all"
+	DEFAULT_OBLIGATION_LOCK_TTL              = "30m"
 )
 
+// PublicReadRoutes returns the read routes which are served without authentication
+// when READ_API_AUTHENTICATION_ENABLED is false. By default it reads the comma
+// separated PUBLIC_READ_ROUTES, e.g. /obligations,/licenses, where a route makes the
+// routes below it public as well. If it is not set, all the read routes are public,
+// and if it is empty, none. It can be replaced in tests.
+var PublicReadRoutes = func() []string {
+	value, ok := os.LookupEnv("PUBLIC_READ_ROUTES")
+	if !ok {
+		return []string{"*"}
+	}
+	var routes []string
+	for _, route := range strings.Split(value, ",") {
+		if route = strings.TrimSpace(route); route != "" {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// registerValidatorsOnce guards registerValidators, as the validator engine is shared
+// by all the routers.
+var registerValidatorsOnce sync.Once
+
+// registerValidators makes validation errors report json keys instead of struct
+// field names, and registers the custom validation tags.
+func registerValidators() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(utils.JSONTagName)
+		_ = v.RegisterValidation("obligation_type", utils.ValidateObligationType)
+		_ = v.RegisterValidation("obligation_topic", utils.ValidateObligationTopic)
+	}
+}
+
 func Router() *gin.Engine {
 
 	port := os.Getenv("PORT")
@@ -70,167 +139,248 @@ func Router() *gin.Engine {
 		authEnabled = DEFAULT_READ_API_AUTHENTICATION_ENABLED
 	}
 
-	// r is a default instance of gin engine
-	r := gin.Default()
+	registerValidatorsOnce.Do(registerValidators)
+
+	exportRateLimit, err := strconv.Atoi(os.Getenv("EXPORT_RATE_LIMIT"))
+	if err != nil || exportRateLimit <= 0 {
+		exportRateLimit = DEFAULT_EXPORT_RATE_LIMIT
+	}
+	exportRateLimiter := middleware.RateLimitMiddleware(exportRateLimit, time.Minute)
+
+	requestTimeout, err := strconv.Atoi(os.Getenv("REQUEST_TIMEOUT_SECONDS"))
+	if err != nil || requestTimeout < 0 {
+		requestTimeout = DEFAULT_REQUEST_TIMEOUT_SECONDS
+	}
+
+	compressionEnabled, err := strconv.ParseBool(os.Getenv("RESPONSE_COMPRESSION_ENABLED"))
+	if err != nil {
+		compressionEnabled = DEFAULT_COMPRESSION_ENABLED
+	}
+	compressionMinSize, err := strconv.Atoi(os.Getenv("RESPONSE_COMPRESSION_MIN_SIZE"))
+	if err != nil || compressionMinSize < 0 {
+		compressionMinSize = DEFAULT_COMPRESSION_MIN_SIZE
+	}
+	errorVerbosity := os.Getenv("ERROR_VERBOSITY")
+	if errorVerbosity == "" {
+		errorVerbosity = DEFAULT_ERROR_VERBOSITY
+	}
+
+	// r is an instance of gin engine with logger and JSON panic recovery
+	r := gin.New()
+	r.Use(gin.Logger(), middleware.RecoveryMiddleware())
+
+	// Paths with a trailing slash are not redirected, which would make clients
+	// drop the body and the headers of the request, see Handler
+	r.RedirectTrailingSlash = false
+	r.RedirectFixedPath = false
 
 	// return error for invalid routes
 	r.NoRoute(HandleInvalidUrl)
 
+	// return error for valid routes with an unsupported method
+	r.HandleMethodNotAllowed = true
+	r.NoMethod(HandleInvalidMethod)
+
 	// CORS middleware
 	r.Use(middleware.CORSMiddleware())
 
+	// Compression middleware, before the pagination middleware so it compresses
+	// the rewritten body
+	if compressionEnabled {
+		r.Use(middleware.GzipMiddleware(compressionMinSize))
+	}
+
 	// Pagination middleware
 	r.Use(middleware.PaginationMiddleware())
 
-	if authEnabled {
-		unAuthorizedv1 := r.Group("/api/v1")
-		{
-			health := unAuthorizedv1.Group("/health")
-			{
-				health.GET("", GetHealth)
-			}
-			login := unAuthorizedv1.Group("/login")
-			{
-				login.POST("", auth.Login)
-			}
-			apiCollection := unAuthorizedv1.Group("/apiCollection")
-			{
-				apiCollection.GET("", GetAPICollection)
-			}
+	// Internal errors are only shown to the clients in debug mode
+	r.Use(middleware.ErrorVerbosityMiddleware(errorVerbosity != "production"))
+
+	// Request timeout middleware, route groups may override the timeout
+	r.Use(middleware.TimeoutMiddleware(time.Duration(requestTimeout) * time.Second))
+
+	// Let the database queries run with the gin context use the request context
+	r.ContextWithFallback = true
+
+	// All the routes are served under the versioned base path. Endpoints with
+	// breaking changes are to be registered under a new version group, e.g. /api/v2.
+	registerRoutes(r.Group(API_V1_BASE_PATH), authEnabled, exportRateLimiter)
+
+	// Host the swagger UI at /swagger/index.html
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return r
+}
+
+// Handler returns the router as an http.Handler which serves the API paths with a
+// trailing slash, e.g. /api/v1/obligations/, by the same routes as the canonical
+// paths without it. The slash is trimmed before the routing, instead of
+// redirecting the client, so the method, body and headers of the request are kept.
+func Handler() http.Handler {
+	router := Router()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := req.URL.Path
+		if strings.HasPrefix(path, API_V1_BASE_PATH+"/") && strings.HasSuffix(path, "/") {
+			req.URL.Path = strings.TrimRight(path, "/")
+			req.URL.RawPath = strings.TrimRight(req.URL.RawPath, "/")
 		}
+		router.ServeHTTP(w, req)
+	})
+}
+
+// registerRoutes registers all the routes of the API on the given base group. If read
+// authentication is disabled, the public read routes are served without authentication.
+func registerRoutes(base *gin.RouterGroup, authEnabled bool, exportRateLimiter gin.HandlerFunc) {
+	// Exports are streamed and limited by EXPORT_STATEMENT_TIMEOUT_SECONDS instead
+	noTimeout := middleware.TimeoutMiddleware(0)
+
+	// The reviewer userlevels are looked up on every request, as they are configurable
+	reviewer := middleware.UserlevelMiddleware(func() []string { return ObligationReviewerUserlevels() })
+	// Drafts and obligations in review are only listed to reviewers, inactive ones to admins
+	statusFilter := middleware.AuthorizeIfMiddleware(middleware.HasQueryCondition("status"), reviewer)
+	inactiveFilter := middleware.AuthorizeIfMiddleware(includesInactiveObligations, middleware.AdminMiddleware())
 
-		authorizedv1 := r.Group("/api/v1")
-		authorizedv1.Use(middleware.AuthenticationMiddleware())
+	// Health, login and the API collection are always public
+	publicRoutes := []string{"/health", "/login", "/apiCollection"}
+	if !authEnabled {
+		publicRoutes = append(PublicReadRoutes(), publicRoutes...)
+	}
+
+	read := base.Group("")
+	read.Use(middleware.PublicRoutesMiddleware(base.BasePath(), publicRoutes))
+	{
+		licenses := read.Group("/licenses")
 		{
-			licenses := authorizedv1.Group("/licenses")
-			{
-				licenses.GET("", FilterLicense)
-				licenses.GET(":shortname", GetLicense)
-				licenses.GET("export", ExportLicenses)
-				licenses.GET("/preview", GetAllLicensePreviews)
-				licenses.POST("", CreateLicense)
-				licenses.PATCH(":shortname", UpdateLicense)
-				licenses.POST("import", ImportLicenses)
-			}
-			search := authorizedv1.Group("/search")
-			{
-				search.POST("", SearchInLicense)
-			}
-			users := authorizedv1.Group("/users")
-			{
-				users.GET("", auth.GetAllUser)
-				users.GET(":id", auth.GetUser)
-				users.POST("", auth.CreateUser)
-			}
-			obligations := authorizedv1.Group("/obligations")
-			{
-				obligations.GET("", GetAllObligation)
-				obligations.GET("/preview", GetAllObligationPreviews)
-				obligations.GET(":topic", GetObligation)
-				obligations.GET(":topic/audits", GetObligationAudits)
-				obligations.GET("export", ExportObligations)
-				obligations.POST("", CreateObligation)
-				obligations.POST("import", ImportObligations)
-				obligations.PATCH(":topic", UpdateObligation)
-				obligations.DELETE(":topic", DeleteObligation)
-			}
-			obMap := authorizedv1.Group("/obligation_maps")
-			{
-				obMap.GET("topic/:topic", GetObligationMapByTopic)
-				obMap.GET("license/:license", GetObligationMapByLicense)
-				obMap.PATCH("topic/:topic/license", PatchObligationMap)
-				obMap.PUT("topic/:topic/license", UpdateLicenseInObligationMap)
-			}
-			audit := authorizedv1.Group("/audits")
-			{
-				audit.GET("", GetAllAudit)
-				audit.GET(":audit_id", GetAudit)
-				audit.GET(":audit_id/changes", GetChangeLogs)
-				audit.GET(":audit_id/changes/:id", GetChangeLogbyId)
-			}
+			licenses.GET("", FilterLicense)
+			licenses.GET(":shortname", GetLicense)
+			licenses.GET("export", ExportLicenses)
+			licenses.GET("/preview", GetAllLicensePreviews)
 		}
-	} else {
-		unAuthorizedv1 := r.Group("/api/v1")
+		search := read.Group("/search")
 		{
-			licenses := unAuthorizedv1.Group("/licenses")
-			{
-				licenses.GET("", FilterLicense)
-				licenses.GET(":shortname", GetLicense)
-				licenses.GET("export", ExportLicenses)
-				licenses.GET("/preview", GetAllLicensePreviews)
-			}
-			search := unAuthorizedv1.Group("/search")
-			{
-				search.POST("", SearchInLicense)
-			}
-			obligations := unAuthorizedv1.Group("/obligations")
-			{
-				obligations.GET("", GetAllObligation)
-				obligations.GET("/preview", GetAllObligationPreviews)
-				obligations.GET(":topic", GetObligation)
-				obligations.GET(":topic/audits", GetObligationAudits)
-				obligations.GET("export", ExportObligations)
-			}
-			obMap := unAuthorizedv1.Group("/obligation_maps")
-			{
-				obMap.GET("topic/:topic", GetObligationMapByTopic)
-				obMap.GET("license/:license", GetObligationMapByLicense)
-			}
-			audit := unAuthorizedv1.Group("/audits")
-			{
-				audit.GET("", GetAllAudit)
-				audit.GET(":audit_id", GetAudit)
-				audit.GET(":audit_id/changes", GetChangeLogs)
-				audit.GET(":audit_id/changes/:id", GetChangeLogbyId)
-			}
-			health := unAuthorizedv1.Group("/health")
-			{
-				health.GET("", GetHealth)
-			}
-			login := unAuthorizedv1.Group("/login")
-			{
-				login.POST("", auth.Login)
-			}
-			apiCollection := unAuthorizedv1.Group("/apiCollection")
-			{
-				apiCollection.GET("", GetAPICollection)
-			}
+			search.POST("", SearchInLicense)
 		}
-
-		authorizedv1 := r.Group("/api/v1")
-		authorizedv1.Use(middleware.AuthenticationMiddleware())
+		obligations := read.Group("/obligations")
 		{
-			licenses := authorizedv1.Group("/licenses")
-			{
-				licenses.POST("", CreateLicense)
-				licenses.PATCH(":shortname", UpdateLicense)
-				licenses.POST("import", ImportLicenses)
-			}
-			users := authorizedv1.Group("/users")
-			{
-				users.GET("", auth.GetAllUser)
-				users.GET(":id", auth.GetUser)
-				users.POST("", auth.CreateUser)
-			}
-			obligations := authorizedv1.Group("/obligations")
-			{
-				obligations.POST("", CreateObligation)
-				obligations.POST("import", ImportObligations)
-				obligations.PATCH(":topic", UpdateObligation)
-				obligations.DELETE(":topic", DeleteObligation)
-			}
-			obMap := authorizedv1.Group("/obligation_maps")
-			{
-				obMap.PATCH("topic/:topic/license", PatchObligationMap)
-				obMap.PUT("topic/:topic/license", UpdateLicenseInObligationMap)
-			}
+			obligations.GET("", inactiveFilter, statusFilter, GetAllObligation)
+			obligations.GET("/preview", statusFilter, GetAllObligationPreviews)
+			obligations.GET("graph", statusFilter, GetObligationGraph)
+			obligations.GET("mapping-health", statusFilter, GetObligationMappingHealth)
+			obligations.GET("usage", statusFilter, GetObligationUsage)
+			obligations.GET("length-distribution", statusFilter, GetObligationLengthDistribution)
+			obligations.GET("lint", statusFilter, GetObligationLint)
+			obligations.GET("changes.atom", GetObligationChangesFeed)
+			obligations.GET("recent", GetRecentObligations)
+			obligations.GET("id/:id", statusFilter, GetObligationById)
+			obligations.GET("id/:id/audits", statusFilter, GetObligationAuditsById)
+			obligations.GET(":topic", statusFilter, GetObligation)
+			obligations.HEAD(":topic", middleware.HeadMiddleware(), statusFilter, HeadObligation)
+			obligations.GET(":topic/audits", statusFilter, GetObligationAudits)
+			obligations.GET(":topic/audits/summary", statusFilter, GetObligationAuditSummary)
+			obligations.GET(":topic/similar", statusFilter, GetSimilarObligations)
+			obligations.GET(":topic/find", statusFilter, FindInObligation)
+			obligations.GET(":topic/translations", statusFilter, GetObligationTranslations)
+			obligations.GET(":topic/notes", statusFilter, GetObligationNotes)
+			obligations.GET(":topic/snapshots", statusFilter, GetObligationSnapshots)
+			obligations.GET(":topic/snapshots/:id", statusFilter, GetObligationSnapshot)
+			obligations.POST("search", statusFilter, SearchInObligation)
+			obligations.POST("check-duplicates", statusFilter, CheckObligationDuplicates)
+			obligations.POST("validate", ValidateObligations)
+			obligations.POST("audits/batch", statusFilter, GetObligationAuditsBatch)
+			obligations.POST("classify", ClassifyObligation)
+			obligations.POST("resolve-expression", ResolveLicenseExpression)
+		}
+		obMap := read.Group("/obligation_maps")
+		{
+			obMap.GET("topic/:topic", statusFilter, GetObligationMapByTopic)
+			obMap.GET("license/:license", statusFilter, GetObligationMapByLicense)
+			obMap.GET("matrix", ExportObligationMapMatrix)
+		}
+		audit := read.Group("/audits")
+		{
+			audit.GET("", GetAllAudit)
+			audit.GET("archive", GetArchivedAudits)
+			audit.GET(":audit_id", GetAudit)
+			audit.GET(":audit_id/changes", GetChangeLogs)
+			audit.GET(":audit_id/changes/:id", GetChangeLogbyId)
+		}
+		classificationRules := read.Group("/classification_rules")
+		{
+			classificationRules.GET("", GetClassificationRules)
+		}
+		health := read.Group("/health")
+		{
+			health.GET("", GetHealth)
+		}
+		login := read.Group("/login")
+		{
+			login.POST("", auth.Login)
+		}
+		apiCollection := read.Group("/apiCollection")
+		{
+			apiCollection.GET("", GetAPICollection)
 		}
 	}
 
-	// Host the swagger UI at /swagger/index.html
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-	return r
+	authorized := base.Group("")
+	authorized.Use(middleware.AuthenticationMiddleware())
+	{
+		licenses := authorized.Group("/licenses")
+		{
+			licenses.POST("", CreateLicense)
+			licenses.PATCH(":shortname", UpdateLicense)
+			licenses.POST("import", ImportLicenses)
+			licenses.POST("obligations/export", noTimeout, exportRateLimiter, ExportLicenseObligations)
+		}
+		users := authorized.Group("/users")
+		{
+			users.GET("", auth.GetAllUser)
+			users.GET(":id", auth.GetUser)
+			users.POST("", auth.CreateUser)
+		}
+		obligations := authorized.Group("/obligations")
+		{
+			obligations.POST("", CreateObligation)
+			obligations.POST("import", ImportObligations)
+			obligations.PATCH(":topic", UpdateObligation)
+			obligations.GET("export", noTimeout, exportRateLimiter, statusFilter, ExportObligations)
+			obligations.PUT(":topic/translations/:lang", UpdateObligationTranslation)
+			obligations.POST(":topic/notes", CreateObligationNote)
+			obligations.POST("tags/assign", AssignObligationTag)
+			obligations.POST("tags/unassign", UnassignObligationTag)
+			obligations.POST("reclassify", middleware.AdminMiddleware(), ReclassifyObligations)
+			obligations.POST("deactivate-by-topics", middleware.AdminMiddleware(), DeactivateObligationsByTopics)
+			obligations.GET("types", middleware.AdminMiddleware(), GetObligationTypes)
+			obligations.GET("watched", GetWatchedObligations)
+			obligations.GET("watched/changes.atom", GetWatchedObligationChangesFeed)
+			obligations.GET(":topic/access-log", middleware.AdminMiddleware(), GetObligationAccessLog)
+			obligations.GET(":topic/editable-fields", GetObligationEditableFields)
+			obligations.POST(":topic/snapshot", CreateObligationSnapshot)
+			obligations.POST(":topic/watch", WatchObligation)
+			obligations.DELETE(":topic/watch", UnwatchObligation)
+			obligations.POST(":topic/lock", LockObligation)
+			obligations.DELETE(":topic/lock", UnlockObligation)
+			obligations.GET(":topic/audit-report", statusFilter, GetObligationAuditReport)
+			obligations.DELETE(":topic", DeleteObligation)
+			obligations.POST(":topic/publish", reviewer, PublishObligation)
+		}
+		obMap := authorized.Group("/obligation_maps")
+		{
+			obMap.PATCH("topic/:topic/license", PatchObligationMap)
+			obMap.PUT("topic/:topic/license", UpdateLicenseInObligationMap)
+			obMap.POST("import", ImportObligationMaps)
+		}
+		audit := authorized.Group("/audits")
+		{
+			audit.POST("archive", middleware.AdminMiddleware(), ArchiveAudits)
+			audit.GET("by-user", middleware.AdminMiddleware(), GetAuditCountsByUser)
+		}
+		classificationRules := authorized.Group("/classification_rules")
+		{
+			classificationRules.POST("", middleware.AdminMiddleware(), CreateClassificationRule)
+			classificationRules.DELETE(":id", middleware.AdminMiddleware(), DeleteClassificationRule)
+		}
+	}
 }
 
 // The HandleInvalidUrl function returns the error when an invalid url is entered
@@ -246,6 +396,19 @@ func HandleInvalidUrl(c *gin.Context) {
 	c.JSON(http.StatusNotFound, er)
 }
 
+// The HandleInvalidMethod function returns the error when a path is requested
+// with a method it does not support
+func HandleInvalidMethod(c *gin.Context) {
+	er := models.LicenseError{
+		Status:    http.StatusMethodNotAllowed,
+		Message:   fmt.Sprintf("Method %s is not allowed on this path", c.Request.Method),
+		Error:     "method not allowed",
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.JSON(http.StatusMethodNotAllowed, er)
+}
+
 // The GetHealth function returns if the DB is running and connected.
 //
 //	@Summary		Check health
@@ -317,6 +480,9 @@ func GetAPICollection(c *gin.Context) {
 		authEnabled = DEFAULT_READ_API_AUTHENTICATION_ENABLED
 	}
 
+	publicRoutes := PublicReadRoutes()
+	pathParam := regexp.MustCompile(`{(\w+)}`)
+
 	var unAuthenticatedApis models.LinksCollection
 	var authenticatedApis models.LinksCollection
 	unAuthenticatedApis.Links = make(map[string]models.Api)
@@ -325,7 +491,8 @@ func GetAPICollection(c *gin.Context) {
 	for _, path := range maps.Keys(swaggerDocAPISecurityScheme.Paths) {
 		for _, method := range maps.Keys(swaggerDocAPISecurityScheme.Paths[path]) {
 			if len(swaggerDocAPISecurityScheme.Paths[path][method].Security) == 0 ||
-				(len(swaggerDocAPISecurityScheme.Paths[path][method].Security) == 2 && !authEnabled) {
+				(len(swaggerDocAPISecurityScheme.Paths[path][method].Security) == 2 && !authEnabled &&
+					middleware.IsPublicRoute(pathParam.ReplaceAllString(path, ":$1"), publicRoutes)) {
 				unAuthenticatedApis.Links[swaggerDocAPISecurityScheme.Paths[path][method].OperationId] = models.Api{
 					Href:          fmt.Sprintf("%s%s", swaggerDocAPISecurityScheme.BasePath, path),
 					RequestMethod: method,
@@ -5,18 +5,43 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
+	"github.com/fossology/LicenseDb/cmd/laas/docs"
 	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/middleware"
 	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
 )
 
 // TestMain is the main testing function for the application. It sets up the testing environment,
@@ -29,26 +54,81 @@ func TestMain(m *testing.M) {
 	password := "fossy"
 	port := "5432"
 	dbhost := "localhost"
+	if os.Getenv("DB_DRIVER") == db.DRIVER_SQLITE {
+		dbname = "file::memory:?cache=shared"
+	}
 	db.Connect(&dbhost, &port, &user, &dbname, &password)
 
+	if !db.IsPostgres() {
+		// The in-memory database is dropped with its last connection, and the
+		// connections of transactions cancelled by the request timeout are closed,
+		// so a connection is kept open until the tests exit
+		sqlDB, err := db.DB.DB()
+		if err != nil {
+			log.Fatalf("Failed to get database connection: %v", err)
+		}
+		if _, err := sqlDB.Conn(context.Background()); err != nil {
+			log.Fatalf("Failed to open database connection: %v", err)
+		}
+		if err := db.DB.AutoMigrate(&models.LicenseDB{}, &models.User{}, &models.Audit{}, &models.ChangeLog{},
+			&models.Obligation{}, &models.ObligationMap{}, &models.ArchivedAudit{},
+			&models.ObligationTranslation{}, &models.ObligationNote{},
+			&models.ObligationTag{}, &models.ClassificationRule{}, &models.ObligationWatch{},
+			&models.ObligationTopicRedirect{}, &models.ObligationAccessLog{},
+			&models.ObligationCreationCount{}, &models.ObligationSnapshot{}, &models.ObligationLock{}); err != nil {
+			log.Fatalf("Failed to automigrate database: %v", err)
+		}
+		seedTestDatabase()
+	}
+
+	// Authenticated requests of the tests use the token of the test user
+	if os.Getenv("API_SECRET") == "" {
+		os.Setenv("API_SECRET", "test-secret")
+	}
+	if os.Getenv("TOKEN_HOUR_LIFESPAN") == "" {
+		os.Setenv("TOKEN_HOUR_LIFESPAN", "1")
+	}
+	w := makeRequest("POST", "/api/v1/login", models.UserLogin{Username: "fossy", Userpassword: "fossy"}, false)
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &login); err != nil || login.Token == "" {
+		log.Fatalf("Failed to log in test user: %s", w.Body.String())
+	}
+	authToken = login.Token
+
 	exitcode := m.Run()
 	os.Exit(exitcode)
 }
 
+// authToken is the JWT of the test user sent along with the authenticated requests
+var authToken string
+
 // makeRequest is a utility function for creating and sending HTTP requests during testing.
 func makeRequest(method, path string, body interface{}, isAuthanticated bool) *httptest.ResponseRecorder {
+	return makeRequestWithHeaders(method, path, body, isAuthanticated, nil)
+}
+
+// makeRequestWithHeaders is same as makeRequest but also sets the given headers on the request.
+func makeRequestWithHeaders(method, path string, body interface{}, isAuthanticated bool,
+	headers map[string]string) *httptest.ResponseRecorder {
 	reqBody, _ := json.Marshal(body)
 	req := httptest.NewRequest(method, path, bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	if isAuthanticated {
-		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("fossy:fossy")))
+		req.Header.Set("Authorization", authToken)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
 	}
 	w := httptest.NewRecorder()
-	Router().ServeHTTP(w, req)
+	Handler().ServeHTTP(w, req)
 	return w
 }
-func TestGetLicense(t *testing.T) {
-	expectLicense := models.LicenseDB{
+
+// testLicenseMIT returns the MIT license which the tests expect to be in the database.
+func testLicenseMIT() models.LicenseDB {
+	return models.LicenseDB{
 		Shortname:     func(s string) *string { return &s }("MIT"),
 		Fullname:      func(s string) *string { return &s }("MIT License"),
 		Text:          func(s string) *string { return &s }("MIT License\n\nCopyright (c) <year> <copyright holders>\n\nPermission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the \"Software\"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:\n\nThe above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.\n\nTHE SOFTWARE IS PROVIDED \"AS IS\", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.\n"),
@@ -60,7 +140,51 @@ func TestGetLicense(t *testing.T) {
 		Marydone:      func(b bool) *bool { return &b }(true),
 		SpdxId:        func(s string) *string { return &s }("MIT"),
 	}
-	w := makeRequest("GET", "/api/licenses/MIT", nil, false)
+}
+
+// seedTestDatabase creates the user and the license the tests rely on in a fresh
+// database, which are otherwise provided by the postgres database of the tests.
+func seedTestDatabase() {
+	password := "fossy"
+	user := models.User{Username: "fossy", Userlevel: "admin", Userpassword: &password}
+	if err := utils.HashPassword(&user); err != nil {
+		log.Fatalf("Failed to hash password: %v", err)
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		log.Fatalf("Failed to create user: %v", err)
+	}
+	license := testLicenseMIT()
+	if err := db.DB.Create(&license).Error; err != nil {
+		log.Fatalf("Failed to create license: %v", err)
+	}
+}
+
+// assertLicenseEqual asserts that the license has the fields of the expected license,
+// ignoring the fields which are not set in it, e.g. the defaults of the database.
+func assertLicenseEqual(t *testing.T, expected, actual models.LicenseDB) {
+	t.Helper()
+	e := reflect.ValueOf(expected)
+	a := reflect.ValueOf(&actual).Elem()
+	for i := 0; i < e.NumField(); i++ {
+		if e.Field(i).IsZero() && a.Field(i).CanSet() {
+			a.Field(i).Set(e.Field(i))
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
+
+// createTestLicenses creates the licenses which are not yet in the database.
+func createTestLicenses(t *testing.T, licenses ...models.LicenseDB) {
+	for _, license := range licenses {
+		if err := db.DB.Where(models.LicenseDB{Shortname: license.Shortname}).FirstOrCreate(&license).Error; err != nil {
+			t.Fatalf("Unable to create license: %v", err)
+		}
+	}
+}
+
+func TestGetLicense(t *testing.T) {
+	expectLicense := testLicenseMIT()
+	w := makeRequest("GET", "/api/v1/licenses/MIT", nil, false)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var res models.LicenseResponse
@@ -69,7 +193,7 @@ func TestGetLicense(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, expectLicense, res.Data[0])
+	assertLicenseEqual(t, expectLicense, res.Data[0])
 
 }
 
@@ -84,7 +208,7 @@ func TestCreateLicense(t *testing.T) {
 		DetectorType:  func(i int64) *int64 { return &i }(1),
 		Active:        func(b bool) *bool { return &b }(true),
 	}
-	w := makeRequest("POST", "/api/licenses", License, true)
+	w := makeRequest("POST", "/api/v1/licenses", License, true)
 	assert.Equal(t, http.StatusCreated, w.Code)
 
 	var res models.LicenseResponse
@@ -93,7 +217,7 @@ func TestCreateLicense(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, License, res.Data[0])
+	assertLicenseEqual(t, License, res.Data[0])
 
 }
 
@@ -113,7 +237,7 @@ func TestUpdateLicense(t *testing.T) {
 		Marydone:      func(b bool) *bool { return &b }(true),
 		SpdxId:        func(s string) *string { return &s }("MIT"),
 	}
-	w := makeRequest("PATCH", "/api/licenses/MIT", License, true)
+	w := makeRequest("PATCH", "/api/v1/licenses/MIT", License, true)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var res models.LicenseResponse
@@ -122,7 +246,7 @@ func TestUpdateLicense(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, expectedLicense, res.Data[0])
+	assertLicenseEqual(t, expectedLicense, res.Data[0])
 
 }
 
@@ -139,12 +263,13 @@ func TestSearchInLicense(t *testing.T) {
 		Marydone:      func(b bool) *bool { return &b }(false),
 		SpdxId:        func(s string) *string { return &s }("PostgreSQL"),
 	}
+	createTestLicenses(t, expectLicense)
 	search := models.SearchLicense{
 		Field:      "fullname",
 		SearchTerm: "Postgresql",
 		Search:     "",
 	}
-	w := makeRequest("POST", "/api/search", search, false)
+	w := makeRequest("POST", "/api/v1/search", search, false)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var res models.LicenseResponse
@@ -153,7 +278,7 @@ func TestSearchInLicense(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, expectLicense, res.Data[0])
+	assertLicenseEqual(t, expectLicense, res.Data[0])
 
 }
 
@@ -186,12 +311,13 @@ func TestSearchInLicense2(t *testing.T) {
 			SpdxId:        func(s string) *string { return &s }("Autoconf-exception-2.0"),
 		},
 	}
+	createTestLicenses(t, expectLicense...)
 	search := models.SearchLicense{
 		Field:      "url",
 		SearchTerm: "http://ac-archive.sourceforge.net/doc/copyright.html",
 		Search:     "",
 	}
-	w := makeRequest("POST", "/api/search", search, false)
+	w := makeRequest("POST", "/api/v1/search", search, false)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var res models.LicenseResponse
@@ -200,17 +326,256 @@ func TestSearchInLicense2(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, expectLicense, res.Data)
+	if assert.Len(t, res.Data, len(expectLicense)) {
+		for i := range expectLicense {
+			assertLicenseEqual(t, expectLicense[i], res.Data[i])
+		}
+	}
+}
+
+func TestInvalidRoute(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"unknown path", "GET", "/api/v1/no-such-path", http.StatusNotFound},
+		{"unsupported method", "DELETE", "/api/v1/health", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := makeRequest(tt.method, tt.path, nil, false)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+			var res models.LicenseError
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Errorf("Error unmarshalling JSON: %v", err)
+				return
+			}
+			assert.Equal(t, tt.status, res.Status)
+			assert.Equal(t, tt.path, res.Path)
+			assert.NotEmpty(t, res.Message)
+			_, err := time.Parse(time.RFC3339, res.Timestamp)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	router := Router()
+	router.GET("/api/v1/test-panic", func(c *gin.Context) {
+		panic("secret internal state")
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/test-panic", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var res models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, http.StatusInternalServerError, res.Status)
+	assert.Equal(t, "/api/v1/test-panic", res.Path)
+	requestId := w.Header().Get("X-Request-Id")
+	if assert.NotEmpty(t, requestId) {
+		assert.Contains(t, res.Error, requestId)
+	}
+	assert.NotContains(t, w.Body.String(), "secret internal state")
+
+	// The server keeps serving requests after a panic
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTrailingSlash(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Type:           "obligation",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{},
+		Active:         true,
+	}
+	update := map[string]interface{}{
+		"comment": "updated comment",
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   func(suffix string) interface{}
+	}{
+		{"create obligation", "POST", "/api/v1/obligations", func(suffix string) interface{} {
+			ob := obligation
+			ob.Topic = "trailing-slash" + suffix
+			ob.Text = "Obligation text for trailing slash" + suffix
+			return ob
+		}},
+		{"update obligation", "PATCH", "/api/v1/obligations/trailing-slash", func(suffix string) interface{} {
+			return update
+		}},
+		{"list obligations", "GET", "/api/v1/obligations", func(suffix string) interface{} {
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := makeRequest(tt.method, tt.path, tt.body(""), true)
+			wSlash := makeRequest(tt.method, tt.path+"/", tt.body("-slash"), true)
+
+			assert.Less(t, w.Code, 300)
+			assert.Equal(t, w.Code, wSlash.Code)
+			assert.False(t, wSlash.Code >= 300 && wSlash.Code < 400, "unexpected redirect %d", wSlash.Code)
+			assert.Empty(t, wSlash.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	r := gin.New()
+	r.Use(middleware.PaginationMiddleware(), middleware.TimeoutMiddleware(10*time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, models.LicenseError{Status: http.StatusInternalServerError})
+	})
+	r.GET("/slow-without-timeout", middleware.TimeoutMiddleware(0), func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			c.Status(http.StatusInternalServerError)
+		case <-time.After(50 * time.Millisecond):
+			c.Status(http.StatusOK)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	var res models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, http.StatusGatewayTimeout, res.Status)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow-without-timeout", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestErrorVerbosity(t *testing.T) {
+	router := func(debug bool) *gin.Engine {
+		r := gin.New()
+		r.Use(middleware.PaginationMiddleware(), middleware.ErrorVerbosityMiddleware(debug))
+		r.GET("/failing", func(c *gin.Context) {
+			c.JSON(http.StatusInternalServerError, models.LicenseError{
+				Status:  http.StatusInternalServerError,
+				Message: "Unable to fetch obligations",
+				Error:   `pq: relation "obligations" does not exist`,
+			})
+		})
+		r.GET("/missing", func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, models.LicenseError{Status: http.StatusNotFound, Error: "record not found"})
+		})
+		return r
+	}
+	get := func(r *gin.Engine, path string) (*httptest.ResponseRecorder, models.LicenseError) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var res models.LicenseError
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return w, res
+	}
+
+	w, res := get(router(false), "/failing")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	id := w.Header().Get("X-Request-Id")
+	assert.NotEmpty(t, id)
+	assert.Equal(t, "internal server error, request id "+id, res.Error)
+	assert.NotContains(t, w.Body.String(), "relation")
+	assert.Equal(t, "Unable to fetch obligations", res.Message)
+
+	// Client errors are returned as they are
+	_, res = get(router(false), "/missing")
+	assert.Equal(t, "record not found", res.Error)
+
+	w, res = get(router(true), "/failing")
+	assert.Equal(t, `pq: relation "obligations" does not exist`, res.Error)
+	assert.Empty(t, w.Header().Get("X-Request-Id"))
+}
+
+func TestPaginationMiddlewareResponseModel(t *testing.T) {
+	r := gin.New()
+	r.Use(middleware.PaginationMiddleware())
+	r.GET("/unknown", func(c *gin.Context) {
+		c.Set("paginationMeta", models.PaginationMeta{ResourceCount: 25})
+		c.JSON(http.StatusOK, struct {
+			Status int                    `json:"status"`
+			Data   []string               `json:"data"`
+			Meta   *models.PaginationMeta `json:"paginationmeta"`
+		}{Status: http.StatusOK, Data: []string{"first"}})
+	})
+	r.GET("/invalid", func(c *gin.Context) {
+		c.Set("paginationMeta", models.PaginationMeta{ResourceCount: 25})
+		c.String(http.StatusOK, "not json")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/unknown?limit=10", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Data []string              `json:"data"`
+		Meta models.PaginationMeta `json:"paginationmeta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, []string{"first"}, res.Data)
+	assert.Equal(t, 25, res.Meta.ResourceCount)
+	assert.Equal(t, int64(3), res.Meta.TotalPages)
+	assert.Equal(t, "/unknown?limit=10&page=2", res.Meta.Next)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/invalid", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var er models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, http.StatusInternalServerError, er.Status)
+}
+
+func TestPaginationMiddlewareNoContent(t *testing.T) {
+	r := gin.New()
+	r.Use(middleware.PaginationMiddleware())
+	r.DELETE("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/empty", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
 }
 
 func TestGetUser(t *testing.T) {
-	password := "fossy"
 	expectUser := models.User{
-		Username:     "fossy",
-		Userpassword: &password,
-		Userlevel:    "admin",
+		Id:        1,
+		Username:  "fossy",
+		Userlevel: "admin",
 	}
-	w := makeRequest("GET", "/api/user/1", nil, false)
+	w := makeRequest("GET", "/api/v1/users/1", nil, true)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var res models.UserResponse
@@ -225,13 +590,13 @@ func TestGetUser(t *testing.T) {
 
 func TestCreateUser(t *testing.T) {
 	password := "abc123"
-	user := models.User{
+	user := models.UserInput{
 		Username:     "general_user",
 		Userpassword: &password,
 		Userlevel:    "participant",
 	}
-	w := makeRequest("POST", "/api/user", user, true)
-	assert.Equal(t, http.StatusOK, w.Code)
+	w := makeRequest("POST", "/api/v1/users", user, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
 
 	var res models.UserResponse
 	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
@@ -239,5 +604,5204 @@ func TestCreateUser(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, user, res.Data[0])
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, user.Username, res.Data[0].Username)
+		assert.Equal(t, user.Userlevel, res.Data[0].Userlevel)
+	}
+}
+
+func TestUpdateObligationIfUnmodifiedSince(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "conditional-update",
+		Type:           "obligation",
+		Text:           "Obligation text for conditional update",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{"MIT"},
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	lastModified := res.Data[0].UpdatedAt
+
+	update := map[string]interface{}{
+		"comment": "updated comment",
+	}
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		stale := lastModified.Add(-time.Hour).UTC().Format(http.TimeFormat)
+		w := makeRequestWithHeaders("PATCH", "/api/v1/obligations/conditional-update", update, true,
+			map[string]string{"If-Unmodified-Since": stale})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("fresh timestamp", func(t *testing.T) {
+		fresh := lastModified.Add(time.Second).UTC().Format(http.TimeFormat)
+		w := makeRequestWithHeaders("PATCH", "/api/v1/obligations/conditional-update", update, true,
+			map[string]string{"If-Unmodified-Since": fresh})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Errorf("Error unmarshalling JSON: %v", err)
+			return
+		}
+		assert.Equal(t, "updated comment", res.Data[0].Comment)
+	})
+
+	w = makeRequest("POST", "/api/v1/obligations/conditional-update/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetAllObligationCreatedRange(t *testing.T) {
+	for year := 2020; year <= 2022; year++ {
+		topic := fmt.Sprintf("created-range-%d", year)
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text created in " + topic,
+			TextHash: topic, Active: true, CreatedAt: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	// topics gives the topics of the obligations listed with the query
+	topics := func(query string) []string {
+		w := makeRequest("GET", "/api/v1/obligations?filter="+url.QueryEscape("topic like 'created-range-%'")+
+			"&"+query, nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		var topics []string
+		for _, obligation := range res.Data {
+			topics = append(topics, obligation.Topic)
+		}
+		return topics
+	}
+
+	// createdAfter is inclusive and createdBefore is exclusive
+	assert.Equal(t, []string{"created-range-2021", "created-range-2022"}, topics("createdAfter=2021-01-01T00:00:00Z"))
+	assert.Equal(t, []string{"created-range-2020", "created-range-2021"}, topics("createdBefore=2022-01-01T00:00:00Z"))
+	assert.Equal(t, []string{"created-range-2021"},
+		topics("createdAfter=2021-01-01T00:00:00Z&createdBefore=2022-01-01T00:00:00Z"))
+	assert.Empty(t, topics("createdAfter=2022-01-01T00:00:00Z&createdBefore=2021-01-01T00:00:00Z"))
+
+	for _, query := range []string{"createdAfter=2021-01-01", "createdBefore=yesterday"} {
+		w := makeRequest("GET", "/api/v1/obligations?"+query, nil, false)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetAllObligationTextUpdatableFilter(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "locked-text",
+		Type:           "obligation",
+		Text:           "Obligation text which is not updatable",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{},
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/locked-text/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for _, textUpdatable := range []bool{true, false} {
+		t.Run(fmt.Sprintf("textUpdatable=%t", textUpdatable), func(t *testing.T) {
+			w := makeRequest("GET", fmt.Sprintf("/api/v1/obligations?textUpdatable=%t", textUpdatable), nil, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var res struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Errorf("Error unmarshalling JSON: %v", err)
+				return
+			}
+			if !textUpdatable {
+				assert.NotEmpty(t, res.Data)
+			}
+			for _, ob := range res.Data {
+				assert.Contains(t, ob, "text_updatable")
+				assert.Equal(t, textUpdatable, ob["text_updatable"])
+			}
+		})
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations?textUpdatable=maybe", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObligationResponseWithoutEnvelope(t *testing.T) {
+	envelopeObligation := models.Obligation{Topic: "no-envelope", Type: "obligation", Text: "Obligation text without envelope",
+		TextHash: "no-envelope", Active: true}
+	if err := db.DB.Create(&envelopeObligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	headers := map[string]string{"X-Response-Envelope": "none"}
+
+	w := makeRequestWithHeaders("GET", "/api/v1/obligations/no-envelope", nil, false, headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var obligation models.Obligation
+	if err := json.Unmarshal(w.Body.Bytes(), &obligation); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, "no-envelope", obligation.Topic)
+
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations?limit=2", nil, false, headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var obligations []models.Obligation
+	if err := json.Unmarshal(w.Body.Bytes(), &obligations); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.LessOrEqual(t, len(obligations), 2)
+	assert.NotEmpty(t, w.Header().Get("X-Total-Count"))
+
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations/no-such-obligation", nil, false, headers)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var er models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, er.Status)
+}
+
+func TestImportObligationsSchemaVersion(t *testing.T) {
+	importFile := func(content string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "obligations.json")
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+
+		req := httptest.NewRequest("POST", "/api/v1/obligations/import", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", authToken)
+		w := httptest.NewRecorder()
+		Router().ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("unsupported version", func(t *testing.T) {
+		w := importFile(`{"schema_version": 99, "obligations": []}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("first version without envelope", func(t *testing.T) {
+		w := importFile(`[{"topic": "schema-v1", "type": "obligation", "text": "Obligation text of schema version 1",
+			"classification": "green", "shortnames": []}]`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var obligation models.Obligation
+		assert.NoError(t, db.DB.Where(models.Obligation{Topic: "schema-v1"}).First(&obligation).Error)
+		assert.Equal(t, models.OBLIGATION_STATUS_DRAFT, obligation.Status)
+	})
+
+	t.Run("second version without manifest", func(t *testing.T) {
+		w := importFile(`{"schema_version": 2, "obligations": [{"topic": "schema-v2", "type": "obligation",
+			"text": "Obligation text of schema version 2", "classification": "green", "shortnames": []}]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var obligation models.Obligation
+		assert.NoError(t, db.DB.Where(models.Obligation{Topic: "schema-v2"}).First(&obligation).Error)
+	})
+
+	export := func(topic string) models.ObligationExportEnvelope {
+		obligations := []models.ObligationJSONFileFormat{{Topic: topic, Type: "obligation", Classification: "green",
+			Text: "Obligation text <of> the current schema version & " + topic, Shortnames: []string{}}}
+		checksum, err := obligationExportChecksum(obligations)
+		if err != nil {
+			t.Fatalf("Unable to compute checksum: %v", err)
+		}
+		return models.ObligationExportEnvelope{
+			SchemaVersion: models.OBLIGATION_EXPORT_SCHEMA_VERSION,
+			Obligations:   obligations,
+			Manifest:      models.ObligationExportManifest{Count: len(obligations), Sha256: checksum},
+		}
+	}
+
+	t.Run("current version", func(t *testing.T) {
+		content, _ := json.Marshal(export("schema-current"))
+		w := importFile(string(content))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var obligation models.Obligation
+		assert.NoError(t, db.DB.Where(models.Obligation{Topic: "schema-current"}).First(&obligation).Error)
+	})
+
+	t.Run("manifest mismatch", func(t *testing.T) {
+		missing := export("schema-manifest-missing")
+		content, _ := json.Marshal(map[string]interface{}{"schema_version": missing.SchemaVersion, "obligations": missing.Obligations})
+		wrongCount := export("schema-manifest-count")
+		wrongCount.Manifest.Count = 2
+		wrongChecksum := export("schema-manifest-checksum")
+		wrongChecksum.Obligations[0].Text = "Obligation text changed after the export"
+		for _, envelope := range []models.ObligationExportEnvelope{wrongCount, wrongChecksum} {
+			envelopeContent, _ := json.Marshal(envelope)
+			w := importFile(string(envelopeContent))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+		w := importFile(string(content))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var count int64
+		assert.NoError(t, db.DB.Model(&models.Obligation{}).Where("topic LIKE ?", "schema-manifest-%").Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestImportObligationsStrategy(t *testing.T) {
+	// importObligation imports the obligation with the strategy and gives the status and action of the import
+	importObligation := func(strategy, changeReason string, obligation models.ObligationJSONFileFormat) (int, string) {
+		content, _ := json.Marshal([]models.ObligationJSONFileFormat{obligation})
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "obligations.json")
+		_, _ = part.Write(content)
+		if changeReason != "" {
+			_ = writer.WriteField("change_reason", changeReason)
+		}
+		_ = writer.Close()
+
+		path := "/api/v1/obligations/import"
+		if strategy != "" {
+			path += "?strategy=" + strategy
+		}
+		req := httptest.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", authToken)
+		w := httptest.NewRecorder()
+		Router().ServeHTTP(w, req)
+		if !assert.Equal(t, http.StatusOK, w.Code) {
+			return 0, ""
+		}
+		var res struct {
+			Data []struct {
+				Status int    `json:"status"`
+				Action string `json:"action"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if !assert.Len(t, res.Data, 1) {
+			return 0, ""
+		}
+		return res.Data[0].Status, res.Data[0].Action
+	}
+	stored := func(topic string) models.Obligation {
+		var obligation models.Obligation
+		if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+			t.Fatalf("Unable to fetch obligation: %v", err)
+		}
+		return obligation
+	}
+
+	obligation := models.ObligationJSONFileFormat{Topic: "import-strategy", Type: "obligation",
+		Text: "Obligation text imported with a strategy", Classification: "green", Shortnames: []string{}}
+	status, action := importObligation("", "", obligation)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, models.IMPORT_ACTION_CREATED, action)
+
+	// Existing obligations are skipped by default
+	obligation.Classification = "red"
+	obligation.Comment = "imported comment"
+	status, action = importObligation("", "", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_SKIPPED, action)
+	assert.Equal(t, "green", stored("import-strategy").Classification)
+
+	status, action = importObligation(models.IMPORT_STRATEGY_MERGE, "", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_MERGED, action)
+	assert.Equal(t, "green", stored("import-strategy").Classification)
+	assert.Equal(t, "imported comment", stored("import-strategy").Comment)
+
+	obligation.Modifications = true
+	obligation.Active = true
+	obligation.Sensitive = true
+	status, action = importObligation(models.IMPORT_STRATEGY_OVERWRITE, "", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_OVERWRITTEN, action)
+	overwritten := stored("import-strategy")
+	assert.Equal(t, "red", overwritten.Classification)
+	assert.True(t, overwritten.Modifications)
+	assert.True(t, overwritten.Active)
+	assert.True(t, overwritten.Sensitive)
+
+	// False and empty values are overwritten as well
+	obligation.Modifications = false
+	obligation.Active = false
+	obligation.Sensitive = false
+	obligation.Comment = ""
+	status, action = importObligation(models.IMPORT_STRATEGY_OVERWRITE, "", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_OVERWRITTEN, action)
+	overwritten = stored("import-strategy")
+	assert.False(t, overwritten.Modifications)
+	assert.False(t, overwritten.Active)
+	assert.False(t, overwritten.Sensitive)
+	assert.Empty(t, overwritten.Comment)
+
+	// An obligation found by its text under another topic is overwritten and keeps its topic
+	obligation.Topic = "import-strategy-renamed"
+	obligation.Classification = "yellow"
+	status, action = importObligation(models.IMPORT_STRATEGY_OVERWRITE, "", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_OVERWRITTEN, action)
+	assert.Equal(t, "yellow", stored("import-strategy").Classification)
+	var count int64
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "import-strategy-renamed"}).Count(&count)
+	assert.Zero(t, count)
+
+	// Overwriting the text requires a change reason, which is audited
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "import-strategy"}).UpdateColumn("text_updatable", true)
+	obligation.Topic = "import-strategy"
+	obligation.Text = "Obligation text overwritten by an import"
+	obligation.TextUpdatable = true
+	status, _ = importObligation(models.IMPORT_STRATEGY_OVERWRITE, "", obligation)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, "Obligation text imported with a strategy", stored("import-strategy").Text)
+	status, action = importObligation(models.IMPORT_STRATEGY_OVERWRITE, "Aligned with the export", obligation)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.IMPORT_ACTION_OVERWRITTEN, action)
+	assert.Equal(t, obligation.Text, stored("import-strategy").Text)
+	var audit models.Audit
+	if assert.NoError(t, db.DB.Where(models.Audit{Type: "Obligation", TypeId: stored("import-strategy").Id}).
+		Order("id desc").First(&audit).Error) {
+		assert.Equal(t, "Aligned with the export", audit.ChangeReason)
+	}
+}
+
+func TestExportObligationsManifest(t *testing.T) {
+	w := makeRequest("GET", "/api/v1/obligations/export", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var envelope models.ObligationExportEnvelope
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, w.Header().Get("X-Total-Count"), strconv.Itoa(envelope.Manifest.Count))
+	assert.NoError(t, verifyObligationExportManifest(&envelope.Manifest, envelope.Obligations))
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &keys); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Contains(t, keys, "schema_version")
+	assert.Contains(t, keys, "exported_at")
+
+	// The first schema version is still exported as a bare array
+	w = makeRequest("GET", "/api/v1/obligations/export?schemaVersion=1", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var obligations []models.ObligationJSONFileFormat
+	if err := json.Unmarshal(w.Body.Bytes(), &obligations); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, envelope.Obligations, obligations)
+
+	w = makeRequest("GET", "/api/v1/obligations/export?schemaVersion=2", nil, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportRateLimit(t *testing.T) {
+	w := makeRequest("GET", "/api/v1/obligations/export", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	middleware.RateLimitNow = func() time.Time { return now }
+	t.Cleanup(func() { middleware.RateLimitNow = time.Now })
+	t.Setenv("EXPORT_RATE_LIMIT", "2")
+	// The requests go through the same router, whose limiter counts them
+	handler := Handler()
+	export := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", authToken)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w = export("/api/v1/obligations/export")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("X-Total-Count"))
+	}
+	w = export("/api/v1/obligations/export")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	var er models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, er.Status)
+
+	now = now.Add(45 * time.Second)
+	w = export("/api/v1/obligations/export")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "15", w.Header().Get("Retry-After"))
+
+	// Another router counts on its own
+	w = makeRequest("GET", "/api/v1/obligations/export", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The limit is reset with the next window
+	now = now.Add(15 * time.Second)
+	w = export("/api/v1/obligations/export")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestExportLicenseObligations(t *testing.T) {
+	shortnames := []string{"export-license-a", "export-license-b"}
+	if err := createLicenseStubs(db.DB, shortnames); err != nil {
+		t.Fatalf("Unable to create licenses: %v", err)
+	}
+	var licenseIds []int64
+	if err := db.DB.Model(&models.LicenseDB{}).Where("rf_shortname IN ?", shortnames).
+		Order("rf_shortname").Pluck("rf_id", &licenseIds).Error; err != nil {
+		t.Fatalf("Unable to fetch licenses: %v", err)
+	}
+	var obligationIds []int64
+	t.Cleanup(func() {
+		db.DB.Where("obligation_pk IN ?", obligationIds).Delete(&models.ObligationMap{})
+		db.DB.Where("id IN ?", obligationIds).Delete(&models.Obligation{})
+		db.DB.Where("rf_id IN ?", licenseIds).Delete(&models.LicenseDB{})
+	})
+	for _, obligation := range []models.Obligation{
+		{Topic: "export-license-shared", Text: "Obligation of both licenses", Active: true},
+		{Topic: "export-license-first", Text: "Obligation of the first license", Active: true},
+		{Topic: "export-license-inactive", Text: "Inactive obligation of the first license", Active: false},
+	} {
+		obligation.Type = "obligation"
+		obligation.Classification = "green"
+		obligation.Status = models.OBLIGATION_STATUS_PUBLISHED
+		obligation.TextHash = utils.ObligationTextHash(obligation.Text)
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		// Gorm skips the false zero value on create
+		db.DB.Model(&obligation).Update("active", obligation.Active)
+		obligationIds = append(obligationIds, obligation.Id)
+		obMaps := []models.ObligationMap{{ObligationPk: obligation.Id, RfPk: licenseIds[0]}}
+		if obligation.Topic == "export-license-shared" {
+			obMaps = append(obMaps, models.ObligationMap{ObligationPk: obligation.Id, RfPk: licenseIds[1]})
+		}
+		if err := db.DB.Create(&obMaps).Error; err != nil {
+			t.Fatalf("Unable to map obligation: %v", err)
+		}
+	}
+
+	input := models.LicenseObligationsExportInput{Shortnames: shortnames}
+	w := makeRequest("POST", "/api/v1/licenses/obligations/export", input, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = makeRequest("POST", "/api/v1/licenses/obligations/export", input, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var exported []models.LicenseObligationExport
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, exported, 2) {
+		assert.Equal(t, "export-license-first", exported[0].Topic)
+		assert.Equal(t, []string{"export-license-a"}, exported[0].Shortnames)
+		assert.Equal(t, "Obligation of both licenses", exported[1].Text)
+		assert.Equal(t, shortnames, exported[1].Shortnames)
+	}
+
+	w = makeRequest("POST", "/api/v1/licenses/obligations/export?format=csv", input, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, "export-license-first", records[1][0])
+		assert.Equal(t, "export-license-a;export-license-b", records[2][6])
+	}
+
+	// Licenses without obligations give an empty file rather than an error
+	input.Shortnames = []string{"export-license-unknown"}
+	w = makeRequest("POST", "/api/v1/licenses/obligations/export", input, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+}
+
+func TestGetSimilarObligations(t *testing.T) {
+	for _, obligation := range []models.Obligation{
+		{Topic: "similar-original", Type: "obligation", Text: "The source code must be made available when distributing the software.", TextHash: "similar-original", Active: true},
+		{Topic: "similar-copy", Type: "obligation", Text: "The source code must be made available when distributing the software!", TextHash: "similar-copy", Active: true},
+		{Topic: "similar-unrelated", Type: "obligation", Text: "Attribution notices are to be kept in all copies.", TextHash: "similar-unrelated", Active: true},
+	} {
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/similar-original/similar?threshold=0.9", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationSimilarityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "similar-copy", res.Data[0].Topic)
+		assert.Equal(t, 1.0, res.Data[0].Score)
+	}
+
+	// Only the best matches are kept
+	w = makeRequest("GET", "/api/v1/obligations/similar-original/similar?threshold=0&count=2", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 2) {
+		assert.Equal(t, "similar-copy", res.Data[0].Topic)
+		assert.GreaterOrEqual(t, res.Data[0].Score, res.Data[1].Score)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/similar-original/similar?threshold=2", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAllObligationFilter(t *testing.T) {
+	for _, obligation := range []models.Obligation{
+		{Topic: "filter-red-modifications", Type: "obligation", Text: "Filter red obligation with modifications",
+			TextHash: "filter-red-modifications", Classification: "red", Modifications: true, Active: true},
+		{Topic: "filter-red", Type: "risk", Text: "Filter red obligation without modifications",
+			TextHash: "filter-red", Classification: "red", Modifications: false, Active: true},
+		{Topic: "filter-green", Type: "risk", Text: "Filter green obligation with modifications",
+			TextHash: "filter-green", Classification: "green", Modifications: true, Active: true},
+		{Topic: "quoted-filter", Type: "risk", Text: "Filter A AND B, quoted",
+			TextHash: "quoted-filter", Classification: "white", Modifications: true, Active: true},
+	} {
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{"topic like 'filter-%' AND classification=red AND modifications=true", []string{"filter-red-modifications"}},
+		{"topic LIKE filter-% and classification != red", []string{"filter-green"}},
+		{"topic in (filter-red, 'filter-green') AND type eq risk", []string{"filter-green", "filter-red"}},
+		{`text="Filter A AND B, quoted"`, []string{"quoted-filter"}},
+		{"text in ('Filter A AND B, quoted', x) and modifications=true", []string{"quoted-filter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			w := makeRequest("GET", "/api/v1/obligations?limit=1000&filter="+url.QueryEscape(tt.filter), nil, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var res models.ObligationResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Errorf("Error unmarshalling JSON: %v", err)
+				return
+			}
+			var topics []string
+			for _, obligation := range res.Data {
+				topics = append(topics, obligation.Topic)
+			}
+			assert.Equal(t, tt.expected, topics)
+		})
+	}
+
+	for _, filter := range []string{"md5=filter-red", "modifications like true", "active=maybe", "topic", "1=1 OR topic=x"} {
+		w := makeRequest("GET", "/api/v1/obligations?filter="+url.QueryEscape(filter), nil, false)
+		assert.Equal(t, http.StatusBadRequest, w.Code, filter)
+	}
+}
+
+func TestGetAllObligationFields(t *testing.T) {
+	w := makeRequest("GET", "/api/v1/obligations?fields=topic,classification,topic", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationProjectionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.NotEmpty(t, res.Data)
+	for _, obligation := range res.Data {
+		assert.Len(t, obligation, 2)
+		assert.Contains(t, obligation, "topic")
+		assert.Contains(t, obligation, "classification")
+	}
+	assert.NotNil(t, res.Meta)
+
+	w = makeRequest("GET", "/api/v1/obligations?fields=topic,md5", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAllObligationExpandLicenses(t *testing.T) {
+	var license models.LicenseDB
+	if err := db.DB.Where("rf_shortname = ?", "MIT").First(&license).Error; err != nil {
+		t.Fatalf("Unable to find license: %v", err)
+	}
+	for _, topic := range []string{"expand-licenses-a", "expand-licenses-b"} {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Active: true}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		if err := db.DB.Create(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).Error; err != nil {
+			t.Fatalf("Unable to create obligation map: %v", err)
+		}
+	}
+	filter := url.QueryEscape("topic like 'expand-licenses-%'")
+
+	w := makeRequest("GET", "/api/v1/obligations?expandLicenses=true&filter="+filter, nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Len(t, res.Data, 2)
+	assert.Nil(t, res.Included)
+	for _, obligation := range res.Data {
+		if assert.Len(t, obligation.Licenses, 1) {
+			assert.Equal(t, license.Id, obligation.Licenses[0].Id)
+			assert.Equal(t, "MIT", obligation.Licenses[0].Shortname)
+		}
+		assert.Empty(t, obligation.LicenseIds)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations?dedupeLicenses=true&filter="+filter, nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	res = models.ObligationResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Len(t, res.Data, 2)
+	for _, obligation := range res.Data {
+		assert.Equal(t, []int64{license.Id}, obligation.LicenseIds)
+		assert.Empty(t, obligation.Licenses)
+	}
+	if assert.Len(t, res.Included, 1) {
+		assert.Equal(t, "MIT", res.Included[license.Id].Shortname)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations?filter="+filter, nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"included"`)
+	assert.NotContains(t, w.Body.String(), `"licenses"`)
+
+	w = makeRequest("GET", "/api/v1/obligations?expandLicenses=maybe", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations?expandLicenses=true&fields=topic", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations?dedupeLicenses=true", nil, false,
+		map[string]string{"X-Response-Envelope": "none"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAllObligationIncludeInactive(t *testing.T) {
+	for _, obligation := range []models.Obligation{
+		{Topic: "include-inactive-active", Type: "obligation", Text: "Active obligation text", TextHash: "include-inactive-active", Active: true},
+		{Topic: "include-inactive-inactive", Type: "obligation", Text: "Inactive obligation text", TextHash: "include-inactive-inactive", Active: false},
+	} {
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations?includeInactive=true&limit=1000", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = makeRequest("GET", "/api/v1/obligations?includeInactive=true&limit=1000", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	states := make(map[string]bool)
+	for _, obligation := range res.Data {
+		states[obligation.Topic] = obligation.Active
+	}
+	if assert.Contains(t, states, "include-inactive-active") {
+		assert.True(t, states["include-inactive-active"])
+	}
+	if assert.Contains(t, states, "include-inactive-inactive") {
+		assert.False(t, states["include-inactive-inactive"])
+	}
+}
+
+func TestUpdateObligationClearComment(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "clear-comment",
+		Type:           "obligation",
+		Text:           "Obligation text for clearing the comment",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{},
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = makeRequest("PATCH", "/api/v1/obligations/clear-comment", map[string]interface{}{"comment": "to be cleared"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("PATCH", "/api/v1/obligations/clear-comment", map[string]interface{}{"comment": nil}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, "", res.Data[0].Comment)
+
+	var stored models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "clear-comment"}).First(&stored).Error; err != nil {
+		t.Fatalf("Unable to fetch obligation: %v", err)
+	}
+	assert.Equal(t, "", stored.Comment)
+
+	var change models.ChangeLog
+	if err := db.DB.Joins("JOIN audits ON audits.id = change_logs.audit_id").
+		Where("audits.type = ? AND audits.type_id = ? AND change_logs.field = ?", "Obligation", stored.Id, "Comment").
+		Order("change_logs.id desc").First(&change).Error; err != nil {
+		t.Fatalf("Unable to fetch changelog: %v", err)
+	}
+	if assert.NotNil(t, change.OldValue) {
+		assert.Equal(t, "to be cleared", *change.OldValue)
+	}
+	assert.Nil(t, change.UpdatedValue)
+}
+
+func TestUpdateObligationNullForbidden(t *testing.T) {
+	obligation := models.Obligation{Topic: "null-type", Type: "obligation", Text: "Obligation text with a type",
+		TextHash: "null-type", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	// Type may be left out of an update
+	w := makeRequest("PATCH", "/api/v1/obligations/null-type", map[string]interface{}{"modifications": true}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// but can not be null
+	w = makeRequest("PATCH", "/api/v1/obligations/null-type", map[string]interface{}{"type": nil}, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var res models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Contains(t, res.Error, "type")
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, "type", res.Errors[0].Field)
+		assert.Equal(t, "not_null", res.Errors[0].Rule)
+		assert.Equal(t, "type cannot be null", res.Errors[0].Message)
+	}
+
+	var stored models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "null-type"}).First(&stored).Error; err != nil {
+		t.Fatalf("Unable to fetch obligation: %v", err)
+	}
+	assert.Equal(t, "obligation", stored.Type)
+}
+
+func TestOptionalDataLargeIntegers(t *testing.T) {
+	var input struct {
+		Id       models.OptionalData[int64]            `json:"id"`
+		Score    models.NullableAndOptionalData[int64] `json:"score"`
+		Metadata models.OptionalData[interface{}]      `json:"metadata"`
+	}
+	body := fmt.Sprintf(`{"id": %d, "score": %d, "metadata": {"id": %d}}`,
+		int64(math.MaxInt64), int64(math.MaxInt64-1), int64(math.MaxInt64-2))
+	if err := json.Unmarshal([]byte(body), &input); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, int64(math.MaxInt64), input.Id.Value)
+	assert.Equal(t, int64(math.MaxInt64-1), input.Score.Value)
+	// Numbers of untyped values are kept as json.Number instead of float64
+	metadata, ok := input.Metadata.Value.(map[string]interface{})
+	if assert.True(t, ok) {
+		assert.Equal(t, json.Number(strconv.FormatInt(math.MaxInt64-2, 10)), metadata["id"])
+	}
+
+	out, err := json.Marshal(map[string]interface{}{"id": input.Id.Value, "metadata": input.Metadata.Value})
+	if err != nil {
+		t.Fatalf("Error marshalling JSON: %v", err)
+	}
+	assert.Equal(t, fmt.Sprintf(`{"id":%d,"metadata":{"id":%d}}`, int64(math.MaxInt64), int64(math.MaxInt64-2)),
+		string(out))
+}
+
+func TestCreateObligationWithoutShortnames(t *testing.T) {
+	tests := []struct {
+		name       string
+		shortnames interface{}
+	}{
+		{"omitted", nil},
+		{"empty", []string{}},
+		{"empty strings", []string{"", ""}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic := fmt.Sprintf("no-shortnames-%d", i)
+			obligation := map[string]interface{}{
+				"topic":          topic,
+				"type":           "obligation",
+				"text":           fmt.Sprintf("Obligation text without shortnames %d", i),
+				"classification": "green",
+				"modifications":  true,
+				"comment":        "comment",
+				"active":         true,
+			}
+			if tt.shortnames != nil {
+				obligation["shortnames"] = tt.shortnames
+			}
+			w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+			assert.Equal(t, http.StatusCreated, w.Code)
+
+			var res models.ObligationResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Errorf("Error unmarshalling JSON: %v", err)
+				return
+			}
+			var count int64
+			db.DB.Model(&models.ObligationMap{}).Where(models.ObligationMap{ObligationPk: res.Data[0].Id}).Count(&count)
+			assert.Equal(t, int64(0), count)
+		})
+	}
+}
+
+func TestCreateObligationExpectedMd5(t *testing.T) {
+	text := "Obligation text verified by its md5"
+	hash := md5.Sum([]byte(text))
+	obligation := func(topic, expectedMd5 string) map[string]interface{} {
+		return map[string]interface{}{
+			"topic":          topic,
+			"type":           "obligation",
+			"text":           text,
+			"classification": "green",
+			"modifications":  true,
+			"comment":        "comment",
+			"active":         true,
+			"expected_md5":   expectedMd5,
+		}
+	}
+
+	w := makeRequest("POST", "/api/v1/obligations", obligation("md5-mismatch", strings.Repeat("0", 32)), true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var res models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, "expected_md5", res.Errors[0].Field)
+	}
+	var count int64
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "md5-mismatch"}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	w = makeRequest("POST", "/api/v1/obligations",
+		obligation("md5-match", strings.ToUpper(hex.EncodeToString(hash[:]))), true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestCreateObligationMissingLicenses(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "missing-licenses",
+		Type:           "obligation",
+		Text:           "Obligation text mapped to missing licenses",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{"MIT", "Missing-License-1.0"},
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = makeRequest("POST", "/api/v1/obligations?createMissingLicenses=true", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = makeRequest("GET", "/api/v1/licenses?autoCreated=true", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.LicenseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "Missing-License-1.0", *res.Data[0].Shortname)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligation_maps/topic/missing-licenses?status=DRAFT", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Missing-License-1.0")
+}
+
+func TestCreateObligationDryRun(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "dry-run",
+		Type:           "obligation",
+		Text:           "Obligation text which is only validated",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{"MIT"},
+		Active:         true,
+	}
+	dryRun := func() models.ObligationDryRunResponse {
+		w := makeRequest("POST", "/api/v1/obligations?dryRun=true", obligation, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationDryRunResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res
+	}
+
+	res := dryRun()
+	assert.Equal(t, models.DRY_RUN_CREATED, res.Outcome)
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "dry-run", res.Data[0].Topic)
+	}
+	var count int64
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "dry-run"}).Count(&count)
+	assert.Zero(t, count)
+
+	obligation.Shortnames = []string{"MIT", "Dry-Run-License-1.0"}
+	res = dryRun()
+	assert.Equal(t, models.DRY_RUN_UNKNOWN_SHORTNAMES, res.Outcome)
+	assert.Equal(t, []string{"Dry-Run-License-1.0"}, res.UnknownShortnames)
+
+	w := makeRequest("POST", "/api/v1/obligations?dryRun=true&createMissingLicenses=true", obligation, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, models.DRY_RUN_CREATED, res.Outcome)
+	assert.Equal(t, []string{"Dry-Run-License-1.0"}, res.UnknownShortnames)
+	db.DB.Model(&models.LicenseDB{}).Where("rf_shortname = ?", "Dry-Run-License-1.0").Count(&count)
+	assert.Zero(t, count)
+
+	obligation.Shortnames = []string{"MIT"}
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	res = dryRun()
+	assert.Equal(t, models.DRY_RUN_CONFLICT, res.Outcome)
+
+	// The dry run conflicts where the create does, as for a former topic
+	var created models.Obligation
+	db.DB.Where(models.Obligation{Topic: "dry-run"}).First(&created)
+	if err := db.DB.Create(&models.ObligationTopicRedirect{Topic: "dry-run-former", ObligationPk: created.Id}).Error; err != nil {
+		t.Fatalf("Unable to reserve topic: %v", err)
+	}
+	obligation.Topic = "dry-run-former"
+	obligation.Text = "Obligation text under a former topic"
+	res = dryRun()
+	assert.Equal(t, models.DRY_RUN_CONFLICT, res.Outcome)
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "dry-run", res.Data[0].Topic)
+	}
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest("POST", "/api/v1/obligations?dryRun=maybe", obligation, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateObligationShortTextConflict(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "short-text",
+		Type:           "obligation",
+		Text:           "Short",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	obligation.Topic = "short-text-again"
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var res models.ObligationConflictError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, "short-text", res.Existing.Topic)
+	assert.Contains(t, res.Error, "'Short'")
+}
+
+func TestCreateObligationTimeout(t *testing.T) {
+	// The insert of the obligation blocks until the request timeout cancels it
+	const callback = "test:block_create"
+	if err := db.DB.Callback().Create().Before("gorm:create").Register(callback, func(tx *gorm.DB) {
+		if obligation, ok := tx.Statement.Dest.(*models.Obligation); ok && obligation.Topic == "create-timeout" {
+			<-tx.Statement.Context.Done()
+			_ = tx.AddError(tx.Statement.Context.Err())
+		}
+	}); err != nil {
+		t.Fatalf("Error registering callback: %v", err)
+	}
+	defer db.DB.Callback().Create().Remove(callback)
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "1")
+
+	w := makeRequest("POST", "/api/v1/obligations", models.ObligationPOSTRequestJSONSchema{Topic: "create-timeout",
+		Type: "obligation", Text: "Obligation text of a create which times out", Classification: "green",
+		Modifications: true, Comment: "comment", Active: true}, true)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	var res models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, http.StatusGatewayTimeout, res.Status)
+
+	var count int64
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "create-timeout"}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestGetObligationGraph(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "graph-obligation",
+		Type:           "obligation",
+		Text:           "Obligation text for the obligation graph",
+		Classification: "red",
+		Modifications:  true,
+		Comment:        "comment",
+		Shortnames:     []string{"MIT"},
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = makeRequest("GET", "/api/v1/obligations/graph?licenses=MIT&classification=red&status=DRAFT", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationGraphResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+
+	labels := make(map[string]string)
+	for _, node := range res.Data.Nodes {
+		labels[node.Id] = node.Label
+		if node.Kind == "obligation" {
+			assert.Equal(t, "red", node.Classification)
+		}
+	}
+	found := false
+	for _, edge := range res.Data.Edges {
+		assert.Equal(t, "MIT", labels[edge.Target])
+		if labels[edge.Source] == "graph-obligation" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestUpdateObligationTextChangeReason(t *testing.T) {
+	obligation := models.Obligation{Topic: "change-reason", Type: "obligation", Text: "Obligation text before the change",
+		TextHash: "change-reason", Active: true, TextUpdatable: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	w := makeRequest("PATCH", "/api/v1/obligations/change-reason",
+		map[string]interface{}{"text": "Obligation text after the change"}, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var validation models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &validation); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, validation.Errors, 1) {
+		assert.Equal(t, "change_reason", validation.Errors[0].Field)
+	}
+
+	w = makeRequest("PATCH", "/api/v1/obligations/change-reason",
+		map[string]interface{}{"text": "Obligation text after the change", "change_reason": "Aligned with the license"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("GET", "/api/v1/obligations/change-reason/audits", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.AuditResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "Aligned with the license", res.Data[0].ChangeReason)
+	}
+}
+
+func TestGetAuditCountsByUser(t *testing.T) {
+	reviewer := models.User{Username: "audit-reviewer", Userlevel: "user"}
+	idle := models.User{Username: "audit-idle", Userlevel: "user"}
+	for _, user := range []*models.User{&reviewer, &idle} {
+		if err := db.DB.Create(user).Error; err != nil {
+			t.Fatalf("Unable to create user: %v", err)
+		}
+	}
+	obligation := models.Obligation{Topic: "audits-by-user", Type: "obligation", Text: "Obligation text audited by users",
+		TextHash: "audits-by-user", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	audits := []models.Audit{
+		{UserId: reviewer.Id, Timestamp: now.AddDate(0, 0, -40), Type: "Obligation", TypeId: obligation.Id},
+		{UserId: reviewer.Id, Timestamp: now.Add(-2 * time.Hour), Type: "Obligation", TypeId: obligation.Id},
+		{UserId: reviewer.Id, Timestamp: now.Add(-time.Hour), Type: "Obligation", TypeId: obligation.Id},
+		// Audits of licenses are not counted
+		{UserId: idle.Id, Timestamp: now, Type: "license", TypeId: 1},
+	}
+	if err := db.DB.Omit("User").Create(&audits).Error; err != nil {
+		t.Fatalf("Unable to create audits: %v", err)
+	}
+
+	// activities gives the activities of the users of this test by username
+	activities := func(query string) map[string]models.AuditUserActivity {
+		w := makeRequest("GET", "/api/v1/audits/by-user?"+query, nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.AuditUserActivityResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		byUsername := make(map[string]models.AuditUserActivity)
+		for i, activity := range res.Data {
+			if i > 0 {
+				assert.LessOrEqual(t, activity.Audits, res.Data[i-1].Audits)
+			}
+			byUsername[activity.Username] = activity
+		}
+		return byUsername
+	}
+
+	all := activities("")
+	assert.Equal(t, int64(3), all["audit-reviewer"].Audits)
+	if assert.NotNil(t, all["audit-reviewer"].LastActivityAt) {
+		assert.WithinDuration(t, now.Add(-time.Hour), *all["audit-reviewer"].LastActivityAt, time.Second)
+	}
+	assert.Equal(t, int64(0), all["audit-idle"].Audits)
+	assert.Nil(t, all["audit-idle"].LastActivityAt)
+
+	lastMonth := activities("from=" + url.QueryEscape(now.AddDate(0, 0, -30).Format(time.RFC3339)) +
+		"&to=" + url.QueryEscape(now.Add(-90*time.Minute).Format(time.RFC3339)))
+	assert.Equal(t, int64(1), lastMonth["audit-reviewer"].Audits)
+	if assert.NotNil(t, lastMonth["audit-reviewer"].LastActivityAt) {
+		assert.WithinDuration(t, now.Add(-2*time.Hour), *lastMonth["audit-reviewer"].LastActivityAt, time.Second)
+	}
+
+	w := makeRequest("GET", "/api/v1/audits/by-user?from=lastmonth", nil, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetObligationAuditsPagination(t *testing.T) {
+	obligation := models.Obligation{Topic: "audit-pages", Type: "obligation", Text: "Obligation text with many audits",
+		TextHash: "audit-pages", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	// Audits of the same time must still be paged in a stable order
+	timestamp := time.Now()
+	audits := make([]models.Audit, 25)
+	for i := range audits {
+		audits[i] = models.Audit{UserId: user.Id, Timestamp: timestamp, Type: "Obligation", TypeId: obligation.Id}
+	}
+	if err := db.DB.Omit("User").Create(&audits).Error; err != nil {
+		t.Fatalf("Unable to create audits: %v", err)
+	}
+
+	seen := make(map[int64]bool)
+	for page := 1; page <= 3; page++ {
+		w := makeRequest("GET", fmt.Sprintf("/api/v1/obligations/audit-pages/audits?page=%d&limit=10", page), nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.AuditResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		assert.Equal(t, 25, res.Meta.ResourceCount)
+		assert.Equal(t, int64(3), res.Meta.TotalPages)
+		assert.Equal(t, int64(page), res.Meta.Page)
+		assert.Equal(t, page < 3, res.Meta.Next != "")
+		assert.Equal(t, page > 1, res.Meta.Previous != "")
+		for i, audit := range res.Data {
+			assert.False(t, seen[audit.Id])
+			seen[audit.Id] = true
+			if i > 0 {
+				assert.Greater(t, res.Data[i-1].Id, audit.Id)
+			}
+		}
+	}
+	assert.Len(t, seen, 25)
+}
+
+func TestGetObligationSnapshots(t *testing.T) {
+	obligation := models.Obligation{Topic: "snapshot-topic", Type: "obligation", Text: "Obligation text to be snapshot",
+		TextHash: "snapshot-topic", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	if _, err := createObligationSnapshot(db.DB, "fossy", &obligation, models.OBLIGATION_SNAPSHOT_REASON_MANUAL); err != nil {
+		t.Fatalf("Unable to create snapshot: %v", err)
+	}
+	// Snapshots keep the obligation as it was when taken
+	if err := db.DB.Model(&obligation).UpdateColumn("text", "Changed obligation text").Error; err != nil {
+		t.Fatalf("Unable to update obligation: %v", err)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/snapshot-topic/snapshots", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationSnapshotResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, models.OBLIGATION_SNAPSHOT_REASON_MANUAL, res.Data[0].Reason)
+		assert.Equal(t, "snapshot-topic", res.Data[0].Obligation.Topic)
+		assert.Equal(t, "Obligation text to be snapshot", res.Data[0].Obligation.Text)
+		assert.Equal(t, "fossy", res.Data[0].User.Username)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/missing-topic/snapshots", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteObligationAudit(t *testing.T) {
+	obligation := models.Obligation{Topic: "delete-audit", Type: "obligation", Text: "Obligation text to be deactivated",
+		TextHash: "delete-audit", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	w := makeRequest("DELETE", "/api/v1/obligations/delete-audit", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var audits []models.Audit
+	if err := db.DB.Preload("User").Preload("ChangeLogs").
+		Where(models.Audit{TypeId: obligation.Id, Type: "Obligation"}).Find(&audits).Error; err != nil {
+		t.Fatalf("Unable to fetch audits: %v", err)
+	}
+	if assert.Len(t, audits, 1) {
+		assert.Equal(t, "fossy", audits[0].User.Username)
+		if assert.Len(t, audits[0].ChangeLogs, 1) {
+			change := audits[0].ChangeLogs[0]
+			assert.Equal(t, "Active", change.Field)
+			assert.Equal(t, "true", *change.OldValue)
+			assert.Equal(t, "false", *change.UpdatedValue)
+		}
+	}
+}
+
+func TestDeleteObligationResponse(t *testing.T) {
+	for _, topic := range []string{"delete-response", "delete-response-minimal"} {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Active: true}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	w := makeRequest("DELETE", "/api/v1/obligations/delete-response", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "delete-response", res.Data[0].Topic)
+		assert.False(t, res.Data[0].Active)
+	}
+
+	w = makeRequestWithHeaders("DELETE", "/api/v1/obligations/delete-response-minimal", nil, true,
+		map[string]string{"Prefer": "return=minimal"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestObligationLicenseCountConcurrent(t *testing.T) {
+	if !db.IsPostgres() {
+		t.Skip("concurrent writes are serialized by locking the whole sqlite database")
+	}
+
+	obligation := models.Obligation{Topic: "license-count", Type: "obligation", Text: "Obligation text with counted licenses",
+		TextHash: "license-count", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	var licenseIds []int64
+	if err := db.DB.Model(&models.LicenseDB{}).Order("rf_id").Limit(20).Pluck("rf_id", &licenseIds).Error; err != nil {
+		t.Fatalf("Unable to fetch licenses: %v", err)
+	}
+
+	// Every worker maps its own license, and every other one removes it again
+	var wg sync.WaitGroup
+	for i, licenseId := range licenseIds {
+		wg.Add(1)
+		go func(i int, licenseId int64) {
+			defer wg.Done()
+			if _, err := PerformObligationMapActions("fossy", obligation, nil, []int64{licenseId}); err != nil {
+				t.Errorf("Unable to map license: %v", err)
+				return
+			}
+			if i%2 == 0 {
+				if _, err := PerformObligationMapActions("fossy", obligation, []int64{licenseId}, nil); err != nil {
+					t.Errorf("Unable to unmap license: %v", err)
+				}
+			}
+		}(i, licenseId)
+	}
+	wg.Wait()
+
+	var maps int64
+	db.DB.Model(&models.ObligationMap{}).Where(models.ObligationMap{ObligationPk: obligation.Id}).Count(&maps)
+	if err := db.DB.First(&obligation, obligation.Id).Error; err != nil {
+		t.Fatalf("Unable to fetch obligation: %v", err)
+	}
+	assert.Equal(t, int64(len(licenseIds)/2), maps)
+	assert.Equal(t, maps, obligation.LicenseCount)
+}
+
+func TestObligationMapAudits(t *testing.T) {
+	obligation := models.Obligation{Topic: "map-audits", Type: "obligation", Text: "Obligation text with audited maps",
+		TextHash: "map-audits", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	shortname := "MIT"
+	var license models.LicenseDB
+	if err := db.DB.Where(&models.LicenseDB{Shortname: &shortname}).First(&license).Error; err != nil {
+		t.Fatalf("Unable to fetch license: %v", err)
+	}
+
+	lastChange := func() models.ChangeLog {
+		var audit models.Audit
+		if err := db.DB.Preload("User").Preload("ChangeLogs").
+			Where(models.Audit{Type: "Obligation", TypeId: obligation.Id}).Order("id desc").First(&audit).Error; err != nil {
+			t.Fatalf("Unable to fetch audit: %v", err)
+		}
+		assert.Equal(t, "fossy", audit.User.Username)
+		if !assert.Len(t, audit.ChangeLogs, 1) {
+			t.FailNow()
+		}
+		return audit.ChangeLogs[0]
+	}
+
+	// Associating a license is recorded as the shortname added
+	if _, err := PerformObligationMapActions("fossy", obligation, nil, []int64{license.Id}); err != nil {
+		t.Fatalf("Unable to map license: %v", err)
+	}
+	change := lastChange()
+	assert.Equal(t, "Shortnames", change.Field)
+	assert.Nil(t, change.OldValue)
+	if assert.NotNil(t, change.UpdatedValue) {
+		assert.Equal(t, "MIT", *change.UpdatedValue)
+	}
+
+	// and dissociating it as the shortname removed
+	if _, err := PerformObligationMapActions("fossy", obligation, []int64{license.Id}, nil); err != nil {
+		t.Fatalf("Unable to unmap license: %v", err)
+	}
+	change = lastChange()
+	assert.Equal(t, "Shortnames", change.Field)
+	assert.Nil(t, change.UpdatedValue)
+	if assert.NotNil(t, change.OldValue) {
+		assert.Equal(t, "MIT", *change.OldValue)
+	}
+
+	var audits int64
+	db.DB.Model(&models.Audit{}).Where(models.Audit{Type: "Obligation", TypeId: obligation.Id}).Count(&audits)
+	assert.Equal(t, int64(2), audits)
+}
+
+func TestImportObligationMaps(t *testing.T) {
+	input := models.ObligationMapImportInput{
+		Maps: []models.ObligationMapPair{
+			{Topic: "conditional-update", Shortname: "MIT"},
+			{Topic: "conditional-update", Shortname: "MIT"},
+			{Topic: "no-such-topic", Shortname: "MIT"},
+			{Topic: "conditional-update", Shortname: "No-Such-License"},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		w := makeRequest("POST", "/api/v1/obligation_maps/import", input, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationMapImportResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		assert.Equal(t, 1, res.Data.Created+res.Data.Existing)
+		if i == 1 {
+			assert.Equal(t, 1, res.Data.Existing)
+		}
+		assert.Equal(t, []string{"no-such-topic"}, res.Data.UnresolvedTopics)
+		assert.Equal(t, []string{"No-Such-License"}, res.Data.UnresolvedShortnames)
+	}
+
+	pairs, err := parseObligationMapsCSV(strings.NewReader("topic,shortname\ncopyleft, GPL-2.0-only\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ObligationMapPair{{Topic: "copyleft", Shortname: "GPL-2.0-only"}}, pairs)
+	_, err = parseObligationMapsCSV(strings.NewReader("copyleft,GPL-2.0-only\n"))
+	assert.Error(t, err)
+	_, err = parseObligationMapsCSV(strings.NewReader("topic,shortname\ncopyleft,\n"))
+	assert.Error(t, err)
+}
+
+func TestGetObligationMappingHealth(t *testing.T) {
+	unmapped := models.Obligation{Topic: "health-unmapped", Type: "obligation", Text: "Unmapped obligation text",
+		TextHash: "health-unmapped", Active: true}
+	phantom := models.Obligation{Topic: "health-missing", Type: "obligation", Text: "Obligation text mapped to no license",
+		TextHash: "health-missing", Active: true}
+	for _, obligation := range []*models.Obligation{&unmapped, &phantom} {
+		if err := db.DB.Create(obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	if err := db.DB.Create(&models.ObligationMap{ObligationPk: phantom.Id, RfPk: 0}).Error; err != nil {
+		t.Fatalf("Unable to create obligation map: %v", err)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/mapping-health", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationMappingHealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Contains(t, res.Data.Unmapped.Topics, "health-unmapped")
+	assert.Contains(t, res.Data.MissingLicenses.Topics, "health-missing")
+	assert.Equal(t, len(res.Data.Unmapped.Topics), res.Data.Unmapped.Count)
+}
+
+func TestUpdateObligationFieldPermissions(t *testing.T) {
+	defaultPolicy := ObligationFieldPolicy
+	defer func() { ObligationFieldPolicy = defaultPolicy }()
+	ObligationFieldPolicy = func(userlevel string) []string {
+		return []string{"comment"}
+	}
+
+	t.Run("disallowed field", func(t *testing.T) {
+		update := map[string]interface{}{
+			"comment":        "allowed comment",
+			"classification": "red",
+		}
+		w := makeRequest("PATCH", "/api/v1/obligations/conditional-update", update, true)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var res models.LicenseError
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Errorf("Error unmarshalling JSON: %v", err)
+			return
+		}
+		assert.Contains(t, res.Message, "classification")
+	})
+
+	t.Run("allowed field", func(t *testing.T) {
+		update := map[string]interface{}{
+			"comment": "allowed comment",
+		}
+		w := makeRequest("PATCH", "/api/v1/obligations/conditional-update", update, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestObligationLock(t *testing.T) {
+	defaultTTL := ObligationLockTTL
+	defer func() { ObligationLockTTL = defaultTTL }()
+	ObligationLockTTL = func() time.Duration { return time.Hour }
+
+	obligation := models.Obligation{Topic: "locked-obligation", Type: "obligation",
+		Text: "Obligation text under review", TextHash: "locked-obligation", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	reviewer := models.User{Username: "lock-reviewer", Userlevel: "participant"}
+	if err := db.DB.Create(&reviewer).Error; err != nil {
+		t.Fatalf("Unable to create user: %v", err)
+	}
+	lock := models.ObligationLock{ObligationPk: obligation.Id, UserId: reviewer.Id, LockedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.DB.Omit("User").Create(&lock).Error; err != nil {
+		t.Fatalf("Unable to create lock: %v", err)
+	}
+
+	// The lock of another user blocks the updates and is returned with the obligation
+	update := map[string]interface{}{"comment": "edited during review"}
+	w := makeRequest("PATCH", "/api/v1/obligations/locked-obligation", update, true)
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Contains(t, w.Body.String(), "lock-reviewer")
+	w = makeRequest("POST", "/api/v1/obligations/locked-obligation/lock", nil, true)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	w = makeRequest("GET", "/api/v1/obligations/locked-obligation", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.NotNil(t, res.Data[0].Lock) {
+		assert.Equal(t, "lock-reviewer", res.Data[0].Lock.User.Username)
+	}
+
+	// Admins can release the locks of others
+	w = makeRequest("DELETE", "/api/v1/obligations/locked-obligation/lock", nil, true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	w = makeRequest("DELETE", "/api/v1/obligations/locked-obligation/lock", nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The holder of the lock can update the obligation
+	w = makeRequest("POST", "/api/v1/obligations/locked-obligation/lock", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var lockRes models.ObligationLockResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &lockRes); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, "fossy", lockRes.Data.User.Username)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), lockRes.Data.ExpiresAt, time.Minute)
+	w = makeRequest("PATCH", "/api/v1/obligations/locked-obligation", update, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Expired locks no longer block the updates
+	if err := db.DB.Model(&models.ObligationLock{}).Where(models.ObligationLock{ObligationPk: obligation.Id}).
+		Updates(map[string]interface{}{"user_id": reviewer.Id, "expires_at": time.Now().Add(-time.Minute)}).Error; err != nil {
+		t.Fatalf("Unable to expire lock: %v", err)
+	}
+	update["comment"] = "edited after the review"
+	w = makeRequest("PATCH", "/api/v1/obligations/locked-obligation", update, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/locked-obligation", nil, false)
+	assert.NotContains(t, w.Body.String(), `"lock"`)
+}
+
+func TestLockedObligationBulkEdits(t *testing.T) {
+	obligation := models.Obligation{Topic: "locked-bulk-edit", Type: "obligation",
+		Text: "Obligation text under review in bulk edits", TextHash: "locked-bulk-edit",
+		Classification: "green", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	reviewer := models.User{Username: "bulk-lock-reviewer", Userlevel: "participant"}
+	if err := db.DB.Create(&reviewer).Error; err != nil {
+		t.Fatalf("Unable to create user: %v", err)
+	}
+	lock := models.ObligationLock{ObligationPk: obligation.Id, UserId: reviewer.Id, LockedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.DB.Omit("User").Create(&lock).Error; err != nil {
+		t.Fatalf("Unable to create lock: %v", err)
+	}
+	stored := func() models.Obligation {
+		var stored models.Obligation
+		if err := db.DB.Where(models.Obligation{Topic: "locked-bulk-edit"}).First(&stored).Error; err != nil {
+			t.Fatalf("Unable to fetch obligation: %v", err)
+		}
+		return stored
+	}
+
+	t.Run("import", func(t *testing.T) {
+		// importObligation imports the obligation with the strategy and gives the status of the import
+		importObligation := func(strategy string, obligation models.ObligationJSONFileFormat) int {
+			content, _ := json.Marshal([]models.ObligationJSONFileFormat{obligation})
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("file", "obligations.json")
+			_, _ = part.Write(content)
+			_ = writer.Close()
+
+			req := httptest.NewRequest("POST", "/api/v1/obligations/import?strategy="+strategy, body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", authToken)
+			w := httptest.NewRecorder()
+			Router().ServeHTTP(w, req)
+			if !assert.Equal(t, http.StatusOK, w.Code) {
+				return 0
+			}
+			var res struct {
+				Data []struct {
+					Status  int    `json:"status"`
+					Message string `json:"message"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			if !assert.Len(t, res.Data, 1) {
+				return 0
+			}
+			if res.Data[0].Status == http.StatusLocked {
+				assert.Contains(t, res.Data[0].Message, "bulk-lock-reviewer")
+			}
+			return res.Data[0].Status
+		}
+
+		imported := models.ObligationJSONFileFormat{Topic: "locked-bulk-edit", Type: "obligation",
+			Text: obligation.Text, Classification: "red", Comment: "imported during review", Shortnames: []string{}}
+		assert.Equal(t, http.StatusLocked, importObligation(models.IMPORT_STRATEGY_OVERWRITE, imported))
+		assert.Equal(t, http.StatusLocked, importObligation(models.IMPORT_STRATEGY_MERGE, imported))
+		assert.Equal(t, "green", stored().Classification)
+		assert.Empty(t, stored().Comment)
+	})
+
+	t.Run("deactivate-by-topics", func(t *testing.T) {
+		w := makeRequest("POST", "/api/v1/obligations/deactivate-by-topics", map[string]interface{}{
+			"topics": []string{"locked-bulk-edit"},
+		}, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationDeactivateResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		assert.Equal(t, []string{"locked-bulk-edit"}, res.Data.Locked)
+		assert.Empty(t, res.Data.Deactivated)
+		assert.True(t, stored().Active)
+
+		// The holder of the lock can deactivate the obligation
+		var user models.User
+		if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+			t.Fatalf("Unable to fetch user: %v", err)
+		}
+		if err := db.DB.Model(&models.ObligationLock{}).Where(models.ObligationLock{ObligationPk: obligation.Id}).
+			Update("user_id", user.Id).Error; err != nil {
+			t.Fatalf("Unable to take over lock: %v", err)
+		}
+		w = makeRequest("POST", "/api/v1/obligations/deactivate-by-topics", map[string]interface{}{
+			"topics": []string{"locked-bulk-edit"},
+		}, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, stored().Active)
+	})
+}
+
+func TestObligationCreationQuota(t *testing.T) {
+	defaultQuota := ObligationCreationQuota
+	defer func() { ObligationCreationQuota = defaultQuota }()
+	ObligationCreationQuota = func(userlevel string) int { return 2 }
+
+	tx := db.DB.Begin()
+	defer tx.Rollback()
+
+	assert.NoError(t, consumeObligationCreationQuota(tx, "fossy", 1))
+	assert.NoError(t, consumeObligationCreationQuota(tx, "fossy", 1))
+	err := consumeObligationCreationQuota(tx, "fossy", 1)
+	assert.ErrorIs(t, err, errObligationQuotaExceeded)
+
+	var count models.ObligationCreationCount
+	if err := tx.Where("day = ?", time.Now().UTC().Format(time.DateOnly)).First(&count).Error; err != nil {
+		t.Fatalf("Error fetching creation count: %v", err)
+	}
+	assert.Equal(t, int64(3), count.Created)
+
+	ObligationCreationQuota = func(userlevel string) int { return 0 }
+	assert.NoError(t, consumeObligationCreationQuota(tx, "fossy", 1))
+}
+
+func TestGetObligationEditableFields(t *testing.T) {
+	defaultPolicy := ObligationFieldPolicy
+	defer func() { ObligationFieldPolicy = defaultPolicy }()
+	ObligationFieldPolicy = func(userlevel string) []string {
+		return []string{"comment", "text"}
+	}
+
+	var obligation models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "conditional-update"}).First(&obligation).Error; err != nil {
+		t.Fatalf("Error fetching obligation: %v", err)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/conditional-update/editable-fields", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationEditableFieldsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Len(t, res.Data, len(obligationPatchFields))
+	assert.True(t, res.Data["comment"])
+	assert.False(t, res.Data["classification"])
+	assert.Equal(t, obligation.TextUpdatable, res.Data["text"])
+
+	w = makeRequest("GET", "/api/v1/obligations/no-such-topic/editable-fields", nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestObligationValidationStatusCodes(t *testing.T) {
+	obligation := models.Obligation{Topic: "status-codes", Type: "obligation", Text: "Obligation text for status codes",
+		TextHash: "status-codes", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     interface{}
+		expected int
+	}{
+		{"create malformed json", "POST", "/api/v1/obligations", "not an object", http.StatusBadRequest},
+		{"create wrong field type", "POST", "/api/v1/obligations", map[string]interface{}{"topic": 1}, http.StatusBadRequest},
+		{"create bad classification", "POST", "/api/v1/obligations", map[string]interface{}{
+			"topic": "status-codes-new", "type": "obligation", "text": "Obligation text", "classification": "blue",
+			"modifications": true, "comment": "comment", "active": true,
+		}, http.StatusUnprocessableEntity},
+		{"create empty text", "POST", "/api/v1/obligations", map[string]interface{}{
+			"topic": "status-codes-new", "type": "obligation", "text": "", "classification": "green",
+			"modifications": true, "comment": "comment", "active": true,
+		}, http.StatusUnprocessableEntity},
+		{"update malformed json", "PATCH", "/api/v1/obligations/status-codes", "not an object", http.StatusBadRequest},
+		{"update empty text", "PATCH", "/api/v1/obligations/status-codes", map[string]interface{}{"text": ""},
+			http.StatusUnprocessableEntity},
+		{"update bad classification", "PATCH", "/api/v1/obligations/status-codes",
+			map[string]interface{}{"classification": "blue"}, http.StatusUnprocessableEntity},
+		{"update unknown type", "PATCH", "/api/v1/obligations/status-codes", map[string]interface{}{"type": "unknown"},
+			http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := makeRequest(tt.method, tt.path, tt.body, true)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestPaginationLinkHeader(t *testing.T) {
+	u, _ := url.Parse("/api/v1/obligations?active=true")
+	tests := []struct {
+		name       string
+		pagination models.PaginationInput
+		totalRows  int64
+		expected   string
+	}{
+		{"first page", models.PaginationInput{Page: 1, Limit: 10}, 25,
+			`</api/v1/obligations?active=true&limit=10&page=2>; rel="next", ` +
+				`</api/v1/obligations?active=true&limit=10&page=1>; rel="first", ` +
+				`</api/v1/obligations?active=true&limit=10&page=3>; rel="last"`},
+		{"last page", models.PaginationInput{Page: 3, Limit: 10}, 25,
+			`</api/v1/obligations?active=true&limit=10&page=2>; rel="prev", ` +
+				`</api/v1/obligations?active=true&limit=10&page=1>; rel="first", ` +
+				`</api/v1/obligations?active=true&limit=10&page=3>; rel="last"`},
+		{"past the end", models.PaginationInput{Page: 9, Limit: 10}, 25,
+			`</api/v1/obligations?active=true&limit=10&page=3>; rel="prev", ` +
+				`</api/v1/obligations?active=true&limit=10&page=1>; rel="first", ` +
+				`</api/v1/obligations?active=true&limit=10&page=3>; rel="last"`},
+		{"empty", models.PaginationInput{Page: 1, Limit: 10}, 0,
+			`</api/v1/obligations?active=true&limit=10&page=1>; rel="first", ` +
+				`</api/v1/obligations?active=true&limit=10&page=1>; rel="last"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils.PaginationLinkHeader(*u, tt.pagination, tt.totalRows))
+		})
+	}
+
+	// The middle page of three needs at least three licenses, which exist on a rerun
+	for i := 1; i <= 3; i++ {
+		shortname := fmt.Sprintf("link-header-%d", i)
+		license := models.LicenseDB{
+			Shortname: &shortname,
+			Fullname:  &shortname,
+			Text:      func(s string) *string { return &s }("license text of " + shortname),
+			SpdxId:    &shortname,
+		}
+		w := makeRequest("POST", "/api/v1/licenses", license, true)
+		assert.Contains(t, []int{http.StatusCreated, http.StatusConflict}, w.Code)
+	}
+
+	w := makeRequest("GET", "/api/v1/licenses?limit=1&page=2", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	link := w.Header().Get("Link")
+	for _, rel := range []string{"next", "prev", "first", "last"} {
+		assert.Contains(t, link, fmt.Sprintf(`rel="%s"`, rel))
+	}
+}
+
+func TestPaginationTruncationWarning(t *testing.T) {
+	w := makeRequest("GET", "/api/v1/licenses?limit=1", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-Result-Truncated"))
+	assert.Contains(t, w.Header().Get("Warning"), "Result truncated at limit 1")
+
+	w = makeRequest("GET", "/api/v1/licenses?limit=100000", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Result-Truncated"))
+	assert.Empty(t, w.Header().Get("Warning"))
+}
+
+func TestGetAllObligationNDJSON(t *testing.T) {
+	for _, topic := range []string{"ndjson-second", "ndjson-first"} {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text streamed as " + topic,
+			TextHash: topic, Classification: "green", Active: true}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	filter := "filter=" + url.QueryEscape("topic like 'ndjson-%'")
+
+	lines := func(query string, headers map[string]string) []map[string]interface{} {
+		w := makeRequestWithHeaders("GET", "/api/v1/obligations?"+query, nil, false, headers)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		var lines []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n") {
+			var obligation map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &obligation); err != nil {
+				t.Fatalf("Error unmarshalling JSON line %q: %v", line, err)
+			}
+			lines = append(lines, obligation)
+		}
+		return lines
+	}
+
+	obligations := lines("format=ndjson&"+filter, nil)
+	if assert.Len(t, obligations, 2) {
+		assert.Equal(t, "ndjson-first", obligations[0]["topic"])
+		assert.Equal(t, "Obligation text streamed as ndjson-first", obligations[0]["text"])
+		assert.Equal(t, "ndjson-second", obligations[1]["topic"])
+	}
+	assert.Equal(t, obligations, lines(filter, map[string]string{"Accept": "application/x-ndjson"}))
+
+	obligations = lines("format=ndjson&fields=topic&order_by=desc&"+filter, nil)
+	assert.Equal(t, []map[string]interface{}{{"topic": "ndjson-second"}, {"topic": "ndjson-first"}}, obligations)
+}
+
+func TestGzipCompression(t *testing.T) {
+	gzipHeaders := map[string]string{"Accept-Encoding": "gzip"}
+
+	for _, path := range []string{"/api/v1/licenses?limit=100", "/api/v1/obligations?format=ndjson"} {
+		t.Run(path, func(t *testing.T) {
+			w := makeRequestWithHeaders("GET", path, nil, false, gzipHeaders)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+			reader, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("Error reading compressed body: %v", err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Error reading compressed body: %v", err)
+			}
+			plain := makeRequest("GET", path, nil, false)
+			assert.Equal(t, plain.Body.String(), string(body))
+		})
+	}
+
+	w := makeRequestWithHeaders("GET", "/api/v1/health", nil, false, gzipHeaders)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+
+	w = makeRequest("GET", "/api/v1/licenses?limit=100", nil, false)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestParseLicenseExpression(t *testing.T) {
+	license := func(shortname string) models.LicenseExpressionNode {
+		return models.LicenseExpressionNode{License: shortname}
+	}
+	tests := []struct {
+		expression string
+		expected   models.LicenseExpressionNode
+		wantErr    bool
+	}{
+		{expression: "MIT", expected: license("MIT")},
+		{
+			expression: "(MIT OR Apache-2.0) AND GPL-2.0-only",
+			expected: models.LicenseExpressionNode{Operator: "AND", Children: []models.LicenseExpressionNode{
+				{Operator: "OR", Children: []models.LicenseExpressionNode{license("MIT"), license("Apache-2.0")}},
+				license("GPL-2.0-only"),
+			}},
+		},
+		{
+			expression: "MIT or Apache-2.0 and GPL-2.0-only",
+			expected: models.LicenseExpressionNode{Operator: "OR", Children: []models.LicenseExpressionNode{
+				license("MIT"),
+				{Operator: "AND", Children: []models.LicenseExpressionNode{license("Apache-2.0"), license("GPL-2.0-only")}},
+			}},
+		},
+		{
+			expression: "(MIT OR BSD-3-Clause) OR ISC",
+			expected: models.LicenseExpressionNode{Operator: "OR", Children: []models.LicenseExpressionNode{
+				license("MIT"), license("BSD-3-Clause"), license("ISC"),
+			}},
+		},
+		{expression: "", wantErr: true},
+		{expression: "MIT AND", wantErr: true},
+		{expression: "(MIT OR ISC", wantErr: true},
+		{expression: "MIT ISC", wantErr: true},
+		{expression: "GPL-2.0-only WITH Classpath-exception-2.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			node, err := parseLicenseExpression(tt.expression)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, node)
+		})
+	}
+}
+
+func TestResolveLicenseExpression(t *testing.T) {
+	w := makeRequest("POST", "/api/v1/obligations/resolve-expression",
+		models.LicenseExpressionInput{Expression: "MIT OR NO-SUCH-LICENSE"}, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ResolvedLicenseExpressionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Equal(t, []string{"NO-SUCH-LICENSE"}, res.Data.UnknownLicenses)
+	if assert.Len(t, res.Data.Expression.Children, 2) {
+		assert.Equal(t, len(res.Data.Obligations), len(res.Data.Expression.Children[0].Obligations))
+		assert.Empty(t, res.Data.Expression.Children[1].Obligations)
+	}
+
+	w = makeRequest("POST", "/api/v1/obligations/resolve-expression",
+		models.LicenseExpressionInput{Expression: "(MIT"}, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObligationNormalizedText(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "normalized-text",
+		Type:           "obligation",
+		Text:           "\ufeffFirst line\r\nsecond line",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/normalized-text/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stored models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "normalized-text"}).First(&stored).Error; err != nil {
+		t.Fatalf("Unable to fetch obligation: %v", err)
+	}
+	assert.Equal(t, "\ufeffFirst line\r\nsecond line", stored.Text)
+	assert.Equal(t, "First line\nsecond line", stored.NormalizedText)
+	assert.Equal(t, utils.ObligationTextHash(stored.NormalizedText), stored.TextHash)
+
+	for raw, expected := range map[string]string{
+		"":      "\ufeffFirst line\r\nsecond line",
+		"true":  "\ufeffFirst line\r\nsecond line",
+		"false": "First line\nsecond line",
+	} {
+		w = makeRequest("GET", "/api/v1/obligations/normalized-text?raw="+raw, nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Errorf("Error unmarshalling JSON: %v", err)
+			return
+		}
+		if assert.Len(t, res.Data, 1) {
+			assert.Equal(t, expected, res.Data[0].Text)
+		}
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/normalized-text?raw=maybe", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The stored normalized text is served, also when only some fields are selected
+	w = makeRequest("GET", "/api/v1/obligations?raw=false&fields=topic,text&filter="+
+		url.QueryEscape("topic=normalized-text"), nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var projection models.ObligationProjectionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &projection); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, projection.Data, 1) {
+		assert.Equal(t, "First line\nsecond line", projection.Data[0]["text"])
+	}
+}
+
+func TestObligationTextHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		encoded   string
+	}{
+		{"escaped newline", `"line one\nline two"`, `"line one\u000aline two"`},
+		{"raw unicode", `"café"`, `"caf\u00e9"`},
+		{"escaped slash", `"GPL-2.0/3.0"`, `"GPL-2.0\/3.0"`},
+		{"escaped quote", `"the \"Software\""`, `"the \u0022Software\u0022"`},
+		{"html escapes", `"<a> & <b>"`, `"\u003ca\u003e \u0026 \u003cb\u003e"`},
+		{"byte order mark", `"text"`, `"\ufefftext"`},
+		{"zero width characters", `"source code"`, `"source\u200b \u200ccode\u200d\u2060"`},
+		{"crlf line endings", `"line one\nline two"`, `"line one\r\nline two"`},
+		{"cr line endings", `"line one\nline two"`, `"line one\rline two"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canonical, encoded string
+			if err := json.Unmarshal([]byte(tt.canonical), &canonical); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.encoded), &encoded); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			assert.Equal(t, utils.ObligationTextHash(canonical), utils.ObligationTextHash(encoded))
+		})
+	}
+
+	assert.NotEqual(t, utils.ObligationTextHash("source code"), utils.ObligationTextHash("source  code"))
+}
+
+func TestValidateObligationTextUpdate(t *testing.T) {
+	text := "Obligation text"
+	oldObligation := models.Obligation{Text: text, TextHash: utils.ObligationTextHash(text)}
+
+	tests := []struct {
+		name            string
+		current         bool
+		incoming        *bool
+		textChanged     bool
+		expectUpdatable bool
+	}{
+		{"locked, not sent, unchanged", false, nil, false, true},
+		{"locked, not sent, changed", false, nil, true, false},
+		{"locked, lock, unchanged", false, func(b bool) *bool { return &b }(false), false, true},
+		{"locked, lock, changed", false, func(b bool) *bool { return &b }(false), true, false},
+		{"locked, unlock, unchanged", false, func(b bool) *bool { return &b }(true), false, true},
+		{"locked, unlock, changed", false, func(b bool) *bool { return &b }(true), true, true},
+		{"unlocked, not sent, unchanged", true, nil, false, true},
+		{"unlocked, not sent, changed", true, nil, true, true},
+		{"unlocked, lock, unchanged", true, func(b bool) *bool { return &b }(false), false, true},
+		{"unlocked, lock, changed", true, func(b bool) *bool { return &b }(false), true, false},
+		{"unlocked, unlock, unchanged", true, func(b bool) *bool { return &b }(true), false, true},
+		{"unlocked, unlock, changed", true, func(b bool) *bool { return &b }(true), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := oldObligation
+			ob.TextUpdatable = tt.current
+
+			var updates models.ObligationPATCHRequestJSONSchema
+			updates.Text.IsDefined = true
+			updates.Text.Value = text
+			if tt.textChanged {
+				updates.Text.Value = "Changed obligation text"
+			}
+			if tt.incoming != nil {
+				updates.TextUpdatable.IsDefined = true
+				updates.TextUpdatable.Value = *tt.incoming
+			}
+
+			err := validateObligationTextUpdate(&ob, &updates)
+			assert.Equal(t, tt.expectUpdatable, err == nil)
+		})
+	}
+}
+
+func TestUpdateObligationTextLock(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "text-lock",
+		Type:           "obligation",
+		Text:           "Obligation text which is locked",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// The locked text can not be changed
+	update := map[string]interface{}{
+		"text":          "Obligation text which changes while locked",
+		"change_reason": "change the locked text",
+	}
+	w = makeRequest("PATCH", "/api/v1/obligations/text-lock", update, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// The text_updatable of the request takes precedence over the stored one
+	update["text_updatable"] = false
+	w = makeRequest("PATCH", "/api/v1/obligations/text-lock", update, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	update["text_updatable"] = true
+	w = makeRequest("PATCH", "/api/v1/obligations/text-lock", update, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	update["text"] = "Obligation text which changes while locking it"
+	update["text_updatable"] = false
+	w = makeRequest("PATCH", "/api/v1/obligations/text-lock", update, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	delete(update, "text_updatable")
+	w = makeRequest("PATCH", "/api/v1/obligations/text-lock", update, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestObligationWatch(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "watch-me",
+		Type:           "obligation",
+		Text:           "Obligation text which is watched",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	watchedTopics := func() []string {
+		w := makeRequest("GET", "/api/v1/obligations/watched", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		var topics []string
+		for _, obligation := range res.Data {
+			topics = append(topics, obligation.Topic)
+		}
+		return topics
+	}
+	assert.NotContains(t, watchedTopics(), "watch-me")
+
+	w = makeRequest("POST", "/api/v1/obligations/watch-me/watch", nil, true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	// Watching twice keeps a single watch
+	w = makeRequest("POST", "/api/v1/obligations/watch-me/watch", nil, true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	watched := 0
+	for _, topic := range watchedTopics() {
+		if topic == "watch-me" {
+			watched++
+		}
+	}
+	assert.Equal(t, 1, watched)
+
+	// The changes of the watched obligation are notified in the feed of the user
+	w = makeRequest("POST", "/api/v1/obligations/watch-me/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("PATCH", "/api/v1/obligations/watch-me",
+		map[string]interface{}{"comment": "watched", "change_reason": "Clarified"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/watched/changes.atom", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var feed models.AtomFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Error unmarshalling XML: %v", err)
+	}
+	var summaries []string
+	for _, entry := range feed.Entries {
+		assert.Equal(t, "watch-me", entry.Title)
+		assert.Equal(t, "/api/v1/obligations/watch-me", entry.Link.Href)
+		summaries = append(summaries, entry.Summary)
+	}
+	assert.Equal(t, []string{"Changed Comment: Clarified", "Changed Status: Published", "Created"}, summaries)
+
+	w = makeRequest("DELETE", "/api/v1/obligations/watch-me/watch", nil, true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.NotContains(t, watchedTopics(), "watch-me")
+	w = makeRequest("GET", "/api/v1/obligations/watched/changes.atom", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "watch-me")
+	w = makeRequest("DELETE", "/api/v1/obligations/watch-me/watch", nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = makeRequest("POST", "/api/v1/obligations/not-existing/watch", nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/watched", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/watched/changes.atom", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCreateObligationBackdated(t *testing.T) {
+	createdAt := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "backdated",
+		Type:           "obligation",
+		Text:           "Obligation text migrated with its creation date",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "migrated",
+		Active:         true,
+		CreatedAt:      &createdAt,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if !assert.Len(t, res.Data, 1) {
+		return
+	}
+	assert.True(t, createdAt.Equal(res.Data[0].CreatedAt))
+
+	var change models.ChangeLog
+	if err := db.DB.Joins("JOIN audits ON audits.id = change_logs.audit_id").
+		Where("audits.type = ? AND audits.type_id = ? AND change_logs.field = ?", "Obligation", res.Data[0].Id, "CreatedAt").
+		First(&change).Error; err != nil {
+		t.Fatalf("Unable to fetch changelog: %v", err)
+	}
+	if assert.NotNil(t, change.UpdatedValue) {
+		assert.Equal(t, createdAt.Format(time.RFC3339), *change.UpdatedValue)
+	}
+
+	future := time.Now().Add(time.Hour)
+	obligation.Topic = "backdated-future"
+	obligation.Text = "Obligation text created in the future"
+	obligation.CreatedAt = &future
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var validation models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &validation); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, validation.Errors, 1) {
+		assert.Equal(t, "created_at", validation.Errors[0].Field)
+	}
+}
+
+func TestObligationCommentEncryption(t *testing.T) {
+	firstKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	secondKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "first:"+firstKey)
+	t.Setenv("FIELD_ENCRYPTION_KEY_ID", "first")
+	// The encrypted values can not be read by the tests after the keys are unset
+	t.Cleanup(func() {
+		var ids []int64
+		if err := db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "encrypted-comment"}).
+			Pluck("id", &ids).Error; err != nil || len(ids) != 1 {
+			return
+		}
+		audits := db.DB.Model(&models.Audit{}).Select("id").Where(models.Audit{Type: "Obligation", TypeId: ids[0]})
+		db.DB.Where("audit_id IN (?)", audits).Delete(&models.ChangeLog{})
+		db.DB.Where(models.Audit{Type: "Obligation", TypeId: ids[0]}).Delete(&models.Audit{})
+		db.DB.Where(models.ObligationSnapshot{ObligationPk: ids[0]}).Delete(&models.ObligationSnapshot{})
+		db.DB.Delete(&models.Obligation{}, ids[0])
+	})
+
+	storedComment := func() string {
+		var comments []string
+		if err := db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "encrypted-comment"}).
+			Pluck("comment", &comments).Error; err != nil || len(comments) != 1 {
+			t.Fatalf("Unable to fetch comment: %v", err)
+		}
+		return comments[0]
+	}
+
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "encrypted-comment",
+		Type:           "obligation",
+		Text:           "Obligation text with a privileged comment",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "privileged note",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "privileged note")
+	assert.True(t, strings.HasPrefix(storedComment(), "enc:first:"))
+	assert.NotContains(t, storedComment(), "privileged note")
+	w = makeRequest("POST", "/api/v1/obligations/encrypted-comment/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Comments encrypted with the rotated key remain readable
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "first:"+firstKey+",second:"+secondKey)
+	t.Setenv("FIELD_ENCRYPTION_KEY_ID", "second")
+	w = makeRequest("GET", "/api/v1/obligations/encrypted-comment", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "privileged note", res.Data[0].Comment)
+	}
+
+	w = makeRequest("PATCH", "/api/v1/obligations/encrypted-comment", map[string]interface{}{
+		"comment": "updated privileged note",
+	}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "updated privileged note")
+	assert.True(t, strings.HasPrefix(storedComment(), "enc:second:"))
+
+	var change models.ChangeLog
+	if err := db.DB.Joins("JOIN audits ON audits.id = change_logs.audit_id").
+		Where("audits.type = ? AND change_logs.field = ?", "Obligation", "Comment").
+		Order("change_logs.id desc").First(&change).Error; err != nil {
+		t.Fatalf("Unable to fetch changelog: %v", err)
+	}
+	if assert.NotNil(t, change.UpdatedValue) {
+		assert.Equal(t, "updated privileged note", *change.UpdatedValue)
+	}
+
+	// Comments can not be matched against their ciphertext
+	w = makeRequest("POST", "/api/v1/obligations/search",
+		models.SearchObligation{Field: "comment", SearchTerm: "privileged", Search: "fuzzy"}, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations?filter="+url.QueryEscape("comment=privileged note"), nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Comments which look like ciphertext are rejected instead of being stored as plaintext
+	w = makeRequest("PATCH", "/api/v1/obligations/encrypted-comment", map[string]interface{}{
+		"comment": "enc:second:forged",
+	}, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.True(t, strings.HasPrefix(storedComment(), "enc:second:"))
+	obligation.Topic = "forged-encrypted-comment"
+	obligation.Comment = "enc:first:forged"
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Only the values of comment changes are decrypted
+	other := models.ChangeLog{Field: "Text", OldValue: &obligation.Comment, UpdatedValue: &obligation.Comment}
+	if assert.NoError(t, other.AfterFind(db.DB)) {
+		assert.Equal(t, "enc:first:forged", *other.OldValue)
+	}
+}
+
+func TestGetObligationUsage(t *testing.T) {
+	var licenseIds []int64
+	if err := db.DB.Model(&models.LicenseDB{}).Where(models.LicenseDB{Active: func(b bool) *bool { return &b }(true)}).
+		Order("rf_id").Limit(2).Pluck("rf_id", &licenseIds).Error; err != nil || len(licenseIds) != 2 {
+		t.Fatalf("Unable to fetch licenses: %v", err)
+	}
+	inactiveLicense := models.LicenseDB{
+		Shortname: func(s string) *string { return &s }("usage-inactive"),
+		Fullname:  func(s string) *string { return &s }("Inactive license of usage"),
+		Text:      func(s string) *string { return &s }("Inactive license text"),
+		SpdxId:    func(s string) *string { return &s }("usage-inactive"),
+		Active:    func(b bool) *bool { return &b }(false),
+	}
+	if err := db.DB.Create(&inactiveLicense).Error; err != nil {
+		t.Fatalf("Unable to create license: %v", err)
+	}
+
+	mappedLicenses := map[string][]int64{
+		"usage-wide":   {licenseIds[0], licenseIds[1], inactiveLicense.Id},
+		"usage-narrow": {licenseIds[0], inactiveLicense.Id},
+	}
+	for topic, licenses := range mappedLicenses {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Active: true}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		for _, licenseId := range licenses {
+			if err := db.DB.Create(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: licenseId}).Error; err != nil {
+				t.Fatalf("Unable to create obligation map: %v", err)
+			}
+		}
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/usage?limit=1000", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationUsageResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	positions := make(map[string]int)
+	for i, usage := range res.Data {
+		positions[usage.Topic] = i
+		if i > 0 {
+			assert.GreaterOrEqual(t, res.Data[i-1].ActiveLicenses, usage.ActiveLicenses)
+		}
+	}
+	if assert.Contains(t, positions, "usage-wide") && assert.Contains(t, positions, "usage-narrow") {
+		assert.Equal(t, int64(2), res.Data[positions["usage-wide"]].ActiveLicenses)
+		assert.Equal(t, int64(1), res.Data[positions["usage-narrow"]].ActiveLicenses)
+	}
+}
+
+func TestCreateObligationTextHashCollision(t *testing.T) {
+	text := "Obligation text whose hash is taken by another text"
+	// Store an obligation with the hash of the text but a different content, as a
+	// crafted collision would
+	colliding := models.Obligation{Topic: "hash-collision", Type: "obligation", Text: "Colliding obligation text",
+		TextHash: utils.ObligationTextHash(text), Active: true}
+	if err := db.DB.Create(&colliding).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "hash-collision-new",
+		Type:           "obligation",
+		Text:           text,
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var er models.LicenseError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	assert.Contains(t, er.Error, "hash-collision")
+
+	var stored models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "hash-collision"}).First(&stored).Error; err != nil {
+		t.Fatalf("Unable to fetch obligation: %v", err)
+	}
+	assert.Equal(t, "Colliding obligation text", stored.Text)
+
+	w = makeRequest("POST", "/api/v1/obligations/check-duplicates",
+		[]models.ObligationDuplicateCheckInput{{Topic: "hash-collision-new", Text: text}}, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationDuplicateCheckResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Errorf("Error unmarshalling JSON: %v", err)
+		return
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.False(t, res.Data[0].TextExists)
+	}
+}
+
+func TestObligationReviewWorkflow(t *testing.T) {
+	listedTopics := func(query string, isAuthenticated bool) []string {
+		w := makeRequest("GET", "/api/v1/obligations?limit=1000"+query, nil, isAuthenticated)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		var topics []string
+		for _, ob := range res.Data {
+			topics = append(topics, ob.Topic)
+		}
+		return topics
+	}
+
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "review-workflow",
+		Type:           "obligation",
+		Text:           "Obligation text which goes through review",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, models.OBLIGATION_STATUS_DRAFT, res.Data[0].Status)
+	}
+
+	assert.NotContains(t, listedTopics("", false), "review-workflow")
+	assert.Contains(t, listedTopics("&status=DRAFT", true), "review-workflow")
+	w = makeRequest("GET", "/api/v1/obligations/review-workflow", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/review-workflow?status=DRAFT", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	search := models.SearchObligation{Field: "text", SearchTerm: "goes through review", Search: "fuzzy"}
+	w = makeRequest("POST", "/api/v1/obligations/search", search, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "review-workflow")
+	w = makeRequest("POST", "/api/v1/obligations/search?status=DRAFT", search, true)
+	assert.Contains(t, w.Body.String(), "review-workflow")
+	w = makeRequest("GET", "/api/v1/obligations/export", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "review-workflow")
+	w = makeRequest("GET", "/api/v1/obligations/export?status=DRAFT", nil, true)
+	assert.Contains(t, w.Body.String(), "review-workflow")
+	w = makeRequest("GET", "/api/v1/obligations?status=DRAFT", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations?status=ARCHIVED", nil, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest("PATCH", "/api/v1/obligations/review-workflow", map[string]string{"status": "PUBLISHED"}, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = makeRequest("PATCH", "/api/v1/obligations/review-workflow", map[string]string{"status": "IN_REVIEW"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, listedTopics("&status=IN_REVIEW", true), "review-workflow")
+
+	defaultReviewers := ObligationReviewerUserlevels
+	ObligationReviewerUserlevels = func() []string { return []string{"reviewer"} }
+	w = makeRequest("POST", "/api/v1/obligations/review-workflow/publish", nil, true)
+	ObligationReviewerUserlevels = defaultReviewers
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = makeRequest("POST", "/api/v1/obligations/review-workflow/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/review-workflow/publish", nil, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, listedTopics("", false), "review-workflow")
+
+	var change models.ChangeLog
+	if err := db.DB.Joins("JOIN audits ON audits.id = change_logs.audit_id").
+		Joins("JOIN obligations ON obligations.id = audits.type_id").
+		Where("audits.type = ? AND obligations.topic = ? AND change_logs.field = ?", "Obligation", "review-workflow", "Status").
+		Order("change_logs.id desc").First(&change).Error; err != nil {
+		t.Fatalf("Unable to fetch changelog: %v", err)
+	}
+	if assert.NotNil(t, change.UpdatedValue) {
+		assert.Equal(t, models.OBLIGATION_STATUS_PUBLISHED, *change.UpdatedValue)
+	}
+}
+
+func TestCreateObligationConflictReturnsExisting(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "conflict-existing",
+		Type:           "obligation",
+		Text:           "Obligation text which is created twice",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if !assert.Len(t, created.Data, 1) {
+		return
+	}
+
+	sameText := obligation
+	sameText.Topic = "conflict-existing-copy"
+	sameTopic := obligation
+	sameTopic.Text = "Another obligation text under the same topic"
+	for name, body := range map[string]models.ObligationPOSTRequestJSONSchema{"same text": sameText, "same topic": sameTopic} {
+		t.Run(name, func(t *testing.T) {
+			w := makeRequest("POST", "/api/v1/obligations", body, true)
+			assert.Equal(t, http.StatusConflict, w.Code)
+			var er models.ObligationConflictError
+			if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			assert.Equal(t, "conflict-existing", er.Existing.Topic)
+			assert.Equal(t, created.Data[0].Id, er.Existing.Id)
+
+			// Only the id and topic of the existing obligation are returned
+			var body struct {
+				Existing map[string]interface{} `json:"existing"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			assert.Len(t, body.Existing, 2)
+		})
+	}
+}
+
+func TestCreateObligationConflictDiff(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "conflict-diff",
+		Type:           "obligation",
+		Text:           "Obligation text which is diffed on conflict",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	changed := obligation
+	changed.Text = "Another obligation text which is diffed on conflict"
+	changed.Classification = "red"
+	w = makeRequest("POST", "/api/v1/obligations", changed, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var er models.ObligationConflictError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if !assert.Len(t, er.Diff, 2) {
+		return
+	}
+	assert.Equal(t, "Text", er.Diff[0].Field)
+	assert.Equal(t, obligation.Text, *er.Diff[0].OldValue)
+	assert.Equal(t, changed.Text, *er.Diff[0].NewValue)
+	assert.Equal(t, "Classification", er.Diff[1].Field)
+	assert.Equal(t, "green", *er.Diff[1].OldValue)
+	assert.Equal(t, "red", *er.Diff[1].NewValue)
+
+	// The draft is not diffed for users who can not read it
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "participant").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	w = makeRequest("POST", "/api/v1/obligations", changed, true)
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "admin").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	assert.Equal(t, http.StatusConflict, w.Code)
+	er = models.ObligationConflictError{}
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Empty(t, er.Diff)
+	assert.Equal(t, "conflict-diff", er.Existing.Topic)
+
+	// A conflict on the text alone has no diff
+	sameText := obligation
+	sameText.Topic = "conflict-diff-copy"
+	w = makeRequest("POST", "/api/v1/obligations", sameText, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	er = models.ObligationConflictError{}
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Empty(t, er.Diff)
+}
+
+func TestGetObligationById(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "lookup-by-id",
+		Type:           "obligation",
+		Text:           "Obligation text looked up by id",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if !assert.Len(t, created.Data, 1) {
+		return
+	}
+	id := created.Data[0].Id
+
+	// Drafts are only found by reviewers asking for them
+	w = makeRequest("GET", fmt.Sprintf("/api/v1/obligations/id/%d", id), nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest("GET", fmt.Sprintf("/api/v1/obligations/id/%d?status=DRAFT", id), nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/lookup-by-id/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("GET", fmt.Sprintf("/api/v1/obligations/id/%d", id), nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "lookup-by-id", res.Data[0].Topic)
+	}
+
+	w = makeRequest("GET", fmt.Sprintf("/api/v1/obligations/id/%d/audits", id), nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var audits models.AuditResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &audits); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	for _, audit := range audits.Data {
+		assert.Equal(t, id, audit.TypeId)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/id/999999999", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/id/999999999/audits", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	for _, invalid := range []string{"lookup-by-id", "0", "-1"} {
+		w = makeRequest("GET", "/api/v1/obligations/id/"+invalid, nil, false)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = makeRequest("GET", "/api/v1/obligations/id/"+invalid+"/audits", nil, false)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestObligationTypes(t *testing.T) {
+	// types gives the allowed obligation types listed by the admin endpoint
+	types := func() []string {
+		w := makeRequest("GET", "/api/v1/obligations/types", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationTypesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/types", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, models.DefaultObligationTypes, types())
+
+	t.Setenv("OBLIGATION_TYPES", "Obligation, risk")
+	assert.Equal(t, []string{"obligation", "risk"}, types())
+
+	obligation := models.ObligationPOSTRequestJSONSchema{Topic: "type-not-allowed", Type: "right",
+		Text: "Obligation text of a type which is not allowed", Classification: "green", Modifications: true,
+		Comment: "comment", Active: true}
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var er models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, er.Errors, 1) {
+		assert.Equal(t, "type", er.Errors[0].Field)
+		assert.Contains(t, er.Errors[0].Message, "[obligation risk]")
+	}
+
+	obligation.Type = "risk"
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestReservedObligationTopics(t *testing.T) {
+	// Every static obligation route must reserve its word as a topic
+	for _, route := range Router().Routes() {
+		rest, found := strings.CutPrefix(route.Path, API_V1_BASE_PATH+"/obligations/")
+		if word, _, _ := strings.Cut(rest, "/"); found && !strings.HasPrefix(word, ":") {
+			assert.True(t, utils.IsReservedObligationTopic(word), route.Path)
+		}
+	}
+
+	obligation := models.ObligationPOSTRequestJSONSchema{Topic: "types", Type: "obligation",
+		Text: "Obligation text of a reserved topic", Classification: "green", Modifications: true,
+		Comment: "comment", Active: true}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var res models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, "topic", res.Errors[0].Field)
+		assert.Equal(t, "obligation_topic", res.Errors[0].Rule)
+	}
+
+	obligation.Topic = "reserved-rename"
+	w = makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = makeRequest("PATCH", "/api/v1/obligations/reserved-rename", map[string]string{"topic": "graph"}, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/reserved-rename?status=DRAFT", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRenameObligationTopic(t *testing.T) {
+	for _, obligation := range []models.ObligationPOSTRequestJSONSchema{
+		{Topic: "rename-before", Type: "obligation", Text: "Obligation text which is renamed", Classification: "green",
+			Modifications: true, Comment: "comment", Active: true},
+		{Topic: "rename-other", Type: "obligation", Text: "Obligation text which is not renamed", Classification: "green",
+			Modifications: true, Comment: "comment", Active: true},
+	} {
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = makeRequest("POST", "/api/v1/obligations/"+obligation.Topic+"/publish", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := makeRequest("PATCH", "/api/v1/obligations/rename-before", map[string]string{"topic": "rename-after"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if !assert.Len(t, res.Data, 1) {
+		return
+	}
+	assert.Equal(t, "rename-after", res.Data[0].Topic)
+
+	w = makeRequest("GET", "/api/v1/obligations/rename-before?raw=false", nil, false)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/v1/obligations/rename-after?raw=false", w.Header().Get("Location"))
+
+	var change models.ChangeLog
+	if err := db.DB.Joins("JOIN audits ON audits.id = change_logs.audit_id").
+		Where("audits.type = ? AND audits.type_id = ? AND change_logs.field = ?", "Obligation", res.Data[0].Id, "Topic").
+		First(&change).Error; err != nil {
+		t.Fatalf("Unable to fetch changelog: %v", err)
+	}
+	if assert.NotNil(t, change.OldValue) {
+		assert.Equal(t, "rename-before", *change.OldValue)
+	}
+
+	// The former topic is reserved for the renamed obligation
+	reuse := models.ObligationPOSTRequestJSONSchema{Topic: "rename-before", Type: "obligation",
+		Text: "Obligation text which reuses a former topic", Classification: "green",
+		Modifications: true, Comment: "comment", Active: true}
+	w = makeRequest("POST", "/api/v1/obligations", reuse, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var er models.ObligationConflictError
+	if err := json.Unmarshal(w.Body.Bytes(), &er); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, "rename-after", er.Existing.Topic)
+	w = makeRequest("PATCH", "/api/v1/obligations/rename-other", map[string]string{"topic": "rename-before"}, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest("PATCH", "/api/v1/obligations/rename-other", map[string]string{"topic": "rename-after"}, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest("PATCH", "/api/v1/obligations/rename-after", map[string]string{"topic": "rename-before"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/rename-before", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/rename-after", nil, false)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/v1/obligations/rename-before", w.Header().Get("Location"))
+
+	// The current topic is escaped in the location
+	w = makeRequest("PATCH", "/api/v1/obligations/rename-before", map[string]string{"topic": "rename spaced?"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/rename-before", nil, false)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/v1/obligations/rename%20spaced%3F", w.Header().Get("Location"))
+
+	// The former topics of a deactivated obligation are gone
+	w = makeRequest("DELETE", "/api/v1/obligations/"+url.PathEscape("rename spaced?"), nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, topic := range []string{"rename-before", "rename-after"} {
+		w = makeRequest("GET", "/api/v1/obligations/"+topic, nil, false)
+		assert.Equal(t, http.StatusGone, w.Code)
+		assert.Empty(t, w.Header().Get("Location"))
+	}
+}
+
+func TestUpdateObligationChangeLogAttribution(t *testing.T) {
+	// changesOf gives the changes of the latest audit of the obligation by field
+	changesOf := func(topic string) map[string]models.ChangeLog {
+		var audit models.Audit
+		if err := db.DB.Joins("JOIN obligations ON obligations.id = audits.type_id").
+			Where("audits.type = ? AND obligations.topic = ?", "Obligation", topic).
+			Order("audits.id DESC").Preload("ChangeLogs").First(&audit).Error; err != nil {
+			t.Fatalf("Unable to fetch audit: %v", err)
+		}
+		changes := make(map[string]models.ChangeLog)
+		for _, change := range audit.ChangeLogs {
+			changes[change.Field] = change
+		}
+		return changes
+	}
+
+	for _, topic := range []string{"attribution-modifications", "attribution-classification"} {
+		obligation := models.ObligationPOSTRequestJSONSchema{Topic: topic, Type: "obligation",
+			Text: "Obligation text of " + topic, Classification: "green", Modifications: true,
+			Comment: "Comment of " + topic, Active: true}
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	t.Run("only modifications", func(t *testing.T) {
+		w := makeRequest("PATCH", "/api/v1/obligations/attribution-modifications",
+			map[string]interface{}{"modifications": false}, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		changes := changesOf("attribution-modifications")
+		assert.NotContains(t, changes, "Classification")
+		change, ok := changes["Modifications"]
+		if !assert.True(t, ok) {
+			return
+		}
+		if assert.NotNil(t, change.OldValue) && assert.NotNil(t, change.UpdatedValue) {
+			assert.Equal(t, "true", *change.OldValue)
+			assert.Equal(t, "false", *change.UpdatedValue)
+		}
+	})
+
+	t.Run("only classification", func(t *testing.T) {
+		w := makeRequest("PATCH", "/api/v1/obligations/attribution-classification",
+			map[string]interface{}{"classification": "red"}, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		changes := changesOf("attribution-classification")
+		assert.NotContains(t, changes, "Modifications")
+		change, ok := changes["Classification"]
+		if !assert.True(t, ok) {
+			return
+		}
+		if assert.NotNil(t, change.OldValue) && assert.NotNil(t, change.UpdatedValue) {
+			assert.Equal(t, "green", *change.OldValue)
+			assert.Equal(t, "red", *change.UpdatedValue)
+		}
+	})
+}
+
+func TestObligationEffectiveWindow(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday, tomorrow := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	for _, obligation := range []models.ObligationPOSTRequestJSONSchema{
+		{Topic: "effective-current", EffectiveFrom: &yesterday, EffectiveUntil: &tomorrow},
+		{Topic: "effective-future", EffectiveFrom: &tomorrow},
+		{Topic: "effective-expired", EffectiveUntil: &yesterday},
+	} {
+		obligation.Type = "obligation"
+		obligation.Text = "Obligation text of the effective window " + obligation.Topic
+		obligation.Classification = "green"
+		obligation.Modifications = true
+		obligation.Comment = "comment"
+		obligation.Active = true
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = makeRequest("POST", "/api/v1/obligations/"+obligation.Topic+"/publish", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	topics := func(active string) []string {
+		w := makeRequest("GET", "/api/v1/obligations?limit=1000&active="+active+"&filter="+
+			url.QueryEscape("topic like 'effective-%'"), nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		var topics []string
+		for _, obligation := range res.Data {
+			topics = append(topics, obligation.Topic)
+		}
+		return topics
+	}
+	assert.Equal(t, []string{"effective-current"}, topics("true"))
+	assert.Equal(t, []string{"effective-expired", "effective-future"}, topics("false"))
+	assert.Len(t, topics("all"), 3)
+
+	// Clearing the start of the window makes the obligation active right away
+	w := makeRequest("PATCH", "/api/v1/obligations/effective-future",
+		map[string]interface{}{"effective_from": nil}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"effective-current", "effective-future"}, topics("true"))
+
+	w = makeRequest("PATCH", "/api/v1/obligations/effective-current",
+		map[string]interface{}{"effective_until": yesterday.AddDate(0, 0, -1)}, true)
+	assertEffectiveWindowError(t, w)
+	w = makeRequest("POST", "/api/v1/obligations", models.ObligationPOSTRequestJSONSchema{Topic: "effective-invalid",
+		Type: "obligation", Text: "Obligation text of an invalid effective window", Classification: "green",
+		Modifications: true, Comment: "comment", Active: true, EffectiveFrom: &tomorrow, EffectiveUntil: &yesterday}, true)
+	assertEffectiveWindowError(t, w)
+}
+
+// assertEffectiveWindowError checks that the response is the validation error of an
+// effective window ending before it starts.
+func assertEffectiveWindowError(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var res models.ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, "effective_until", res.Errors[0].Field)
+		assert.Equal(t, "gtfield", res.Errors[0].Rule)
+	}
+}
+
+func TestUpdateObligationResponseIsComplete(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{Topic: "patch-response", Type: "restriction",
+		Text: "Obligation text of the patch response", Classification: "green", Modifications: true,
+		Comment: "Comment of the patch response", Active: true}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = makeRequest("PATCH", "/api/v1/obligations/patch-response",
+		map[string]interface{}{"classification": "yellow"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res models.ObligationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if !assert.Len(t, res.Data, 1) {
+		return
+	}
+	updated := res.Data[0]
+	assert.Equal(t, "yellow", updated.Classification)
+	assert.Equal(t, obligation.Topic, updated.Topic)
+	assert.Equal(t, obligation.Text, updated.Text)
+	assert.Equal(t, obligation.Type, updated.Type)
+	assert.Equal(t, obligation.Comment, updated.Comment)
+	assert.True(t, updated.Modifications)
+	assert.False(t, updated.CreatedAt.IsZero())
+}
+
+func TestUpdateObligationAllFieldsAudit(t *testing.T) {
+	// The text can not be changed along with text_updatable, so the text is unlocked beforehand
+	allFields := []string{"Topic", "Type", "Text", "Classification", "Modifications", "Comment", "Active",
+		"Sensitive", "Status", "EffectiveFrom", "EffectiveUntil"}
+	// updateAll changes every field of a new obligation and gives the audits of the change
+	updateAll := func(topic string) []models.Audit {
+		obligation := models.ObligationPOSTRequestJSONSchema{Topic: topic, Type: "obligation",
+			Text: "Obligation text of " + topic, Classification: "green", Modifications: true,
+			Comment: "Comment of " + topic, Active: true}
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		if !assert.Equal(t, http.StatusCreated, w.Code) {
+			return nil
+		}
+		var created models.Obligation
+		db.DB.Where(models.Obligation{Topic: topic}).First(&created)
+		db.DB.Model(&created).UpdateColumn("text_updatable", true)
+
+		w = makeRequest("PATCH", "/api/v1/obligations/"+topic, map[string]interface{}{
+			"topic":           topic + "-updated",
+			"type":            "risk",
+			"text":            "Updated obligation text of " + topic,
+			"classification":  "red",
+			"modifications":   false,
+			"comment":         "Updated comment of " + topic,
+			"active":          false,
+			"sensitive":       true,
+			"status":          models.OBLIGATION_STATUS_IN_REVIEW,
+			"effective_from":  "2024-01-01T00:00:00Z",
+			"effective_until": "2024-12-31T23:59:59Z",
+			"change_reason":   "Updated every field",
+		}, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var audits []models.Audit
+		db.DB.Where(models.Audit{Type: "Obligation", TypeId: created.Id}).Preload("ChangeLogs").Find(&audits)
+		return audits
+	}
+
+	t.Run("all changelogs", func(t *testing.T) {
+		audits := updateAll("audit-all-fields")
+		if !assert.Len(t, audits, 1) {
+			return
+		}
+		var fields []string
+		for _, change := range audits[0].ChangeLogs {
+			fields = append(fields, change.Field)
+		}
+		assert.ElementsMatch(t, allFields, fields)
+		assert.False(t, audits[0].Truncated)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		defaultMaxChangeLogs := MaxAuditChangeLogs
+		defer func() { MaxAuditChangeLogs = defaultMaxChangeLogs }()
+		MaxAuditChangeLogs = func() int { return 5 }
+
+		audits := updateAll("audit-truncated-fields")
+		if !assert.Len(t, audits, 1) {
+			return
+		}
+		assert.True(t, audits[0].Truncated)
+		if !assert.Len(t, audits[0].ChangeLogs, 5) {
+			return
+		}
+		var summary *models.ChangeLog
+		for i, change := range audits[0].ChangeLogs {
+			if change.Field == "Truncated" {
+				summary = &audits[0].ChangeLogs[i]
+			}
+		}
+		if assert.NotNil(t, summary) && assert.NotNil(t, summary.UpdatedValue) {
+			assert.Contains(t, *summary.UpdatedValue, fmt.Sprintf("%d more fields changed", len(allFields)-4))
+		}
+	})
+}
+
+func TestGetObligationLengthDistribution(t *testing.T) {
+	distribution := func(query string) []models.ObligationLengthBucket {
+		w := makeRequest("GET", "/api/v1/obligations/length-distribution"+query, nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationLengthDistributionResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	before := distribution("?buckets=10,20")
+	for _, obligation := range []models.Obligation{
+		{Topic: "length-stub", Text: "Stub.", TextHash: "length-stub", Active: true},
+		{Topic: "length-short", Text: "Short obligation", TextHash: "length-short", Active: true},
+		{Topic: "length-long", Text: "Obligation text of some length", TextHash: "length-long", Active: true},
+	} {
+		obligation.Type = "obligation"
+		obligation.Classification = "green"
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	after := distribution("?buckets=10,20")
+
+	if assert.Len(t, after, 3) && assert.Len(t, before, 3) {
+		assert.Equal(t, 0, after[0].Min)
+		if assert.NotNil(t, after[0].Max) {
+			assert.Equal(t, 10, *after[0].Max)
+		}
+		assert.Equal(t, 20, after[2].Min)
+		assert.Nil(t, after[2].Max)
+		for i := range after {
+			assert.Equal(t, before[i].Count+1, after[i].Count)
+		}
+	}
+	assert.Len(t, distribution(""), 4)
+
+	for _, buckets := range []string{"20,10", "0,10", "ten"} {
+		w := makeRequest("GET", "/api/v1/obligations/length-distribution?buckets="+buckets, nil, false)
+		assert.Equal(t, http.StatusBadRequest, w.Code, buckets)
+	}
+}
+
+func TestGetObligationLint(t *testing.T) {
+	var license models.LicenseDB
+	if err := db.DB.Where("rf_active = ?", true).First(&license).Error; err != nil {
+		t.Fatalf("Unable to fetch an active license: %v", err)
+	}
+	hashText := "Obligation text whose md5 is its topic"
+	hash := md5.Sum([]byte(hashText))
+	obligations := []models.Obligation{
+		{Topic: hex.EncodeToString(hash[:]), Text: hashText, Classification: "green", Active: true},
+		{Topic: "lint-empty-classification", Text: "Obligation text without classification", Active: true},
+		{Topic: "lint-short-text", Text: "Tiny", Classification: "green", Active: true},
+		{Topic: "lint-inactive-mapped", Text: "Inactive obligation text of an active license", Classification: "green"},
+		{Topic: "lint-clean", Text: "Obligation text which is not suspicious", Classification: "green", Active: true},
+	}
+	for i := range obligations {
+		obligations[i].Type = "obligation"
+		obligations[i].TextHash = utils.ObligationTextHash(obligations[i].Text)
+		if err := db.DB.Create(&obligations[i]).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		if i >= 3 {
+			if err := db.DB.Create(&models.ObligationMap{ObligationPk: obligations[i].Id, RfPk: license.Id}).Error; err != nil {
+				t.Fatalf("Unable to create obligation map: %v", err)
+			}
+		}
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/lint", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationLintResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	lint := res.Data
+	assert.Contains(t, lint.TopicIsTextHash.Topics, obligations[0].Topic)
+	assert.Contains(t, lint.EmptyClassification.Topics, "lint-empty-classification")
+	assert.Contains(t, lint.ShortText.Topics, "lint-short-text")
+	assert.Contains(t, lint.Unmapped.Topics, "lint-short-text")
+	assert.Contains(t, lint.InactiveMappedToActiveLicenses.Topics, "lint-inactive-mapped")
+	assert.NotContains(t, lint.Unmapped.Topics, "lint-inactive-mapped")
+	for _, category := range []models.ObligationLintCategory{lint.TopicIsTextHash, lint.EmptyClassification,
+		lint.ShortText, lint.Unmapped, lint.InactiveMappedToActiveLicenses} {
+		assert.NotContains(t, category.Topics, "lint-clean")
+		assert.Equal(t, len(category.Topics), category.Count)
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/lint?minTextLength=3", nil, false)
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.NotContains(t, res.Data.ShortText.Topics, "lint-short-text")
+
+	w = makeRequest("GET", "/api/v1/obligations/lint?minTextLength=short", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestValidateObligations(t *testing.T) {
+	valid := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "validate-valid",
+		Type:           "obligation",
+		Text:           "Obligation text which passes the validation",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "comment",
+		Active:         true,
+	}
+	invalid := valid
+	invalid.Topic = "Validate Invalid"
+	invalid.Type = "unknown"
+	invalid.Classification = "purple"
+	invalid.Text = "Tiny"
+	repeated := valid
+	repeated.Text = "Obligation text of a repeated topic"
+	existing := valid
+	existing.Topic = "conditional-update"
+	existing.Text = "Obligation text of an existing topic"
+
+	var before int64
+	db.DB.Model(&models.Obligation{}).Count(&before)
+
+	w := makeRequest("POST", "/api/v1/obligations/validate",
+		[]models.ObligationPOSTRequestJSONSchema{valid, invalid, repeated, existing}, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationValidationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.False(t, res.Valid)
+	if !assert.Len(t, res.Data, 4) {
+		return
+	}
+	assert.True(t, res.Data[0].Valid)
+	assert.Empty(t, res.Data[0].Errors)
+
+	// All the errors of an obligation are reported. A field may violate several rules,
+	// like the short text, which may also be the text of an obligation of another test.
+	rules := make(map[string][]string)
+	for _, fieldError := range res.Data[1].Errors {
+		rules[fieldError.Field] = append(rules[fieldError.Field], fieldError.Rule)
+	}
+	assert.False(t, res.Data[1].Valid)
+	assert.Len(t, rules, 4)
+	for field, rule := range map[string]string{"topic": "slug", "type": "obligation_type", "classification": "oneof",
+		"text": "min"} {
+		assert.Contains(t, rules[field], rule)
+	}
+
+	assert.Equal(t, []models.FieldError{{Field: "topic", Rule: "unique",
+		Message: "topic 'validate-valid' is already used by obligation 0 of the batch"}}, res.Data[2].Errors)
+	assert.Equal(t, []models.FieldError{{Field: "topic", Rule: "unique",
+		Message: "an obligation with topic 'conditional-update' already exists"}}, res.Data[3].Errors)
+
+	var after int64
+	db.DB.Model(&models.Obligation{}).Count(&after)
+	assert.Equal(t, before, after)
+}
+
+func TestGetObligationTopicSuggestions(t *testing.T) {
+	w := makeRequest("GET", "/api/v1/obligations/conditional-updte", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var res models.ObligationNotFoundError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.LessOrEqual(t, len(res.Suggestions), TOPIC_SUGGESTION_COUNT)
+	if assert.NotEmpty(t, res.Suggestions) {
+		assert.Equal(t, "conditional-update", res.Suggestions[0])
+	}
+
+	// Unrelated topics are not suggested
+	w = makeRequest("GET", "/api/v1/obligations/zzzzzzzzzzzzzzzzzzzz", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	res = models.ObligationNotFoundError{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.NotNil(t, res.Suggestions)
+	assert.Empty(t, res.Suggestions)
+
+	assert.Equal(t, 0, utils.LevenshteinDistance("copyleft", "copyleft"))
+	assert.Equal(t, 2, utils.LevenshteinDistance("copyleft", "coplyeft"))
+	assert.Equal(t, 3, utils.LevenshteinDistance("kitten", "sitting"))
+}
+
+func TestGetObligationPlainText(t *testing.T) {
+	var obligation models.Obligation
+	if err := db.DB.Where(models.Obligation{Topic: "conditional-update"}).First(&obligation).Error; err != nil {
+		t.Fatalf("Error fetching obligation: %v", err)
+	}
+
+	w := makeRequestWithHeaders("GET", "/api/v1/obligations/conditional-update", nil, false,
+		map[string]string{"Accept": "text/plain"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, obligation.Text, w.Body.String())
+
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations/conditional-update", nil, false,
+		map[string]string{"Accept": "text/html,*/*"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations/conditional-update", nil, false,
+		map[string]string{"Accept": "image/png"})
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+}
+
+func TestHeadObligation(t *testing.T) {
+	obligation := models.Obligation{Topic: "head-obligation", Type: "obligation", Text: "Obligation text checked with HEAD",
+		TextHash: "head-obligation", Classification: "green", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	get := makeRequest("GET", "/api/v1/obligations/head-obligation", nil, false)
+	head := makeRequest("HEAD", "/api/v1/obligations/head-obligation", nil, false)
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Equal(t, get.Code, head.Code)
+	assert.Equal(t, get.Header().Get("Content-Type"), head.Header().Get("Content-Type"))
+	assert.Empty(t, head.Body.Bytes())
+
+	head = makeRequest("HEAD", "/api/v1/obligations/head-obligation-unknown", nil, false)
+	assert.Equal(t, http.StatusNotFound, head.Code)
+	assert.Empty(t, head.Body.Bytes())
+}
+
+func TestPublicReadWithInvalidCredentials(t *testing.T) {
+	headers := map[string]string{"Authorization": "invalid-token"}
+
+	w := makeRequestWithHeaders("GET", "/api/v1/obligations", nil, false, headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequestWithHeaders("GET", "/api/v1/licenses/MIT", nil, false, headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Parameters reserved to some users still require valid credentials
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations?status=DRAFT", nil, false, headers)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	// The other routes are not served
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations/watched", nil, false, headers)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestObligationAccessLog(t *testing.T) {
+	defaultReadAudit := ObligationReadAuditEnabled
+	defer func() { ObligationReadAuditEnabled = defaultReadAudit }()
+	ObligationReadAuditEnabled = func() bool { return true }
+
+	var sensitiveId int64
+	for _, obligation := range []models.ObligationPOSTRequestJSONSchema{
+		{Topic: "access-log-sensitive", Type: "obligation", Text: "Obligation text which is sensitive",
+			Classification: "green", Modifications: true, Comment: "comment", Active: true, Sensitive: true},
+		{Topic: "access-log-plain", Type: "obligation", Text: "Obligation text which is not sensitive",
+			Classification: "green", Modifications: true, Comment: "comment", Active: true},
+	} {
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		w = makeRequest("POST", "/api/v1/obligations/"+obligation.Topic+"/publish", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		if obligation.Sensitive && assert.Len(t, res.Data, 1) {
+			assert.True(t, res.Data[0].Sensitive)
+			sensitiveId = res.Data[0].Id
+		}
+	}
+
+	accessLog := func(topic string) []models.ObligationAccessLog {
+		w := makeRequest("GET", "/api/v1/obligations/"+topic+"/access-log", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationAccessLogResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	countAudits := func() int64 {
+		var audits int64
+		db.DB.Model(&models.Audit{}).Where(models.Audit{Type: "Obligation", TypeId: sensitiveId}).Count(&audits)
+		return audits
+	}
+	auditsBefore := countAudits()
+
+	for _, path := range []string{"/api/v1/obligations/access-log-sensitive",
+		fmt.Sprintf("/api/v1/obligations/id/%d", sensitiveId), "/api/v1/obligations/access-log-plain"} {
+		w := makeRequest("GET", path, nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	entries := accessLog("access-log-sensitive")
+	if assert.Len(t, entries, 2) {
+		for _, entry := range entries {
+			assert.Equal(t, "access-log-sensitive", entry.Topic)
+			assert.Equal(t, "fossy", entry.Username)
+		}
+	}
+	assert.Empty(t, accessLog("access-log-plain"))
+
+	// The reads are not added to the audits of the changes
+	assert.Equal(t, auditsBefore, countAudits())
+
+	w := makeRequest("GET", "/api/v1/obligations/access-log-sensitive", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	entries = accessLog("access-log-sensitive")
+	if assert.Len(t, entries, 3) {
+		assert.Empty(t, entries[0].Username)
+	}
+	// Reads with invalid credentials are served and logged anonymously
+	w = makeRequestWithHeaders("GET", "/api/v1/obligations/access-log-sensitive", nil, false,
+		map[string]string{"Authorization": "invalid-token"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	entries = accessLog("access-log-sensitive")
+	if assert.Len(t, entries, 4) {
+		assert.Empty(t, entries[0].Username)
+	}
+
+	ObligationReadAuditEnabled = func() bool { return false }
+	w = makeRequest("GET", "/api/v1/obligations/access-log-sensitive", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, accessLog("access-log-sensitive"), 4)
+
+	w = makeRequest("GET", "/api/v1/obligations/access-log-sensitive/access-log", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/access-log-unknown/access-log", nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeactivateObligationsByTopics(t *testing.T) {
+	for _, obligation := range []models.Obligation{
+		{Topic: "retire-active", Type: "obligation", Text: "Obligation text which is retired",
+			TextHash: "retire-active", Classification: "green", Active: true},
+		{Topic: "retire-inactive", Type: "obligation", Text: "Obligation text which is already retired",
+			TextHash: "retire-inactive", Classification: "green", Active: true},
+		{Topic: "retire-kept", Type: "obligation", Text: "Obligation text which is not retired",
+			TextHash: "retire-kept", Classification: "green", Active: true},
+	} {
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Topic: "retire-inactive"}).Update("active", false)
+
+	w := makeRequest("POST", "/api/v1/obligations/deactivate-by-topics", map[string]interface{}{
+		"topics":        []string{"retire-active", "retire-inactive", "retire-unknown", "retire-active"},
+		"change_reason": "Retired with the cleanup",
+	}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationDeactivateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, []string{"retire-active"}, res.Data.Deactivated)
+	assert.Equal(t, []string{"retire-inactive"}, res.Data.AlreadyInactive)
+	assert.Equal(t, []string{"retire-unknown"}, res.Data.NotFound)
+
+	var retired, kept models.Obligation
+	db.DB.Where(models.Obligation{Topic: "retire-active"}).First(&retired)
+	db.DB.Where(models.Obligation{Topic: "retire-kept"}).First(&kept)
+	assert.False(t, retired.Active)
+	assert.True(t, kept.Active)
+	var audit models.Audit
+	if assert.NoError(t, db.DB.Where(models.Audit{Type: "Obligation", TypeId: retired.Id}).
+		Order("id DESC").First(&audit).Error) {
+		assert.Equal(t, "Retired with the cleanup", audit.ChangeReason)
+	}
+
+	t.Run("too many topics", func(t *testing.T) {
+		topics := make([]string, MAX_DEACTIVATE_TOPICS+1)
+		for i := range topics {
+			topics[i] = fmt.Sprintf("retire-%d", i)
+		}
+		w := makeRequest("POST", "/api/v1/obligations/deactivate-by-topics",
+			map[string]interface{}{"topics": topics}, true)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("no topics", func(t *testing.T) {
+		w := makeRequest("POST", "/api/v1/obligations/deactivate-by-topics",
+			map[string]interface{}{"topics": []string{}}, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestClassifyObligation(t *testing.T) {
+	ruleIds := make(map[string]int64)
+	for _, rule := range []models.ClassificationRule{
+		{Keyword: "zorblax", Classification: "red"},
+		{Keyword: " frobnicate ", Classification: "yellow", Weight: 2},
+		{Keyword: "wibble", Classification: "red"},
+	} {
+		w := makeRequest("POST", "/api/v1/classification_rules", rule, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var res models.ClassificationRuleResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if assert.Len(t, res.Data, 1) {
+			ruleIds[res.Data[0].Keyword] = res.Data[0].Id
+		}
+	}
+	t.Cleanup(func() {
+		db.DB.Where("keyword IN ?", []string{"zorblax", "frobnicate", "wibble"}).Delete(&models.ClassificationRule{})
+	})
+	assert.Contains(t, ruleIds, "frobnicate")
+
+	classify := func(text string) models.ObligationClassification {
+		w := makeRequest("POST", "/api/v1/obligations/classify", models.ObligationClassifyInput{Text: text}, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationClassificationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	// A tie is broken in favour of the more severe classification
+	suggestion := classify("ZORBLAX frobnicate wibble")
+	assert.Equal(t, "red", suggestion.Classification)
+	assert.Equal(t, []string{"frobnicate", "wibble", "zorblax"}, suggestion.MatchedKeywords)
+	assert.Equal(t, map[string]int{"red": 2, "yellow": 2}, suggestion.Scores)
+
+	w := makeRequest("DELETE", fmt.Sprintf("/api/v1/classification_rules/%d", ruleIds["wibble"]), nil, true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "yellow", classify("zorblax frobnicate wibble").Classification)
+
+	suggestion = classify("xyzzy")
+	assert.Empty(t, suggestion.Classification)
+	assert.Empty(t, suggestion.MatchedKeywords)
+
+	w = makeRequest("POST", "/api/v1/obligations/classify", map[string]string{}, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("POST", "/api/v1/classification_rules",
+		models.ClassificationRule{Keyword: "zorblax", Classification: "green"}, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest("POST", "/api/v1/classification_rules",
+		models.ClassificationRule{Keyword: "quux", Classification: "purple"}, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("DELETE", fmt.Sprintf("/api/v1/classification_rules/%d", ruleIds["wibble"]), nil, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "participant").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	w = makeRequest("POST", "/api/v1/classification_rules",
+		models.ClassificationRule{Keyword: "quux", Classification: "green"}, true)
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "admin").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReclassifyObligations(t *testing.T) {
+	for _, obligation := range []models.Obligation{
+		{Topic: "reclassify-red", Type: "right", Text: "Obligation text which is reclassified",
+			TextHash: "reclassify-red", Classification: "red", Modifications: false, Active: true},
+		{Topic: "reclassify-red-modified", Type: "right", Text: "Obligation text which is modified",
+			TextHash: "reclassify-red-modified", Classification: "red", Modifications: true, Active: true},
+		{Topic: "reclassify-green", Type: "right", Text: "Obligation text which is green",
+			TextHash: "reclassify-green", Classification: "green", Modifications: false, Active: true},
+	} {
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	input := map[string]interface{}{
+		"filter":         map[string]interface{}{"classification": "red", "modifications": false, "type": "right"},
+		"classification": "yellow",
+		"change_reason":  "Re-leveled the risk of unmodified red rights",
+	}
+	reclassify := func(query string) models.ObligationReclassifyResult {
+		w := makeRequest("POST", "/api/v1/obligations/reclassify"+query, input, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationReclassifyResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+	classificationOf := func(topic string) string {
+		var obligation models.Obligation
+		if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+			t.Fatalf("Unable to fetch obligation: %v", err)
+		}
+		return obligation.Classification
+	}
+
+	preview := reclassify("?dryRun=true")
+	assert.True(t, preview.DryRun)
+	assert.Contains(t, preview.Topics, "reclassify-red")
+	assert.NotContains(t, preview.Topics, "reclassify-red-modified")
+	assert.NotContains(t, preview.Topics, "reclassify-green")
+	assert.Equal(t, len(preview.Topics), preview.Count)
+	assert.Equal(t, "red", classificationOf("reclassify-red"))
+
+	applied := reclassify("")
+	assert.False(t, applied.DryRun)
+	assert.Equal(t, preview.Topics, applied.Topics)
+	assert.Equal(t, "yellow", classificationOf("reclassify-red"))
+	assert.Equal(t, "red", classificationOf("reclassify-red-modified"))
+	assert.Equal(t, "green", classificationOf("reclassify-green"))
+
+	var audit models.Audit
+	if err := db.DB.Joins("JOIN obligations ON obligations.id = audits.type_id").
+		Where("audits.type = ? AND obligations.topic = ?", "Obligation", "reclassify-red").
+		Preload("ChangeLogs").First(&audit).Error; err != nil {
+		t.Fatalf("Unable to fetch audit: %v", err)
+	}
+	assert.Equal(t, "Re-leveled the risk of unmodified red rights", audit.ChangeReason)
+	if assert.Len(t, audit.ChangeLogs, 1) {
+		assert.Equal(t, "Classification", audit.ChangeLogs[0].Field)
+	}
+
+	// Reclassified obligations no longer match
+	assert.Empty(t, reclassify("").Topics)
+
+	input["classification"] = "purple"
+	w := makeRequest("POST", "/api/v1/obligations/reclassify", input, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	input["classification"] = "yellow"
+	w = makeRequest("POST", "/api/v1/obligations/reclassify?dryRun=maybe", input, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObligationChangesFeed(t *testing.T) {
+	since := url.QueryEscape(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	obligation := models.ObligationPOSTRequestJSONSchema{Topic: "feed-change", Type: "obligation",
+		Text: "Obligation text which is followed in a feed", Classification: "white", Modifications: true,
+		Comment: "comment", Active: true}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/feed-change/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("PATCH", "/api/v1/obligations/feed-change",
+		map[string]interface{}{"comment": "followed", "change_reason": "Clarified"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("DELETE", "/api/v1/obligations/feed-change", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// summaries gives the summaries of the entries of the obligation in the feed, latest first
+	summaries := func(query string) []string {
+		w := makeRequest("GET", "/api/v1/obligations/changes.atom?"+query, nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/atom+xml")
+		var feed models.AtomFeed
+		if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("Error unmarshalling XML: %v", err)
+		}
+		var summaries []string
+		for _, entry := range feed.Entries {
+			if entry.Title == "feed-change" {
+				assert.Equal(t, "/api/v1/obligations/feed-change", entry.Link.Href)
+				summaries = append(summaries, entry.Summary)
+			}
+		}
+		return summaries
+	}
+
+	assert.Equal(t, []string{"Deactivated", "Changed Comment: Clarified", "Changed Status: Published", "Created"},
+		summaries("since="+since+"&classification=white"))
+	assert.Empty(t, summaries("classification=red"))
+	assert.Empty(t, summaries("since="+url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))))
+
+	w = makeRequest("GET", "/api/v1/obligations/changes.atom?since=yesterday", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/changes.atom?classification=purple", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetRecentObligations(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	obligations := []models.Obligation{
+		{Topic: "recent-old", Text: "Obligation text changed long ago"},
+		{Topic: "recent-new", Text: "Obligation text changed recently"},
+		{Topic: "recent-draft", Text: "Draft obligation text changed recently", Status: models.OBLIGATION_STATUS_DRAFT},
+	}
+	for i := range obligations {
+		obligations[i].Type = "obligation"
+		obligations[i].TextHash = utils.ObligationTextHash(obligations[i].Text)
+		obligations[i].Active = true
+		if err := db.DB.Create(&obligations[i]).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	audit := func(obligation models.Obligation, at time.Time, fields ...string) {
+		audit := models.Audit{UserId: user.Id, Timestamp: at, Type: "Obligation", TypeId: obligation.Id}
+		for _, field := range fields {
+			audit.ChangeLogs = append(audit.ChangeLogs, models.ChangeLog{Field: field})
+		}
+		if err := db.DB.Omit("User").Create(&audit).Error; err != nil {
+			t.Fatalf("Unable to create audit: %v", err)
+		}
+	}
+	now := time.Now()
+	audit(obligations[0], now.AddDate(0, 0, -10), "Text")
+	audit(obligations[1], now.AddDate(0, 0, -10), "Active")
+	audit(obligations[1], now.Add(-2*time.Hour), "Text", "Classification")
+	audit(obligations[1], now.Add(-time.Hour), "Text")
+	audit(obligations[2], now.Add(-time.Hour), "Text")
+
+	// recent gives the recent changes of the obligations of this test by topic
+	recent := func(since string) map[string]models.ObligationRecentChange {
+		w := makeRequest("GET", "/api/v1/obligations/recent?since="+url.QueryEscape(since), nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationRecentChangesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		changes := make(map[string]models.ObligationRecentChange)
+		for i, change := range res.Data {
+			if i > 0 {
+				assert.False(t, change.LastChangedAt.After(res.Data[i-1].LastChangedAt))
+			}
+			if strings.HasPrefix(change.Obligation.Topic, "recent-") {
+				assert.NotContains(t, changes, change.Obligation.Topic)
+				changes[change.Obligation.Topic] = change
+			}
+		}
+		return changes
+	}
+
+	changes := recent("7d")
+	assert.Len(t, changes, 1)
+	if change, ok := changes["recent-new"]; assert.True(t, ok) {
+		assert.Equal(t, []string{"Classification", "Text"}, change.ChangedFields)
+		assert.Equal(t, 2, change.Audits)
+		assert.WithinDuration(t, now.Add(-time.Hour), change.LastChangedAt, time.Second)
+	}
+
+	changes = recent(now.AddDate(0, 0, -30).Format(time.RFC3339))
+	assert.Len(t, changes, 2)
+	assert.Equal(t, []string{"Active", "Classification", "Text"}, changes["recent-new"].ChangedFields)
+	assert.Equal(t, []string{"Text"}, changes["recent-old"].ChangedFields)
+
+	assert.Len(t, recent("90m"), 1)
+	assert.Empty(t, recent("30m"))
+
+	w := makeRequest("GET", "/api/v1/obligations/recent?since=lastweek", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchInObligation(t *testing.T) {
+	obligation := models.Obligation{Topic: "searched-obligation", Type: "obligation",
+		Text: "Obligation text with a searchable Quokka <clause> & more", TextHash: "searched-obligation", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	for _, search := range []string{"fuzzy", "full_text_search"} {
+		t.Run(search, func(t *testing.T) {
+			w := makeRequest("POST", "/api/v1/obligations/search",
+				models.SearchObligation{Field: "text", SearchTerm: "quokka", Search: search}, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+			var res models.ObligationSearchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Errorf("Error unmarshalling JSON: %v", err)
+				return
+			}
+			if assert.Len(t, res.Data, 1) {
+				assert.Equal(t, "searched-obligation", res.Data[0].Topic)
+				assert.Equal(t, "Obligation text with a searchable <mark>Quokka</mark> &lt;clause&gt; &amp; more",
+					res.Data[0].Highlight)
+			}
+		})
+	}
+}
+
+func TestObligationTranslations(t *testing.T) {
+	obligation := models.ObligationPOSTRequestJSONSchema{Topic: "translated-obligation", Type: "obligation",
+		Text: "Obligation text which is translated", Classification: "green", Modifications: true,
+		Comment: "comment", Active: true}
+	w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/translated-obligation/publish", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("PUT", "/api/v1/obligations/translated-obligation/translations/de",
+		models.ObligationTranslationInput{Text: "Erster Text"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	// Language tags are case-insensitive, so this updates the same translation
+	w = makeRequest("PUT", "/api/v1/obligations/translated-obligation/translations/DE",
+		models.ObligationTranslationInput{Text: "Übersetzter\r\nText"}, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("GET", "/api/v1/obligations/translated-obligation/translations", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var translations models.ObligationTranslationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &translations); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, translations.Data, 1) {
+		assert.Equal(t, "de", translations.Data[0].Language)
+		assert.Equal(t, "Übersetzter\r\nText", translations.Data[0].Text)
+	}
+
+	get := func(query string, headers map[string]string) (string, string) {
+		w := makeRequestWithHeaders("GET", "/api/v1/obligations/translated-obligation"+query, nil, false, headers)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if !assert.Len(t, res.Data, 1) {
+			return "", ""
+		}
+		return res.Data[0].Text, w.Header().Get("Content-Language")
+	}
+	// A regional language falls back to its base language
+	text, language := get("", map[string]string{"Accept-Language": "fr;q=0.9, de-AT"})
+	assert.Equal(t, "Übersetzter\r\nText", text)
+	assert.Equal(t, "de", language)
+	text, language = get("?lang=fr", map[string]string{"Accept-Language": "de"})
+	assert.Equal(t, obligation.Text, text)
+	assert.Empty(t, language)
+	text, _ = get("?lang=de&raw=false", nil)
+	assert.Equal(t, "Übersetzter\nText", text)
+
+	w = makeRequest("PUT", "/api/v1/obligations/translated-obligation/translations/not-a-language!",
+		models.ObligationTranslationInput{Text: "Text"}, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("PUT", "/api/v1/obligations/translated-obligation/translations/de", map[string]string{}, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("PUT", "/api/v1/obligations/untranslated-missing/translations/de",
+		models.ObligationTranslationInput{Text: "Text"}, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestObligationNotes(t *testing.T) {
+	obligation := models.Obligation{Topic: "noted-obligation", Type: "obligation", Text: "Obligation text with notes",
+		TextHash: "noted-obligation", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	for _, text := range []string{"First note", "Second note", "Third note"} {
+		w := makeRequest("POST", "/api/v1/obligations/noted-obligation/notes", models.ObligationNoteInput{Text: text}, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var res models.ObligationNoteResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if assert.Len(t, res.Data, 1) {
+			assert.Equal(t, text, res.Data[0].Text)
+			assert.Equal(t, "fossy", res.Data[0].User.Username)
+		}
+	}
+
+	// The notes are listed newest first, and paginated
+	w := makeRequest("GET", "/api/v1/obligations/noted-obligation/notes?limit=2", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationNoteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Data, 2) {
+		assert.Equal(t, "Third note", res.Data[0].Text)
+		assert.Equal(t, "Second note", res.Data[1].Text)
+	}
+	assert.Equal(t, 3, res.Meta.ResourceCount)
+
+	w = makeRequest("POST", "/api/v1/obligations/noted-obligation/notes", map[string]string{}, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("POST", "/api/v1/obligations/noted-obligation/notes", models.ObligationNoteInput{Text: "Note"}, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/unnoted-missing/notes", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestObligationBulkTags(t *testing.T) {
+	obligationIds := make(map[string]int64)
+	for _, obligation := range []models.Obligation{
+		{Topic: "bulk-tag-red", Classification: "red"},
+		{Topic: "bulk-tag-red-other", Classification: "red"},
+		{Topic: "bulk-tag-green", Classification: "green"},
+	} {
+		obligation.Type = "risk"
+		obligation.Text = "Obligation text which is tagged " + obligation.Topic
+		obligation.TextHash = obligation.Topic
+		obligation.Active = true
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		obligationIds[obligation.Topic] = obligation.Id
+	}
+	tagged := func() []string {
+		var topics []string
+		db.DB.Model(&models.Obligation{}).
+			Joins("JOIN obligation_tags ON obligation_tags.obligation_pk = obligations.id").
+			Where("obligation_tags.tag = ? AND obligations.topic LIKE ?", "bulk-tag", "bulk-tag-%").
+			Order("topic").Pluck("topic", &topics)
+		return topics
+	}
+	update := func(action string, input models.ObligationTagAssignInput) int64 {
+		w := makeRequest("POST", "/api/v1/obligations/tags/"+action, input, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationTagAssignResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data.Count
+	}
+
+	byTopics := models.ObligationTagAssignInput{Tag: "bulk-tag", Topics: []string{"bulk-tag-red", "bulk-tag-green"}}
+	assert.Equal(t, int64(2), update("assign", byTopics))
+	// Obligations which already have the tag are not counted
+	assert.Zero(t, update("assign", byTopics))
+	assert.Equal(t, []string{"bulk-tag-green", "bulk-tag-red"}, tagged())
+
+	byFilter := models.ObligationTagAssignInput{Tag: "bulk-tag",
+		Filter: &models.ObligationTagFilter{Type: "risk", Classification: "red"}}
+	assert.GreaterOrEqual(t, update("assign", byFilter), int64(1))
+	assert.Equal(t, []string{"bulk-tag-green", "bulk-tag-red", "bulk-tag-red-other"}, tagged())
+
+	assert.Equal(t, int64(1), update("unassign", models.ObligationTagAssignInput{Tag: "bulk-tag",
+		Topics: []string{"bulk-tag-green"}}))
+	assert.Equal(t, []string{"bulk-tag-red", "bulk-tag-red-other"}, tagged())
+	var audit models.Audit
+	if assert.NoError(t, db.DB.Preload("ChangeLogs").Where(models.Audit{Type: "Obligation",
+		TypeId: obligationIds["bulk-tag-green"]}).Order("id desc").First(&audit).Error) &&
+		assert.Len(t, audit.ChangeLogs, 1) {
+		assert.Equal(t, "Tags", audit.ChangeLogs[0].Field)
+		assert.Equal(t, "bulk-tag", *audit.ChangeLogs[0].OldValue)
+		assert.Nil(t, audit.ChangeLogs[0].UpdatedValue)
+	}
+
+	for _, input := range []models.ObligationTagAssignInput{
+		{Tag: " ", Topics: []string{"bulk-tag-red"}},
+		{Tag: "bulk-tag"},
+		{Tag: "bulk-tag", Topics: []string{"bulk-tag-red"}, Filter: byFilter.Filter},
+	} {
+		w := makeRequest("POST", "/api/v1/obligations/tags/assign", input, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestFindInObligation(t *testing.T) {
+	obligation := models.Obligation{
+		Topic: "find-in-text",
+		Type:  "obligation",
+		Text:  "Über notice: keep the Notice. Every NOTICE stays.",
+	}
+	obligation.TextHash = utils.ObligationTextHash(obligation.Text)
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/find-in-text/find?q=notice", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationTextMatchesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, "notice", res.Data.Query)
+	text := []rune(obligation.Text)
+	if assert.Len(t, res.Data.Matches, 3) {
+		for i, offset := range []int{5, 22, 36} {
+			match := res.Data.Matches[i]
+			assert.Equal(t, offset, match.Offset)
+			assert.Equal(t, 6, match.Length)
+			assert.True(t, strings.EqualFold("notice", string(text[match.Offset:match.Offset+match.Length])))
+			snippet := []rune(match.Snippet)
+			assert.Equal(t, string(text[match.Offset:match.Offset+match.Length]),
+				string(snippet[match.SnippetOffset:match.SnippetOffset+match.Length]))
+		}
+	}
+
+	w = makeRequest("GET", "/api/v1/obligations/find-in-text/find?q=absent", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"matches":[]`)
+
+	w = makeRequest("GET", "/api/v1/obligations/find-in-text/find", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/no-such-topic/find?q=notice", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	draft := models.Obligation{
+		Topic:  "probe-draft",
+		Type:   "obligation",
+		Text:   "Secret draft obligation text here",
+		Status: models.OBLIGATION_STATUS_DRAFT,
+	}
+	draft.TextHash = utils.ObligationTextHash(draft.Text)
+	if err := db.DB.Create(&draft).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	w = makeRequest("GET", "/api/v1/obligations/probe-draft/find?q=secret", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "Secret")
+	w = makeRequest("GET", "/api/v1/obligations/probe-draft/find?q=secret&status=DRAFT", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations/probe-draft/find?q=secret&status=DRAFT", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Secret")
+}
+
+func TestDraftObligationsHiddenFromPublicReads(t *testing.T) {
+	draft := models.Obligation{
+		Topic:  "hidden-draft",
+		Type:   "obligation",
+		Text:   "Hidden draft obligation text for the public reads",
+		Active: true,
+		Status: models.OBLIGATION_STATUS_DRAFT,
+	}
+	draft.NormalizedText = utils.NormalizeObligationText(draft.Text)
+	draft.TextHash = utils.ObligationTextHash(draft.Text)
+	if err := db.DB.Create(&draft).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	shortname := "MIT"
+	var license models.LicenseDB
+	if err := db.DB.Where(&models.LicenseDB{Shortname: &shortname}).First(&license).Error; err != nil {
+		t.Fatalf("Unable to fetch license: %v", err)
+	}
+	if err := db.DB.Create(&models.ObligationMap{ObligationPk: draft.Id, RfPk: license.Id}).Error; err != nil {
+		t.Fatalf("Unable to create obligation map: %v", err)
+	}
+
+	listings := []string{
+		"/api/v1/obligations/preview",
+		"/api/v1/obligations/usage",
+		"/api/v1/obligations/lint",
+		"/api/v1/obligations/graph",
+		"/api/v1/obligations/mapping-health",
+		"/api/v1/obligation_maps/license/MIT",
+	}
+	for _, path := range listings {
+		t.Run(path, func(t *testing.T) {
+			w := makeRequest("GET", path, nil, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotContains(t, w.Body.String(), "hidden-draft")
+
+			w = makeRequest("GET", path+"?status=DRAFT", nil, false)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			w = makeRequest("GET", path+"?status=DRAFT", nil, true)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), "hidden-draft")
+		})
+	}
+
+	topicReads := []string{
+		"/api/v1/obligation_maps/topic/hidden-draft",
+		"/api/v1/obligations/hidden-draft/similar",
+		"/api/v1/obligations/hidden-draft/translations",
+		"/api/v1/obligations/hidden-draft/notes",
+		"/api/v1/obligations/hidden-draft/snapshots",
+	}
+	for _, path := range topicReads {
+		t.Run(path, func(t *testing.T) {
+			w := makeRequest("GET", path, nil, false)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+
+			w = makeRequest("GET", path+"?status=DRAFT", nil, true)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	t.Run("length-distribution", func(t *testing.T) {
+		count := func(w *httptest.ResponseRecorder) int64 {
+			var res models.ObligationLengthDistributionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Error unmarshalling JSON: %v", err)
+			}
+			var total int64
+			for _, bucket := range res.Data {
+				total += bucket.Count
+			}
+			return total
+		}
+		w := makeRequest("GET", "/api/v1/obligations/length-distribution", nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		public := count(w)
+		w = makeRequest("GET", "/api/v1/obligations/length-distribution?status=PUBLISHED", nil, true)
+		assert.Equal(t, public, count(w))
+		w = makeRequest("GET", "/api/v1/obligations/length-distribution?status=DRAFT", nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotZero(t, count(w))
+		w = makeRequest("GET", "/api/v1/obligations/length-distribution?status=DRAFT", nil, false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("check-duplicates", func(t *testing.T) {
+		input := []models.ObligationDuplicateCheckInput{{Topic: "hidden-draft", Text: draft.Text}}
+		w := makeRequest("POST", "/api/v1/obligations/check-duplicates", input, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationDuplicateCheckResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if assert.Len(t, res.Data, 1) {
+			assert.False(t, res.Data[0].TopicExists)
+			assert.False(t, res.Data[0].TextExists)
+		}
+
+		w = makeRequest("POST", "/api/v1/obligations/check-duplicates?status=DRAFT", input, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		if assert.Len(t, res.Data, 1) {
+			assert.True(t, res.Data[0].TopicExists)
+			assert.True(t, res.Data[0].TextExists)
+		}
+	})
+
+	t.Run("head", func(t *testing.T) {
+		w := makeRequest("HEAD", "/api/v1/obligations/hidden-draft", nil, false)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = makeRequest("HEAD", "/api/v1/obligations/hidden-draft?status=DRAFT", nil, false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestDraftObligationAuditsHiddenFromPublicReads(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	draft := models.Obligation{
+		Topic:  "hidden-draft-audits",
+		Type:   "obligation",
+		Text:   "Hidden draft obligation text of the audits",
+		Active: true,
+		Status: models.OBLIGATION_STATUS_DRAFT,
+	}
+	draft.NormalizedText = utils.NormalizeObligationText(draft.Text)
+	draft.TextHash = utils.ObligationTextHash(draft.Text)
+	if err := db.DB.Create(&draft).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	oldText := "Former draft text"
+	audit := models.Audit{UserId: user.Id, Timestamp: time.Now(), Type: "Obligation", TypeId: draft.Id}
+	if err := db.DB.Omit("User").Create(&audit).Error; err != nil {
+		t.Fatalf("Unable to create audit: %v", err)
+	}
+	changelog := models.ChangeLog{AuditId: audit.Id, Field: "Text", OldValue: &oldText, UpdatedValue: &draft.Text}
+	if err := db.DB.Omit("Audit").Create(&changelog).Error; err != nil {
+		t.Fatalf("Unable to create changelog: %v", err)
+	}
+	archived := models.ArchivedAudit{Id: audit.Id + 1000000, UserId: user.Id, Timestamp: time.Now(),
+		Type: "Obligation", TypeId: draft.Id, ArchivedAt: time.Now()}
+	if err := db.DB.Create(&archived).Error; err != nil {
+		t.Fatalf("Unable to create archived audit: %v", err)
+	}
+
+	obligationReads := []string{
+		"/api/v1/obligations/hidden-draft-audits/audits",
+		"/api/v1/obligations/hidden-draft-audits/audits/summary",
+		fmt.Sprintf("/api/v1/obligations/id/%d/audits", draft.Id),
+	}
+	for _, path := range obligationReads {
+		t.Run(path, func(t *testing.T) {
+			w := makeRequest("GET", path, nil, false)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			w = makeRequest("GET", path+"?status=DRAFT", nil, false)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			w = makeRequest("GET", path+"?status=DRAFT", nil, true)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		body := models.ObligationAuditBatchRequest{Topics: []string{"hidden-draft-audits"}}
+		w := makeRequest("POST", "/api/v1/obligations/audits/batch", body, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationAuditBatchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		assert.Equal(t, []string{"hidden-draft-audits"}, res.UnknownTopics)
+		assert.Empty(t, res.Data)
+
+		w = makeRequest("POST", "/api/v1/obligations/audits/batch?status=DRAFT", body, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		res = models.ObligationAuditBatchResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		assert.Empty(t, res.UnknownTopics)
+		assert.Len(t, res.Data, 1)
+	})
+
+	auditReads := []string{
+		fmt.Sprintf("/api/v1/audits/%d", audit.Id),
+		fmt.Sprintf("/api/v1/audits/%d/changes", audit.Id),
+		fmt.Sprintf("/api/v1/audits/%d/changes/%d", audit.Id, changelog.Id),
+	}
+	for _, path := range auditReads {
+		t.Run(path, func(t *testing.T) {
+			w := makeRequest("GET", path, nil, false)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.NotContains(t, w.Body.String(), oldText)
+			w = makeRequest("GET", path, nil, true)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	auditListings := map[string]int64{
+		"/api/v1/audits": audit.Id,
+		fmt.Sprintf("/api/v1/audits/archive?type=obligation&type_id=%d", draft.Id): archived.Id,
+	}
+	for path, id := range auditListings {
+		t.Run(path, func(t *testing.T) {
+			ids := func(w *httptest.ResponseRecorder) []int64 {
+				var res struct {
+					Data []struct {
+						Id int64 `json:"id"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+					t.Fatalf("Error unmarshalling JSON: %v", err)
+				}
+				ids := []int64{}
+				for _, audit := range res.Data {
+					ids = append(ids, audit.Id)
+				}
+				return ids
+			}
+			w := makeRequest("GET", path, nil, false)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotContains(t, ids(w), id)
+			w = makeRequest("GET", path, nil, true)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, ids(w), id)
+		})
+	}
+}
+
+func TestGetObligationAuditsBatch(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	obligations := []models.Obligation{
+		{Topic: "batch-audits-a", Text: "First obligation text with batch audits"},
+		{Topic: "batch-audits-b", Text: "Second obligation text with batch audits"},
+	}
+	for i := range obligations {
+		obligations[i].Type = "obligation"
+		obligations[i].TextHash = utils.ObligationTextHash(obligations[i].Text)
+		if err := db.DB.Create(&obligations[i]).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	now := time.Now()
+	audits := []models.Audit{
+		{UserId: user.Id, Timestamp: now.Add(-3 * time.Hour), Type: "Obligation", TypeId: obligations[0].Id},
+		{UserId: user.Id, Timestamp: now.Add(-2 * time.Hour), Type: "Obligation", TypeId: obligations[1].Id},
+		{UserId: user.Id, Timestamp: now.Add(-time.Hour), Type: "Obligation", TypeId: obligations[0].Id},
+	}
+	if err := db.DB.Omit("User").Create(&audits).Error; err != nil {
+		t.Fatalf("Unable to create audits: %v", err)
+	}
+
+	body := models.ObligationAuditBatchRequest{
+		Topics: []string{"batch-audits-b", "no-such-topic", "batch-audits-a", "batch-audits-b"},
+	}
+	w := makeRequest("POST", "/api/v1/obligations/audits/batch", body, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ObligationAuditBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, []string{"no-such-topic"}, res.UnknownTopics)
+	assert.Equal(t, 3, res.Meta.ResourceCount)
+	if assert.Len(t, res.Data, 2) {
+		assert.Equal(t, "batch-audits-b", res.Data[0].Topic)
+		assert.Len(t, res.Data[0].Audits, 1)
+		assert.Equal(t, "batch-audits-a", res.Data[1].Topic)
+		if assert.Len(t, res.Data[1].Audits, 2) {
+			assert.Equal(t, audits[2].Id, res.Data[1].Audits[0].Id)
+			assert.Equal(t, audits[0].Id, res.Data[1].Audits[1].Id)
+		}
+	}
+
+	// The pagination is over the audits, newest first
+	w = makeRequest("POST", "/api/v1/obligations/audits/batch?limit=1", body, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	res = models.ObligationAuditBatchResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, int64(3), res.Meta.TotalPages)
+	if assert.Len(t, res.Data, 1) {
+		assert.Equal(t, "batch-audits-a", res.Data[0].Topic)
+	}
+
+	w = makeRequest("POST", "/api/v1/obligations/audits/batch", models.ObligationAuditBatchRequest{}, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportObligationMapMatrix(t *testing.T) {
+	licenses := make(map[string]int64)
+	for _, shortname := range []string{"matrix-a", "matrix-b", "matrix-inactive"} {
+		license := models.LicenseDB{
+			Shortname: func(s string) *string { return &s }(shortname),
+			Fullname:  func(s string) *string { return &s }("License " + shortname),
+			Text:      func(s string) *string { return &s }("License text of " + shortname),
+			SpdxId:    func(s string) *string { return &s }(shortname),
+			Active:    func(b bool) *bool { return &b }(shortname != "matrix-inactive"),
+		}
+		if err := db.DB.Create(&license).Error; err != nil {
+			t.Fatalf("Unable to create license: %v", err)
+		}
+		licenses[shortname] = license.Id
+	}
+	mappedLicenses := map[string][]string{
+		"matrix-one": {"matrix-a", "matrix-b", "matrix-inactive"},
+		"matrix-two": {"matrix-b"},
+		"matrix-off": {"matrix-a"},
+	}
+	for topic, shortnames := range mappedLicenses {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Active: topic != "matrix-off"}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		for _, shortname := range shortnames {
+			if err := db.DB.Create(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: licenses[shortname]}).Error; err != nil {
+				t.Fatalf("Unable to create obligation map: %v", err)
+			}
+		}
+	}
+
+	matrix := func(query string) [][]string {
+		w := makeRequest("GET", "/api/v1/obligation_maps/matrix"+query, nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Error reading csv: %v", err)
+		}
+		return records
+	}
+
+	assert.Equal(t, [][]string{
+		{"license", "matrix-one", "matrix-two"},
+		{"matrix-a", "x", ""},
+		{"matrix-b", "x", "x"},
+	}, matrix("?licenses=matrix-b,matrix-a&format=csv"))
+
+	// All the active licenses are the rows by default
+	records := matrix("")
+	assert.Equal(t, "license", records[0][0])
+	var rows []string
+	for _, record := range records[1:] {
+		assert.Len(t, record, len(records[0]))
+		rows = append(rows, record[0])
+	}
+	assert.Contains(t, rows, "matrix-a")
+	assert.Contains(t, rows, "matrix-b")
+	assert.NotContains(t, rows, "matrix-inactive")
+	assert.Contains(t, records[0], "matrix-one")
+	assert.NotContains(t, records[0], "matrix-off")
+
+	w := makeRequest("GET", "/api/v1/obligation_maps/matrix?licenses=matrix-a,matrix-unknown", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "matrix-unknown")
+	w = makeRequest("GET", "/api/v1/obligation_maps/matrix?format=json", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAllObligationActiveFilter(t *testing.T) {
+	for _, active := range []bool{true, false} {
+		topic := fmt.Sprintf("active-filter-%t", active)
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Active: active}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	// topics gives the topics of the obligations of this test listed with the query
+	topics := func(query string) []string {
+		w := makeRequest("GET", "/api/v1/obligations?limit=1000&filter="+url.QueryEscape("topic like 'active-filter-%'")+query,
+			nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		topics := []string{}
+		for _, obligation := range res.Data {
+			topics = append(topics, obligation.Topic)
+		}
+		return topics
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		fallback string
+		expected []string
+	}{
+		{"all", "&active=all", "", []string{"active-filter-false", "active-filter-true"}},
+		{"true", "&active=true", "all", []string{"active-filter-true"}},
+		{"false", "&active=false", "", []string{"active-filter-false"}},
+		{"omitted", "", "", []string{"active-filter-true"}},
+		{"omitted with all default", "", "all", []string{"active-filter-false", "active-filter-true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OBLIGATION_ACTIVE_DEFAULT", tt.fallback)
+			assert.Equal(t, tt.expected, topics(tt.query))
+		})
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations?active=both", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetObligationAuditReport(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	obligation := models.Obligation{Topic: "audit-report", Type: "obligation", Text: "Obligation text with a report",
+		TextHash: "audit-report", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	updated := "red"
+	audit := models.Audit{UserId: user.Id, Timestamp: time.Now().Add(-time.Hour), Type: "Obligation",
+		TypeId: obligation.Id, ChangeLogs: []models.ChangeLog{{Field: "Classification", UpdatedValue: &updated}}}
+	if err := db.DB.Omit("User").Create(&audit).Error; err != nil {
+		t.Fatalf("Unable to create audit: %v", err)
+	}
+	archived := models.ArchivedAudit{Id: audit.Id + 1000000, UserId: user.Id, Timestamp: time.Now().AddDate(-2, 0, 0),
+		Type: "Obligation", TypeId: obligation.Id, ArchivedAt: time.Now(),
+		ChangeLogs: datatypes.NewJSONType([]models.ChangeLog{{Field: "Active"}})}
+	if err := db.DB.Create(&archived).Error; err != nil {
+		t.Fatalf("Unable to create archived audit: %v", err)
+	}
+
+	t.Setenv("AUDIT_REPORT_SIGNING_KEY", "")
+	w := makeRequest("GET", "/api/v1/obligations/audit-report/audit-report", nil, true)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	key := "report-signing-key"
+	t.Setenv("AUDIT_REPORT_SIGNING_KEY", key)
+	w = makeRequest("GET", "/api/v1/obligations/audit-report/audit-report", nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var raw struct {
+		Data      json.RawMessage        `json:"data"`
+		Signature models.ReportSignature `json:"signature"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(raw.Data)
+	assert.Equal(t, "HMAC-SHA256", raw.Signature.Algorithm)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), raw.Signature.Value)
+
+	var report models.ObligationAuditReport
+	if err := json.Unmarshal(raw.Data, &report); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Equal(t, "fossy", report.GeneratedBy)
+	assert.WithinDuration(t, time.Now(), report.GeneratedAt, time.Minute)
+	if assert.Len(t, report.Audits, 2) {
+		assert.True(t, report.Audits[0].Archived)
+		assert.Equal(t, "Active", report.Audits[0].ChangeLogs[0].Field)
+		assert.False(t, report.Audits[1].Archived)
+		assert.Equal(t, "fossy", report.Audits[1].Username)
+		if assert.Len(t, report.Audits[1].ChangeLogs, 1) {
+			assert.Equal(t, "red", *report.Audits[1].ChangeLogs[0].UpdatedValue)
+		}
+	}
+
+	// An altered report no longer matches the signature
+	tampered := strings.Replace(string(raw.Data), `"red"`, `"green"`, 1)
+	mac = hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(tampered))
+	assert.NotEqual(t, hex.EncodeToString(mac.Sum(nil)), raw.Signature.Value)
+}
+
+func TestGetAllObligationHasComment(t *testing.T) {
+	for topic, comment := range map[string]string{
+		"has-comment-annotated":      "Checked against the license text",
+		"has-comment-also-annotated": "Needs a second review",
+		"has-comment-blank":          "",
+	} {
+		obligation := models.Obligation{Topic: topic, Type: "obligation", Text: "Obligation text of " + topic,
+			TextHash: topic, Comment: comment, Active: true}
+		if err := db.DB.Create(&obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	// topics gives the topics of the obligations of this test listed with the query
+	topics := func(query string) ([]string, *models.PaginationMeta) {
+		w := makeRequest("GET", "/api/v1/obligations?filter="+url.QueryEscape("topic like 'has-comment-%'")+query,
+			nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		topics := []string{}
+		for _, obligation := range res.Data {
+			topics = append(topics, obligation.Topic)
+		}
+		return topics, res.Meta
+	}
+
+	annotated, meta := topics("&hasComment=true")
+	assert.Equal(t, []string{"has-comment-also-annotated", "has-comment-annotated"}, annotated)
+	assert.Equal(t, 2, meta.ResourceCount)
+	blank, _ := topics("&hasComment=false")
+	assert.Equal(t, []string{"has-comment-blank"}, blank)
+
+	// Combined with the pagination, the count is of the commented obligations only
+	page, meta := topics("&hasComment=true&limit=1&page=2")
+	assert.Equal(t, []string{"has-comment-annotated"}, page)
+	assert.Equal(t, 2, meta.ResourceCount)
+	assert.Equal(t, int64(2), meta.TotalPages)
+
+	w := makeRequest("GET", "/api/v1/obligations?hasComment=maybe", nil, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetObligationAuditSummary(t *testing.T) {
+	obligation := models.Obligation{Topic: "audit-summary", Type: "obligation", Text: "Obligation text with audits",
+		TextHash: "audit-summary", Classification: "green", Comment: "comment", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	summary := func() models.ObligationAuditSummary {
+		w := makeRequest("GET", "/api/v1/obligations/audit-summary/audits/summary", nil, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.ObligationAuditSummaryResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	empty := summary()
+	assert.Zero(t, empty.TotalAudits)
+	assert.Nil(t, empty.LastChangedAt)
+	assert.Nil(t, empty.LastChangedBy)
+	assert.Empty(t, empty.FieldChangeCounts)
+
+	for _, updates := range []map[string]interface{}{
+		{"classification": "yellow"},
+		{"classification": "red", "comment": "updated comment"},
+	} {
+		w := makeRequest("PATCH", "/api/v1/obligations/audit-summary", updates, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	changed := summary()
+	assert.Equal(t, int64(2), changed.TotalAudits)
+	assert.Equal(t, map[string]int64{"Classification": 2, "Comment": 1}, changed.FieldChangeCounts)
+	if assert.NotNil(t, changed.LastChangedBy) && assert.NotNil(t, changed.LastChangedAt) {
+		assert.Equal(t, "fossy", *changed.LastChangedBy)
+		assert.WithinDuration(t, time.Now(), *changed.LastChangedAt, time.Minute)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/audit-summary-missing/audits/summary", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestArchiveAudits(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	obligation := models.Obligation{Topic: "archived-audits", Type: "obligation", Text: "Obligation text with archived audits",
+		TextHash: "archived-audits", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	// More audits than fit in a batch, older than the audits of the other tests
+	count := AUDIT_ARCHIVE_BATCH_SIZE + AUDIT_ARCHIVE_BATCH_SIZE/2
+	timestamp := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	var auditIds []int64
+	for i := 0; i < count; i++ {
+		oldValue, updatedValue := fmt.Sprintf("comment %d", i), fmt.Sprintf("comment %d", i+1)
+		audit := models.Audit{UserId: user.Id, Timestamp: timestamp.Add(time.Duration(i) * time.Minute),
+			Type: "Obligation", TypeId: obligation.Id, ChangeLogs: []models.ChangeLog{
+				{Field: "Comment", OldValue: &oldValue, UpdatedValue: &updatedValue},
+			}}
+		if err := db.DB.Create(&audit).Error; err != nil {
+			t.Fatalf("Unable to create audit: %v", err)
+		}
+		auditIds = append(auditIds, audit.Id)
+	}
+	archive := func(query string) models.AuditArchiveResult {
+		w := makeRequest("POST", "/api/v1/audits/archive?before=1991-01-01T00:00:00Z"+query, nil, true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res models.AuditArchiveResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Error unmarshalling JSON: %v", err)
+		}
+		return res.Data
+	}
+
+	result := archive("&dryRun=true")
+	assert.True(t, result.DryRun)
+	assert.Equal(t, int64(count), result.Count)
+
+	result = archive("")
+	assert.False(t, result.DryRun)
+	assert.Equal(t, int64(count), result.Count)
+
+	var remaining int64
+	db.DB.Model(&models.Audit{}).Where("id IN ?", auditIds).Count(&remaining)
+	assert.Zero(t, remaining)
+	db.DB.Model(&models.ChangeLog{}).Where("audit_id IN ?", auditIds).Count(&remaining)
+	assert.Zero(t, remaining)
+	var archived []models.ArchivedAudit
+	if err := db.DB.Where("id IN ?", auditIds).Order("id").Find(&archived).Error; err != nil {
+		t.Fatalf("Unable to fetch archived audits: %v", err)
+	}
+	if assert.Len(t, archived, count) {
+		changeLogs := archived[count-1].ChangeLogs.Data()
+		if assert.Len(t, changeLogs, 1) {
+			assert.Equal(t, fmt.Sprintf("comment %d", count), *changeLogs[0].UpdatedValue)
+		}
+	}
+
+	assert.Zero(t, archive("").Count)
+
+	// The archived audits of the obligation are listed, the latest first
+	w := makeRequest("GET", fmt.Sprintf("/api/v1/audits/archive?type=obligation&type_id=%d&limit=10", obligation.Id),
+		nil, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.ArchivedAuditResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	if assert.Len(t, res.Data, 10) {
+		assert.Equal(t, auditIds[count-1], res.Data[0].Id)
+	}
+
+	for _, query := range []string{"?before=yesterday", "?dryRun=maybe"} {
+		w = makeRequest("POST", "/api/v1/audits/archive"+query, nil, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "participant").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	w = makeRequest("POST", "/api/v1/audits/archive?dryRun=true", nil, true)
+	if err := db.DB.Model(&models.User{}).Where(models.User{Username: "fossy"}).
+		Update("userlevel", "admin").Error; err != nil {
+		t.Fatalf("Unable to update userlevel: %v", err)
+	}
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRunMigrations(t *testing.T) {
+	// The backfills are not run on the fixtures of the other tests
+	defaultMigrations := db.Migrations
+	defer func() { db.Migrations = defaultMigrations }()
+	applyCount := 0
+	db.Migrations = []db.Migration{
+		{Version: 1000, Description: "Counted migration", Migrate: func(tx *gorm.DB) error {
+			applyCount++
+			return nil
+		}},
+	}
+
+	assert.NoError(t, db.RunMigrations())
+	assert.NoError(t, db.RunMigrations())
+	assert.Equal(t, 1, applyCount)
+	pending, err := db.PendingMigrations()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	var record models.SchemaMigration
+	if assert.NoError(t, db.DB.Where(models.SchemaMigration{Version: 1000}).First(&record).Error) {
+		assert.Equal(t, "Counted migration", record.Description)
+	}
+
+	obligation := models.Obligation{Topic: "migration-rollback", Type: "obligation", Text: "Obligation text of a failed migration",
+		TextHash: "migration-rollback", Classification: "green", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	db.Migrations = append(db.Migrations,
+		db.Migration{Version: 1001, Description: "Failing migration", Migrate: func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Obligation{}).Where(models.Obligation{Id: obligation.Id}).
+				UpdateColumn("classification", "red").Error; err != nil {
+				return err
+			}
+			return errors.New("migration failed")
+		}},
+		db.Migration{Version: 1002, Description: "Migration after the failing one", Migrate: func(tx *gorm.DB) error {
+			return nil
+		}},
+	)
+	assert.Error(t, db.RunMigrations())
+
+	// The failed migration is rolled back, and it and the later ones stay pending
+	pending, err = db.PendingMigrations()
+	assert.NoError(t, err)
+	var versions []int
+	for _, migration := range pending {
+		versions = append(versions, migration.Version)
+	}
+	assert.Equal(t, []int{1001, 1002}, versions)
+	var stored models.Obligation
+	db.DB.Where(models.Obligation{Id: obligation.Id}).First(&stored)
+	assert.Equal(t, "green", stored.Classification)
+}
+
+func TestBackfillObligationTimestamps(t *testing.T) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: "fossy"}).First(&user).Error; err != nil {
+		t.Fatalf("Unable to fetch user: %v", err)
+	}
+	audited := models.Obligation{Topic: "legacy-audited", Type: "obligation", Text: "Legacy obligation text with audits",
+		Active: true}
+	unaudited := models.Obligation{Topic: "legacy-unaudited", Type: "obligation", Text: "Legacy obligation text without audits",
+		Active: true}
+	for _, obligation := range []*models.Obligation{&audited, &unaudited} {
+		obligation.TextHash = utils.ObligationTextHash(obligation.Text)
+		if err := db.DB.Create(obligation).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+	firstAudit := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	lastAudit := firstAudit.Add(24 * time.Hour)
+	for _, timestamp := range []time.Time{lastAudit, firstAudit} {
+		audit := models.Audit{UserId: user.Id, Timestamp: timestamp, Type: "Obligation", TypeId: audited.Id}
+		if err := db.DB.Omit("User", "ChangeLogs").Create(&audit).Error; err != nil {
+			t.Fatalf("Unable to create audit: %v", err)
+		}
+	}
+	unauditedUpdate := firstAudit.Add(-time.Hour)
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Id: audited.Id}).
+		UpdateColumns(map[string]interface{}{"created_at": gorm.Expr("NULL"), "updated_at": gorm.Expr("NULL")})
+	db.DB.Model(&models.Obligation{}).Where(models.Obligation{Id: unaudited.Id}).
+		UpdateColumns(map[string]interface{}{"created_at": gorm.Expr("NULL"), "updated_at": unauditedUpdate})
+
+	assert.NoError(t, db.DB.Transaction(db.BackfillObligationTimestamps))
+
+	var stored models.Obligation
+	if assert.NoError(t, db.DB.Where(models.Obligation{Id: audited.Id}).First(&stored).Error) {
+		assert.True(t, firstAudit.Equal(stored.CreatedAt), "created at %v", stored.CreatedAt)
+		assert.True(t, lastAudit.Equal(stored.UpdatedAt), "updated at %v", stored.UpdatedAt)
+	}
+	stored = models.Obligation{}
+	if assert.NoError(t, db.DB.Where(models.Obligation{Id: unaudited.Id}).First(&stored).Error) {
+		assert.True(t, unauditedUpdate.Equal(stored.CreatedAt), "created at %v", stored.CreatedAt)
+		assert.True(t, unauditedUpdate.Equal(stored.UpdatedAt), "updated at %v", stored.UpdatedAt)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations?createdBefore="+url.QueryEscape(lastAudit.Format(time.RFC3339)), nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "legacy-audited")
+	assert.Contains(t, w.Body.String(), "legacy-unaudited")
+}
+
+func TestRehashObligationTexts(t *testing.T) {
+	obligations := []models.Obligation{
+		{Topic: "rehash-crlf", Text: "Legacy obligation text\r\nwith CRLF line endings"},
+		{Topic: "rehash-lf", Text: "\uFEFFLegacy obligation text\nwith CRLF line endings"},
+		{Topic: "rehash-unique", Text: "Legacy obligation text which is unique"},
+	}
+	for i := range obligations {
+		obligations[i].Type = "obligation"
+		legacy := md5.Sum([]byte(obligations[i].Text))
+		obligations[i].TextHash = hex.EncodeToString(legacy[:])
+		if err := db.DB.Create(&obligations[i]).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+	}
+
+	assert.NoError(t, db.DB.Transaction(db.RehashObligationTexts))
+
+	hashes := make(map[string]string)
+	for _, obligation := range obligations {
+		var stored models.Obligation
+		if assert.NoError(t, db.DB.Where(models.Obligation{Id: obligation.Id}).First(&stored).Error) {
+			hashes[stored.Topic] = stored.TextHash
+		}
+	}
+	assert.Equal(t, utils.ObligationTextHash(obligations[0].Text), hashes["rehash-crlf"])
+	assert.Equal(t, obligations[1].TextHash, hashes["rehash-lf"])
+	assert.Equal(t, utils.ObligationTextHash(obligations[2].Text), hashes["rehash-unique"])
+}
+
+func TestCreateUniqueObligationMapIndex(t *testing.T) {
+	obligation := models.Obligation{Topic: "duplicate-maps", Type: "obligation", Text: "Obligation text mapped twice to a license"}
+	obligation.TextHash = utils.ObligationTextHash(obligation.Text)
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+	shortname := "MIT"
+	var license models.LicenseDB
+	if err := db.DB.Where(&models.LicenseDB{Shortname: &shortname}).First(&license).Error; err != nil {
+		t.Fatalf("Unable to fetch license: %v", err)
+	}
+	countMaps := func() int64 {
+		var count int64
+		db.DB.Model(&models.ObligationMap{}).Where(models.ObligationMap{ObligationPk: obligation.Id}).Count(&count)
+		return count
+	}
+	// The index is already there on a database the migrations were applied to
+	duplicates := 2
+	if db.DB.Migrator().HasIndex(&models.ObligationMap{}, db.OBLIGATION_MAP_INDEX) {
+		duplicates = 1
+	}
+	for i := 0; i < duplicates; i++ {
+		if err := db.DB.Create(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).Error; err != nil {
+			t.Fatalf("Unable to create obligation map: %v", err)
+		}
+	}
+	assert.Equal(t, int64(duplicates), countMaps())
+
+	assert.NoError(t, db.DB.Transaction(db.CreateUniqueObligationMapIndex))
+	assert.NoError(t, db.DB.Transaction(db.CreateUniqueObligationMapIndex))
+	assert.Equal(t, int64(1), countMaps())
+	var stored models.Obligation
+	if assert.NoError(t, db.DB.Where(models.Obligation{Id: obligation.Id}).First(&stored).Error) {
+		assert.Equal(t, int64(1), stored.LicenseCount)
+	}
+	assert.Error(t, db.DB.Create(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).Error)
+	assert.Equal(t, int64(1), countMaps())
+}
+
+func TestSeedObligations(t *testing.T) {
+	fixture := []byte(`{
+		"licenses": [{"rf_shortname": "seed-license", "rf_fullname": "Seed License", "rf_text": "Seed license text",
+			"rf_spdx_compatible": "false", "rf_active": "true"}],
+		"obligations": [
+			{"topic": "seed-mapped", "type": "obligation", "text": "Seeded obligation mapped to a license",
+				"classification": "green", "active": true, "shortnames": ["seed-license"]},
+			{"topic": "seed-unmapped", "type": "risk", "text": "Seeded obligation without licenses",
+				"classification": "red", "active": true, "shortnames": []}
+		]
+	}`)
+	seedTopics := []string{"seed-mapped", "seed-unmapped", "seed-valid", "seed-invalid"}
+	seeded := func() (map[string]models.Obligation, int64) {
+		var obligations []models.Obligation
+		db.DB.Where("topic IN ?", seedTopics).Find(&obligations)
+		byTopic := make(map[string]models.Obligation)
+		for _, obligation := range obligations {
+			byTopic[obligation.Topic] = obligation
+		}
+		var mapCount int64
+		db.DB.Model(&models.ObligationMap{}).
+			Joins("JOIN obligations ON obligations.id = obligation_maps.obligation_pk").
+			Where("obligations.topic IN ?", seedTopics).Count(&mapCount)
+		return byTopic, mapCount
+	}
+	// The seeded obligations of earlier runs are removed first, and the ones of this
+	// run afterwards, so the test does not depend on what is left in the database
+	removeSeeded := func() {
+		seededIds := db.DB.Model(&models.Obligation{}).Select("id").Where("topic IN ?", seedTopics)
+		db.DB.Where("obligation_pk IN (?)", seededIds).Delete(&models.ObligationMap{})
+		db.DB.Where("topic IN ?", seedTopics).Delete(&models.Obligation{})
+	}
+	removeSeeded()
+	t.Cleanup(removeSeeded)
+
+	t.Run("idempotent", func(t *testing.T) {
+		assert.NoError(t, SeedObligations(fixture, false))
+		first, firstMapCount := seeded()
+		assert.NoError(t, SeedObligations(fixture, false))
+		second, secondMapCount := seeded()
+
+		assert.Len(t, second, 2)
+		assert.Equal(t, int64(1), secondMapCount)
+		assert.Equal(t, firstMapCount, secondMapCount)
+		for topic, obligation := range first {
+			assert.Equal(t, obligation.Id, second[topic].Id)
+		}
+		assert.Equal(t, int64(1), second["seed-mapped"].LicenseCount)
+		assert.Equal(t, models.OBLIGATION_STATUS_PUBLISHED, second["seed-unmapped"].Status)
+		var licenseCount int64
+		db.DB.Model(&models.LicenseDB{}).Where("rf_shortname = ?", "seed-license").Count(&licenseCount)
+		assert.Equal(t, int64(1), licenseCount)
+	})
+
+	t.Run("invalid fixture is not applied", func(t *testing.T) {
+		invalid := []byte(`{"obligations": [
+			{"topic": "seed-valid", "type": "obligation", "text": "Valid seeded obligation",
+				"classification": "green", "active": true, "shortnames": []},
+			{"topic": "seed-invalid", "type": "obligation", "text": "Seeded obligation of an unknown license",
+				"classification": "green", "active": true, "shortnames": ["seed-unknown-license"]}
+		]}`)
+		assert.Error(t, SeedObligations(invalid, false))
+		obligations, _ := seeded()
+		assert.NotContains(t, obligations, "seed-valid")
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		// The reset is rolled back so the obligations of the other tests are kept
+		defaultDB := db.DB
+		db.DB = defaultDB.Begin()
+		defer func() {
+			db.DB.Rollback()
+			db.DB = defaultDB
+		}()
+
+		var license models.LicenseDB
+		if err := db.DB.Where("rf_shortname = ?", "seed-license").First(&license).Error; err != nil {
+			t.Fatalf("Unable to fetch license: %v", err)
+		}
+		other := models.Obligation{Topic: "seed-reset-other", Type: "obligation", Text: "Obligation text deleted by the reset",
+			TextHash: "seed-reset-other", Active: true}
+		if err := db.DB.Create(&other).Error; err != nil {
+			t.Fatalf("Unable to create obligation: %v", err)
+		}
+		if err := db.DB.Create(&models.ObligationMap{ObligationPk: other.Id, RfPk: license.Id}).Error; err != nil {
+			t.Fatalf("Unable to create obligation map: %v", err)
+		}
+
+		if !assert.NoError(t, SeedObligations(fixture, true)) {
+			return
+		}
+		var topics []string
+		db.DB.Model(&models.Obligation{}).Pluck("topic", &topics)
+		assert.ElementsMatch(t, []string{"seed-mapped", "seed-unmapped"}, topics)
+		var count int64
+		db.DB.Model(&models.ObligationMap{}).Where(models.ObligationMap{ObligationPk: other.Id}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("bundled fixture", func(t *testing.T) {
+		var seed models.ObligationFixture
+		if assert.NoError(t, json.Unmarshal(DefaultObligationFixture, &seed)) {
+			assert.NotEmpty(t, seed.Licenses)
+			assert.NotEmpty(t, seed.Obligations)
+		}
+	})
+}
+
+func TestPublicReadRoutes(t *testing.T) {
+	defaultPublicReadRoutes := PublicReadRoutes
+	defer func() { PublicReadRoutes = defaultPublicReadRoutes }()
+	PublicReadRoutes = func() []string { return []string{"/licenses"} }
+
+	w := makeRequest("GET", "/api/v1/licenses", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/licenses/MIT", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest("GET", "/api/v1/obligations", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/health", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest("GET", "/api/v1/apiCollection", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res models.APICollectionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error unmarshalling JSON: %v", err)
+	}
+	assert.Contains(t, res.Data.UnAuthenticated.Links, "GetLicense")
+	assert.Contains(t, res.Data.Authenticated.Links, "GetObligation")
+
+	PublicReadRoutes = func() []string { return nil }
+	w = makeRequest("GET", "/api/v1/licenses", nil, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = makeRequest("GET", "/api/v1/health", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// openAPISchema is the part of a swagger schema which is checked against the responses.
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Enum       []interface{}             `json:"enum"`
+	Required   []string                  `json:"required"`
+	Items      *openAPISchema            `json:"items"`
+	Properties map[string]*openAPISchema `json:"properties"`
+}
+
+// openAPISpec is the part of the generated swagger spec which declares the responses.
+type openAPISpec struct {
+	Paths map[string]map[string]struct {
+		Responses map[string]struct {
+			Schema *openAPISchema `json:"schema"`
+		} `json:"responses"`
+	} `json:"paths"`
+	Definitions map[string]*openAPISchema `json:"definitions"`
+}
+
+// loadOpenAPISpec loads the swagger spec generated from the annotations of the handlers.
+func loadOpenAPISpec(t *testing.T) openAPISpec {
+	var spec openAPISpec
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec); err != nil {
+		t.Fatalf("Unable to parse the OpenAPI spec: %v", err)
+	}
+	return spec
+}
+
+// assertResponseMatchesSpec checks that the status code of the response is declared for the
+// operation and that its body matches the declared schema.
+func assertResponseMatchesSpec(t *testing.T, spec openAPISpec, method, path string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	responses := spec.Paths[path][strings.ToLower(method)].Responses
+	response, ok := responses[strconv.Itoa(w.Code)]
+	if !ok {
+		t.Errorf("%s %s: status %d is not declared in the spec", method, path, w.Code)
+		return
+	}
+	if response.Schema == nil {
+		assert.Empty(t, w.Body.String(), "%s %s: status %d has no body in the spec", method, path, w.Code)
+		return
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Errorf("%s %s: Error unmarshalling JSON: %v", method, path, err)
+		return
+	}
+	for _, mismatch := range spec.mismatches(response.Schema, body, "body") {
+		t.Errorf("%s %s %d: %s", method, path, w.Code, mismatch)
+	}
+}
+
+// mismatches lists where the value does not match the schema. Fields which are not
+// declared in the schema are reported as well, as they point to a stale spec.
+func (spec openAPISpec) mismatches(schema *openAPISchema, value interface{}, location string) []string {
+	if schema.Ref != "" {
+		definition, ok := spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown definition %s", location, schema.Ref)}
+		}
+		return spec.mismatches(definition, value, location)
+	}
+	// Nil pointers, slices and maps are encoded as null
+	if value == nil {
+		return nil
+	}
+
+	var result []string
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %v", location, value)}
+		}
+		for _, field := range schema.Required {
+			if _, ok := object[field]; !ok {
+				result = append(result, fmt.Sprintf("%s.%s: required field is missing", location, field))
+			}
+		}
+		// Objects without properties, like interface{} fields, can hold anything
+		if schema.Properties == nil {
+			return result
+		}
+		for field, fieldValue := range object {
+			fieldSchema, ok := schema.Properties[field]
+			if !ok {
+				result = append(result, fmt.Sprintf("%s.%s: field is not declared in the spec", location, field))
+				continue
+			}
+			result = append(result, spec.mismatches(fieldSchema, fieldValue, location+"."+field)...)
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %v", location, value)}
+		}
+		for i, item := range array {
+			result = append(result, spec.mismatches(schema.Items, item, fmt.Sprintf("%s[%d]", location, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected a string, got %v", location, value)}
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return []string{fmt.Sprintf("%s: expected an integer, got %v", location, value)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: expected a number, got %v", location, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %v", location, value)}
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, fmt.Sprintf("%s: %v is not one of %v", location, value, schema.Enum))
+		}
+	}
+	return result
+}
+
+func TestResponsesMatchOpenAPISpec(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+
+	obligation := models.Obligation{Topic: "openapi-spec", Type: "risk", Text: "Obligation text checked against the spec",
+		Classification: "yellow", TextHash: "openapi-spec", Active: true}
+	if err := db.DB.Create(&obligation).Error; err != nil {
+		t.Fatalf("Unable to create obligation: %v", err)
+	}
+
+	w := makeRequest("GET", "/api/v1/obligations/openapi-spec", nil, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertResponseMatchesSpec(t, spec, "GET", "/obligations/{topic}", w)
+
+	w = makeRequest("GET", "/api/v1/obligations/openapi-spec-missing", nil, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertResponseMatchesSpec(t, spec, "GET", "/obligations/{topic}", w)
+
+	input := models.ObligationPOSTRequestJSONSchema{
+		Topic:          "openapi-spec-created",
+		Type:           "obligation",
+		Text:           "Obligation text created and checked against the spec",
+		Classification: "green",
+		Modifications:  true,
+		Comment:        "checked against the spec",
+		Shortnames:     []string{"MIT"},
+		Active:         true,
+	}
+	w = makeRequest("POST", "/api/v1/obligations", input, true)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assertResponseMatchesSpec(t, spec, "POST", "/obligations", w)
+
+	w = makeRequest("POST", "/api/v1/obligations", input, true)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertResponseMatchesSpec(t, spec, "POST", "/obligations", w)
+
+	w = makeRequest("POST", "/api/v1/obligations", "not an obligation", true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertResponseMatchesSpec(t, spec, "POST", "/obligations", w)
+
+	input.Topic = ""
+	w = makeRequest("POST", "/api/v1/obligations", input, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assertResponseMatchesSpec(t, spec, "POST", "/obligations", w)
+}
+
+// BenchmarkCreateObligationWithManyShortnames measures creating an obligation
+// mapped to all the licenses in the database.
+func BenchmarkCreateObligationWithManyShortnames(b *testing.B) {
+	var shortnames []string
+	if err := db.DB.Model(&models.LicenseDB{}).Limit(DEFAULT_MAX_OBLIGATION_SHORTNAMES).
+		Pluck("rf_shortname", &shortnames).Error; err != nil {
+		b.Fatalf("Unable to fetch license shortnames: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		obligation := models.ObligationPOSTRequestJSONSchema{
+			Topic:          fmt.Sprintf("benchmark-%d-%d", time.Now().UnixNano(), i),
+			Type:           "obligation",
+			Text:           fmt.Sprintf("Benchmark obligation text %d %d", time.Now().UnixNano(), i),
+			Classification: "green",
+			Modifications:  true,
+			Comment:        "benchmark",
+			Shortnames:     shortnames,
+			Active:         true,
+		}
+		w := makeRequest("POST", "/api/v1/obligations", obligation, true)
+		if w.Code != http.StatusCreated {
+			b.Fatalf("Unexpected status %d: %s", w.Code, w.Body.String())
+		}
+	}
 }
@@ -7,19 +7,27 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fossology/LicenseDb/pkg/db"
 	"github.com/fossology/LicenseDb/pkg/models"
 	"github.com/fossology/LicenseDb/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // GetAllAudit retrieves a list of all audit records from the database
 //
 //	@Summary		Get audit records
-//	@Description	Get all audit records from the server
+//	@Description	Get all audit records from the server. The audits of obligations which are not published
+//	@Description	are only listed to reviewers.
 //	@Id				GetAllAudit
 //	@Tags			Audits
 //	@Accept			json
@@ -34,6 +42,7 @@ func GetAllAudit(c *gin.Context) {
 	var audits []models.Audit
 
 	query := db.DB.Model(&models.Audit{}).Preload("User")
+	filterReadableAudits(c, query, "audits")
 
 	_ = utils.PreparePaginateResponse(c, query, &models.AuditResponse{})
 
@@ -68,7 +77,8 @@ func GetAllAudit(c *gin.Context) {
 // GetAudit retrieves a specific audit record by its ID from the database
 //
 //	@Summary		Get an audit record
-//	@Description	Get a specific audit records by ID
+//	@Description	Get a specific audit records by ID. The audits of obligations which are not published
+//	@Description	are only found by reviewers.
 //	@Id				GetAudit
 //	@Tags			Audits
 //	@Accept			json
@@ -87,7 +97,9 @@ func GetAudit(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Preload("User").Where(&models.Audit{Id: parsedId}).First(&audit).Error; err != nil {
+	query := db.DB.Preload("User")
+	filterReadableAudits(c, query, "audits")
+	if err := query.Where(&models.Audit{Id: parsedId}).First(&audit).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   "no audit with such id exists",
@@ -117,7 +129,8 @@ func GetAudit(c *gin.Context) {
 // GetChangeLogs retrieves a list of change history records associated with a specific audit
 //
 //	@Summary		Get changelogs
-//	@Description	Get changelogs of an audit record
+//	@Description	Get changelogs of an audit record. The changelogs of obligations which are not published
+//	@Description	are only found by reviewers.
 //	@Id				GetChangeLogs
 //	@Tags			Audits
 //	@Accept			json
@@ -137,7 +150,8 @@ func GetChangeLogs(c *gin.Context) {
 		return
 	}
 
-	result := db.DB.Where(models.ChangeLog{AuditId: parsedId}).Find(&changelog)
+	result := db.DB.Where(models.ChangeLog{AuditId: parsedId}).
+		Where("change_logs.audit_id IN (?)", readableAuditIds(c)).Find(&changelog)
 	if result.Error != nil {
 		er := models.LicenseError{
 			Status:    http.StatusInternalServerError,
@@ -176,7 +190,8 @@ func GetChangeLogs(c *gin.Context) {
 // GetChangeLogbyId retrieves a specific change history record by its ID for a given audit.
 //
 //	@Summary		Get a changelog
-//	@Description	Get a specific changelog of an audit record by its ID
+//	@Description	Get a specific changelog of an audit record by its ID. The changelogs of obligations which
+//	@Description	are not published are only found by reviewers.
 //	@Id				GetChangeLogbyId
 //	@Tags			Audits
 //	@Accept			json
@@ -201,7 +216,8 @@ func GetChangeLogbyId(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Where(models.ChangeLog{Id: parsedChangeLogId}).Find(&changelog).Error; err != nil {
+	if err := db.DB.Where(models.ChangeLog{Id: parsedChangeLogId}).
+		Where("change_logs.audit_id IN (?)", readableAuditIds(c)).Find(&changelog).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   "no change history with such id exists",
@@ -233,6 +249,27 @@ func GetChangeLogbyId(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// filterReadableAudits restricts the audits of the query, from the audits or the
+// archived audits table, to the ones the user of the request may read. The audits of
+// obligations which are not published, whose changelogs hold their text, are only
+// read by reviewers.
+func filterReadableAudits(c *gin.Context, query *gorm.DB, table string) {
+	if isObligationReviewer(db.DB.WithContext(c), c.GetString("username")) {
+		return
+	}
+	published := db.DB.Model(&models.Obligation{}).Select("id").
+		Where(models.Obligation{Status: models.OBLIGATION_STATUS_PUBLISHED})
+	query.Where(fmt.Sprintf("LOWER(%[1]s.type) <> ? OR %[1]s.type_id IN (?)", table), "obligation", published)
+}
+
+// readableAuditIds returns the query of the ids of the audits the user of the request
+// may read, see filterReadableAudits.
+func readableAuditIds(c *gin.Context) *gorm.DB {
+	query := db.DB.Model(&models.Audit{}).Select("audits.id")
+	filterReadableAudits(c, query, "audits")
+	return query
+}
+
 // getAuditEntity is an utility function to fetch obligation or license associated with an audit
 func getAuditEntity(c *gin.Context, audit *models.Audit) error {
 	if audit.Type == "license" || audit.Type == "License" {
@@ -264,3 +301,370 @@ func getAuditEntity(c *gin.Context, audit *models.Audit) error {
 	}
 	return nil
 }
+
+// ArchiveAudits moves audits older than a cutoff into the archived audits table
+//
+//	@Summary		Archive old audit records
+//	@Description	Move audits older than the cutoff, along with their change logs, into the archive.
+//	@Description	The cutoff defaults to AUDIT_RETENTION_DAYS days before now. The audits are archived
+//	@Description	in batches, so a failed request may have archived some of them, which it reports.
+//	@Id				ArchiveAudits
+//	@Tags			Audits
+//	@Accept			json
+//	@Produce		json
+//	@Param			before	query		string	false	"Archive audits older than this RFC3339 timestamp"
+//	@Param			dryRun	query		bool	false	"Only count the audits which would be archived"
+//	@Success		200		{object}	models.AuditArchiveResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid query parameter"
+//	@Failure		403		{object}	models.LicenseError	"User is not an admin"
+//	@Failure		500		{object}	models.LicenseError	"Unable to archive audits"
+//	@Security		ApiKeyAuth
+//	@Router			/audits/archive [post]
+func ArchiveAudits(c *gin.Context) {
+	retentionDays, err := strconv.Atoi(os.Getenv("AUDIT_RETENTION_DAYS"))
+	if err != nil || retentionDays <= 0 {
+		retentionDays = DEFAULT_AUDIT_RETENTION_DAYS
+	}
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	if b := c.Query("before"); b != "" {
+		before, err = time.Parse(time.RFC3339, b)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid before value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", b),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	dryRun := false
+	if d := c.Query("dryRun"); d != "" {
+		dryRun, err = strconv.ParseBool(d)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid dryRun value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", d),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	result := models.AuditArchiveResult{
+		Before: before,
+		DryRun: dryRun,
+	}
+
+	if dryRun {
+		if err := db.DB.Model(&models.Audit{}).Where("timestamp < ?", before).Count(&result.Count).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "unable to count audits",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	} else {
+		// The audits are archived in batches, each in its own transaction, so the tables
+		// are not locked for the whole archive. A failed batch leaves the earlier ones
+		// archived, and archiving again continues with the remaining audits.
+		var audits []models.Audit
+		err = db.DB.Preload("ChangeLogs").Where("timestamp < ?", before).
+			FindInBatches(&audits, AUDIT_ARCHIVE_BATCH_SIZE, func(_ *gorm.DB, _ int) error {
+				if err := archiveAuditBatch(audits); err != nil {
+					return err
+				}
+				result.Count += int64(len(audits))
+				return nil
+			}).Error
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "unable to archive audits",
+				Error:     fmt.Sprintf("%d audits were archived before the error: %s", result.Count, err.Error()),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	}
+
+	res := models.AuditArchiveResponse{
+		Status: http.StatusOK,
+		Data:   result,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// archiveAuditBatch moves the audits, along with their change logs, into the archived
+// audits table in a transaction.
+func archiveAuditBatch(audits []models.Audit) error {
+	archivedAt := time.Now()
+	auditIds := make([]int64, 0, len(audits))
+	archivedAudits := make([]models.ArchivedAudit, 0, len(audits))
+	for _, audit := range audits {
+		auditIds = append(auditIds, audit.Id)
+		archivedAudits = append(archivedAudits, models.ArchivedAudit{
+			Id:           audit.Id,
+			UserId:       audit.UserId,
+			Timestamp:    audit.Timestamp,
+			Type:         audit.Type,
+			TypeId:       audit.TypeId,
+			ChangeReason: audit.ChangeReason,
+			Truncated:    audit.Truncated,
+			ChangeLogs:   datatypes.NewJSONType(audit.ChangeLogs),
+			ArchivedAt:   archivedAt,
+		})
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&archivedAudits).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("audit_id IN ?", auditIds).Delete(&models.ChangeLog{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", auditIds).Delete(&models.Audit{}).Error
+	})
+}
+
+// GetArchivedAudits retrieves archived audit records, optionally filtered by entity
+//
+//	@Summary		Get archived audit records
+//	@Description	Get audit records which were moved to the archive. The audits of obligations which are not
+//	@Description	published are only listed to reviewers.
+//	@Id				GetArchivedAudits
+//	@Tags			Audits
+//	@Accept			json
+//	@Produce		json
+//	@Param			type	query		string	false	"Type of the audited entity"	Enums(obligation, license)
+//	@Param			type_id	query		int		false	"Id of the audited entity"
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Success		200		{object}	models.ArchivedAuditResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid type id"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch archived audits"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/audits/archive [get]
+func GetArchivedAudits(c *gin.Context) {
+	var audits []models.ArchivedAudit
+
+	query := db.DB.Model(&models.ArchivedAudit{})
+	filterReadableAudits(c, query, "archived_audits")
+	if auditType := c.Query("type"); auditType != "" {
+		query.Where("LOWER(type) = ?", strings.ToLower(auditType))
+	}
+	if typeId := c.Query("type_id"); typeId != "" {
+		parsedTypeId, err := utils.ParseIdToInt(c, typeId, "type")
+		if err != nil {
+			return
+		}
+		query.Where(models.ArchivedAudit{TypeId: parsedTypeId})
+	}
+
+	_ = utils.PreparePaginateResponse(c, query, &models.ArchivedAuditResponse{})
+
+	if err := query.Order("timestamp desc").Find(&audits).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "unable to fetch archived audits",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ArchivedAuditResponse{
+		Data:   audits,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(audits),
+		},
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetAuditCountsByUser counts the obligation audits of every user
+//
+//	@Summary		Count obligation audits per user
+//	@Description	Count, for every user, the audits of obligations made by the user and give the time of
+//	@Description	the last one, optionally within a range of time. Users without audits in the range are
+//	@Description	listed with a count of 0. The users are listed by their count, the most active first.
+//	@Id				GetAuditCountsByUser
+//	@Tags			Audits
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Only the audits at or after this RFC3339 timestamp"
+//	@Param			to		query		string	false	"Only the audits before this RFC3339 timestamp"
+//	@Success		200		{object}	models.AuditUserActivityResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid from or to value"
+//	@Failure		403		{object}	models.LicenseError	"User is not an admin"
+//	@Failure		500		{object}	models.LicenseError	"Unable to count audits"
+//	@Security		ApiKeyAuth
+//	@Router			/audits/by-user [get]
+func GetAuditCountsByUser(c *gin.Context) {
+	// parseBound parses the RFC3339 timestamp of the query parameter if any, else it
+	// writes the error response and returns false
+	parseBound := func(param string) (*time.Time, bool) {
+		value := c.Query(param)
+		if value == "" {
+			return nil, true
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   fmt.Sprintf("Invalid %s value", param),
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return nil, false
+		}
+		return &t, true
+	}
+	from, ok := parseBound("from")
+	if !ok {
+		return
+	}
+	to, ok := parseBound("to")
+	if !ok {
+		return
+	}
+
+	// filter selects the obligation audits in the range from the audits of the table
+	filter := func(query *gorm.DB, table string) *gorm.DB {
+		query = query.Where(table+".type = ?", "Obligation")
+		if from != nil {
+			query = query.Where(table+".timestamp >= ?", *from)
+		}
+		if to != nil {
+			query = query.Where(table+".timestamp < ?", *to)
+		}
+		return query
+	}
+
+	var users []models.User
+	var counts []struct {
+		UserId int64
+		Audits int64
+	}
+	// The last audits are selected by a subquery rather than by MAX, as sqlite returns
+	// the maximum of a timestamp as a string
+	var lastAudits []struct {
+		UserId    int64
+		Timestamp time.Time
+	}
+	err := db.DB.WithContext(c).Order("username").Find(&users).Error
+	if err == nil {
+		err = filter(db.DB.WithContext(c).Model(&models.Audit{}), "audits").
+			Select("audits.user_id, COUNT(*) AS audits").Group("audits.user_id").Scan(&counts).Error
+	}
+	if err == nil {
+		latest := filter(db.DB.Table("audits AS latest").Select("MAX(latest.timestamp)").
+			Where("latest.user_id = audits.user_id"), "latest")
+		err = filter(db.DB.WithContext(c).Model(&models.Audit{}), "audits").
+			Select("DISTINCT audits.user_id, audits.timestamp").
+			Where("audits.timestamp = (?)", latest).Scan(&lastAudits).Error
+	}
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to count audits",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	auditCounts := make(map[int64]int64, len(counts))
+	for _, count := range counts {
+		auditCounts[count.UserId] = count.Audits
+	}
+	lastActivity := make(map[int64]time.Time, len(lastAudits))
+	for _, lastAudit := range lastAudits {
+		lastActivity[lastAudit.UserId] = lastAudit.Timestamp
+	}
+
+	activities := make([]models.AuditUserActivity, 0, len(users))
+	for _, user := range users {
+		activity := models.AuditUserActivity{
+			UserId:   user.Id,
+			Username: user.Username,
+			Audits:   auditCounts[user.Id],
+		}
+		if timestamp, ok := lastActivity[user.Id]; ok {
+			activity.LastActivityAt = &timestamp
+		}
+		activities = append(activities, activity)
+	}
+	sort.SliceStable(activities, func(i, j int) bool { return activities[i].Audits > activities[j].Audits })
+
+	res := models.AuditUserActivityResponse{
+		Data:   activities,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(activities),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// MaxAuditChangeLogs returns the maximum number of changelogs of an audit. By default it
+// reads MAX_AUDIT_CHANGELOGS, falling back to DEFAULT_MAX_AUDIT_CHANGELOGS, and it can
+// be replaced in tests.
+var MaxAuditChangeLogs = func() int {
+	maxChangeLogs, err := strconv.Atoi(os.Getenv("MAX_AUDIT_CHANGELOGS"))
+	if err != nil || maxChangeLogs <= 0 {
+		return DEFAULT_MAX_AUDIT_CHANGELOGS
+	}
+	return maxChangeLogs
+}
+
+// createAudit creates the audit and inserts its changelogs in batches. If the audit has
+// more changelogs than the maximum, the ones over it are summarized in a single
+// changelog of the field "Truncated" and the audit is flagged as truncated.
+func createAudit(tx *gorm.DB, audit *models.Audit) error {
+	changes := audit.ChangeLogs
+	if maxChangeLogs := MaxAuditChangeLogs(); len(changes) > maxChangeLogs {
+		var fields []string
+		for _, change := range changes[maxChangeLogs-1:] {
+			fields = append(fields, change.Field)
+		}
+		summary := fmt.Sprintf("%d more fields changed: %s", len(fields), strings.Join(fields, ", "))
+		changes = append(changes[:maxChangeLogs-1:maxChangeLogs-1], models.ChangeLog{
+			Field:        "Truncated",
+			UpdatedValue: &summary,
+		})
+		audit.Truncated = true
+	}
+
+	if err := tx.Omit("ChangeLogs").Create(audit).Error; err != nil {
+		return err
+	}
+	for i := range changes {
+		changes[i].AuditId = audit.Id
+	}
+	audit.ChangeLogs = changes
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&audit.ChangeLogs, CHANGELOG_BATCH_SIZE).Error
+}
@@ -0,0 +1,376 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// classificationSeverity orders the classifications to break ties in favour of
+// the more severe one
+var classificationSeverity = map[string]int{
+	"green":  0,
+	"white":  1,
+	"yellow": 2,
+	"red":    3,
+}
+
+// ClassifyObligation suggests a classification for an obligation text
+//
+//	@Summary		Suggest a classification
+//	@Description	Suggest a classification for an obligation text based on the classification rules.
+//	@Description	Each rule whose keyword appears in the text adds its weight to its classification, and
+//	@Description	the classification with the highest score is suggested. Nothing is persisted.
+//	@Id				ClassifyObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			obligation	body		models.ObligationClassifyInput	true	"Obligation text"
+//	@Success		200			{object}	models.ObligationClassificationResponse
+//	@Failure		400			{object}	models.ValidationError	"Invalid request body"
+//	@Failure		500			{object}	models.LicenseError		"Unable to fetch classification rules"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/classify [post]
+func ClassifyObligation(c *gin.Context) {
+	var input models.ObligationClassifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var rules []models.ClassificationRule
+	if err := db.DB.Order("keyword").Find(&rules).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch classification rules",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationClassificationResponse{
+		Data:   suggestClassification(input.Text, rules),
+		Status: http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// suggestClassification scores the classifications by the weights of the rules
+// whose keyword appears in the text, case insensitively
+func suggestClassification(text string, rules []models.ClassificationRule) models.ObligationClassification {
+	suggestion := models.ObligationClassification{
+		MatchedKeywords: []string{},
+		Scores:          map[string]int{},
+	}
+	lowerText := strings.ToLower(text)
+	for _, rule := range rules {
+		if !strings.Contains(lowerText, strings.ToLower(rule.Keyword)) {
+			continue
+		}
+		suggestion.MatchedKeywords = append(suggestion.MatchedKeywords, rule.Keyword)
+		suggestion.Scores[rule.Classification] += rule.Weight
+	}
+
+	best := -1
+	for classification, score := range suggestion.Scores {
+		if score > best || (score == best &&
+			classificationSeverity[classification] > classificationSeverity[suggestion.Classification]) {
+			best = score
+			suggestion.Classification = classification
+		}
+	}
+	return suggestion
+}
+
+// GetClassificationRules retrieves all the classification rules
+//
+//	@Summary		Get classification rules
+//	@Description	Get the keyword rules used to suggest obligation classifications
+//	@Id				GetClassificationRules
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.ClassificationRuleResponse
+//	@Failure		500	{object}	models.LicenseError	"Unable to fetch classification rules"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/classification_rules [get]
+func GetClassificationRules(c *gin.Context) {
+	var rules []models.ClassificationRule
+	if err := db.DB.Order("keyword").Find(&rules).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch classification rules",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ClassificationRuleResponse{
+		Data:   rules,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(rules),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateClassificationRule creates a new classification rule
+//
+//	@Summary		Create a classification rule
+//	@Description	Create a keyword rule used to suggest obligation classifications. Only for admins.
+//	@Id				CreateClassificationRule
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			rule	body		models.ClassificationRule	true	"Rule to create"
+//	@Success		201		{object}	models.ClassificationRuleResponse
+//	@Failure		400		{object}	models.ValidationError	"Invalid request body"
+//	@Failure		403		{object}	models.LicenseError		"User is not an admin"
+//	@Failure		409		{object}	models.LicenseError		"Rule with same keyword exists"
+//	@Failure		500		{object}	models.LicenseError		"Unable to create classification rule"
+//	@Security		ApiKeyAuth
+//	@Router			/classification_rules [post]
+func CreateClassificationRule(c *gin.Context) {
+	var rule models.ClassificationRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	rule.Id = 0
+	rule.Keyword = strings.TrimSpace(rule.Keyword)
+	if rule.Weight == 0 {
+		rule.Weight = 1
+	}
+
+	result := db.DB.Where(models.ClassificationRule{Keyword: rule.Keyword}).FirstOrCreate(&rule)
+	if result.Error != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to create classification rule",
+			Error:     result.Error.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	} else if result.RowsAffected == 0 {
+		er := models.LicenseError{
+			Status:    http.StatusConflict,
+			Message:   "can not create classification rule with same keyword",
+			Error:     fmt.Sprintf("Error: Classification rule with keyword '%s' already exists", rule.Keyword),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusConflict, er)
+		return
+	}
+
+	res := models.ClassificationRuleResponse{
+		Data:   []models.ClassificationRule{rule},
+		Status: http.StatusCreated,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// DeleteClassificationRule deletes a classification rule
+//
+//	@Summary		Delete a classification rule
+//	@Description	Delete a keyword rule used to suggest obligation classifications. Only for admins.
+//	@Id				DeleteClassificationRule
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	int	true	"Id of the rule"
+//	@Success		204
+//	@Failure		400	{object}	models.LicenseError	"Invalid rule id"
+//	@Failure		403	{object}	models.LicenseError	"User is not an admin"
+//	@Failure		404	{object}	models.LicenseError	"No rule with given id found"
+//	@Security		ApiKeyAuth
+//	@Router			/classification_rules/{id} [delete]
+func DeleteClassificationRule(c *gin.Context) {
+	parsedId, err := utils.ParseIdToInt(c, c.Param("id"), "classification rule")
+	if err != nil {
+		return
+	}
+
+	result := db.DB.Delete(&models.ClassificationRule{Id: parsedId})
+	if result.Error != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to delete classification rule",
+			Error:     result.Error.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	} else if result.RowsAffected == 0 {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   "no classification rule with such id exists",
+			Error:     fmt.Sprintf("classification rule with id %d not found", parsedId),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReclassifyObligations sets the classification of all the obligations matching a filter
+//
+//	@Summary		Reclassify obligations
+//	@Description	Set the classification of all the obligations matching the filter in one transaction,
+//	@Description	e.g. all red obligations without modifications to yellow. An audit is written for every
+//	@Description	reclassified obligation. With dryRun, only list the obligations which would be reclassified.
+//	@Id				ReclassifyObligations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			reclassify	body		models.ObligationReclassifyInput	true	"Filter of the obligations and their new classification"
+//	@Param			dryRun		query		bool								false	"Only list the obligations which would be reclassified"
+//	@Success		200			{object}	models.ObligationReclassifyResponse
+//	@Failure		400			{object}	models.ValidationError	"Invalid request body, classification or dryRun value"
+//	@Failure		403			{object}	models.LicenseError		"User is not an admin"
+//	@Failure		500			{object}	models.LicenseError		"Unable to reclassify obligations"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/reclassify [post]
+func ReclassifyObligations(c *gin.Context) {
+	var input models.ObligationReclassifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	dryRun := false
+	if d := c.Query("dryRun"); d != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(d)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid dryRun value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", d),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	changeReason := input.ChangeReason
+	if changeReason == "" {
+		changeReason = "Reclassified"
+	}
+	result := models.ObligationReclassifyResult{
+		Classification: input.Classification,
+		Topics:         []string{},
+		DryRun:         dryRun,
+	}
+
+	err := db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Obligations which already have the classification are left untouched
+		query := tx.Model(&models.Obligation{}).Where("classification <> ?", input.Classification)
+		if input.Filter.Classification != "" {
+			query.Where(models.Obligation{Classification: input.Filter.Classification})
+		}
+		if input.Filter.Modifications != nil {
+			query.Where("modifications = ?", *input.Filter.Modifications)
+		}
+		if input.Filter.Type != "" {
+			query.Where(models.Obligation{Type: input.Filter.Type})
+		}
+		if input.Filter.Active != nil {
+			filterActiveObligations(query, *input.Filter.Active)
+		}
+		var obligations []models.Obligation
+		if err := query.Order("topic").Find(&obligations).Error; err != nil {
+			return err
+		}
+
+		for _, oldObligation := range obligations {
+			result.Topics = append(result.Topics, oldObligation.Topic)
+			if dryRun {
+				continue
+			}
+			newObligation := oldObligation
+			newObligation.Classification = input.Classification
+			if err := tx.Model(&newObligation).Update("classification", input.Classification).Error; err != nil {
+				return err
+			}
+			if err := addChangelogsForObligationUpdate(tx, c.GetString("username"), &newObligation,
+				&oldObligation, changeReason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to reclassify obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	result.Count = len(result.Topics)
+	res := models.ObligationReclassifyResponse{
+		Status: http.StatusOK,
+		Data:   result,
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// ResolveLicenseExpression resolves the obligations of the licenses of an SPDX license expression
+//
+//	@Summary		Resolve the obligations of an SPDX license expression
+//	@Description	Parse an SPDX license expression of license shortnames combined with AND, OR and
+//	@Description	parentheses, and resolve the active obligations of each license. The expression is
+//	@Description	returned with the obligations of each license, along with the combined obligations of
+//	@Description	all the licenses of the expression.
+//	@Id				ResolveLicenseExpression
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			expression	body		models.LicenseExpressionInput	true	"SPDX license expression"
+//	@Success		200			{object}	models.ResolvedLicenseExpressionResponse
+//	@Failure		400			{object}	models.ValidationError	"Bad request body or invalid expression"
+//	@Failure		500			{object}	models.LicenseError		"Failed to fetch obligations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/resolve-expression [post]
+func ResolveLicenseExpression(c *gin.Context) {
+	var input models.LicenseExpressionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	expression, err := parseLicenseExpression(input.Expression)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid license expression",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	shortnames := expressionLicenses(&expression, nil)
+	obligations, shortnamesByObligation, err := fetchLicenseObligations(db.DB.WithContext(c), shortnames)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Failed to fetch obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	var knownShortnames []string
+	if err := db.DB.WithContext(c).Model(&models.LicenseDB{}).Where("rf_shortname IN ?", shortnames).
+		Pluck("rf_shortname", &knownShortnames).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Failed to fetch licenses",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	topicsByShortname := make(map[string][]string)
+	resolved := models.ResolvedLicenseExpression{
+		Obligations:     []models.LicenseObligationExport{},
+		UnknownLicenses: []string{},
+	}
+	for _, obligation := range obligations {
+		for _, shortname := range shortnamesByObligation[obligation.Id] {
+			topicsByShortname[shortname] = append(topicsByShortname[shortname], obligation.Topic)
+		}
+		resolved.Obligations = append(resolved.Obligations,
+			licenseObligationExport(obligation, shortnamesByObligation[obligation.Id]))
+	}
+	for _, shortname := range shortnames {
+		if !slices.Contains(knownShortnames, shortname) {
+			resolved.UnknownLicenses = append(resolved.UnknownLicenses, shortname)
+		}
+	}
+	annotateLicenseExpression(&expression, topicsByShortname)
+	resolved.Expression = expression
+
+	res := models.ResolvedLicenseExpressionResponse{
+		Status: http.StatusOK,
+		Data:   resolved,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// parseLicenseExpression parses an SPDX license expression of licenses combined
+// with AND, OR and parentheses, where AND binds tighter than OR. Operators are
+// case-insensitive. Chains of the same operator are flattened into one node.
+func parseLicenseExpression(expression string) (models.LicenseExpressionNode, error) {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	if len(tokens) == 0 {
+		return models.LicenseExpressionNode{}, errors.New("expression is empty")
+	}
+	parser := licenseExpressionParser{tokens: tokens}
+	node, err := parser.parseOperation("OR")
+	if err != nil {
+		return node, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return node, fmt.Errorf("unexpected '%s'", parser.tokens[parser.pos])
+	}
+	return node, nil
+}
+
+// licenseExpressionParser is a recursive descent parser of license expressions
+type licenseExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseOperation parses the operands joined by the operator, where the operands
+// of OR are AND operations.
+func (p *licenseExpressionParser) parseOperation(operator string) (models.LicenseExpressionNode, error) {
+	parseOperand := p.parseOperand
+	if operator == "OR" {
+		parseOperand = func() (models.LicenseExpressionNode, error) {
+			return p.parseOperation("AND")
+		}
+	}
+
+	node, err := parseOperand()
+	if err != nil || p.pos >= len(p.tokens) || !strings.EqualFold(p.tokens[p.pos], operator) {
+		return node, err
+	}
+
+	operation := models.LicenseExpressionNode{Operator: operator}
+	for {
+		if node.Operator == operator {
+			operation.Children = append(operation.Children, node.Children...)
+		} else {
+			operation.Children = append(operation.Children, node)
+		}
+		if p.pos >= len(p.tokens) || !strings.EqualFold(p.tokens[p.pos], operator) {
+			return operation, nil
+		}
+		p.pos++
+		if node, err = parseOperand(); err != nil {
+			return node, err
+		}
+	}
+}
+
+// parseOperand parses a license or an expression in parentheses
+func (p *licenseExpressionParser) parseOperand() (models.LicenseExpressionNode, error) {
+	if p.pos >= len(p.tokens) {
+		return models.LicenseExpressionNode{}, errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token == "(":
+		node, err := p.parseOperation("OR")
+		if err != nil {
+			return node, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return node, errors.New("missing ')'")
+		}
+		p.pos++
+		return node, nil
+	case token == ")" || strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR"):
+		return models.LicenseExpressionNode{}, fmt.Errorf("unexpected '%s'", token)
+	case strings.EqualFold(token, "WITH"):
+		return models.LicenseExpressionNode{}, errors.New("license exceptions are not supported")
+	}
+	return models.LicenseExpressionNode{License: token}, nil
+}
+
+// expressionLicenses appends the distinct licenses of the expression to licenses
+func expressionLicenses(node *models.LicenseExpressionNode, licenses []string) []string {
+	if node.License != "" {
+		if slices.Contains(licenses, node.License) {
+			return licenses
+		}
+		return append(licenses, node.License)
+	}
+	for i := range node.Children {
+		licenses = expressionLicenses(&node.Children[i], licenses)
+	}
+	return licenses
+}
+
+// annotateLicenseExpression sets the obligations of the licenses of the expression
+func annotateLicenseExpression(node *models.LicenseExpressionNode, topicsByShortname map[string][]string) {
+	if node.License != "" {
+		node.Obligations = topicsByShortname[node.License]
+		return
+	}
+	for i := range node.Children {
+		annotateLicenseExpression(&node.Children[i], topicsByShortname)
+	}
+}
@@ -0,0 +1,368 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+)
+
+// OBLIGATION_CHANGES_FEED_SIZE is the number of latest changes in the feed
+const OBLIGATION_CHANGES_FEED_SIZE = 50
+
+// GetObligationChangesFeed gives the latest changes of obligations as an Atom feed
+//
+//	@Summary		Get the changes of obligations as an Atom feed
+//	@Description	Get the latest changes of the published obligations as an Atom feed to follow in a
+//	@Description	feed reader. The updates and deactivations are taken from the audits, the creations from
+//	@Description	the creation dates of the obligations. Each entry is titled by the topic of the obligation
+//	@Description	and summarizes the changed fields.
+//	@Id				GetObligationChangesFeed
+//	@Tags			Obligations
+//	@Produce		application/atom+xml
+//	@Param			since			query		string				false	"Only the changes after this RFC3339 timestamp"
+//	@Param			classification	query		string				false	"Only the changes of obligations of this classification"	Enums(green, white, yellow, red)
+//	@Success		200				{string}	string				"Atom feed"
+//	@Failure		400				{object}	models.LicenseError	"Invalid since or classification value"
+//	@Failure		500				{object}	models.LicenseError	"Unable to fetch changes"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/changes.atom [get]
+func GetObligationChangesFeed(c *gin.Context) {
+	writeObligationChangesFeed(c, "urn:licensedb:obligations:changes", "LicenseDB obligation changes",
+		path.Dir(c.Request.URL.Path), 0)
+}
+
+// writeObligationChangesFeed writes the Atom feed of the latest changes of the published
+// obligations, of those watched by the user of the id watchedBy if it is not 0. The
+// entries link to the obligations under obligationsPath.
+func writeObligationChangesFeed(c *gin.Context, id, title, obligationsPath string, watchedBy int64) {
+	var since time.Time
+	if s := c.Query("since"); s != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, s); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid since value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", s),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	classification := c.Query("classification")
+	if _, ok := classificationSeverity[classification]; classification != "" && !ok {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid classification value",
+			Error:     fmt.Sprintf("classification must be one of green, white, yellow or red, got '%s'", classification),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	entries, err := obligationChangeEntries(c, since, classification, obligationsPath, watchedBy)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch changes",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	feed := models.AtomFeed{
+		Id:      id,
+		Title:   title,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Link:    models.AtomLink{Href: c.Request.URL.String(), Rel: "self"},
+		Entries: entries,
+	}
+	if len(entries) != 0 {
+		feed.Updated = entries[0].Updated
+	}
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch changes",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// obligationChangeEntries returns the latest changes of the published obligations after
+// since, of the given classification if any and watched by the user of the id watchedBy
+// if it is not 0, latest first. Obligations are not audited on creation, so the
+// creations are taken from their creation dates.
+func obligationChangeEntries(c *gin.Context, since time.Time, classification, obligationsPath string,
+	watchedBy int64) ([]models.AtomEntry, error) {
+	watched := db.DB.Model(&models.ObligationWatch{}).Select("obligation_pk").Where("user_id = ?", watchedBy)
+	entryOf := func(id, topic, author, summary string, updated time.Time) models.AtomEntry {
+		return models.AtomEntry{
+			Id:      id,
+			Title:   topic,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Author:  models.AtomAuthor{Name: author},
+			Link:    models.AtomLink{Href: path.Join(obligationsPath, topic), Rel: "alternate"},
+			Summary: summary,
+		}
+	}
+
+	var audits []models.Audit
+	query := db.DB.WithContext(c).Model(&models.Audit{}).
+		Joins("JOIN obligations ON obligations.id = audits.type_id").
+		Where("audits.type = ? AND obligations.status = ?", "Obligation", models.OBLIGATION_STATUS_PUBLISHED)
+	if !since.IsZero() {
+		query.Where("audits.timestamp > ?", since)
+	}
+	if classification != "" {
+		query.Where("obligations.classification = ?", classification)
+	}
+	if watchedBy != 0 {
+		query.Where("audits.type_id IN (?)", watched)
+	}
+	if err := query.Preload("User").Preload("ChangeLogs").Order("audits.timestamp DESC").
+		Limit(OBLIGATION_CHANGES_FEED_SIZE).Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	var obligations []models.Obligation
+	query = db.DB.WithContext(c).Model(&models.Obligation{}).
+		Where(models.Obligation{Status: models.OBLIGATION_STATUS_PUBLISHED})
+	if !since.IsZero() {
+		query.Where("created_at > ?", since)
+	}
+	if classification != "" {
+		query.Where(models.Obligation{Classification: classification})
+	}
+	if watchedBy != 0 {
+		query.Where("id IN (?)", watched)
+	}
+	if err := query.Order("created_at DESC").Limit(OBLIGATION_CHANGES_FEED_SIZE).Find(&obligations).Error; err != nil {
+		return nil, err
+	}
+
+	var auditedIds []int64
+	for _, audit := range audits {
+		if !slices.Contains(auditedIds, audit.TypeId) {
+			auditedIds = append(auditedIds, audit.TypeId)
+		}
+	}
+	topics := make(map[int64]string)
+	if len(auditedIds) != 0 {
+		var audited []models.Obligation
+		if err := db.DB.WithContext(c).Select("id", "topic").Where("id IN ?", auditedIds).
+			Find(&audited).Error; err != nil {
+			return nil, err
+		}
+		for _, obligation := range audited {
+			topics[obligation.Id] = obligation.Topic
+		}
+	}
+
+	type change struct {
+		at    time.Time
+		entry models.AtomEntry
+	}
+	changes := make([]change, 0, len(audits)+len(obligations))
+	for _, audit := range audits {
+		changes = append(changes, change{audit.Timestamp, entryOf(fmt.Sprintf("urn:licensedb:audit:%d", audit.Id),
+			topics[audit.TypeId], audit.User.Username, auditSummary(&audit), audit.Timestamp)})
+	}
+	for _, obligation := range obligations {
+		changes = append(changes, change{obligation.CreatedAt, entryOf(
+			fmt.Sprintf("urn:licensedb:obligation:%d:created", obligation.Id),
+			obligation.Topic, "LicenseDB", "Created", obligation.CreatedAt)})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.After(changes[j].at) })
+	if len(changes) > OBLIGATION_CHANGES_FEED_SIZE {
+		changes = changes[:OBLIGATION_CHANGES_FEED_SIZE]
+	}
+
+	entries := make([]models.AtomEntry, 0, len(changes))
+	for _, ch := range changes {
+		entries = append(entries, ch.entry)
+	}
+	return entries, nil
+}
+
+// auditSummary summarizes the changed fields of an audit of an obligation, along
+// with the reason of the change if any.
+func auditSummary(audit *models.Audit) string {
+	var fields []string
+	deactivated := false
+	for _, change := range audit.ChangeLogs {
+		if change.Field == "Active" && change.UpdatedValue != nil && *change.UpdatedValue == "false" {
+			deactivated = true
+		}
+		fields = append(fields, change.Field)
+	}
+	summary := "Changed " + strings.Join(fields, ", ")
+	if deactivated {
+		summary = "Deactivated"
+	}
+	if audit.ChangeReason != "" {
+		summary += ": " + audit.ChangeReason
+	}
+	return summary
+}
+
+// GetRecentObligations lists the obligations changed within a window
+//
+//	@Summary		Get recently changed obligations
+//	@Description	Get the published obligations changed since the given time, by their audits, with the
+//	@Description	time of their last change and the fields changed since then. Each obligation is listed
+//	@Description	once, the last changed first. since is either a duration before now, in days like 7d or
+//	@Description	as 12h or 30m, or an RFC3339 timestamp.
+//	@Id				GetRecentObligations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			since	query		string	false	"Duration before now or RFC3339 timestamp"	default(7d)
+//	@Success		200		{object}	models.ObligationRecentChangesResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid since value"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch changes"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/recent [get]
+func GetRecentObligations(c *gin.Context) {
+	s := c.DefaultQuery("since", DEFAULT_RECENT_OBLIGATIONS_SINCE)
+	since, err := parseSince(s, time.Now())
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid since value",
+			Error:     fmt.Sprintf("Parsing failed for value '%s'", s),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	changes, err := recentObligationChanges(c, since)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch changes",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationRecentChangesResponse{
+		Data:   changes,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(changes),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// parseSince parses a duration before now, in days like 7d or as accepted by
+// time.ParseDuration, or else an RFC3339 timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(s); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// recentObligationChanges returns the published obligations audited after since, the
+// last changed first, with the fields changed by their audits after since.
+func recentObligationChanges(c *gin.Context, since time.Time) ([]models.ObligationRecentChange, error) {
+	// The audits are aggregated here rather than in the query, as sqlite returns the
+	// maximum of a timestamp as a string
+	var rows []struct {
+		AuditId   int64
+		TypeId    int64
+		Timestamp time.Time
+		Field     *string
+	}
+	if err := db.DB.WithContext(c).Model(&models.Audit{}).
+		Select("audits.id AS audit_id, audits.type_id, audits.timestamp, change_logs.field").
+		Joins("LEFT JOIN change_logs ON change_logs.audit_id = audits.id").
+		Where("audits.type = ? AND audits.timestamp > ?", "Obligation", since).
+		Order("audits.timestamp DESC").Order("audits.id DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var obligationIds []int64
+	changesById := make(map[int64]*models.ObligationRecentChange)
+	auditIds := make(map[int64]bool)
+	for _, row := range rows {
+		change, ok := changesById[row.TypeId]
+		if !ok {
+			obligationIds = append(obligationIds, row.TypeId)
+			change = &models.ObligationRecentChange{LastChangedAt: row.Timestamp, ChangedFields: []string{}}
+			changesById[row.TypeId] = change
+		}
+		if !auditIds[row.AuditId] {
+			auditIds[row.AuditId] = true
+			change.Audits++
+		}
+		if row.Field != nil && !slices.Contains(change.ChangedFields, *row.Field) {
+			change.ChangedFields = append(change.ChangedFields, *row.Field)
+		}
+	}
+
+	changes := []models.ObligationRecentChange{}
+	if len(obligationIds) == 0 {
+		return changes, nil
+	}
+	var obligations []models.Obligation
+	if err := db.DB.WithContext(c).Where("id IN ?", obligationIds).
+		Where(models.Obligation{Status: models.OBLIGATION_STATUS_PUBLISHED}).Find(&obligations).Error; err != nil {
+		return nil, err
+	}
+	obligationsById := make(map[int64]models.Obligation, len(obligations))
+	for _, obligation := range obligations {
+		obligationsById[obligation.Id] = obligation
+	}
+	for _, id := range obligationIds {
+		obligation, ok := obligationsById[id]
+		if !ok {
+			continue
+		}
+		change := changesById[id]
+		change.Obligation = obligation
+		sort.Strings(change.ChangedFields)
+		changes = append(changes, *change)
+	}
+	return changes, nil
+}
@@ -46,6 +46,7 @@ import (
 //	@Param			osiapproved		query		bool					false	"OSI Approved flag status of license"
 //	@Param			fsffree			query		bool					false	"FSF Free flag status of license"
 //	@Param			copyleft		query		bool					false	"Copyleft flag status of license"
+//	@Param			autoCreated		query		bool					false	"Stub licenses auto created with obligations"
 //	@Param			page			query		int						false	"Page number"
 //	@Param			limit			query		int						false	"Limit of responses per page"
 //	@Param			externalRef		query		string					false	"External reference parameters"
@@ -65,6 +66,7 @@ func FilterLicense(c *gin.Context) {
 	OSIapproved := c.Query("osiapproved")
 	fsffree := c.Query("fsffree")
 	copyleft := c.Query("copyleft")
+	autoCreated := c.Query("autoCreated")
 	externalRef := c.Query("externalRef")
 
 	externalRefData := make(map[string]string)
@@ -163,6 +165,14 @@ func FilterLicense(c *gin.Context) {
 		query = query.Where(models.LicenseDB{Marydone: &parsedMarydone})
 	}
 
+	if autoCreated != "" {
+		parsedAutoCreated, err := strconv.ParseBool(autoCreated)
+		if err != nil {
+			parsedAutoCreated = false
+		}
+		query = query.Where(models.LicenseDB{AutoCreated: &parsedAutoCreated})
+	}
+
 	for externalRefKey, externalRefValue := range externalRefData {
 		query = query.Where(fmt.Sprintf("external_ref->>'%s' = ?", externalRefKey), externalRefValue)
 	}
@@ -425,7 +435,11 @@ func UpdateLicense(c *gin.Context) {
 		}
 
 		// Overwrite values of existing keys, add new key value pairs and remove keys with null values.
-		if err := tx.Model(&models.LicenseDB{}).Where(models.LicenseDB{Id: oldLicense.Id}).UpdateColumn("external_ref", gorm.Expr("jsonb_strip_nulls(COALESCE(external_ref, '{}'::jsonb) || ?)", externalRefsPayload.ExternalRef)).Error; err != nil {
+		mergeExpr, err := utils.MergeJSONExpr(tx, "external_ref", externalRefsPayload.ExternalRef)
+		if err == nil {
+			err = tx.Model(&models.LicenseDB{}).Where(models.LicenseDB{Id: oldLicense.Id}).UpdateColumn("external_ref", mergeExpr).Error
+		}
+		if err != nil {
 			er := models.LicenseError{
 				Status:    http.StatusInternalServerError,
 				Message:   "Failed to update license",
@@ -642,6 +656,15 @@ func addChangelogsForLicenseUpdate(tx *gorm.DB, username string,
 			UpdatedValue: &newVal,
 		})
 	}
+	if *oldLicense.AutoCreated != *newLicense.AutoCreated {
+		oldVal := strconv.FormatBool(*oldLicense.AutoCreated)
+		newVal := strconv.FormatBool(*newLicense.AutoCreated)
+		changes = append(changes, models.ChangeLog{
+			Field:        "AutoCreated",
+			OldValue:     &oldVal,
+			UpdatedValue: &newVal,
+		})
+	}
 
 	oldLicenseExternalRef := oldLicense.ExternalRef.Data()
 	oldExternalRefVal := reflect.ValueOf(oldLicenseExternalRef)
@@ -730,7 +753,7 @@ func addChangelogsForLicenseUpdate(tx *gorm.DB, username string,
 			ChangeLogs: changes,
 		}
 
-		if err := tx.Create(&audit).Error; err != nil {
+		if err := createAudit(tx, &audit); err != nil {
 			return err
 		}
 	}
@@ -785,10 +808,22 @@ func SearchInLicense(c *gin.Context) {
 	}
 
 	if input.Search == "fuzzy" {
-		query = query.Where(fmt.Sprintf("%s ILIKE ?", input.Field),
+		likeOperator := "LIKE"
+		if db.IsPostgres() {
+			likeOperator = "ILIKE"
+		}
+		query = query.Where(fmt.Sprintf("%s %s ?", input.Field, likeOperator),
 			fmt.Sprintf("%%%s%%", input.SearchTerm))
 	} else if input.Search == "" || input.Search == "full_text_search" {
-		query = query.Where(input.Field+" @@ plainto_tsquery(?)", input.SearchTerm)
+		if db.IsPostgres() {
+			query = query.Where(input.Field+" @@ plainto_tsquery(?)", input.SearchTerm)
+		} else {
+			// Portable fallback matching all the words of the search term
+			for _, word := range strings.Fields(input.SearchTerm) {
+				query = query.Where(fmt.Sprintf("LOWER(%s) LIKE ?", input.Field),
+					fmt.Sprintf("%%%s%%", strings.ToLower(word)))
+			}
+		}
 	} else {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// GetObligationLint flags suspicious obligations for review
+//
+//	@Summary		Lint obligations
+//	@Description	Flag suspicious obligations for reviewers to fix: the topic is the md5 or the hash of the
+//	@Description	text, the classification is empty, the text is shorter than minTextLength, the obligation
+//	@Description	is not mapped to any license, or it is inactive but mapped to active licenses. The
+//	@Description	topics are listed by the check flagging them.
+//	@Id				GetObligationLint
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			minTextLength	query		int		false	"Texts shorter than this many characters are flagged"	default(20)
+//	@Param			status			query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200				{object}	models.ObligationLintResponse
+//	@Failure		400				{object}	models.LicenseError	"Invalid minTextLength or status value"
+//	@Failure		401				{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403				{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		500				{object}	models.LicenseError	"Unable to fetch obligations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/lint [get]
+func GetObligationLint(c *gin.Context) {
+	minTextLength := DEFAULT_LINT_MIN_TEXT_LENGTH
+	if m := c.Query("minTextLength"); m != "" {
+		var err error
+		if minTextLength, err = strconv.Atoi(m); err != nil || minTextLength < 0 {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid minTextLength value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", m),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	var rows []struct {
+		Topic          string
+		Text           string
+		Classification string
+		Active         bool
+		TextHash       string
+		Maps           int
+		ActiveLicenses int
+	}
+	query := db.DB.WithContext(c).Model(&models.Obligation{})
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.
+		Select("obligations.topic, obligations.text, obligations.classification, obligations.active, "+
+			"obligations.md5 AS text_hash, COUNT(obligation_maps.om_pk) AS maps, "+
+			"SUM(CASE WHEN license_dbs.rf_active = ? THEN 1 ELSE 0 END) AS active_licenses", true).
+		Joins("LEFT JOIN obligation_maps ON obligation_maps.obligation_pk = obligations.id").
+		Joins("LEFT JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk").
+		Group("obligations.id, obligations.topic, obligations.text, obligations.classification, " +
+			"obligations.active, obligations.md5").
+		Order("obligations.topic").
+		Scan(&rows).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	lint := models.ObligationLint{
+		TopicIsTextHash:                models.ObligationLintCategory{Topics: []string{}},
+		EmptyClassification:            models.ObligationLintCategory{Topics: []string{}},
+		ShortText:                      models.ObligationLintCategory{Topics: []string{}},
+		Unmapped:                       models.ObligationLintCategory{Topics: []string{}},
+		InactiveMappedToActiveLicenses: models.ObligationLintCategory{Topics: []string{}},
+	}
+	flag := func(category *models.ObligationLintCategory, topic string) {
+		category.Count++
+		category.Topics = append(category.Topics, topic)
+	}
+	for _, row := range rows {
+		if topicIsTextHash(row.Topic, row.Text, row.TextHash) {
+			flag(&lint.TopicIsTextHash, row.Topic)
+		}
+		if strings.TrimSpace(row.Classification) == "" {
+			flag(&lint.EmptyClassification, row.Topic)
+		}
+		if utf8.RuneCountInString(strings.TrimSpace(row.Text)) < minTextLength {
+			flag(&lint.ShortText, row.Topic)
+		}
+		if row.Maps == 0 {
+			flag(&lint.Unmapped, row.Topic)
+		}
+		if !row.Active && row.ActiveLicenses > 0 {
+			flag(&lint.InactiveMappedToActiveLicenses, row.Topic)
+		}
+	}
+
+	res := models.ObligationLintResponse{
+		Status: http.StatusOK,
+		Data:   lint,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// topicIsTextHash reports whether the topic is the md5 of the text or of its normalized
+// form, as set by faulty imports, or the stored hash of the text.
+func topicIsTextHash(topic, text, textHash string) bool {
+	topic = strings.ToLower(strings.TrimSpace(topic))
+	md5Hash := md5.Sum([]byte(text))
+	normalizedMd5Hash := md5.Sum([]byte(utils.NormalizeObligationText(text)))
+	return topic == hex.EncodeToString(md5Hash[:]) || topic == hex.EncodeToString(normalizedMd5Hash[:]) ||
+		(textHash != "" && topic == strings.ToLower(textHash))
+}
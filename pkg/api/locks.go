@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+)
+
+// errObligationLocked is returned when an obligation is locked by another user
+var errObligationLocked = errors.New("obligation is locked by another user")
+
+// ObligationLockTTL returns how long a lock of an obligation is held before it expires.
+// By default it reads OBLIGATION_LOCK_TTL as a duration like 30m, falling back to
+// DEFAULT_OBLIGATION_LOCK_TTL, and it can be replaced in tests.
+var ObligationLockTTL = func() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("OBLIGATION_LOCK_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl, _ = time.ParseDuration(DEFAULT_OBLIGATION_LOCK_TTL)
+	}
+	return ttl
+}
+
+// findObligationLock returns the unexpired lock of the obligation with the given id
+// along with its holder, or nil if the obligation is not locked.
+func findObligationLock(tx *gorm.DB, obligationPk int64) (*models.ObligationLock, error) {
+	var lock models.ObligationLock
+	err := tx.Preload("User").Where(models.ObligationLock{ObligationPk: obligationPk}).
+		Where("expires_at > ?", time.Now()).First(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// obligationLockedByOther returns the unexpired lock of the obligation with the given id
+// if it is held by another user than the one with the given username, else nil.
+func obligationLockedByOther(tx *gorm.DB, obligationPk int64, username string) (*models.ObligationLock, error) {
+	lock, err := findObligationLock(tx, obligationPk)
+	if err != nil || lock == nil || lock.User.Username == username {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// obligationLockedMessage describes the lock of the obligation with the given topic.
+func obligationLockedMessage(topic string, lock *models.ObligationLock) string {
+	return fmt.Sprintf("obligation with topic '%s' is locked by '%s' until %s", topic,
+		lock.User.Username, lock.ExpiresAt.Format(time.RFC3339))
+}
+
+// obligationLockedError writes the error response for an update of an obligation which
+// is locked by another user.
+func obligationLockedError(c *gin.Context, topic string, lock *models.ObligationLock) {
+	er := models.LicenseError{
+		Status:    http.StatusLocked,
+		Message:   obligationLockedMessage(topic, lock),
+		Error:     errObligationLocked.Error(),
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.JSON(http.StatusLocked, er)
+}
+
+// LockObligation locks an obligation for the user
+//
+//	@Summary		Lock an obligation
+//	@Description	Lock an obligation under review, so that only the user can update it until the lock is
+//	@Description	released or expires after OBLIGATION_LOCK_TTL. Locking an obligation again renews the lock.
+//	@Id				LockObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Success		200		{object}	models.ObligationLockResponse
+//	@Failure		401		{object}	models.LicenseError	"User not found"
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		423		{object}	models.LicenseError	"Obligation is locked by another user"
+//	@Failure		500		{object}	models.LicenseError	"Unable to lock obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/lock [post]
+func LockObligation(c *gin.Context) {
+	obligation, user, ok := getWatchObligationAndUser(c)
+	if !ok {
+		return
+	}
+
+	var lock models.ObligationLock
+	err := db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		existing, err := findObligationLock(tx, obligation.Id)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.UserId != user.Id {
+			lock = *existing
+			return errObligationLocked
+		}
+
+		// Expired locks of other users are taken over
+		now := time.Now()
+		lock = models.ObligationLock{ObligationPk: obligation.Id}
+		if err := tx.Where(lock).Assign(models.ObligationLock{UserId: user.Id, LockedAt: now,
+			ExpiresAt: now.Add(ObligationLockTTL())}).Omit("User").FirstOrCreate(&lock).Error; err != nil {
+			return err
+		}
+		lock.User = user
+		return nil
+	})
+	if errors.Is(err, errObligationLocked) {
+		obligationLockedError(c, obligation.Topic, &lock)
+		return
+	} else if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to lock obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationLockResponse{
+		Data:   lock,
+		Status: http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// UnlockObligation releases the lock of an obligation
+//
+//	@Summary		Unlock an obligation
+//	@Description	Release the lock of an obligation. Only the holder of the lock or an admin can release it.
+//	@Id				UnlockObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path	string	true	"Topic of the obligation"
+//	@Success		204
+//	@Failure		401	{object}	models.LicenseError	"User not found"
+//	@Failure		404	{object}	models.LicenseError	"No obligation with given topic found or not locked"
+//	@Failure		423	{object}	models.LicenseError	"Obligation is locked by another user"
+//	@Failure		500	{object}	models.LicenseError	"Unable to unlock obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/lock [delete]
+func UnlockObligation(c *gin.Context) {
+	obligation, user, ok := getWatchObligationAndUser(c)
+	if !ok {
+		return
+	}
+
+	lock, err := findObligationLock(db.DB.WithContext(c), obligation.Id)
+	if err == nil && lock != nil && (lock.UserId == user.Id || user.Userlevel == "admin") {
+		err = db.DB.WithContext(c).Delete(lock).Error
+	}
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to unlock obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	} else if lock == nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' is not locked", obligation.Topic),
+			Error:     "lock not found",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	} else if lock.UserId != user.Id && user.Userlevel != "admin" {
+		obligationLockedError(c, obligation.Topic, lock)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// GetObligationNotes retrieves the notes of an obligation
+//
+//	@Summary		Get notes of an obligation
+//	@Description	Get the review notes of an obligation, newest first
+//	@Id				GetObligationNotes
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationNoteResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch notes"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/notes [get]
+func GetObligationNotes(c *gin.Context) {
+	var obligation models.Obligation
+	var notes []models.ObligationNote
+	topic := c.Param("topic")
+
+	obligationQuery := db.DB.Model(&obligation)
+	if !filterObligationStatus(c, obligationQuery) {
+		return
+	}
+	if err := obligationQuery.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	query := db.DB.Model(&models.ObligationNote{}).Where(models.ObligationNote{ObligationPk: obligation.Id})
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationNoteResponse{})
+
+	if err := query.Preload("User").Order("created_at desc").Order("id desc").Find(&notes).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch notes",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationNoteResponse{
+		Data:   notes,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(notes),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateObligationNote adds a note to an obligation
+//
+//	@Summary		Add a note to an obligation
+//	@Description	Add a review note to an obligation as the logged in user
+//	@Id				CreateObligationNote
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string						true	"Topic of the obligation"
+//	@Param			note	body		models.ObligationNoteInput	true	"Note to add"
+//	@Success		201		{object}	models.ObligationNoteResponse
+//	@Failure		400		{object}	models.ValidationError	"Invalid request body"
+//	@Failure		404		{object}	models.LicenseError		"No obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError		"Unable to add note"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/notes [post]
+func CreateObligationNote(c *gin.Context) {
+	var obligation models.Obligation
+	var user models.User
+	var input models.ObligationNoteInput
+	topic := c.Param("topic")
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	if err := db.DB.Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to add note",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	note := models.ObligationNote{
+		ObligationPk: obligation.Id,
+		UserId:       user.Id,
+		Text:         input.Text,
+	}
+	if err := db.DB.Omit("User", "Obligation").Create(&note).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to add note",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	user.Userpassword = nil
+	note.User = user
+
+	res := models.ObligationNoteResponse{
+		Data:   []models.ObligationNote{note},
+		Status: http.StatusCreated,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+	}
+	c.JSON(http.StatusCreated, res)
+}
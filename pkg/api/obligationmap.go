@@ -6,8 +6,10 @@
 package api
 
 import (
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,7 +19,9 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/middleware"
 	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,8 +34,12 @@ import (
 //	@Accept			json
 //	@Produce		json
 //	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
 //	@Success		200		{object}	models.ObligationMapResponse
-//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found or no map for
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found or no map for
 //	obligation exists"
 //	@Security		ApiKeyAuth || {}
 //	@Router			/obligation_maps/topic/{topic} [get]
@@ -43,7 +51,11 @@ func GetObligationMapByTopic(c *gin.Context) {
 
 	topic := c.Param("topic")
 
-	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
@@ -55,7 +67,7 @@ func GetObligationMapByTopic(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Where(models.ObligationMap{ObligationPk: obligation.Id}).Find(&obMap).Error; err != nil {
+	if err := db.DB.WithContext(c).Where(models.ObligationMap{ObligationPk: obligation.Id}).Find(&obMap).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("Obligation map not found for topic '%s'", topic),
@@ -69,7 +81,7 @@ func GetObligationMapByTopic(c *gin.Context) {
 
 	for i := 0; i < len(obMap); i++ {
 		var license models.LicenseDB
-		if err := db.DB.Where(models.LicenseDB{Id: obMap[i].RfPk}).First(&license).Error; err != nil {
+		if err := db.DB.WithContext(c).Where(models.LicenseDB{Id: obMap[i].RfPk}).First(&license).Error; err != nil {
 			er := models.LicenseError{
 				Status:    http.StatusNotFound,
 				Message:   "Unable to fetch license shortnames",
@@ -108,7 +120,11 @@ func GetObligationMapByTopic(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			license	path		string	true	"Shortname of the license"
+//	@Param			status	query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
 //	@Success		200		{object}	models.ObligationMapResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
 //	@Failure		404		{object}	models.LicenseError	"No license with given shortname found or no map for
 //	license exists"
 //	@Security		ApiKeyAuth || {}
@@ -120,7 +136,7 @@ func GetObligationMapByLicense(c *gin.Context) {
 
 	licenseShortName := c.Param("license")
 
-	if err := db.DB.Where(models.LicenseDB{Shortname: &licenseShortName}).First(&license).Error; err != nil {
+	if err := db.DB.WithContext(c).Where(models.LicenseDB{Shortname: &licenseShortName}).First(&license).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("license with shortname '%s' not found", licenseShortName),
@@ -132,7 +148,7 @@ func GetObligationMapByLicense(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Where(models.ObligationMap{RfPk: license.Id}).Find(&obMap).Error; err != nil {
+	if err := db.DB.WithContext(c).Where(models.ObligationMap{RfPk: license.Id}).Find(&obMap).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("Obligation map not found for license '%s'", licenseShortName),
@@ -144,19 +160,27 @@ func GetObligationMapByLicense(c *gin.Context) {
 		return
 	}
 
-	for i := 0; i < len(obMap); i++ {
-		var obligation models.Obligation
-		if err := db.DB.Where(models.Obligation{Id: obMap[i].ObligationPk}).First(&obligation).Error; err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusNotFound,
-				Message:   fmt.Sprintf("Unable to fetch obligations linked with license '%s'", licenseShortName),
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.JSON(http.StatusNotFound, er)
-			return
+	obligationPks := make([]int64, 0, len(obMap))
+	for _, m := range obMap {
+		obligationPks = append(obligationPks, m.ObligationPk)
+	}
+	var obligations []models.Obligation
+	query := db.DB.WithContext(c).Model(&models.Obligation{}).Where("obligations.id IN ?", obligationPks)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Order("obligations.id").Find(&obligations).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("Unable to fetch obligations linked with license '%s'", licenseShortName),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
 		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+	for _, obligation := range obligations {
 		resObMapList = append(resObMapList, models.ObligationMapUser{
 			Type:       obligation.Type,
 			Topic:      obligation.Topic,
@@ -177,7 +201,8 @@ func GetObligationMapByLicense(c *gin.Context) {
 // PatchObligationMap Add or remove licenses from obligation map for a given obligation topic
 //
 //	@Summary		Add or remove licenses from obligation map
-//	@Description	Add or remove licenses from obligation map for a given obligation topic
+//	@Description	Add or remove licenses from obligation map for a given obligation topic. The added and
+//	@Description	removed shortnames are recorded as an audit of the obligation.
 //	@Id				PatchObligationMap
 //	@Tags			Obligations
 //	@Accept			json
@@ -210,7 +235,7 @@ func PatchObligationMap(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+	if err := db.DB.WithContext(c).Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
@@ -225,7 +250,7 @@ func PatchObligationMap(c *gin.Context) {
 	for i := 0; i < len(obMapInput.MapInput); i++ {
 		var license models.LicenseDB
 		var obligationMap models.ObligationMap
-		if err := db.DB.Where(&models.LicenseDB{Shortname: &obMapInput.MapInput[i].Shortname}).First(&license).Error; err != nil {
+		if err := db.DB.WithContext(c).Where(&models.LicenseDB{Shortname: &obMapInput.MapInput[i].Shortname}).First(&license).Error; err != nil {
 			er := models.LicenseError{
 				Status:    http.StatusNotFound,
 				Message:   fmt.Sprintf("license with shortname '%s' not found", obMapInput.MapInput[i].Shortname),
@@ -236,7 +261,7 @@ func PatchObligationMap(c *gin.Context) {
 			c.JSON(http.StatusNotFound, er)
 			return
 		}
-		if err := db.DB.Where(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).First(&obligationMap).Error; err != nil {
+		if err := db.DB.WithContext(c).Where(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).First(&obligationMap).Error; err != nil {
 			// License not in map
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				if obMapInput.MapInput[i].Add {
@@ -286,6 +311,7 @@ func PatchObligationMap(c *gin.Context) {
 //
 //	@Summary		Change license list
 //	@Description	Replaces the license list of an obligation topic with the given list in the obligation map.
+//	@Description	The added and removed shortnames are recorded as an audit of the obligation.
 //	@Id				UpdateLicenseInObligationMap
 //	@Tags			Obligations
 //	@Accept			json
@@ -305,7 +331,11 @@ func UpdateLicenseInObligationMap(c *gin.Context) {
 
 	topic := c.Param("topic")
 
-	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
@@ -353,11 +383,242 @@ func UpdateLicenseInObligationMap(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// ImportObligationMaps maps existing obligations to licenses in bulk
+//
+//	@Summary		Import obligation maps
+//	@Description	Map existing obligations to licenses by a list of topic and shortname pairs, sent as
+//	@Description	json or uploaded as the multipart form file "file", a csv file with the header
+//	@Description	"topic,shortname". Existing maps are left untouched, so importing the same maps twice
+//	@Description	creates them once. Pairs whose topic or shortname is unknown are skipped and reported.
+//	@Description	The maps are created in a single transaction.
+//	@Id				ImportObligationMaps
+//	@Tags			Obligations
+//	@Accept			json,multipart/form-data
+//	@Produce		json
+//	@Param			maps	body		models.ObligationMapImportInput	true	"Topic and shortname pairs"
+//	@Success		200		{object}	models.ObligationMapImportResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid json body or csv file"
+//	@Failure		500		{object}	models.LicenseError	"Failure to insert new maps"
+//	@Security		ApiKeyAuth
+//	@Router			/obligation_maps/import [post]
+func ImportObligationMaps(c *gin.Context) {
+	var pairs []models.ObligationMapPair
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "input file must be present",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		defer file.Close()
+
+		if pairs, err = parseObligationMapsCSV(file); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "invalid csv file",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	} else {
+		var input models.ObligationMapImportInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "invalid json body",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		pairs = input.Maps
+	}
+
+	var result models.ObligationMapImportResult
+	username := c.GetString("username")
+	if err := db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		return importObligationMaps(tx, username, pairs, &result)
+	}); err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Failure to insert new maps",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationMapImportResponse{
+		Status: http.StatusOK,
+		Data:   result,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// parseObligationMapsCSV parses the topic and shortname pairs of a csv file, whose first
+// line is the header "topic,shortname".
+func parseObligationMapsCSV(r io.Reader) ([]models.ObligationMapPair, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || !strings.EqualFold(strings.TrimSpace(records[0][0]), "topic") ||
+		!strings.EqualFold(strings.TrimSpace(records[0][1]), "shortname") {
+		return nil, errors.New("the first line must be the header topic,shortname")
+	}
+
+	pairs := make([]models.ObligationMapPair, 0, len(records)-1)
+	for i, record := range records[1:] {
+		pair := models.ObligationMapPair{
+			Topic:     strings.TrimSpace(record[0]),
+			Shortname: strings.TrimSpace(record[1]),
+		}
+		if pair.Topic == "" || pair.Shortname == "" {
+			return nil, fmt.Errorf("line %d: topic and shortname are required", i+2)
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("the file has no obligation maps")
+	}
+	return pairs, nil
+}
+
+// importObligationMaps creates the maps of the pairs which do not exist yet, and audits
+// the changed maps of each obligation. Pairs with an unknown topic or shortname are
+// skipped and reported in the result.
+func importObligationMaps(tx *gorm.DB, username string, pairs []models.ObligationMapPair,
+	result *models.ObligationMapImportResult) error {
+	var topics, shortnames []string
+	for _, pair := range pairs {
+		if !slices.Contains(topics, pair.Topic) {
+			topics = append(topics, pair.Topic)
+		}
+		if !slices.Contains(shortnames, pair.Shortname) {
+			shortnames = append(shortnames, pair.Shortname)
+		}
+	}
+
+	var obligations []struct {
+		Id    int64
+		Topic string
+	}
+	if err := tx.Model(&models.Obligation{}).Select("id", "topic").Where("topic IN ?", topics).
+		Scan(&obligations).Error; err != nil {
+		return err
+	}
+	obligationIds := make(map[string]int64, len(obligations))
+	for _, obligation := range obligations {
+		obligationIds[obligation.Topic] = obligation.Id
+	}
+
+	var licenses []struct {
+		Id        int64  `gorm:"column:rf_id"`
+		Shortname string `gorm:"column:rf_shortname"`
+	}
+	if err := tx.Model(&models.LicenseDB{}).Select("rf_id", "rf_shortname").Where("rf_shortname IN ?", shortnames).
+		Scan(&licenses).Error; err != nil {
+		return err
+	}
+	licenseIds := make(map[string]int64, len(licenses))
+	for _, license := range licenses {
+		licenseIds[license.Shortname] = license.Id
+	}
+
+	result.UnresolvedTopics = []string{}
+	for _, topic := range topics {
+		if _, ok := obligationIds[topic]; !ok {
+			result.UnresolvedTopics = append(result.UnresolvedTopics, topic)
+		}
+	}
+	result.UnresolvedShortnames = []string{}
+	for _, shortname := range shortnames {
+		if _, ok := licenseIds[shortname]; !ok {
+			result.UnresolvedShortnames = append(result.UnresolvedShortnames, shortname)
+		}
+	}
+
+	// The licenses to map by obligation, in the order of the pairs
+	var obligationOrder []int64
+	licensesByObligation := make(map[int64][]int64)
+	for _, pair := range pairs {
+		obligationId, ok := obligationIds[pair.Topic]
+		if !ok {
+			continue
+		}
+		licenseId, ok := licenseIds[pair.Shortname]
+		if !ok {
+			continue
+		}
+		if _, ok := licensesByObligation[obligationId]; !ok {
+			obligationOrder = append(obligationOrder, obligationId)
+		}
+		if !slices.Contains(licensesByObligation[obligationId], licenseId) {
+			licensesByObligation[obligationId] = append(licensesByObligation[obligationId], licenseId)
+		}
+	}
+
+	for _, obligationId := range obligationOrder {
+		var oldObMaps []models.ObligationMap
+		if err := tx.Where(models.ObligationMap{ObligationPk: obligationId}).Find(&oldObMaps).Error; err != nil {
+			return err
+		}
+
+		var insertObMaps []models.ObligationMap
+		for _, licenseId := range licensesByObligation[obligationId] {
+			if slices.ContainsFunc(oldObMaps, func(obMap models.ObligationMap) bool { return obMap.RfPk == licenseId }) {
+				result.Existing++
+				continue
+			}
+			insertObMaps = append(insertObMaps, models.ObligationMap{
+				ObligationPk: obligationId,
+				RfPk:         licenseId,
+			})
+		}
+		if len(insertObMaps) == 0 {
+			continue
+		}
+
+		created := tx.CreateInBatches(&insertObMaps, OBLIGATION_MAP_BATCH_SIZE)
+		if created.Error != nil {
+			return created.Error
+		}
+		if err := adjustObligationLicenseCount(tx, obligationId, created.RowsAffected); err != nil {
+			return err
+		}
+		result.Created += len(insertObMaps)
+
+		newObMaps := append(append([]models.ObligationMap{}, oldObMaps...), insertObMaps...)
+		if err := createObligationMapChangelog(tx, username, oldObMaps, newObMaps,
+			&models.Obligation{Id: obligationId}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GenerateDiffOfLicenses calculates diff from the obligation maps list in database and the list provided by the user to determine the licenses to be
 // inserted and the licenses to be removed. Basically, it replaces the list present in database by the list given by the user.
 func GenerateDiffOfLicenses(c *gin.Context, obligation *models.Obligation, inputShortnames []string, removeLicenseIds, insertLicenseIds *[]int64) error {
 	var oldObMaps []models.ObligationMap
-	if err := db.DB.Where(models.ObligationMap{ObligationPk: obligation.Id}).Find(&oldObMaps).Error; err != nil {
+	if err := db.DB.WithContext(c).Where(models.ObligationMap{ObligationPk: obligation.Id}).Find(&oldObMaps).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
 			Message:   fmt.Sprintf("obligation maps for obligation with topic '%s' not found", obligation.Topic),
@@ -376,7 +637,7 @@ func GenerateDiffOfLicenses(c *gin.Context, obligation *models.Obligation, input
 	for i := 0; i < len(inputShortnames); i++ {
 		var license models.LicenseDB
 		var obligationMap models.ObligationMap
-		if err := db.DB.Where(&models.LicenseDB{Shortname: &inputShortnames[i]}).First(&license).Error; err != nil {
+		if err := db.DB.WithContext(c).Where(&models.LicenseDB{Shortname: &inputShortnames[i]}).First(&license).Error; err != nil {
 			er := models.LicenseError{
 				Status:    http.StatusNotFound,
 				Message:   fmt.Sprintf("license with shortname '%s' not found", inputShortnames[i]),
@@ -387,7 +648,7 @@ func GenerateDiffOfLicenses(c *gin.Context, obligation *models.Obligation, input
 			c.JSON(http.StatusNotFound, er)
 			return err
 		}
-		if err := db.DB.Where(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).First(&obligationMap).Error; err != nil {
+		if err := db.DB.WithContext(c).Where(&models.ObligationMap{ObligationPk: obligation.Id, RfPk: license.Id}).First(&obligationMap).Error; err != nil {
 			// License not in map, add to insert slice
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				*insertLicenseIds = append(*insertLicenseIds, license.Id)
@@ -445,13 +706,22 @@ func PerformObligationMapActions(username string, obligation models.Obligation,
 	if err := db.DB.Transaction(func(tx *gorm.DB) error {
 		if len(removeObMaps) > 0 {
 			// Bulk delete removeObMaps from DB
-			if err := tx.Delete(&removeObMaps).Error; err != nil {
+			result := tx.Delete(&removeObMaps)
+			if result.Error != nil {
+				return result.Error
+			}
+			// Maps removed concurrently in the meantime are not counted twice
+			if err := adjustObligationLicenseCount(tx, obligation.Id, -result.RowsAffected); err != nil {
 				return err
 			}
 		}
 		if len(insertObMaps) > 0 {
 			// Bulk create insertObMaps in DB
-			if err := tx.Create(&insertObMaps).Error; err != nil {
+			result := tx.Create(&insertObMaps)
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := adjustObligationLicenseCount(tx, obligation.Id, result.RowsAffected); err != nil {
 				return err
 			}
 		}
@@ -482,24 +752,79 @@ func PerformObligationMapActions(username string, obligation models.Obligation,
 	return &res, nil
 }
 
-// createObligationMapChangelog creates the changelog for the obligation map changes.
-func createObligationMapChangelog(tx *gorm.DB, username string, oldObMaps, newObMaps []models.ObligationMap, obligation *models.Obligation) error {
-	var oldLicenses []string
-	var newLicenses []string
+// adjustObligationLicenseCount adds delta to the license count of the obligation. The
+// count is updated in a single statement, so concurrent changes of the obligation maps
+// can not overwrite each other's count. It must be called in the transaction changing
+// the maps.
+func adjustObligationLicenseCount(tx *gorm.DB, obligationId int64, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+	return tx.Model(&models.Obligation{}).Where(models.Obligation{Id: obligationId}).
+		UpdateColumn("license_count", gorm.Expr("license_count + ?", delta)).Error
+}
 
+// createObligationMapChangelog records the obligation map changes as an audit of the
+// obligation, with a changelog of the field "Shortnames" for every license added to or
+// removed from the obligation. The added shortnames are the updated values and the
+// removed ones the old values.
+func createObligationMapChangelog(tx *gorm.DB, username string, oldObMaps, newObMaps []models.ObligationMap, obligation *models.Obligation) error {
+	var oldLicenseIds, newLicenseIds []int64
 	for i := 0; i < len(oldObMaps); i++ {
-		oldLicenses = append(oldLicenses, strconv.FormatInt(oldObMaps[i].RfPk, 10))
+		oldLicenseIds = append(oldLicenseIds, oldObMaps[i].RfPk)
 	}
 	for i := 0; i < len(newObMaps); i++ {
-		newLicenses = append(newLicenses, strconv.FormatInt(newObMaps[i].RfPk, 10))
+		newLicenseIds = append(newLicenseIds, newObMaps[i].RfPk)
+	}
+
+	var addedLicenseIds, removedLicenseIds []int64
+	for _, licenseId := range newLicenseIds {
+		if !slices.Contains(oldLicenseIds, licenseId) && !slices.Contains(addedLicenseIds, licenseId) {
+			addedLicenseIds = append(addedLicenseIds, licenseId)
+		}
+	}
+	for _, licenseId := range oldLicenseIds {
+		if !slices.Contains(newLicenseIds, licenseId) && !slices.Contains(removedLicenseIds, licenseId) {
+			removedLicenseIds = append(removedLicenseIds, licenseId)
+		}
+	}
+	if len(addedLicenseIds) == 0 && len(removedLicenseIds) == 0 {
+		return nil
 	}
 
-	oldVal := strings.Join(oldLicenses, ",")
-	newVal := strings.Join(newLicenses, ",")
-	change := models.ChangeLog{
-		Field:        "RfPk",
-		OldValue:     &oldVal,
-		UpdatedValue: &newVal,
+	var licenses []struct {
+		Id        int64  `gorm:"column:rf_id"`
+		Shortname string `gorm:"column:rf_shortname"`
+	}
+	if err := tx.Model(&models.LicenseDB{}).Select("rf_id", "rf_shortname").
+		Where("rf_id IN ?", append(append([]int64{}, addedLicenseIds...), removedLicenseIds...)).
+		Scan(&licenses).Error; err != nil {
+		return err
+	}
+	shortnames := make(map[int64]string, len(licenses))
+	for _, license := range licenses {
+		shortnames[license.Id] = license.Shortname
+	}
+	shortnameOf := func(licenseId int64) *string {
+		shortname, ok := shortnames[licenseId]
+		if !ok {
+			shortname = strconv.FormatInt(licenseId, 10)
+		}
+		return &shortname
+	}
+
+	var changes []models.ChangeLog
+	for _, licenseId := range addedLicenseIds {
+		changes = append(changes, models.ChangeLog{
+			Field:        "Shortnames",
+			UpdatedValue: shortnameOf(licenseId),
+		})
+	}
+	for _, licenseId := range removedLicenseIds {
+		changes = append(changes, models.ChangeLog{
+			Field:    "Shortnames",
+			OldValue: shortnameOf(licenseId),
+		})
 	}
 
 	var user models.User
@@ -511,15 +836,10 @@ func createObligationMapChangelog(tx *gorm.DB, username string, oldObMaps, newOb
 		UserId:     user.Id,
 		TypeId:     obligation.Id,
 		Timestamp:  time.Now(),
-		Type:       "license",
-		ChangeLogs: []models.ChangeLog{change},
+		Type:       "Obligation",
+		ChangeLogs: changes,
 	}
-
-	if err := tx.Create(&audit).Error; err != nil {
-		return err
-	}
-
-	return nil
+	return createAudit(tx, &audit)
 }
 
 // removeFromSlice removes the item from the slice if it exists.
@@ -546,3 +866,409 @@ func createObligationMapUser(obligation models.Obligation, obMaps []models.Oblig
 		Shortnames: shortnameList,
 	}, nil
 }
+
+// GetObligationGraph returns the graph of the obligations and the licenses they are mapped to
+//
+//	@Summary		Get obligation graph
+//	@Description	Get the active obligations and the licenses as nodes and the obligation maps as edges,
+//	@Description	optionally scoped to a set of licenses or classifications
+//	@Id				GetObligationGraph
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			licenses		query		string	false	"Comma separated shortnames of the licenses"
+//	@Param			classification	query		string	false	"Comma separated classifications of the obligations"
+//	@Param			status			query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200				{object}	models.ObligationGraphResponse
+//	@Failure		400				{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401				{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403				{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		500				{object}	models.LicenseError	"Unable to fetch obligation graph"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/graph [get]
+func GetObligationGraph(c *gin.Context) {
+	var rows []struct {
+		ObligationId   int64
+		Topic          string
+		Type           string
+		Classification string
+		LicenseId      int64
+		Shortname      string
+	}
+
+	query := db.DB.WithContext(c).Model(&models.ObligationMap{}).
+		Select("obligations.id AS obligation_id, obligations.topic, obligations.type, obligations.classification, " +
+			"license_dbs.rf_id AS license_id, license_dbs.rf_shortname AS shortname").
+		Joins("JOIN obligations ON obligations.id = obligation_maps.obligation_pk").
+		Joins("JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk")
+	filterActiveObligations(query, true)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if licenses := c.Query("licenses"); licenses != "" {
+		query.Where("license_dbs.rf_shortname IN ?", strings.Split(licenses, ","))
+	}
+	if classification := c.Query("classification"); classification != "" {
+		query.Where("obligations.classification IN ?", strings.Split(classification, ","))
+	}
+
+	if err := query.Order("obligations.topic").Order("license_dbs.rf_shortname").Scan(&rows).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligation graph",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	graph := models.ObligationGraph{
+		Nodes: []models.ObligationGraphNode{},
+		Edges: []models.ObligationGraphEdge{},
+	}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		obligationNode := fmt.Sprintf("obligation:%d", row.ObligationId)
+		licenseNode := fmt.Sprintf("license:%d", row.LicenseId)
+		if !seen[obligationNode] {
+			seen[obligationNode] = true
+			graph.Nodes = append(graph.Nodes, models.ObligationGraphNode{
+				Id:             obligationNode,
+				Kind:           "obligation",
+				Label:          row.Topic,
+				Type:           row.Type,
+				Classification: row.Classification,
+			})
+		}
+		if !seen[licenseNode] {
+			seen[licenseNode] = true
+			graph.Nodes = append(graph.Nodes, models.ObligationGraphNode{
+				Id:    licenseNode,
+				Kind:  "license",
+				Label: row.Shortname,
+			})
+		}
+		graph.Edges = append(graph.Edges, models.ObligationGraphEdge{
+			Source: obligationNode,
+			Target: licenseNode,
+		})
+	}
+
+	res := models.ObligationGraphResponse{
+		Status: http.StatusOK,
+		Data:   graph,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetObligationMappingHealth classifies the obligations by the licenses they are mapped to
+//
+//	@Summary		Get obligation mapping health
+//	@Description	Classify the obligations as fully mapped to active licenses, mapped to some inactive
+//	@Description	licenses, mapped to license ids which do not exist, or unmapped, with the topics of each
+//	@Description	class
+//	@Id				GetObligationMappingHealth
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			status	query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationMappingHealthResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch obligation maps"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/mapping-health [get]
+func GetObligationMappingHealth(c *gin.Context) {
+	var rows []struct {
+		Topic    string
+		Maps     int
+		Missing  int
+		Inactive int
+	}
+
+	query := db.DB.WithContext(c).Model(&models.Obligation{})
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.
+		Select("obligations.topic, COUNT(obligation_maps.om_pk) AS maps, "+
+			"SUM(CASE WHEN obligation_maps.om_pk IS NOT NULL AND license_dbs.rf_id IS NULL THEN 1 ELSE 0 END) AS missing, "+
+			"SUM(CASE WHEN license_dbs.rf_active = ? THEN 1 ELSE 0 END) AS inactive", false).
+		Joins("LEFT JOIN obligation_maps ON obligation_maps.obligation_pk = obligations.id").
+		Joins("LEFT JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk").
+		Group("obligations.id, obligations.topic").
+		Order("obligations.topic").
+		Scan(&rows).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligation maps",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	health := models.ObligationMappingHealth{
+		FullyMapped:      models.ObligationMappingHealthCategory{Topics: []string{}},
+		InactiveLicenses: models.ObligationMappingHealthCategory{Topics: []string{}},
+		MissingLicenses:  models.ObligationMappingHealthCategory{Topics: []string{}},
+		Unmapped:         models.ObligationMappingHealthCategory{Topics: []string{}},
+	}
+	for _, row := range rows {
+		category := &health.FullyMapped
+		if row.Maps == 0 {
+			category = &health.Unmapped
+		} else if row.Missing > 0 {
+			category = &health.MissingLicenses
+		} else if row.Inactive > 0 {
+			category = &health.InactiveLicenses
+		}
+		category.Count++
+		category.Topics = append(category.Topics, row.Topic)
+	}
+
+	res := models.ObligationMappingHealthResponse{
+		Status: http.StatusOK,
+		Data:   health,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetObligationUsage ranks the active obligations by the number of active licenses mapped to them
+//
+//	@Summary		Get obligation usage
+//	@Description	Get the active obligations with the number of distinct active licenses mapped to them,
+//	@Description	most used first, to prioritize the review of the most impactful obligations
+//	@Id				GetObligationUsage
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Param			status	query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationUsageResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch obligation usage"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/usage [get]
+func GetObligationUsage(c *gin.Context) {
+	usages := []models.ObligationUsage{}
+
+	query := db.DB.WithContext(c).Model(&models.Obligation{}).
+		Select("obligations.topic, obligations.type, obligations.classification, "+
+			"COUNT(DISTINCT license_dbs.rf_id) AS active_licenses").
+		Joins("LEFT JOIN obligation_maps ON obligation_maps.obligation_pk = obligations.id").
+		Joins("LEFT JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk AND license_dbs.rf_active = ?", true).
+		Group("obligations.id, obligations.topic, obligations.type, obligations.classification")
+	filterActiveObligations(query, true)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationUsageResponse{})
+
+	if err := query.Order("active_licenses DESC").Order("obligations.topic").Scan(&usages).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligation usage",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationUsageResponse{
+		Status: http.StatusOK,
+		Data:   usages,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(usages),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ExportObligationMapMatrix exports the obligation maps as a license by obligation matrix
+//
+//	@Summary		Export obligation maps as a matrix
+//	@Description	Export the obligation maps of the given licenses, or of all the active licenses, as a csv
+//	@Description	matrix. Each row is a license, by shortname, and each column the topic of an active
+//	@Description	obligation mapped to any of the licenses. A cell is "x" if the obligation applies to the
+//	@Description	license, else it is empty. The rows are streamed one by one.
+//	@Id				ExportObligationMapMatrix
+//	@Tags			Obligations
+//	@Produce		text/csv
+//	@Param			licenses	query		string				false	"Comma separated shortnames of the licenses, all the active licenses if not given"
+//	@Param			format		query		string				false	"Format of the matrix"	Enums(csv)	default(csv)
+//	@Success		200			{string}	string				"csv matrix"
+//	@Failure		400			{object}	models.LicenseError	"Invalid format value"
+//	@Failure		404			{object}	models.LicenseError	"Licenses with given shortnames not found"
+//	@Failure		500			{object}	models.LicenseError	"Unable to export obligation maps"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligation_maps/matrix [get]
+func ExportObligationMapMatrix(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid format value",
+			Error:     fmt.Sprintf("format must be csv, got '%s'", format),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var shortnames []string
+	for _, shortname := range strings.Split(c.Query("licenses"), ",") {
+		if shortname = strings.TrimSpace(shortname); shortname != "" && !slices.Contains(shortnames, shortname) {
+			shortnames = append(shortnames, shortname)
+		}
+	}
+
+	// filterLicenses narrows the query on license_dbs to the licenses of the matrix
+	filterLicenses := func(query *gorm.DB) *gorm.DB {
+		if len(shortnames) != 0 {
+			return query.Where("license_dbs.rf_shortname IN ?", shortnames)
+		}
+		return query.Where("license_dbs.rf_active = ?", true)
+	}
+	internalError := func(err error) {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to export obligation maps",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+	}
+
+	if len(shortnames) != 0 {
+		var found []string
+		if err := db.DB.WithContext(c).Model(&models.LicenseDB{}).
+			Where("rf_shortname IN ?", shortnames).Pluck("rf_shortname", &found).Error; err != nil {
+			internalError(err)
+			return
+		}
+		var missing []string
+		for _, shortname := range shortnames {
+			if !slices.Contains(found, shortname) {
+				missing = append(missing, shortname)
+			}
+		}
+		if len(missing) != 0 {
+			er := models.LicenseError{
+				Status:    http.StatusNotFound,
+				Message:   "Licenses with given shortnames not found",
+				Error:     fmt.Sprintf("unknown licenses: %s", strings.Join(missing, ", ")),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusNotFound, er)
+			return
+		}
+	}
+
+	// The columns are known before the rows are streamed
+	var columns []struct {
+		Id    int64
+		Topic string
+	}
+	query := db.DB.WithContext(c).Model(&models.Obligation{}).
+		Distinct("obligations.id", "obligations.topic").
+		Joins("JOIN obligation_maps ON obligation_maps.obligation_pk = obligations.id").
+		Joins("JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk")
+	filterActiveObligations(filterLicenses(query), true)
+	if err := query.Order("obligations.topic").Scan(&columns).Error; err != nil {
+		internalError(err)
+		return
+	}
+	header := []string{"license"}
+	columnIndex := make(map[int64]int, len(columns))
+	for i, column := range columns {
+		header = append(header, column.Topic)
+		columnIndex[column.Id] = i + 1
+	}
+
+	// A row per license and mapped obligation, adjacent for the same license, so each
+	// license is written as soon as its rows are read
+	rows, err := filterLicenses(db.DB.WithContext(c).Model(&models.LicenseDB{}).
+		Select("license_dbs.rf_shortname, obligation_maps.obligation_pk").
+		Joins("LEFT JOIN obligation_maps ON obligation_maps.rf_pk = license_dbs.rf_id")).
+		Order("license_dbs.rf_shortname").Rows()
+	if err != nil {
+		internalError(err)
+		return
+	}
+	defer rows.Close()
+
+	fileName := strings.Map(func(r rune) rune {
+		if r == '+' || r == ':' {
+			return '_'
+		}
+		return r
+	}, fmt.Sprintf("obligation-map-matrix-%s.csv", time.Now().Format(time.RFC3339)))
+
+	middleware.StreamResponse(c)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	var record []string
+	// writeRecord writes the row of the current license, if any
+	writeRecord := func() error {
+		if record == nil {
+			return nil
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		writer.Flush()
+		c.Writer.Flush()
+		return writer.Error()
+	}
+	if err := writer.Write(header); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	for rows.Next() {
+		var shortname string
+		var obligationPk *int64
+		if err := rows.Scan(&shortname, &obligationPk); err != nil {
+			// The status is already sent, so the client sees a truncated file
+			_ = c.Error(err)
+			return
+		}
+		if record == nil || record[0] != shortname {
+			if err := writeRecord(); err != nil {
+				_ = c.Error(err)
+				return
+			}
+			record = make([]string, len(header))
+			record[0] = shortname
+		}
+		if obligationPk != nil {
+			if i, ok := columnIndex[*obligationPk]; ok {
+				record[i] = "x"
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := writeRecord(); err != nil {
+		_ = c.Error(err)
+	}
+}
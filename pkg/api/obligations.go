@@ -7,64 +7,293 @@
 package api
 
 import (
-	"crypto/md5"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/middleware"
 	"github.com/fossology/LicenseDb/pkg/models"
 	"github.com/fossology/LicenseDb/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/exp/slices"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// obligationFilterColumns are the obligation fields which can be used in the filter
+// expression of GetAllObligation
+var obligationFilterColumns = map[string]utils.FilterColumn{
+	"topic":          {Name: "topic", Type: utils.FILTER_STRING},
+	"type":           {Name: "type", Type: utils.FILTER_STRING},
+	"text":           {Name: "text", Type: utils.FILTER_STRING},
+	"classification": {Name: "classification", Type: utils.FILTER_STRING},
+	"comment":        {Name: "comment", Type: utils.FILTER_STRING},
+	"modifications":  {Name: "modifications", Type: utils.FILTER_BOOL},
+	"active":         {Name: "active", Type: utils.FILTER_BOOL},
+	"text_updatable": {Name: "text_updatable", Type: utils.FILTER_BOOL},
+}
+
+// obligationProjectionFields are the json keys of the obligation fields which can be
+// selected with the fields parameter of GetAllObligation, named as their columns
+var obligationProjectionFields = []string{"id", "topic", "type", "text", "classification", "modifications",
+	"comment", "active", "text_updatable", "sensitive", "license_count", "status", "created_at", "updated_at",
+	"effective_from", "effective_until"}
+
 // GetAllObligation retrieves a list of all obligation records
 //
 //	@Summary		Get all active obligations
-//	@Description	Get all active obligations from the service
+//	@Description	Get all active obligations from the service. With format=ndjson or Accept: application/x-ndjson,
+//	@Description	all the matching obligations are streamed as newline delimited json instead.
+//	@Description	The filter expression joins conditions on topic, type, text, classification, comment,
+//	@Description	modifications, active and text_updatable with AND, using the operators =, !=, eq, ne,
+//	@Description	in and like, e.g. "type in (obligation,risk) AND topic like '%copyleft%'".
+//	@Description	With fields, only the given fields of the obligations are fetched and returned, e.g.
+//	@Description	"topic,classification,active", with the other fields left out of the obligations.
+//	@Description	Without active, only the active obligations are listed, or all of them if
+//	@Description	OBLIGATION_ACTIVE_DEFAULT is all.
+//	@Description	With expandLicenses, the licenses the obligations are mapped to are listed in their licenses.
+//	@Description	With dedupeLicenses, the obligations only list the ids of their licenses in license_ids
+//	@Description	and every license is returned once in the included map of the response, keyed by id.
 //	@Id				GetAllObligation
 //	@Tags			Obligations
 //	@Accept			json
-//	@Produce		json
-//	@Param			active		query		bool	true	"Active obligation only"
-//	@Param			page		query		int		false	"Page number"
-//	@Param			limit		query		int		false	"Number of records per page"
-//	@Param			order_by	query		string	false	"Asc or desc ordering"	Enums(asc, desc)	default(asc)
-//	@Success		200			{object}	models.ObligationResponse
-//	@Failure		404			{object}	models.LicenseError	"No obligations in DB"
+//	@Produce		json,application/x-ndjson
+//	@Param			active				query		string	false	"Active obligation only, considering the effective window, or all for both"	Enums(true, false, all)
+//	@Param			includeInactive		query		bool	false	"Admin only, both active and inactive obligations, ignoring active"
+//	@Param			createdAfter		query		string	false	"Only obligations created at or after this RFC3339 timestamp"
+//	@Param			createdBefore		query		string	false	"Only obligations created before this RFC3339 timestamp"
+//	@Param			textUpdatable		query		bool	false	"Only obligations whose text is (not) updatable"
+//	@Param			hasComment			query		bool	false	"Only obligations with (without) a comment"
+//	@Param			status				query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			raw					query		bool	false	"false for the normalized text instead of the text as sent"							default(true)
+//	@Param			filter				query		string	false	"Filter expression, e.g. classification=red AND modifications=true. Not on comment while field encryption is enabled"
+//	@Param			fields				query		string	false	"Comma separated fields of the obligations to return"
+//	@Param			expandLicenses		query		bool	false	"Expand the licenses of the obligations inline, not with fields or format=ndjson"
+//	@Param			dedupeLicenses		query		bool	false	"Expand the licenses once into included, referenced by id from the obligations"
+//	@Param			page				query		int		false	"Page number"
+//	@Param			limit				query		int		false	"Number of records per page"
+//	@Param			order_by			query		string	false	"Asc or desc ordering"												Enums(asc, desc)	default(asc)
+//	@Param			format				query		string	false	"ndjson to stream all obligations one per line, without pagination"	Enums(ndjson)
+//	@Param			X-Response-Envelope	header		string	false	"none for the obligations without the response envelope"			Enums(none)
+//	@Success		200					{object}	models.ObligationResponse
+//	@Failure		400					{object}	models.LicenseError	"Invalid active, includeInactive, createdAfter, createdBefore, textUpdatable, hasComment, status, filter, fields, expandLicenses, dedupeLicenses or raw value"
+//	@Failure		401					{object}	models.LicenseError	"includeInactive or status without valid credentials"
+//	@Failure		403					{object}	models.LicenseError	"includeInactive by a non admin user, or status by a non reviewer"
+//	@Failure		404					{object}	models.LicenseError	"No obligations in DB"
 //	@Security		ApiKeyAuth || {}
 //	@Router			/obligations [get]
 func GetAllObligation(c *gin.Context) {
 	var obligations []models.Obligation
 	active := c.Query("active")
 	if active == "" {
-		active = "true"
+		active = os.Getenv("OBLIGATION_ACTIVE_DEFAULT")
+		if active != OBLIGATION_ACTIVE_ALL {
+			active = DEFAULT_OBLIGATION_ACTIVE
+		}
 	}
 	var parsedActive bool
-	parsedActive, err := strconv.ParseBool(active)
+	var err error
+	if active != OBLIGATION_ACTIVE_ALL {
+		parsedActive, err = strconv.ParseBool(active)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid active value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s', must be true, false or %s", active, OBLIGATION_ACTIVE_ALL),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	query := db.DB.WithContext(c).Model(&models.Obligation{})
+
+	includeInactive := false
+	if value := c.Query("includeInactive"); value != "" {
+		includeInactive, err = strconv.ParseBool(value)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid includeInactive value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	if !includeInactive && active != OBLIGATION_ACTIVE_ALL {
+		filterActiveObligations(query, parsedActive)
+	}
+
+	if !filterObligationStatus(c, query) {
+		return
+	}
+
+	if createdAfter := c.Query("createdAfter"); createdAfter != "" {
+		parsedCreatedAfter, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid createdAfter value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", createdAfter),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		query.Where("created_at >= ?", parsedCreatedAfter)
+	}
+
+	if createdBefore := c.Query("createdBefore"); createdBefore != "" {
+		parsedCreatedBefore, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid createdBefore value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", createdBefore),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		query.Where("created_at < ?", parsedCreatedBefore)
+	}
+
+	if textUpdatable := c.Query("textUpdatable"); textUpdatable != "" {
+		parsedTextUpdatable, err := strconv.ParseBool(textUpdatable)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid textUpdatable value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", textUpdatable),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		query.Where("text_updatable = ?", parsedTextUpdatable)
+	}
+
+	// Cleared comments are stored empty, so they count as no comment like NULL ones
+	if hasComment := c.Query("hasComment"); hasComment != "" {
+		parsedHasComment, err := strconv.ParseBool(hasComment)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid hasComment value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", hasComment),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		if parsedHasComment {
+			query.Where("comment IS NOT NULL AND comment <> ''")
+		} else {
+			query.Where("comment IS NULL OR comment = ''")
+		}
+	}
+
+	// Encrypted comments can not be compared in the database
+	filterColumns := obligationFilterColumns
+	if models.FieldEncryptionKeyId() != "" {
+		filterColumns = make(map[string]utils.FilterColumn, len(obligationFilterColumns))
+		for field, column := range obligationFilterColumns {
+			if field != "comment" {
+				filterColumns[field] = column
+			}
+		}
+	}
+	if err := utils.ApplyFilter(query, c.Query("filter"), filterColumns); err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid filter value",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	fields, err := parseObligationFields(c.Query("fields"))
 	if err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusBadRequest,
-			Message:   "Invalid active value",
-			Error:     fmt.Sprintf("Parsing failed for value '%s'", active),
+			Message:   "Invalid fields value",
+			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
 		c.JSON(http.StatusBadRequest, er)
 		return
 	}
-	query := db.DB.Model(&models.Obligation{})
-	query.Where("active = ?", parsedActive)
 
-	_ = utils.PreparePaginateResponse(c, query, &models.ObligationResponse{})
+	expandLicenses := false
+	if value := c.Query("expandLicenses"); value != "" {
+		if expandLicenses, err = strconv.ParseBool(value); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid expandLicenses value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	dedupeLicenses := false
+	if value := c.Query("dedupeLicenses"); value != "" {
+		if dedupeLicenses, err = strconv.ParseBool(value); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid dedupeLicenses value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	ndjson := c.Query("format") == "ndjson" || strings.Contains(c.GetHeader("Accept"), "application/x-ndjson")
+	// The included licenses are only written in the response envelope
+	if (expandLicenses || dedupeLicenses) && (len(fields) != 0 || ndjson) ||
+		dedupeLicenses && c.GetHeader("X-Response-Envelope") == "none" {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid expandLicenses or dedupeLicenses value",
+			Error:     "licenses can not be expanded with fields or format=ndjson, nor deduplicated without the response envelope",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
 
 	orderBy := c.Query("order_by")
 	queryOrderString := "topic"
@@ -73,6 +302,28 @@ func GetAllObligation(c *gin.Context) {
 		queryOrderString += " desc"
 	}
 
+	// The normalized text is written instead of the text with raw=false
+	columns := fields
+	if slices.Contains(fields, "text") {
+		columns = append(slices.Clone(fields), "normalized_text")
+	}
+
+	if ndjson {
+		if len(fields) != 0 {
+			query.Select(columns)
+		}
+		streamObligationsNDJSON(c, query.Order(queryOrderString), fields)
+		return
+	}
+
+	if len(fields) != 0 {
+		_ = utils.PreparePaginateResponse(c, query, &models.ObligationProjectionResponse{})
+		// Selected after the count of the pagination, which would else count the column
+		query.Select(columns)
+	} else {
+		_ = utils.PreparePaginateResponse(c, query, &models.ObligationResponse{})
+	}
+
 	query.Order(queryOrderString)
 
 	if err = query.Find(&obligations).Error; err != nil {
@@ -86,6 +337,10 @@ func GetAllObligation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, er)
 		return
 	}
+	if len(fields) != 0 {
+		writeObligationProjection(c, obligations, fields)
+		return
+	}
 	res := models.ObligationResponse{
 		Data:   obligations,
 		Status: http.StatusOK,
@@ -93,580 +348,3472 @@ func GetAllObligation(c *gin.Context) {
 			ResourceCount: len(obligations),
 		},
 	}
+	if expandLicenses || dedupeLicenses {
+		if res.Included, err = expandObligationLicenses(c, obligations, dedupeLicenses); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to fetch the licenses of the obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	}
+
+	writeObligationResponse(c, res, false)
+}
+
+// expandObligationLicenses sets the licenses the obligations are mapped to on the
+// obligations. With dedupe, only the license ids are set on the obligations and the
+// licenses are returned once by id instead.
+func expandObligationLicenses(c *gin.Context, obligations []models.Obligation, dedupe bool) (map[int64]models.ObligationLicense, error) {
+	var rows []struct {
+		ObligationPk int64
+		models.ObligationLicense
+	}
+	var included map[int64]models.ObligationLicense
+	if dedupe {
+		included = make(map[int64]models.ObligationLicense)
+	}
+	if len(obligations) == 0 {
+		return included, nil
+	}
+
+	obligationIds := make([]int64, len(obligations))
+	for i := range obligations {
+		obligationIds[i] = obligations[i].Id
+	}
+	err := db.DB.WithContext(c).Model(&models.ObligationMap{}).
+		Select("obligation_maps.obligation_pk, license_dbs.rf_id AS id, license_dbs.rf_shortname AS shortname, "+
+			"license_dbs.rf_fullname AS fullname, license_dbs.rf_spdx_id AS spdx_id, license_dbs.rf_url AS url").
+		Joins("JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk").
+		Where("obligation_maps.obligation_pk IN ?", obligationIds).
+		Order("license_dbs.rf_shortname").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[int64]int, len(obligations))
+	for i := range obligations {
+		positions[obligations[i].Id] = i
+	}
+	for _, row := range rows {
+		obligation := &obligations[positions[row.ObligationPk]]
+		if dedupe {
+			obligation.LicenseIds = append(obligation.LicenseIds, row.Id)
+			included[row.Id] = row.ObligationLicense
+		} else {
+			obligation.Licenses = append(obligation.Licenses, row.ObligationLicense)
+		}
+	}
+	return included, nil
+}
+
+// parseObligationFields parses the comma separated fields parameter of GetAllObligation.
+// Duplicate fields are skipped and unknown ones are rejected.
+func parseObligationFields(fields string) ([]string, error) {
+	var parsed, unknown []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(parsed, field) {
+			continue
+		}
+		if !slices.Contains(obligationProjectionFields, field) {
+			unknown = append(unknown, field)
+			continue
+		}
+		parsed = append(parsed, field)
+	}
+	if len(unknown) != 0 {
+		return nil, fmt.Errorf("unknown fields [%s], must be of [%s]", strings.Join(unknown, " "),
+			strings.Join(obligationProjectionFields, " "))
+	}
+	return parsed, nil
+}
+
+// projectObligation returns the given fields of the obligation by their json keys.
+func projectObligation(obligation *models.Obligation, fields []string) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(obligation)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		projected[field] = all[field]
+	}
+	return projected, nil
+}
+
+// writeObligationProjection writes the given fields of the obligations of a GET request
+// in an ObligationProjectionResponse, handling raw and X-Response-Envelope as
+// writeObligationResponse does.
+func writeObligationProjection(c *gin.Context, obligations []models.Obligation, fields []string) {
+	if !normalizeObligationTexts(c, obligations) {
+		return
+	}
+
+	res := models.ObligationProjectionResponse{
+		Data:   make([]map[string]interface{}, 0, len(obligations)),
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(obligations),
+		},
+	}
+	for i := range obligations {
+		projected, err := projectObligation(&obligations[i], fields)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to fetch obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+		res.Data = append(res.Data, projected)
+	}
 
-	c.JSON(http.StatusOK, res)
+	if c.GetHeader("X-Response-Envelope") != "none" {
+		c.JSON(http.StatusOK, res)
+		return
+	}
+	if paginationMeta, ok := c.Get("paginationMeta"); ok {
+		c.Header("X-Total-Count", strconv.Itoa(paginationMeta.(models.PaginationMeta).ResourceCount))
+	}
+	middleware.StreamResponse(c)
+	c.JSON(http.StatusOK, res.Data)
 }
 
-// GetObligation retrieves an active obligation record
-//
-//	@Summary		Get an obligation
-//	@Description	Get an active based on given topic
-//	@Id				GetObligation
-//	@Tags			Obligations
-//	@Accept			json
-//	@Produce		json
-//	@Param			topic	path		string	true	"Topic of the obligation"
-//	@Success		200		{object}	models.ObligationResponse
-//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
-//	@Security		ApiKeyAuth || {}
-//	@Router			/obligations/{topic} [get]
-func GetObligation(c *gin.Context) {
-	var obligation models.Obligation
-	query := db.DB.Model(&obligation)
-	tp := c.Param("topic")
-	if err := query.Where(models.Obligation{Topic: tp}).First(&obligation).Error; err != nil {
+// streamObligationsNDJSON streams the obligations of the query as newline delimited
+// json, one obligation per line, without loading all of them in memory. If fields are
+// given, only those fields of the obligations are written.
+func streamObligationsNDJSON(c *gin.Context, query *gorm.DB, fields []string) {
+	rows, err := query.Rows()
+	if err != nil {
 		er := models.LicenseError{
-			Status:    http.StatusNotFound,
-			Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligations",
 			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusNotFound, er)
+		c.JSON(http.StatusInternalServerError, er)
 		return
 	}
-	res := models.ObligationResponse{
-		Data:   []models.Obligation{obligation},
-		Status: http.StatusOK,
-		Meta: &models.PaginationMeta{
-			ResourceCount: 1,
-		},
+	defer rows.Close()
+
+	middleware.StreamResponse(c)
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for rows.Next() {
+		var obligation models.Obligation
+		if err := db.DB.ScanRows(rows, &obligation); err != nil {
+			// The status is already sent, so the client sees a truncated stream
+			_ = c.Error(err)
+			return
+		}
+		// ScanRows does not run the hooks decrypting the obligation
+		if err := obligation.AfterFind(db.DB); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		var line interface{} = &obligation
+		if len(fields) != 0 {
+			if line, err = projectObligation(&obligation, fields); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		if err := encoder.Encode(line); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Writer.Flush()
+	}
+	if err := rows.Err(); err != nil {
+		_ = c.Error(err)
 	}
-	c.JSON(http.StatusOK, res)
 }
 
-// CreateObligation creates a new obligation record and associates it with relevant licenses.
+// GetObligationTypes retrieves the allowed obligation types
 //
-//	@Summary		Create an obligation
-//	@Description	Create an obligation and associate it with licenses
-//	@Id				CreateObligation
+//	@Summary		Get obligation types
+//	@Description	Get the allowed values of the type of an obligation, configured by OBLIGATION_TYPES
+//	@Id				GetObligationTypes
 //	@Tags			Obligations
 //	@Accept			json
 //	@Produce		json
-//	@Param			obligation	body		models.ObligationPOSTRequestJSONSchema	true	"Obligation to create"
-//	@Success		201			{object}	models.ObligationResponse
-//	@Failure		400			{object}	models.LicenseError	"Bad request body"
-//	@Failure		409			{object}	models.LicenseError	"Obligation with same body exists"
-//	@Failure		500			{object}	models.LicenseError	"Unable to create obligation"
+//	@Success		200	{object}	models.ObligationTypesResponse
+//	@Failure		401	{object}	models.LicenseError	"Authentication needed"
+//	@Failure		403	{object}	models.LicenseError	"User is not an admin"
 //	@Security		ApiKeyAuth
-//	@Router			/obligations [post]
-func CreateObligation(c *gin.Context) {
-	var input models.ObligationPOSTRequestJSONSchema
+//	@Router			/obligations/types [get]
+func GetObligationTypes(c *gin.Context) {
+	res := models.ObligationTypesResponse{
+		Data:   utils.ObligationTypes(),
+		Status: http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
+// GetObligationLengthDistribution counts the obligations by the length of their text
+//
+//	@Summary		Get obligation text length distribution
+//	@Description	Count the obligations by the length of their text in buckets, e.g. to spot stub or
+//	@Description	accidentally huge obligations. The buckets are given by their ascending upper bounds,
+//	@Description	with a last bucket for the longer texts.
+//	@Id				GetObligationLengthDistribution
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			buckets	query		string	false	"Comma separated ascending upper bounds of the buckets"	default(50,500,2000)
+//	@Param			active	query		bool	false	"Active obligation only"								default(true)
+//	@Param			status	query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationLengthDistributionResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid buckets, active or status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch obligation lengths"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/length-distribution [get]
+func GetObligationLengthDistribution(c *gin.Context) {
+	active := c.Query("active")
+	if active == "" {
+		active = "true"
+	}
+	parsedActive, err := strconv.ParseBool(active)
+	if err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusBadRequest,
-			Message:   "invalid json body",
-			Error:     err.Error(),
+			Message:   "Invalid active value",
+			Error:     fmt.Sprintf("Parsing failed for value '%s'", active),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
 		c.JSON(http.StatusBadRequest, er)
 		return
 	}
-	s := input.Text
-	hash := md5.Sum([]byte(s))
-	md5hash := hex.EncodeToString(hash[:])
 
-	obligation := models.Obligation{
-		Md5:            md5hash,
-		Type:           input.Type,
-		Topic:          input.Topic,
-		Text:           input.Text,
-		Classification: input.Classification,
-		Comment:        input.Comment,
-		Modifications:  input.Modifications,
-		Active:         input.Active,
-		TextUpdatable:  false,
-	}
-
-	result := db.DB.
-		Where(&models.Obligation{Topic: obligation.Topic}).
-		Or(&models.Obligation{Md5: obligation.Md5}).
-		FirstOrCreate(&obligation)
-
-	if result.RowsAffected == 0 {
+	buckets := c.DefaultQuery("buckets", DEFAULT_LENGTH_BUCKETS)
+	bounds, err := parseLengthBuckets(buckets)
+	if err != nil {
 		er := models.LicenseError{
-			Status:  http.StatusConflict,
-			Message: "can not create obligation with same topic or text",
-			Error: fmt.Sprintf("Error: Obligation with topic '%s' or Text '%s'... already exists",
-				obligation.Topic, obligation.Text[0:10]),
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid buckets value",
+			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusConflict, er)
+		c.JSON(http.StatusBadRequest, er)
 		return
 	}
-	if result.Error != nil {
+
+	// The bucket of every obligation is its index in the bounds, or the number of
+	// bounds if its text is longer
+	bucket := "CASE"
+	args := make([]interface{}, 0, len(bounds))
+	for i, bound := range bounds {
+		bucket += fmt.Sprintf(" WHEN LENGTH(text) < ? THEN %d", i)
+		args = append(args, bound)
+	}
+	bucket += fmt.Sprintf(" ELSE %d END", len(bounds))
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	query := db.DB.WithContext(c).Model(&models.Obligation{}).
+		Select(bucket+" AS bucket, COUNT(*) AS count", args...)
+	filterActiveObligations(query, parsedActive)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Group("bucket").Scan(&rows).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusInternalServerError,
-			Message:   "Failed to create obligation",
-			Error:     result.Error.Error(),
+			Message:   "Unable to fetch obligation lengths",
+			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
 		c.JSON(http.StatusInternalServerError, er)
 		return
 	}
-	for _, i := range input.Shortnames {
-		var license models.LicenseDB
-		db.DB.Where(models.LicenseDB{Shortname: &i}).Find(&license)
-		obmap := models.ObligationMap{
-			ObligationPk: obligation.Id,
-			RfPk:         license.Id,
+
+	distribution := make([]models.ObligationLengthBucket, len(bounds)+1)
+	for i := range distribution {
+		if i > 0 {
+			distribution[i].Min = bounds[i-1]
 		}
-		db.DB.Create(&obmap)
+		if i < len(bounds) {
+			distribution[i].Max = &bounds[i]
+		}
+	}
+	for _, row := range rows {
+		distribution[row.Bucket].Count = row.Count
 	}
 
-	res := models.ObligationResponse{
-		Data:   []models.Obligation{obligation},
-		Status: http.StatusCreated,
-		Meta: &models.PaginationMeta{
-			ResourceCount: 1,
-		},
+	res := models.ObligationLengthDistributionResponse{
+		Status: http.StatusOK,
+		Data:   distribution,
 	}
+	c.JSON(http.StatusOK, res)
+}
 
-	c.JSON(http.StatusCreated, res)
+// parseLengthBuckets parses the comma separated upper bounds of the text length buckets,
+// which must be positive and ascending.
+func parseLengthBuckets(buckets string) ([]int, error) {
+	var bounds []int
+	for _, value := range strings.Split(buckets, ",") {
+		bound, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || bound <= 0 {
+			return nil, fmt.Errorf("bucket bound '%s' is not a positive integer", value)
+		}
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("bucket bounds must be ascending, got %d after %d", bound, bounds[len(bounds)-1])
+		}
+		bounds = append(bounds, bound)
+	}
+	if len(bounds) > MAX_LENGTH_BUCKETS {
+		return nil, fmt.Errorf("at most %d bucket bounds can be given", MAX_LENGTH_BUCKETS)
+	}
+	return bounds, nil
 }
 
-// UpdateObligation updates an existing active obligation record
+// GetObligation retrieves an active obligation record
 //
-//	@Summary		Update obligation
-//	@Description	Update an existing obligation record
-//	@Id				UpdateObligation
+//	@Summary		Get an obligation
+//	@Description	Get an active based on given topic. The text is translated to the language requested
+//	@Description	by lang or Accept-Language when a translation exists, else the canonical text is returned.
+//	@Description	A former topic of a renamed obligation is redirected to its current topic. Reads of
+//	@Description	sensitive obligations are logged when OBLIGATION_READ_AUDIT_ENABLED is set. With
+//	@Description	Accept: text/plain, only the text of the obligation is returned. If there is no obligation
+//	@Description	with the topic, the closest existing topics are suggested. The lock of an obligation
+//	@Description	under review is returned with the obligation until it is released or expires.
+//	@Id				GetObligation
 //	@Tags			Obligations
 //	@Accept			json
-//	@Produce		json
-//	@Param			topic		path		string									true	"Topic of the obligation to be updated"
-//	@Param			obligation	body		models.ObligationPATCHRequestJSONSchema	true	"Obligation to be updated"
-//	@Success		200			{object}	models.ObligationResponse
-//	@Failure		400			{object}	models.LicenseError	"Invalid request"
-//	@Failure		404			{object}	models.LicenseError	"No obligation with given topic found"
-//	@Failure		500			{object}	models.LicenseError	"Unable to update obligation"
-//	@Security		ApiKeyAuth
-//	@Router			/obligations/{topic} [patch]
-func UpdateObligation(c *gin.Context) {
-	_ = db.DB.Transaction(func(tx *gorm.DB) error {
-		var updates models.ObligationPATCHRequestJSONSchema
-		var oldObligation models.Obligation
-		newObligationMap := make(map[string]interface{})
-		username := c.GetString("username")
-		tp := c.Param("topic")
-
-		if err := tx.Model(&oldObligation).Where(models.Obligation{Topic: tp}).First(&oldObligation).Error; err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusNotFound,
-				Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.JSON(http.StatusNotFound, er)
-			return err
-		}
-
-		if err := c.ShouldBindJSON(&updates); err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusBadRequest,
-				Message:   "invalid json body",
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.JSON(http.StatusBadRequest, er)
-			return err
-		}
-
-		if updates.Text.IsDefined {
-			if updates.Text.Value == "" {
-				er := models.LicenseError{
-					Status:    http.StatusBadRequest,
-					Message:   "Text cannot be an empty string",
-					Error:     "invalid request",
-					Path:      c.Request.URL.Path,
-					Timestamp: time.Now().Format(time.RFC3339),
-				}
-				c.JSON(http.StatusBadRequest, er)
-				return errors.New("invalid request")
-			}
-
-			updatedHash := md5.Sum([]byte(updates.Text.Value))
-			updatedMd5hash := hex.EncodeToString(updatedHash[:])
-			if !oldObligation.TextUpdatable {
-				if updatedMd5hash != oldObligation.Md5 {
-					er := models.LicenseError{
-						Status:    http.StatusBadRequest,
-						Message:   "Can not update obligation text",
-						Error:     "invalid request",
-						Path:      c.Request.URL.Path,
-						Timestamp: time.Now().Format(time.RFC3339),
-					}
-					c.JSON(http.StatusBadRequest, er)
-					return errors.New("invalid request")
-				}
-			}
-			newObligationMap["md5"] = updatedMd5hash
-			newObligationMap["text"] = updates.Text.Value
-		}
-
-		if updates.Type.IsDefined {
-			if updates.Type.Value == "" {
+//	@Produce		json,plain
+//	@Param			topic				path		string	true	"Topic of the obligation"
+//	@Param			lang				query		string	false	"Language of the text"																example(de)
+//	@Param			raw					query		bool	false	"false for the normalized text instead of the text as sent"							default(true)
+//	@Param			status				query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			Accept-Language		header		string	false	"Preferred languages of the text"
+//	@Param			X-Response-Envelope	header		string	false	"none for the obligations without the response envelope"	Enums(none)
+//	@Success		200					{object}	models.ObligationResponse
+//	@Success		301
+//	@Header			301	{string}	Location						"Path of the obligation under its current topic"
+//	@Failure		400	{object}	models.LicenseError				"Invalid language, raw or status value"
+//	@Failure		401	{object}	models.LicenseError				"status without valid credentials"
+//	@Failure		403	{object}	models.LicenseError				"status by a non reviewer"
+//	@Failure		404	{object}	models.ObligationNotFoundError	"No published obligation with given topic found, with the closest topics"
+//	@Failure		406	{object}	models.LicenseError				"Accept allows neither json nor plain text"
+//	@Failure		410	{object}	models.LicenseError				"Former topic of an obligation which is deactivated"
+//	@Failure		500	{object}	models.LicenseError				"Unable to fetch translation or lock, or log the access"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic} [get]
+func GetObligation(c *gin.Context) {
+	var obligation models.Obligation
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	query = query.Session(&gorm.Session{})
+	tp := c.Param("topic")
+	if err := query.Where(models.Obligation{Topic: tp}).First(&obligation).Error; err != nil {
+		// Former topics of renamed obligations are redirected to their current topic, if the
+		// renamed obligation is found as it would be by its current topic
+		var redirect models.ObligationTopicRedirect
+		var renamed models.Obligation
+		if db.DB.WithContext(c).Where(models.ObligationTopicRedirect{Topic: tp}).First(&redirect).Error == nil &&
+			query.Where("obligations.id = ?", redirect.ObligationPk).First(&renamed).Error == nil {
+			if !renamed.Active {
 				er := models.LicenseError{
-					Status:    http.StatusBadRequest,
-					Message:   "Type cannot be an empty string",
-					Error:     "invalid request",
+					Status:    http.StatusGone,
+					Message:   fmt.Sprintf("obligation with topic '%s' was renamed and is deactivated", tp),
+					Error:     fmt.Sprintf("obligation '%s' is deactivated", renamed.Topic),
 					Path:      c.Request.URL.Path,
 					Timestamp: time.Now().Format(time.RFC3339),
 				}
-				c.JSON(http.StatusBadRequest, er)
-				return errors.New("invalid request")
+				c.JSON(http.StatusGone, er)
+				return
 			}
-			newObligationMap["type"] = updates.Type.Value
-		}
-
-		if updates.Classification.IsDefined {
-			if updates.Classification.Value == "" {
-				er := models.LicenseError{
-					Status:    http.StatusBadRequest,
-					Message:   "Classification cannot be an empty string",
-					Error:     "invalid request",
-					Path:      c.Request.URL.Path,
-					Timestamp: time.Now().Format(time.RFC3339),
-				}
-				c.JSON(http.StatusBadRequest, er)
-				return errors.New("invalid request")
+			location := strings.TrimSuffix(c.FullPath(), ":topic") + url.PathEscape(renamed.Topic)
+			if c.Request.URL.RawQuery != "" {
+				location += "?" + c.Request.URL.RawQuery
 			}
-			newObligationMap["classification"] = updates.Classification.Value
-		}
-
-		if updates.Modifications.IsDefined {
-			newObligationMap["modifications"] = updates.Modifications.Value
+			c.Redirect(http.StatusMovedPermanently, location)
+			return
 		}
-
-		if updates.Comment.IsDefined {
-			newObligationMap["comment"] = updates.Comment.Value
+		er := models.ObligationNotFoundError{
+			Status:      http.StatusNotFound,
+			Message:     fmt.Sprintf("obligation with topic '%s' not found", tp),
+			Error:       err.Error(),
+			Suggestions: suggestObligationTopics(c, tp),
+			Path:        c.Request.URL.Path,
+			Timestamp:   time.Now().Format(time.RFC3339),
 		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
 
-		if updates.Active.IsDefined {
-			newObligationMap["active"] = updates.Active.Value
-		}
+	writeObligation(c, &obligation)
+}
 
-		if updates.TextUpdatable.IsDefined {
-			newObligationMap["text_updatable"] = updates.TextUpdatable.Value
-		}
+// suggestObligationTopics returns up to TOPIC_SUGGESTION_COUNT existing topics closest to
+// the given one by their Levenshtein distance, closest first. Topics which differ in more
+// than half of their characters are not suggested. At most MAX_TOPIC_SUGGESTION_CANDIDATES
+// topics are compared, so the suggestions stay cheap on large datasets.
+func suggestObligationTopics(c *gin.Context, topic string) []string {
+	suggestions := []string{}
+	var topics []string
+	if err := db.DB.WithContext(c).Model(&models.Obligation{}).
+		Where(models.Obligation{Status: models.OBLIGATION_STATUS_PUBLISHED}).Order("id").
+		Limit(MAX_TOPIC_SUGGESTION_CANDIDATES).Pluck("topic", &topics).Error; err != nil {
+		return suggestions
+	}
 
-		var newObligation models.Obligation
-		newObligation.Id = oldObligation.Id
-		if err := tx.Model(&newObligation).Clauses(clause.Returning{}).Updates(newObligationMap).Error; err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusInternalServerError,
-				Message:   "Failed to update license",
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.JSON(http.StatusInternalServerError, er)
-			return err
+	type candidate struct {
+		topic    string
+		distance int
+	}
+	var candidates []candidate
+	lowerTopic := strings.ToLower(topic)
+	for _, t := range topics {
+		distance := utils.LevenshteinDistance(lowerTopic, strings.ToLower(t))
+		longest := utf8.RuneCountInString(lowerTopic)
+		if n := utf8.RuneCountInString(t); n > longest {
+			longest = n
 		}
-
-		if err := addChangelogsForObligationUpdate(tx, username, &newObligation, &oldObligation); err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusInternalServerError,
-				Message:   "Failed to update license",
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.JSON(http.StatusInternalServerError, er)
-			return err
+		if distance*2 > longest {
+			continue
 		}
-
-		res := models.ObligationResponse{
-			Data:   []models.Obligation{newObligation},
-			Status: http.StatusOK,
-			Meta: &models.PaginationMeta{
-				ResourceCount: 1,
-			},
+		candidates = append(candidates, candidate{topic: t, distance: distance})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
 		}
-		c.JSON(http.StatusOK, res)
-
-		return nil
+		return candidates[i].topic < candidates[j].topic
 	})
+	for i := 0; i < len(candidates) && i < TOPIC_SUGGESTION_COUNT; i++ {
+		suggestions = append(suggestions, candidates[i].topic)
+	}
+	return suggestions
 }
 
-// DeleteObligation marks an existing obligation record as inactive
+// HeadObligation checks whether an obligation exists
 //
-//	@Summary		Deactivate obligation
-//	@Description	Deactivate an obligation
-//	@Id				DeleteObligation
+//	@Summary		Check an obligation
+//	@Description	Get the status and headers of the obligation with the given topic as with the GET request,
+//	@Description	without the body, e.g. to check that the obligation exists.
+//	@Id				HeadObligation
+//	@Tags			Obligations
+//	@Param			topic	path	string	true	"Topic of the obligation"
+//	@Success		200
+//	@Success		301
+//	@Header			301	{string}	Location	"Path of the obligation under its current topic"
+//	@Failure		404
+//	@Failure		410
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic} [head]
+func HeadObligation(c *gin.Context) {
+	GetObligation(c)
+}
+
+// GetObligationById retrieves an obligation record by its id
+//
+//	@Summary		Get an obligation by id
+//	@Description	Get an obligation by its id, which unlike its topic never changes. The text is translated
+//	@Description	and the text alone is returned with Accept: text/plain as for the lookup by topic.
+//	@Id				GetObligationById
 //	@Tags			Obligations
 //	@Accept			json
-//	@Produce		json
-//	@Param			topic	path	string	true	"Topic of the obligation to be updated"
-//	@Success		204
-//	@Failure		404	{object}	models.LicenseError	"No obligation with given topic found"
-//	@Security		ApiKeyAuth
-//	@Router			/obligations/{topic} [delete]
-func DeleteObligation(c *gin.Context) {
+//	@Produce		json,plain
+//	@Param			id					path		int		true	"Id of the obligation"
+//	@Param			lang				query		string	false	"Language of the text"																example(de)
+//	@Param			raw					query		bool	false	"false for the normalized text instead of the text as sent"							default(true)
+//	@Param			status				query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			Accept-Language		header		string	false	"Preferred languages of the text"
+//	@Param			X-Response-Envelope	header		string	false	"none for the obligations without the response envelope"	Enums(none)
+//	@Success		200					{object}	models.ObligationResponse
+//	@Failure		400					{object}	models.LicenseError	"Invalid id, language, raw or status value"
+//	@Failure		401					{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403					{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404					{object}	models.LicenseError	"No published obligation with given id found"
+//	@Failure		406					{object}	models.LicenseError	"Accept allows neither json nor plain text"
+//	@Failure		500					{object}	models.LicenseError	"Unable to fetch translation or log the access"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/id/{id} [get]
+func GetObligationById(c *gin.Context) {
 	var obligation models.Obligation
-	tp := c.Param("topic")
-	if err := db.DB.Where(models.Obligation{Topic: tp}).First(&obligation).Error; err != nil {
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if !findObligationById(c, query, &obligation) {
+		return
+	}
+	writeObligation(c, &obligation)
+}
+
+// findObligationById fetches the obligation with the id of the path parameter by the
+// query, else it writes the error response.
+func findObligationById(c *gin.Context, query *gorm.DB, obligation *models.Obligation) bool {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid id value",
+			Error:     fmt.Sprintf("id must be a positive integer, got '%s'", c.Param("id")),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return false
+	}
+	// A struct condition would drop the zero id, so the id is compared explicitly
+	if err := query.Where("obligations.id = ?", id).First(obligation).Error; err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusNotFound,
-			Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+			Message:   fmt.Sprintf("obligation with id %d not found", id),
 			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
 		c.JSON(http.StatusNotFound, er)
-		return
+		return false
 	}
-	obligation.Active = false
-	db.DB.Where(models.Obligation{Topic: tp}).Save(&obligation)
-	c.Status(http.StatusNoContent)
+	return true
 }
 
-// GetObligationAudits fetches audits corresponding to an obligation
-
-// @Summary		Fetches audits corresponding to an obligation
-// @Description	Fetches audits corresponding to an obligation
-// @Id				GetObligationAudits
-// @Tags			Obligations
-// @Accept			json
-// @Produce		json
-// @Param			topic	path		string	true	"Topic of the obligation for which audits need to be fetched"
-// @Param			page	query		int		false	"Page number"
-// @Param			limit	query		int		false	"Number of records per page"
-// @Success		200		{object}	models.AuditResponse
-// @Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
-// @Failure		500		{object}	models.LicenseError	"unable to find audits with such obligation topic"
-//
-//	@Security		ApiKeyAuth || {}
-//
-// @Router			/obligations/{topic}/audits [get]
-func GetObligationAudits(c *gin.Context) {
-	var obligation models.Obligation
-	topic := c.Param("topic")
-
-	result := db.DB.Where(models.Obligation{Topic: topic}).Select("id").First(&obligation)
-	if result.Error != nil {
+// writeObligation writes the obligation of a GET request, translated to the language
+// requested by the client if a translation exists. Clients accepting text/plain but not
+// json get only the text of the obligation.
+func writeObligation(c *gin.Context, obligation *models.Obligation) {
+	format := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain)
+	if format == "" {
 		er := models.LicenseError{
-			Status:    http.StatusNotFound,
-			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
-			Error:     result.Error.Error(),
+			Status:    http.StatusNotAcceptable,
+			Message:   "unsupported Accept value",
+			Error:     fmt.Sprintf("Accept must allow %s or %s, got '%s'", gin.MIMEJSON, gin.MIMEPlain, c.GetHeader("Accept")),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusNotFound, er)
+		c.JSON(http.StatusNotAcceptable, er)
 		return
 	}
 
-	var audits []models.Audit
-	query := db.DB.Model(&models.Audit{})
-	query.Where(models.Audit{TypeId: obligation.Id, Type: "Obligation"})
-	_ = utils.PreparePaginateResponse(c, query, &models.AuditResponse{})
-
-	res := query.Find(&audits)
-	if res.Error != nil {
+	languages := utils.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if lang := c.Query("lang"); lang != "" {
+		language, err := utils.NormalizeLanguage(lang)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "invalid language",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		languages = []string{language}
+	}
+	language, err := translateObligation(obligation, languages)
+	if err != nil {
 		er := models.LicenseError{
 			Status:    http.StatusInternalServerError,
-			Message:   "unable to find audits with such obligation topic",
-			Error:     res.Error.Error(),
+			Message:   "Unable to fetch translation",
+			Error:     err.Error(),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
 		c.JSON(http.StatusInternalServerError, er)
 		return
 	}
+	if language != "" {
+		c.Header("Content-Language", language)
+	}
 
-	response := models.AuditResponse{
-		Data:   audits,
-		Status: http.StatusOK,
-		Meta: &models.PaginationMeta{
-			ResourceCount: len(audits),
+	if err := logObligationAccess(c, obligation); err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to log access to the obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	lock, err := findObligationLock(db.DB.WithContext(c), obligation.Id)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch the lock of the obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	obligation.Lock = lock
+
+	res := models.ObligationResponse{
+		Data:   []models.Obligation{*obligation},
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
 		},
 	}
+	c.Header("Last-Modified", obligation.UpdatedAt.UTC().Format(http.TimeFormat))
+	if format == gin.MIMEPlain {
+		if normalizeObligationTexts(c, res.Data) {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(res.Data[0].Text))
+		}
+		return
+	}
+	writeObligationResponse(c, res, true)
+}
 
-	c.JSON(http.StatusOK, response)
+// writeObligationResponse writes the obligations of a GET request in an
+// ObligationResponse. With raw=false, the normalized texts are written instead of
+// the texts as sent. If the client sent X-Response-Envelope: none, the obligations
+// are written without the envelope, a single obligation as an object, else as an
+// array with the total count in the X-Total-Count header.
+func writeObligationResponse(c *gin.Context, res models.ObligationResponse, single bool) {
+	if !normalizeObligationTexts(c, res.Data) {
+		return
+	}
+
+	if c.GetHeader("X-Response-Envelope") != "none" {
+		c.JSON(http.StatusOK, res)
+		return
+	}
+	if single {
+		c.JSON(http.StatusOK, res.Data[0])
+		return
+	}
+
+	// The bare array must not be processed by the pagination middleware
+	if paginationMeta, ok := c.Get("paginationMeta"); ok {
+		c.Header("X-Total-Count", strconv.Itoa(paginationMeta.(models.PaginationMeta).ResourceCount))
+	}
+	middleware.StreamResponse(c)
+	c.JSON(http.StatusOK, res.Data)
 }
 
-// ImportObligations creates new obligation records via a json file.
+// normalizeObligationTexts replaces the texts of the obligations by their normalized
+// texts if the request has raw=false. It returns false after writing the error response
+// if raw is invalid.
+func normalizeObligationTexts(c *gin.Context, obligations []models.Obligation) bool {
+	if raw := c.Query("raw"); raw != "" {
+		parsedRaw, err := strconv.ParseBool(raw)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid raw value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", raw),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return false
+		}
+		// translateObligation normalizes the texts it translates
+		if !parsedRaw {
+			for i := range obligations {
+				obligations[i].Text = obligations[i].NormalizedText
+			}
+		}
+	}
+	return true
+}
+
+// CreateObligation creates a new obligation record and associates it with relevant licenses.
 //
-//	@Summary		Import obligations by uploading a json file
-//	@Description	Import obligations by uploading a json file
-//	@Id				ImportObligations
+//	@Summary		Create an obligation
+//	@Description	Create an obligation and associate it with licenses. Without shortnames, a standalone
+//	@Description	obligation is created. Empty shortnames are skipped. Shortnames which do not match a
+//	@Description	license are rejected, unless createMissingLicenses is set. Then a stub license flagged
+//	@Description	as auto_created is created for each of them. With dryRun, only validate the obligation
+//	@Description	and report what would happen without creating it. Admins can backdate the obligation
+//	@Description	by a past created_at, which is audited. Users may create OBLIGATION_CREATION_QUOTA
+//	@Description	obligations per day, admins OBLIGATION_CREATION_QUOTA_ADMIN, where 0 is unlimited.
+//	@Description	With expected_md5, the md5 of the canonical text must match it, else the text was altered
+//	@Description	in transit and the obligation is rejected.
+//	@Id				CreateObligation
 //	@Tags			Obligations
-//	@Accept			multipart/form-data
+//	@Accept			json
 //	@Produce		json
-//	@Param			file	formData	file	true	"obligations json file list"
-//	@Success		200		{object}	models.ImportObligationsResponse{data=[]models.ObligationImportStatus}
-//	@Failure		400		{object}	models.LicenseError	"input file must be present"
-//	@Failure		500		{object}	models.LicenseError	"Internal server error"
+//	@Param			obligation				body		models.ObligationPOSTRequestJSONSchema	true	"Obligation to create"
+//	@Param			dryRun					query		bool									false	"Validate without creating"
+//	@Param			createMissingLicenses	query		bool									false	"Create stub licenses for unknown shortnames"
+//	@Success		200						{object}	models.ObligationDryRunResponse			"Result of dry run"
+//	@Success		201						{object}	models.ObligationResponse
+//	@Failure		400						{object}	models.ValidationError			"Malformed request body, invalid query parameter or expected_md5 mismatch"
+//	@Failure		403						{object}	models.LicenseError				"created_at by a non admin user"
+//	@Failure		409						{object}	models.ObligationConflictError	"Obligation with same topic or text exists, whose id and topic are returned, with a diff if its text differs"
+//	@Failure		422						{object}	models.ValidationError			"Invalid obligation, unknown or too many shortnames, or future created_at"
+//	@Failure		429						{object}	models.LicenseError				"Daily obligation creation quota exceeded"
+//	@Failure		500						{object}	models.LicenseError				"Unable to create obligation"
+//	@Failure		504						{object}	models.LicenseError				"Checking or creating the obligation timed out"
 //	@Security		ApiKeyAuth
-//	@Router			/obligations/import [post]
-func ImportObligations(c *gin.Context) {
-	username := c.GetString("username")
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		er := models.LicenseError{
-			Status:    http.StatusBadRequest,
-			Message:   "input file must be present",
+//	@Router			/obligations [post]
+func CreateObligation(c *gin.Context) {
+	var input models.ObligationPOSTRequestJSONSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		status := utils.BindErrorStatus(err)
+		er := models.ValidationError{
+			Status:    status,
+			Message:   "invalid json body",
 			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusBadRequest, er)
+		c.JSON(status, er)
 		return
 	}
-	defer file.Close()
 
-	if filepath.Ext(header.Filename) != ".json" {
-		er := models.LicenseError{
-			Status:    http.StatusBadRequest,
-			Message:   "only files with format *.json are allowed",
-			Error:     "only files with format *.json are allowed",
+	// Empty shortnames are skipped, so an omitted or empty list creates a
+	// standalone obligation without any obligation maps
+	shortnames := []string{}
+	for _, shortname := range input.Shortnames {
+		if shortname != "" {
+			shortnames = append(shortnames, shortname)
+		}
+	}
+	input.Shortnames = shortnames
+
+	maxShortnames, err := strconv.Atoi(os.Getenv("MAX_OBLIGATION_SHORTNAMES"))
+	if err != nil || maxShortnames <= 0 {
+		maxShortnames = DEFAULT_MAX_OBLIGATION_SHORTNAMES
+	}
+	if len(input.Shortnames) > maxShortnames {
+		er := models.ValidationError{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "invalid json body",
+			Error:   fmt.Sprintf("shortnames can have at most %d elements", maxShortnames),
+			Errors: []models.FieldError{
+				{
+					Field:   "shortnames",
+					Rule:    "max",
+					Message: fmt.Sprintf("shortnames must be at most %d", maxShortnames),
+				},
+			},
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusBadRequest, er)
+		c.JSON(http.StatusUnprocessableEntity, er)
 		return
 	}
 
-	var obligations []models.ObligationJSONFileFormat
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&obligations); err != nil {
-		er := models.LicenseError{
-			Status:    http.StatusInternalServerError,
-			Message:   "invalid json",
-			Error:     err.Error(),
+	if input.ExpectedMd5 != "" && !strings.EqualFold(input.ExpectedMd5, utils.ObligationTextMD5(input.Text)) {
+		er := models.ValidationError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid json body",
+			Error:   "the md5 of the text does not match expected_md5, the text may have been altered in transit",
+			Errors: []models.FieldError{
+				{
+					Field:   "expected_md5",
+					Rule:    "md5",
+					Message: "expected_md5 must be the md5 of the canonical text",
+				},
+			},
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusInternalServerError, er)
+		c.JSON(http.StatusBadRequest, er)
 		return
 	}
 
-	res := models.ImportObligationsResponse{
-		Status: http.StatusOK,
+	normalizedText := utils.NormalizeObligationText(input.Text)
+	textHash := utils.NormalizedObligationTextHash(normalizedText)
+
+	obligation := models.Obligation{
+		TextHash:       textHash,
+		Type:           input.Type,
+		Topic:          input.Topic,
+		Text:           input.Text,
+		NormalizedText: normalizedText,
+		Classification: input.Classification,
+		Comment:        input.Comment,
+		Modifications:  input.Modifications,
+		Active:         input.Active,
+		TextUpdatable:  false,
+		Sensitive:      input.Sensitive,
+		Status:         models.OBLIGATION_STATUS_DRAFT,
+		EffectiveFrom:  input.EffectiveFrom,
+		EffectiveUntil: input.EffectiveUntil,
 	}
 
-	for _, obligation := range obligations {
-		_ = db.DB.Transaction(func(tx *gorm.DB) error {
-			ob := models.Obligation{
-				Topic:          obligation.Topic,
-				Type:           obligation.Type,
-				Text:           obligation.Text,
-				Classification: obligation.Classification,
-				Modifications:  obligation.Modifications,
-				Comment:        obligation.Comment,
-				Active:         obligation.Active,
-				TextUpdatable:  obligation.TextUpdatable,
+	if err := validateEffectiveWindow(obligation.EffectiveFrom, obligation.EffectiveUntil); err != nil {
+		invalidEffectiveWindowError(c, err)
+		return
+	}
+
+	if strings.HasPrefix(obligation.Comment, models.ENCRYPTED_FIELD_PREFIX) {
+		invalidObligationCommentError(c)
+		return
+	}
+
+	// Only admins may backdate obligations, e.g. when migrating historical ones
+	recordedAt := time.Now()
+	if input.CreatedAt != nil {
+		if !middleware.AuthorizeUserlevel(c, "admin") {
+			return
+		}
+		if input.CreatedAt.After(recordedAt) {
+			er := models.ValidationError{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "invalid json body",
+				Error:   fmt.Sprintf("created_at '%s' is in the future", input.CreatedAt.Format(time.RFC3339)),
+				Errors: []models.FieldError{
+					{
+						Field:   "created_at",
+						Rule:    "past",
+						Message: "created_at must not be in the future",
+					},
+				},
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusUnprocessableEntity, er)
+			return
+		}
+		obligation.CreatedAt = *input.CreatedAt
+	}
+
+	createMissing := false
+	if createMissingLicenses := c.Query("createMissingLicenses"); createMissingLicenses != "" {
+		parsedCreateMissing, err := strconv.ParseBool(createMissingLicenses)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid createMissingLicenses value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", createMissingLicenses),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
 			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		createMissing = parsedCreateMissing
+	}
 
-			hash := md5.Sum([]byte(ob.Text))
-			md5hash := hex.EncodeToString(hash[:])
-			ob.Md5 = md5hash
+	if dryRun := c.Query("dryRun"); dryRun != "" {
+		parsedDryRun, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid dryRun value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", dryRun),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		if parsedDryRun {
+			createObligationDryRun(c, &obligation, input.Shortnames, createMissing)
+			return
+		}
+	}
 
-			oldObligation := ob
-			result := tx.
-				Where(&models.Obligation{Topic: ob.Topic}).
-				Or(&models.Obligation{Md5: ob.Md5}).
-				FirstOrCreate(&oldObligation)
-			if result.Error != nil {
-				res.Data = append(res.Data, models.LicenseError{
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		conflict, _, err := obligationCreationConflict(c, tx, &obligation)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to create obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		if conflict != nil {
+			c.JSON(http.StatusConflict, conflict)
+			return errors.New("obligation already exists")
+		}
+
+		if err := tx.Create(&obligation).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to create obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if err := consumeObligationCreationQuota(tx, c.GetString("username"), 1); err != nil {
+			if errors.Is(err, errObligationQuotaExceeded) {
+				writeObligationQuotaExceeded(c, err)
+				return err
+			}
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to create obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		unknown, err := unknownShortnames(tx, input.Shortnames)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to resolve license shortnames",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		if len(unknown) != 0 && !createMissing {
+			er := models.ValidationError{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "invalid json body",
+				Error:   fmt.Sprintf("no licenses with shortnames [%s]", strings.Join(unknown, " ")),
+				Errors: []models.FieldError{
+					{
+						Field:   "shortnames",
+						Rule:    "exists",
+						Message: fmt.Sprintf("shortnames must be existing licenses, unknown are [%s]", strings.Join(unknown, " ")),
+					},
+				},
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusUnprocessableEntity, er)
+			return errors.New("unknown shortnames")
+		}
+		if err := createLicenseStubs(tx, unknown); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to create missing licenses",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if err := createObligationMaps(tx, obligation.Id, input.Shortnames); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to associate obligation with licenses",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if input.CreatedAt != nil {
+			if err := addChangelogForCreatedAtOverride(tx, c.GetString("username"), &obligation, recordedAt); err != nil {
+				er := models.LicenseError{
 					Status:    http.StatusInternalServerError,
-					Message:   fmt.Sprintf("Failed to create obligation: %s", result.Error.Error()),
-					Error:     ob.Topic,
+					Message:   "Failed to audit the overridden creation date",
+					Error:     err.Error(),
 					Path:      c.Request.URL.Path,
 					Timestamp: time.Now().Format(time.RFC3339),
-				})
-				return err
-			} else if result.RowsAffected == 0 {
-				// case when obligation exists in database and is updated
-				result := tx.Model(&ob).Clauses(clause.Returning{}).Where(&models.Obligation{Topic: ob.Topic}).Updates(&ob)
-				if result.Error != nil {
-					res.Data = append(res.Data, models.LicenseError{
-						Status:    http.StatusInternalServerError,
-						Message:   fmt.Sprintf("Failed to update obligation: %s", result.Error.Error()),
-						Error:     ob.Topic,
-						Path:      c.Request.URL.Path,
-						Timestamp: time.Now().Format(time.RFC3339),
-					})
-					return err
 				}
+				c.JSON(http.StatusInternalServerError, er)
+				return err
+			}
+		}
+
+		res := models.ObligationResponse{
+			Data:   []models.Obligation{obligation},
+			Status: http.StatusCreated,
+			Meta: &models.PaginationMeta{
+				ResourceCount: 1,
+			},
+		}
+
+		c.JSON(http.StatusCreated, res)
+		return nil
+	})
+}
+
+// obligationConflictDiff returns the differences of the text, classification and type
+// between the existing obligation and the one to create.
+func obligationConflictDiff(proposed, existing *models.Obligation) []models.ObligationFieldDiff {
+	diff := []models.ObligationFieldDiff{}
+	for _, change := range obligationChangeLogs(proposed, existing) {
+		if change.Field == "Text" || change.Field == "Classification" || change.Field == "Type" {
+			diff = append(diff, models.ObligationFieldDiff{
+				Field:    change.Field,
+				OldValue: change.OldValue,
+				NewValue: change.UpdatedValue,
+			})
+		}
+	}
+	return diff
+}
+
+// createObligationMaps maps the obligation to the licenses with given shortnames.
+// The licenses are looked up in a single query and the maps are inserted in
+// batches. Shortnames which do not match a license are ignored.
+func createObligationMaps(tx *gorm.DB, obligationId int64, shortnames []string) error {
+	if len(shortnames) == 0 {
+		return nil
+	}
+
+	var licenseIds []int64
+	if err := tx.Model(&models.LicenseDB{}).Where("rf_shortname IN ?", shortnames).
+		Pluck("rf_id", &licenseIds).Error; err != nil {
+		return err
+	}
+	if len(licenseIds) == 0 {
+		return nil
+	}
+
+	obligationMaps := make([]models.ObligationMap, 0, len(licenseIds))
+	for _, licenseId := range licenseIds {
+		obligationMaps = append(obligationMaps, models.ObligationMap{
+			ObligationPk: obligationId,
+			RfPk:         licenseId,
+		})
+	}
+
+	result := tx.CreateInBatches(&obligationMaps, OBLIGATION_MAP_BATCH_SIZE)
+	if result.Error != nil {
+		return result.Error
+	}
+	return adjustObligationLicenseCount(tx, obligationId, result.RowsAffected)
+}
+
+// unknownShortnames returns the shortnames which do not match a license.
+func unknownShortnames(tx *gorm.DB, shortnames []string) ([]string, error) {
+	if len(shortnames) == 0 {
+		return nil, nil
+	}
+
+	var knownShortnames []string
+	if err := tx.Model(&models.LicenseDB{}).Where("rf_shortname IN ?", shortnames).
+		Pluck("rf_shortname", &knownShortnames).Error; err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	for _, shortname := range shortnames {
+		if !slices.Contains(knownShortnames, shortname) && !slices.Contains(unknown, shortname) {
+			unknown = append(unknown, shortname)
+		}
+	}
+	return unknown, nil
+}
+
+// createLicenseStubs creates a minimal license for each of the shortnames. The
+// stubs are flagged as auto created, so they can be reviewed and completed later.
+func createLicenseStubs(tx *gorm.DB, shortnames []string) error {
+	if len(shortnames) == 0 {
+		return nil
+	}
+
+	autoCreated := true
+	stubs := make([]models.LicenseDB, 0, len(shortnames))
+	for _, shortname := range shortnames {
+		shortname := shortname
+		stubs = append(stubs, models.LicenseDB{
+			Shortname:   &shortname,
+			Fullname:    &shortname,
+			SpdxId:      &shortname,
+			Text:        func(s string) *string { return &s }(LICENSE_STUB_TEXT),
+			AutoCreated: &autoCreated,
+		})
+	}
+
+	return tx.Create(&stubs).Error
+}
+
+// obligationTextCollides reports whether the existing obligation has the same text hash
+// as the given text but a different normalized text. Such an obligation must not be
+// taken as a duplicate, as a crafted hash collision would otherwise let one text
+// overwrite or shadow another.
+func obligationTextCollides(existing *models.Obligation, text string) bool {
+	normalizedText := utils.NormalizeObligationText(text)
+	return existing.TextHash == utils.NormalizedObligationTextHash(normalizedText) &&
+		existing.NormalizedText != normalizedText
+}
+
+// truncateObligationText returns the text cut to at most length runes, so the text is
+// not cut in the middle of a multi byte character.
+func truncateObligationText(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length])
+}
+
+// createObligationDryRun runs the checks of the creation of the obligation and writes
+// what would happen, without creating it.
+func createObligationDryRun(c *gin.Context, obligation *models.Obligation, shortnames []string, createMissing bool) {
+	res := models.ObligationDryRunResponse{
+		Data:   []models.Obligation{*obligation},
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+		Outcome: models.DRY_RUN_CREATED,
+	}
+
+	tx := db.DB.WithContext(c)
+	conflict, existing, err := obligationCreationConflict(c, tx, obligation)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Failed to check for existing obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	if conflict != nil {
+		res.Data = []models.Obligation{*existing}
+		res.Outcome = models.DRY_RUN_CONFLICT
+		c.JSON(http.StatusOK, res)
+		return
+	}
+
+	unknown, err := unknownShortnames(tx, shortnames)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Failed to resolve license shortnames",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	// The unknown shortnames are listed too if stub licenses would be created for them
+	res.UnknownShortnames = unknown
+	if len(unknown) != 0 && !createMissing {
+		res.Outcome = models.DRY_RUN_UNKNOWN_SHORTNAMES
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// obligationCreationConflict checks whether the obligation can be created, which it can
+// not if its topic is reserved or an obligation with the same topic or text exists. It
+// returns the conflict along with the obligation conflicted with, or nil if there is none.
+func obligationCreationConflict(c *gin.Context, tx *gorm.DB, obligation *models.Obligation) (*models.ObligationConflictError, *models.Obligation, error) {
+	conflict := models.ObligationConflictError{
+		Status:    http.StatusConflict,
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	// Former topics of renamed obligations are reserved
+	reservedFor, err := topicReservedFor(tx, obligation.Topic)
+	if err != nil {
+		return nil, nil, err
+	}
+	var existing models.Obligation
+	if reservedFor != 0 {
+		if err := tx.First(&existing, reservedFor).Error; err != nil {
+			return nil, nil, err
+		}
+		conflict.Message = "can not create obligation with reserved topic"
+		conflict.Error = fmt.Sprintf("Error: Topic '%s' is the former topic of obligation '%s'", obligation.Topic, existing.Topic)
+		conflict.Existing = models.ObligationConflictExisting{Id: existing.Id, Topic: existing.Topic}
+		return &conflict, &existing, nil
+	}
+
+	err = tx.
+		Where(&models.Obligation{Topic: obligation.Topic}).
+		Or(&models.Obligation{TextHash: obligation.TextHash}).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflict.Existing = models.ObligationConflictExisting{Id: existing.Id, Topic: existing.Topic}
+	switch {
+	case obligationTextCollides(&existing, obligation.Text):
+		conflict.Message = "can not create obligation with colliding text hash"
+		conflict.Error = fmt.Sprintf("Error: Text of obligation '%s' has the same hash but a different content",
+			existing.Topic)
+	case existing.Topic == obligation.Topic && existing.TextHash != obligation.TextHash:
+		// The diff tells the caller whether to update the obligation instead
+		if obligationReadableBy(tx, &existing, c.GetString("username")) {
+			conflict.Diff = obligationConflictDiff(obligation, &existing)
+		}
+		conflict.Message = "can not create obligation with same topic and different text"
+		conflict.Error = fmt.Sprintf("Error: Obligation with topic '%s' already exists with a different text, update it instead",
+			existing.Topic)
+	default:
+		conflict.Message = "can not create obligation with same topic or text"
+		conflict.Error = fmt.Sprintf("Error: Obligation with topic '%s' or Text '%s'... already exists",
+			existing.Topic, truncateObligationText(existing.Text, 10))
+	}
+	return &conflict, &existing, nil
+}
+
+// CheckObligationDuplicates checks a batch of obligations for collisions with existing ones
+//
+//	@Summary		Check obligations for duplicates
+//	@Description	Check, for each given obligation, whether an obligation with the same topic or the same
+//	@Description	text (compared by hash) already exists among the published obligations, or among those
+//	@Description	of the status reviewers ask for. Nothing is written.
+//	@Id				CheckObligationDuplicates
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			obligations	body		[]models.ObligationDuplicateCheckInput	true	"Obligations to check"
+//	@Param			status		query		string									false	"Reviewers only, check against obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200			{object}	models.ObligationDuplicateCheckResponse
+//	@Failure		400			{object}	models.ValidationError	"Bad request body, too many obligations or invalid status value"
+//	@Failure		401			{object}	models.LicenseError		"status without valid credentials"
+//	@Failure		403			{object}	models.LicenseError		"status by a non reviewer"
+//	@Failure		500			{object}	models.LicenseError		"Unable to check obligations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/check-duplicates [post]
+func CheckObligationDuplicates(c *gin.Context) {
+	var input []models.ObligationDuplicateCheckInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	if len(input) > MAX_DUPLICATE_CHECK_BATCH_SIZE {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     fmt.Sprintf("at most %d obligations can be checked at once", MAX_DUPLICATE_CHECK_BATCH_SIZE),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	topics := make([]string, 0, len(input))
+	textHashes := make([]string, 0, len(input))
+	for _, ob := range input {
+		topics = append(topics, ob.Topic)
+		textHashes = append(textHashes, utils.ObligationTextHash(ob.Text))
+	}
+
+	var existing []models.Obligation
+	if len(input) != 0 {
+		query := db.DB.WithContext(c).Model(&models.Obligation{})
+		if !filterObligationStatus(c, query) {
+			return
+		}
+		if err := query.Select("topic", "text", "normalized_text", "md5").
+			Where(db.DB.Where("topic IN ?", topics).Or("md5 IN ?", textHashes)).Find(&existing).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to check obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	}
+
+	existingTopics := make(map[string]bool, len(existing))
+	obligationsByHash := make(map[string]models.Obligation, len(existing))
+	for _, ob := range existing {
+		existingTopics[ob.Topic] = true
+		obligationsByHash[ob.TextHash] = ob
+	}
+
+	results := make([]models.ObligationDuplicateCheckResult, 0, len(input))
+	for i, ob := range input {
+		existingTopic := ""
+		existingObligation, textExists := obligationsByHash[textHashes[i]]
+		if textExists && obligationTextCollides(&existingObligation, ob.Text) {
+			textExists = false
+		} else if textExists {
+			existingTopic = existingObligation.Topic
+		}
+		results = append(results, models.ObligationDuplicateCheckResult{
+			Topic:         ob.Topic,
+			TopicExists:   existingTopics[ob.Topic],
+			TextExists:    textExists,
+			ExistingTopic: existingTopic,
+		})
+	}
+
+	res := models.ObligationDuplicateCheckResponse{
+		Data:   results,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(results),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// UpdateObligation updates an existing active obligation record
+//
+//	@Summary		Update obligation
+//	@Description	Update an existing obligation record. The text can only be changed if text_updatable
+//	@Description	is true, where text_updatable of the request takes precedence over the stored one. So a
+//	@Description	request may unlock the text and change it, but not change it while locking it.
+//	@Description	Changing the text requires a change_reason, which is stored on the audit of the update.
+//	@Description	The status can be set to DRAFT or IN_REVIEW, obligations are published with the publish endpoint.
+//	@Description	A renamed obligation keeps its former topic reserved, and GET requests for it are redirected.
+//	@Description	An obligation locked by another user can not be updated until the lock is released or expires.
+//	@Id				UpdateObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic				path		string									true	"Topic of the obligation to be updated"
+//	@Param			If-Unmodified-Since	header		string									false	"Only update if the obligation was not modified after this HTTP date"
+//	@Param			obligation			body		models.ObligationPATCHRequestJSONSchema	true	"Obligation to be updated"
+//	@Success		200					{object}	models.ObligationResponse
+//	@Failure		400					{object}	models.ValidationError	"Malformed request body or header"
+//	@Failure		403					{object}	models.LicenseError		"Userlevel of the user can not update a field or withdraw a published obligation"
+//	@Failure		404					{object}	models.LicenseError		"No obligation with given topic found"
+//	@Failure		409					{object}	models.LicenseError		"Topic is used or reserved by another obligation"
+//	@Failure		412					{object}	models.LicenseError		"Obligation was modified after If-Unmodified-Since"
+//	@Failure		422					{object}	models.ValidationError	"Invalid obligation fields"
+//	@Failure		423					{object}	models.LicenseError		"Obligation is locked by another user"
+//	@Failure		500					{object}	models.LicenseError		"Unable to update obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic} [patch]
+func UpdateObligation(c *gin.Context) {
+	var updates models.ObligationPATCHRequestJSONSchema
+	if err := c.ShouldBindBodyWith(&updates, binding.JSON); err != nil {
+		status := utils.BindErrorStatus(err)
+		message := err.Error()
+		fieldErrors := utils.GetValidationErrors(err)
+		if errors.Is(err, models.ErrNullValue) {
+			// The decoder does not name the null field, so look it up in the body
+			fieldErrors = utils.GetNullFieldErrors(c.MustGet(gin.BodyBytesKey).([]byte), &updates)
+			var messages []string
+			for _, fe := range fieldErrors {
+				messages = append(messages, fe.Message)
+			}
+			message = strings.Join(messages, ", ")
+		}
+		er := models.ValidationError{
+			Status:    status,
+			Message:   "invalid json body",
+			Error:     message,
+			Errors:    fieldErrors,
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(status, er)
+		return
+	}
+
+	var user models.User
+	if err := db.DB.WithContext(c).Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnauthorized, er)
+		return
+	}
+	if allowedFields := ObligationFieldPolicy(user.Userlevel); allowedFields != nil {
+		for _, field := range updatedObligationFields(&updates) {
+			if !slices.Contains(allowedFields, field) {
+				er := models.LicenseError{
+					Status:    http.StatusForbidden,
+					Message:   fmt.Sprintf("Users with userlevel '%s' can not update the field '%s'", user.Userlevel, field),
+					Error:     fmt.Sprintf("field '%s' is not allowed", field),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusForbidden, er)
+				return
+			}
+		}
+	}
+
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var oldObligation models.Obligation
+		newObligationMap := make(map[string]interface{})
+		username := c.GetString("username")
+		tp := c.Param("topic")
+
+		if err := tx.Model(&oldObligation).Where(models.Obligation{Topic: tp}).First(&oldObligation).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusNotFound,
+				Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusNotFound, er)
+			return err
+		}
+
+		if ifUnmodifiedSince := c.GetHeader("If-Unmodified-Since"); ifUnmodifiedSince != "" {
+			since, err := http.ParseTime(ifUnmodifiedSince)
+			if err != nil {
+				er := models.LicenseError{
+					Status:    http.StatusBadRequest,
+					Message:   "Invalid If-Unmodified-Since header",
+					Error:     err.Error(),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusBadRequest, er)
+				return err
+			}
+			// HTTP dates have a precision of one second
+			if oldObligation.UpdatedAt.Truncate(time.Second).After(since) {
+				er := models.LicenseError{
+					Status:  http.StatusPreconditionFailed,
+					Message: fmt.Sprintf("obligation with topic '%s' was modified after %s", tp, ifUnmodifiedSince),
+					Error: fmt.Sprintf("obligation last modified at %s",
+						oldObligation.UpdatedAt.UTC().Format(http.TimeFormat)),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusPreconditionFailed, er)
+				return errors.New("precondition failed")
+			}
+		}
+
+		// Obligations under review can only be updated by the holder of their lock
+		lock, err := findObligationLock(tx, oldObligation.Id)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to fetch the lock of the obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		if lock != nil && lock.UserId != user.Id {
+			obligationLockedError(c, tp, lock)
+			return errObligationLocked
+		}
+
+		renamed := updates.Topic.IsDefined && updates.Topic.Value != oldObligation.Topic
+		if renamed {
+			if strings.TrimSpace(updates.Topic.Value) == "" {
+				er := models.LicenseError{
+					Status:    http.StatusUnprocessableEntity,
+					Message:   "Topic cannot be an empty string",
+					Error:     "invalid request",
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			if utils.IsReservedObligationTopic(updates.Topic.Value) {
+				er := models.ValidationError{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "invalid json body",
+					Error:   fmt.Sprintf("topic '%s' is reserved", updates.Topic.Value),
+					Errors: []models.FieldError{
+						{
+							Field:   "topic",
+							Rule:    "obligation_topic",
+							Message: utils.ReservedObligationTopicMessage("topic"),
+						},
+					},
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			var taken int64
+			err := tx.Model(&models.Obligation{}).Where(models.Obligation{Topic: updates.Topic.Value}).Count(&taken).Error
+			var reservedFor int64
+			if err == nil {
+				reservedFor, err = topicReservedFor(tx, updates.Topic.Value)
+			}
+			if err != nil {
+				er := models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   "Failed to update obligation",
+					Error:     err.Error(),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusInternalServerError, er)
+				return err
+			}
+			// The obligation may take back one of its own former topics
+			if taken != 0 || (reservedFor != 0 && reservedFor != oldObligation.Id) {
+				er := models.LicenseError{
+					Status:    http.StatusConflict,
+					Message:   "Topic is already taken",
+					Error:     fmt.Sprintf("topic '%s' is used or reserved by another obligation", updates.Topic.Value),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusConflict, er)
+				return errors.New("topic taken")
+			}
+			newObligationMap["topic"] = updates.Topic.Value
+		}
+
+		if updates.Text.IsDefined {
+			if updates.Text.Value == "" {
+				er := models.LicenseError{
+					Status:    http.StatusUnprocessableEntity,
+					Message:   "Text cannot be an empty string",
+					Error:     "invalid request",
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+
+			updatedNormalizedText := utils.NormalizeObligationText(updates.Text.Value)
+			updatedTextHash := utils.NormalizedObligationTextHash(updatedNormalizedText)
+			if err := validateObligationTextUpdate(&oldObligation, &updates); err != nil {
+				er := models.LicenseError{
+					Status:    http.StatusUnprocessableEntity,
+					Message:   "Can not update obligation text",
+					Error:     err.Error(),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return err
+			}
+			if updatedTextHash != oldObligation.TextHash && strings.TrimSpace(updates.ChangeReason) == "" {
+				er := models.ValidationError{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "invalid json body",
+					Error:   "a change reason is required to change the text",
+					Errors: []models.FieldError{
+						{
+							Field:   "change_reason",
+							Rule:    "required",
+							Message: "change_reason is required when text is changed",
+						},
+					},
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			newObligationMap["md5"] = updatedTextHash
+			newObligationMap["text"] = updates.Text.Value
+			newObligationMap["normalized_text"] = updatedNormalizedText
+		}
+
+		if updates.Type.IsDefined {
+			if updates.Type.Value == "" {
+				er := models.LicenseError{
+					Status:    http.StatusUnprocessableEntity,
+					Message:   "Type cannot be an empty string",
+					Error:     "invalid request",
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			if !utils.IsValidObligationType(updates.Type.Value) {
+				er := models.ValidationError{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "invalid json body",
+					Error:   fmt.Sprintf("unknown obligation type '%s'", updates.Type.Value),
+					Errors: []models.FieldError{
+						{
+							Field:   "type",
+							Rule:    "obligation_type",
+							Message: fmt.Sprintf("type must be one of [%s]", strings.Join(utils.ObligationTypes(), " ")),
+						},
+					},
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			newObligationMap["type"] = updates.Type.Value
+		}
+
+		if updates.Classification.IsDefined {
+			if updates.Classification.Value == "" {
+				er := models.LicenseError{
+					Status:    http.StatusUnprocessableEntity,
+					Message:   "Classification cannot be an empty string",
+					Error:     "invalid request",
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			if !slices.Contains(models.ObligationClassifications, updates.Classification.Value) {
+				er := models.ValidationError{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "invalid json body",
+					Error:   fmt.Sprintf("unknown obligation classification '%s'", updates.Classification.Value),
+					Errors: []models.FieldError{
+						{
+							Field:   "classification",
+							Rule:    "oneof",
+							Message: fmt.Sprintf("classification must be one of [%s]", strings.Join(models.ObligationClassifications, " ")),
+						},
+					},
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			newObligationMap["classification"] = updates.Classification.Value
+		}
+
+		if updates.Modifications.IsDefined {
+			newObligationMap["modifications"] = updates.Modifications.Value
+		}
+
+		// An explicit null clears the comment
+		if updates.Comment.IsDefinedAndNotNull {
+			if strings.HasPrefix(updates.Comment.Value, models.ENCRYPTED_FIELD_PREFIX) {
+				invalidObligationCommentError(c)
+				return models.ErrEncryptedFieldPrefix
+			}
+			newObligationMap["comment"] = updates.Comment.Value
+		} else if updates.Comment.IsNull {
+			newObligationMap["comment"] = ""
+		}
+
+		if updates.Active.IsDefined {
+			newObligationMap["active"] = updates.Active.Value
+		}
+
+		if updates.TextUpdatable.IsDefined {
+			newObligationMap["text_updatable"] = updates.TextUpdatable.Value
+		}
+
+		if updates.Sensitive.IsDefined {
+			newObligationMap["sensitive"] = updates.Sensitive.Value
+		}
+
+		if updates.Status.IsDefined && updates.Status.Value != oldObligation.Status {
+			if updates.Status.Value != models.OBLIGATION_STATUS_DRAFT && updates.Status.Value != models.OBLIGATION_STATUS_IN_REVIEW {
+				er := models.ValidationError{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "invalid json body",
+					Error:   fmt.Sprintf("status can not be changed to '%s'", updates.Status.Value),
+					Errors: []models.FieldError{
+						{
+							Field:   "status",
+							Rule:    "oneof",
+							Message: fmt.Sprintf("status must be one of [%s %s]", models.OBLIGATION_STATUS_DRAFT, models.OBLIGATION_STATUS_IN_REVIEW),
+						},
+					},
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusUnprocessableEntity, er)
+				return errors.New("invalid request")
+			}
+			// Withdrawing a published obligation is a review decision as well
+			if oldObligation.Status == models.OBLIGATION_STATUS_PUBLISHED &&
+				!slices.Contains(ObligationReviewerUserlevels(), user.Userlevel) {
+				er := models.LicenseError{
+					Status:    http.StatusForbidden,
+					Message:   fmt.Sprintf("Users with userlevel '%s' can not withdraw published obligations", user.Userlevel),
+					Error:     fmt.Sprintf("user '%s' is not a reviewer", user.Username),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusForbidden, er)
+				return errors.New("not a reviewer")
+			}
+			newObligationMap["status"] = updates.Status.Value
+		}
+
+		effectiveFrom := oldObligation.EffectiveFrom
+		if updates.EffectiveFrom.IsDefinedAndNotNull {
+			effectiveFrom = &updates.EffectiveFrom.Value
+			newObligationMap["effective_from"] = effectiveFrom
+		} else if updates.EffectiveFrom.IsNull {
+			effectiveFrom = nil
+			newObligationMap["effective_from"] = nil
+		}
+
+		effectiveUntil := oldObligation.EffectiveUntil
+		if updates.EffectiveUntil.IsDefinedAndNotNull {
+			effectiveUntil = &updates.EffectiveUntil.Value
+			newObligationMap["effective_until"] = effectiveUntil
+		} else if updates.EffectiveUntil.IsNull {
+			effectiveUntil = nil
+			newObligationMap["effective_until"] = nil
+		}
+
+		if err := validateEffectiveWindow(effectiveFrom, effectiveUntil); err != nil {
+			invalidEffectiveWindowError(c, err)
+			return err
+		}
+
+		var newObligation models.Obligation
+		newObligation.Id = oldObligation.Id
+		if err := tx.Model(&newObligation).Updates(newObligationMap).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to update license",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		// The updated obligation is fetched as a whole, the fields which were not
+		// updated are not returned by every database
+		if err := tx.Where(models.Obligation{Id: oldObligation.Id}).First(&newObligation).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to update license",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if renamed {
+			if err := renameObligationTopic(tx, &oldObligation, updates.Topic.Value); err != nil {
+				er := models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   "Failed to update obligation",
+					Error:     err.Error(),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusInternalServerError, er)
+				return err
+			}
+		}
+
+		if err := addChangelogsForObligationUpdate(tx, username, &newObligation, &oldObligation,
+			updates.ChangeReason); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to update license",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		res := models.ObligationResponse{
+			Data:   []models.Obligation{newObligation},
+			Status: http.StatusOK,
+			Meta: &models.PaginationMeta{
+				ResourceCount: 1,
+			},
+		}
+		c.JSON(http.StatusOK, res)
+
+		return nil
+	})
+}
+
+// GetObligationEditableFields tells which fields of an obligation the user may update
+//
+//	@Summary		Get editable fields of an obligation
+//	@Description	Get whether the user may update each field of the obligation, by json key, following
+//	@Description	the field permissions of the userlevel of the user, text_updatable of the obligation,
+//	@Description	and that only reviewers may withdraw published obligations.
+//	@Id				GetObligationEditableFields
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Success		200		{object}	models.ObligationEditableFieldsResponse
+//	@Failure		401		{object}	models.LicenseError	"User not found"
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/editable-fields [get]
+func GetObligationEditableFields(c *gin.Context) {
+	var user models.User
+	if err := db.DB.WithContext(c).Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnauthorized, er)
+		return
+	}
+
+	var obligation models.Obligation
+	tp := c.Param("topic")
+	if err := db.DB.WithContext(c).Select("id", "text_updatable", "status").
+		Where(models.Obligation{Topic: tp}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	allowedFields := ObligationFieldPolicy(user.Userlevel)
+	editable := make(map[string]bool, len(obligationPatchFields))
+	for _, field := range obligationPatchFields {
+		editable[field] = allowedFields == nil || slices.Contains(allowedFields, field)
+	}
+	// The text of a locked obligation is editable along with unlocking it
+	editable["text"] = editable["text"] && (obligation.TextUpdatable || editable["text_updatable"])
+	if obligation.Status == models.OBLIGATION_STATUS_PUBLISHED {
+		editable["status"] = editable["status"] && slices.Contains(ObligationReviewerUserlevels(), user.Userlevel)
+	}
+
+	res := models.ObligationEditableFieldsResponse{
+		Status: http.StatusOK,
+		Data:   editable,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// DeleteObligation marks an existing obligation record as inactive
+//
+//	@Summary		Deactivate obligation
+//	@Description	Deactivate an obligation. The deactivation is recorded as an audit of the obligation.
+//	@Description	The deactivated obligation is returned, unless the client sent Prefer: return=minimal,
+//	@Description	which gets an empty 204 response instead.
+//	@Id				DeleteObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation to be updated"
+//	@Param			Prefer	header		string	false	"return=minimal for a response without body"	Enums(return=minimal)
+//	@Success		200		{object}	models.ObligationResponse
+//	@Success		204
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to deactivate obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic} [delete]
+func DeleteObligation(c *gin.Context) {
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var oldObligation models.Obligation
+		tp := c.Param("topic")
+		if err := tx.Where(models.Obligation{Topic: tp}).First(&oldObligation).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusNotFound,
+				Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusNotFound, er)
+			return err
+		}
+
+		newObligation := oldObligation
+		newObligation.Active = false
+		if err := tx.Model(&newObligation).Update("active", false).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to deactivate obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if err := addChangelogsForObligationUpdate(tx, c.GetString("username"), &newObligation,
+			&oldObligation, ""); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to deactivate obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if c.GetHeader("Prefer") == "return=minimal" {
+			c.Status(http.StatusNoContent)
+		} else {
+			res := models.ObligationResponse{
+				Data:   []models.Obligation{newObligation},
+				Status: http.StatusOK,
+				Meta: &models.PaginationMeta{
+					ResourceCount: 1,
+				},
+			}
+			c.JSON(http.StatusOK, res)
+		}
+		return nil
+	})
+}
+
+// DeactivateObligationsByTopics marks the obligations of the given topics as inactive
+//
+//	@Summary		Deactivate obligations by topic
+//	@Description	Deactivate the obligations of an explicit list of topics in one transaction. An audit is
+//	@Description	written for every deactivated obligation. The topics which were deactivated, which were
+//	@Description	already inactive, which were not found and which are locked by another user, and so were
+//	@Description	left active, are returned. At most 500 topics can be given.
+//	@Id				DeactivateObligationsByTopics
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			deactivate	body		models.ObligationDeactivateInput	true	"Topics of the obligations to deactivate"
+//	@Success		200			{object}	models.ObligationDeactivateResponse
+//	@Failure		400			{object}	models.ValidationError	"Invalid request body"
+//	@Failure		403			{object}	models.LicenseError		"User is not an admin"
+//	@Failure		422			{object}	models.ValidationError	"Too many topics"
+//	@Failure		500			{object}	models.LicenseError		"Unable to deactivate obligations"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/deactivate-by-topics [post]
+func DeactivateObligationsByTopics(c *gin.Context) {
+	var input models.ObligationDeactivateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var topics []string
+	for _, topic := range input.Topics {
+		if topic != "" && !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) > MAX_DEACTIVATE_TOPICS {
+		er := models.ValidationError{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "invalid json body",
+			Error:   fmt.Sprintf("topics can have at most %d elements", MAX_DEACTIVATE_TOPICS),
+			Errors: []models.FieldError{
+				{
+					Field:   "topics",
+					Rule:    "max",
+					Message: fmt.Sprintf("topics must be at most %d", MAX_DEACTIVATE_TOPICS),
+				},
+			},
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnprocessableEntity, er)
+		return
+	}
+
+	result := models.ObligationDeactivateResult{
+		Deactivated:     []string{},
+		AlreadyInactive: []string{},
+		NotFound:        []string{},
+		Locked:          []string{},
+	}
+
+	err := db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var obligations []models.Obligation
+		if err := tx.Where("topic IN ?", topics).Find(&obligations).Error; err != nil {
+			return err
+		}
+		byTopic := make(map[string]models.Obligation, len(obligations))
+		for _, obligation := range obligations {
+			byTopic[obligation.Topic] = obligation
+		}
+
+		for _, topic := range topics {
+			oldObligation, ok := byTopic[topic]
+			if !ok {
+				result.NotFound = append(result.NotFound, topic)
+				continue
+			}
+			if !oldObligation.Active {
+				result.AlreadyInactive = append(result.AlreadyInactive, topic)
+				continue
+			}
+			lock, err := obligationLockedByOther(tx, oldObligation.Id, c.GetString("username"))
+			if err != nil {
+				return err
+			}
+			if lock != nil {
+				result.Locked = append(result.Locked, topic)
+				continue
+			}
+			newObligation := oldObligation
+			newObligation.Active = false
+			if err := tx.Model(&newObligation).Update("active", false).Error; err != nil {
+				return err
+			}
+			if err := addChangelogsForObligationUpdate(tx, c.GetString("username"), &newObligation,
+				&oldObligation, input.ChangeReason); err != nil {
+				return err
+			}
+			result.Deactivated = append(result.Deactivated, topic)
+		}
+		return nil
+	})
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to deactivate obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationDeactivateResponse{
+		Status: http.StatusOK,
+		Data:   result,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// PublishObligation publishes a reviewed obligation
+//
+//	@Summary		Publish obligation
+//	@Description	Publish an obligation, so it is listed to consumers. Only users whose userlevel is one of
+//	@Description	OBLIGATION_REVIEWER_USERLEVELS may publish. The publication is recorded as an audit of the obligation,
+//	@Description	along with a snapshot of the published obligation.
+//	@Id				PublishObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation to be published"
+//	@Success		200		{object}	models.ObligationResponse
+//	@Failure		401		{object}	models.LicenseError	"Invalid credentials"
+//	@Failure		403		{object}	models.LicenseError	"Userlevel of the user can not publish obligations"
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		409		{object}	models.LicenseError	"Obligation is already published"
+//	@Failure		500		{object}	models.LicenseError	"Unable to publish obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/publish [post]
+func PublishObligation(c *gin.Context) {
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var oldObligation models.Obligation
+		tp := c.Param("topic")
+		if err := tx.Where(models.Obligation{Topic: tp}).First(&oldObligation).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusNotFound,
+				Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusNotFound, er)
+			return err
+		}
+		if oldObligation.Status == models.OBLIGATION_STATUS_PUBLISHED {
+			er := models.LicenseError{
+				Status:    http.StatusConflict,
+				Message:   "Obligation is already published",
+				Error:     fmt.Sprintf("obligation with topic '%s' is already published", tp),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusConflict, er)
+			return errors.New("obligation already published")
+		}
+
+		newObligation := oldObligation
+		newObligation.Status = models.OBLIGATION_STATUS_PUBLISHED
+		if err := tx.Model(&newObligation).Update("status", models.OBLIGATION_STATUS_PUBLISHED).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to publish obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if err := addChangelogsForObligationUpdate(tx, c.GetString("username"), &newObligation,
+			&oldObligation, "Published"); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to publish obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		if _, err := createObligationSnapshot(tx, c.GetString("username"), &newObligation,
+			models.OBLIGATION_SNAPSHOT_REASON_PUBLISH); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to publish obligation",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		res := models.ObligationResponse{
+			Data:   []models.Obligation{newObligation},
+			Status: http.StatusOK,
+			Meta: &models.PaginationMeta{
+				ResourceCount: 1,
+			},
+		}
+		c.JSON(http.StatusOK, res)
+		return nil
+	})
+}
+
+// GetObligationAudits fetches audits corresponding to an obligation
+
+// @Summary		Fetches audits corresponding to an obligation
+// @Description	Fetches audits corresponding to an obligation, newest first. The total number of audits
+// @Description	and the links to the next and previous pages are in the pagination meta.
+// @Id				GetObligationAudits
+// @Tags			Obligations
+// @Accept			json
+// @Produce		json
+// @Param			topic	path		string	true	"Topic of the obligation for which audits need to be fetched"
+// @Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+// @Param			page	query		int		false	"Page number"
+// @Param			limit	query		int		false	"Number of records per page"
+// @Success		200		{object}	models.AuditResponse
+// @Failure		400		{object}	models.LicenseError	"Invalid status value"
+// @Failure		401		{object}	models.LicenseError	"status without valid credentials"
+// @Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+// @Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+// @Failure		500		{object}	models.LicenseError	"unable to find audits with such obligation topic"
+//
+//	@Security		ApiKeyAuth || {}
+//
+// @Router			/obligations/{topic}/audits [get]
+func GetObligationAudits(c *gin.Context) {
+	var obligation models.Obligation
+	topic := c.Param("topic")
+
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	result := query.Where(models.Obligation{Topic: topic}).Select("id").First(&obligation)
+	if result.Error != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     result.Error.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	writeObligationAudits(c, obligation.Id)
+}
+
+// GetObligationAuditsById fetches audits corresponding to an obligation by its id
+//
+//	@Summary		Fetches audits corresponding to an obligation by its id
+//	@Description	Fetches audits corresponding to an obligation by its id, which unlike its topic never changes,
+//	@Description	newest first. The total number of audits and the links to the next and previous pages are in
+//	@Description	the pagination meta.
+//	@Id				GetObligationAuditsById
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int		true	"Id of the obligation for which audits need to be fetched"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Success		200		{object}	models.AuditResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid id or status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given id found"
+//	@Failure		500		{object}	models.LicenseError	"unable to find audits with such obligation id"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/id/{id}/audits [get]
+func GetObligationAuditsById(c *gin.Context) {
+	var obligation models.Obligation
+	query := db.DB.WithContext(c).Model(&obligation).Select("id")
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if !findObligationById(c, query, &obligation) {
+		return
+	}
+	writeObligationAudits(c, obligation.Id)
+}
+
+// writeObligationAudits writes a page of the audits of the obligation with the given id,
+// newest first. Audits of the same time are ordered by id, so the pages are stable.
+func writeObligationAudits(c *gin.Context, obligationId int64) {
+	var audits []models.Audit
+	query := db.DB.WithContext(c).Model(&models.Audit{})
+	query.Where(models.Audit{TypeId: obligationId, Type: "Obligation"})
+	_ = utils.PreparePaginateResponse(c, query, &models.AuditResponse{})
+
+	res := query.Order("timestamp desc").Order("id desc").Find(&audits)
+	if res.Error != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "unable to find audits of the obligation",
+			Error:     res.Error.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	response := models.AuditResponse{
+		Data:   audits,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(audits),
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetObligationAuditsBatch fetches the audits of several obligations
+//
+//	@Summary		Fetches audits of several obligations
+//	@Description	Fetches the audits of the obligations with the given topics at once, grouped by topic in
+//	@Description	the order of the topics, newest first within each topic. The pagination is over all the
+//	@Description	audits, so a topic may continue on the next page. Topics without a published obligation,
+//	@Description	or one of the requested status, are returned in unknown_topics.
+//	@Id				GetObligationAuditsBatch
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topics	body		models.ObligationAuditBatchRequest	true	"Topics of the obligations"
+//	@Param			status	query		string								false	"Reviewers only, the obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			page	query		int									false	"Page number"
+//	@Param			limit	query		int									false	"Number of records per page"
+//	@Success		200		{object}	models.ObligationAuditBatchResponse
+//	@Failure		400		{object}	models.ValidationError	"Invalid request body, status value or too many topics"
+//	@Failure		401		{object}	models.LicenseError		"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError		"status by a non reviewer"
+//	@Failure		500		{object}	models.LicenseError		"Unable to fetch audits"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/audits/batch [post]
+func GetObligationAuditsBatch(c *gin.Context) {
+	var input models.ObligationAuditBatchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	if len(input.Topics) > MAX_AUDIT_BATCH_TOPICS {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     fmt.Sprintf("audits of at most %d topics can be fetched at once", MAX_AUDIT_BATCH_TOPICS),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var obligations []models.Obligation
+	obligationQuery := db.DB.WithContext(c).Model(&models.Obligation{})
+	if !filterObligationStatus(c, obligationQuery) {
+		return
+	}
+	if err := obligationQuery.Select("id", "topic").Where("topic IN ?", input.Topics).
+		Find(&obligations).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch audits",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+	topicsById := make(map[int64]string, len(obligations))
+	ids := make([]int64, 0, len(obligations))
+	found := make(map[string]bool, len(obligations))
+	for _, obligation := range obligations {
+		topicsById[obligation.Id] = obligation.Topic
+		ids = append(ids, obligation.Id)
+		found[obligation.Topic] = true
+	}
+
+	var audits []models.Audit
+	query := db.DB.WithContext(c).Model(&models.Audit{}).
+		Where("type = ? AND type_id IN ?", "Obligation", ids)
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationAuditBatchResponse{})
+	if len(ids) != 0 {
+		if err := query.Order("timestamp desc").Order("id desc").Find(&audits).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to fetch audits",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	}
+
+	auditsByTopic := make(map[string][]models.Audit, len(obligations))
+	for _, audit := range audits {
+		topic := topicsById[audit.TypeId]
+		auditsByTopic[topic] = append(auditsByTopic[topic], audit)
+	}
+
+	// The groups keep the order of the requested topics, each topic listed once
+	data := []models.ObligationAudits{}
+	unknownTopics := []string{}
+	seen := make(map[string]bool, len(input.Topics))
+	for _, topic := range input.Topics {
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		if !found[topic] {
+			unknownTopics = append(unknownTopics, topic)
+		} else if topicAudits, ok := auditsByTopic[topic]; ok {
+			data = append(data, models.ObligationAudits{Topic: topic, Audits: topicAudits})
+		}
+	}
+
+	res := models.ObligationAuditBatchResponse{
+		Data:          data,
+		UnknownTopics: unknownTopics,
+		Status:        http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(audits),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetObligationAuditSummary summarizes the audits of an obligation
+//
+//	@Summary		Get audit summary of an obligation
+//	@Description	Get the number of audits of an obligation, when and by whom it was last changed, and
+//	@Description	how many times each field was changed
+//	@Id				GetObligationAuditSummary
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationAuditSummaryResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to summarize audits"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/audits/summary [get]
+func GetObligationAuditSummary(c *gin.Context) {
+	var obligation models.Obligation
+	topic := c.Param("topic")
+
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Where(models.Obligation{Topic: topic}).Select("id").First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	summary := models.ObligationAuditSummary{
+		FieldChangeCounts: map[string]int64{},
+	}
+	auditFilter := models.Audit{TypeId: obligation.Id, Type: "Obligation"}
+
+	var fieldCounts []struct {
+		Field string
+		Count int64
+	}
+	var lastAudit models.Audit
+	err := db.DB.WithContext(c).Model(&models.Audit{}).Where(auditFilter).Count(&summary.TotalAudits).Error
+	if err == nil && summary.TotalAudits > 0 {
+		err = db.DB.WithContext(c).Preload("User").Where(auditFilter).Order("timestamp desc").First(&lastAudit).Error
+	}
+	if err == nil && summary.TotalAudits > 0 {
+		err = db.DB.WithContext(c).Model(&models.ChangeLog{}).
+			Select("change_logs.field AS field, COUNT(*) AS count").
+			Joins("JOIN audits ON audits.id = change_logs.audit_id").
+			Where("audits.type_id = ? AND audits.type = ?", obligation.Id, "Obligation").
+			Group("change_logs.field").
+			Scan(&fieldCounts).Error
+	}
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to summarize audits",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	if summary.TotalAudits > 0 {
+		summary.LastChangedAt = &lastAudit.Timestamp
+		summary.LastChangedBy = &lastAudit.User.Username
+	}
+	for _, fieldCount := range fieldCounts {
+		summary.FieldChangeCounts[fieldCount.Field] = fieldCount.Count
+	}
+
+	res := models.ObligationAuditSummaryResponse{
+		Data:   summary,
+		Status: http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// obligationExportMigrations migrate the obligations of an export from the schema
+// version of the key to the next version.
+var obligationExportMigrations = map[int]func(json.RawMessage) (json.RawMessage, error){
+	// Version 2 only added the envelope, the obligations are unchanged
+	1: func(obligations json.RawMessage) (json.RawMessage, error) {
+		return obligations, nil
+	},
+	// Version 3 only added the manifest, which older exports can not be verified by
+	2: func(obligations json.RawMessage) (json.RawMessage, error) {
+		return obligations, nil
+	},
+}
+
+// obligationExportChecksum returns the hex encoded SHA-256 of the obligations of an
+// export, serialized as the export serializes them, see models.ObligationExportManifest.
+func obligationExportChecksum(obligations []models.ObligationJSONFileFormat) (string, error) {
+	checksum := sha256.New()
+	encoder := json.NewEncoder(checksum)
+	for i := range obligations {
+		if err := encoder.Encode(&obligations[i]); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+// verifyObligationExportManifest checks that the obligations of an export are the ones
+// its manifest was made for, so truncated or corrupted exports are not imported.
+func verifyObligationExportManifest(manifest *models.ObligationExportManifest,
+	obligations []models.ObligationJSONFileFormat) error {
+	if manifest == nil {
+		return errors.New("the manifest is missing")
+	}
+	if manifest.Count != len(obligations) {
+		return fmt.Errorf("the manifest counts %d obligations, the export has %d", manifest.Count, len(obligations))
+	}
+	checksum, err := obligationExportChecksum(obligations)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(manifest.Sha256, checksum) {
+		return fmt.Errorf("the sha256 of the obligations is %s, the manifest has %s", checksum, manifest.Sha256)
+	}
+	return nil
+}
+
+// ImportObligations creates new obligation records via a json file.
+//
+//	@Summary		Import obligations by uploading a json file
+//	@Description	Import obligations by uploading a json file. An obligation with the same topic or text as an
+//	@Description	existing one is handled by the strategy: skip leaves the existing obligation untouched,
+//	@Description	overwrite updates it unless its text is not updatable, and merge only fills its empty fields.
+//	@Description	An existing obligation found by its text keeps its topic.
+//	@Description	Without a strategy, existing obligations are skipped, where they used to be overwritten, so
+//	@Description	re-imports relying on that have to pass overwrite. An obligation failing to import is not
+//	@Description	changed at all. Overwriting the text of an obligation requires a change_reason, which is
+//	@Description	stored on the audit of the update. Exports of older schema versions are migrated to the current one, exports of unknown
+//	@Description	versions are rejected. Exports with a manifest are rejected if the count or the sha256 of
+//	@Description	their obligations does not match it. Admins can backdate new obligations by a past created_at, which is
+//	@Description	audited. New obligations count against the daily obligation creation quota of the user,
+//	@Description	those over it are reported with the status 429. Existing obligations locked by another user
+//	@Description	are neither overwritten nor merged and are reported with the status 423.
+//	@Id				ImportObligations
+//	@Tags			Obligations
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	true	"obligations json file list"
+//	@Param			strategy		query		string	false	"Conflict resolution strategy"	Enums(skip, overwrite, merge)	default(skip)
+//	@Param			change_reason	formData	string	false	"Reason of the text changes of overwritten obligations"
+//	@Success		200			{object}	models.ImportObligationsResponse{data=[]models.ObligationImportStatus}
+//	@Failure		400			{object}	models.LicenseError	"input file must be present, invalid strategy, unsupported schema version or manifest mismatch"
+//	@Failure		403			{object}	models.LicenseError	"created_at by a non admin user"
+//	@Failure		500			{object}	models.LicenseError	"Internal server error"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/import [post]
+func ImportObligations(c *gin.Context) {
+	username := c.GetString("username")
+	strategy := c.DefaultQuery("strategy", models.IMPORT_STRATEGY_SKIP)
+	if strategy != models.IMPORT_STRATEGY_SKIP && strategy != models.IMPORT_STRATEGY_OVERWRITE &&
+		strategy != models.IMPORT_STRATEGY_MERGE {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid strategy value",
+			Error:     fmt.Sprintf("strategy must be one of skip, overwrite or merge, got '%s'", strategy),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "input file must be present",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	defer file.Close()
+	changeReason := strings.TrimSpace(c.PostForm("change_reason"))
+
+	if filepath.Ext(header.Filename) != ".json" {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "only files with format *.json are allowed",
+			Error:     "only files with format *.json are allowed",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var envelope struct {
+		SchemaVersion int                              `json:"schema_version"`
+		Obligations   json.RawMessage                  `json:"obligations"`
+		Manifest      *models.ObligationExportManifest `json:"manifest"`
+	}
+	data, err := io.ReadAll(file)
+	if err == nil {
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 && data[0] == '[' {
+			// Exports without envelope are of the first schema version
+			envelope.SchemaVersion = 1
+			envelope.Obligations = data
+		} else {
+			err = json.Unmarshal(data, &envelope)
+		}
+	}
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "invalid json",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	if envelope.SchemaVersion < 1 || envelope.SchemaVersion > models.OBLIGATION_EXPORT_SCHEMA_VERSION {
+		er := models.LicenseError{
+			Status:  http.StatusBadRequest,
+			Message: "unsupported schema version",
+			Error: fmt.Sprintf("schema_version must be between 1 and %d, got %d",
+				models.OBLIGATION_EXPORT_SCHEMA_VERSION, envelope.SchemaVersion),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	for version := envelope.SchemaVersion; version < models.OBLIGATION_EXPORT_SCHEMA_VERSION; version++ {
+		envelope.Obligations, err = obligationExportMigrations[version](envelope.Obligations)
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   fmt.Sprintf("unable to migrate export of schema version %d", version),
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	var obligations []models.ObligationJSONFileFormat
+	if err := json.Unmarshal(envelope.Obligations, &obligations); err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "invalid json",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	// Exports with a manifest are verified before anything is applied
+	if envelope.SchemaVersion >= 3 {
+		if err := verifyObligationExportManifest(envelope.Manifest, obligations); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "export does not match its manifest",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	// Only admins may backdate obligations, e.g. when migrating historical ones
+	for _, obligation := range obligations {
+		if obligation.CreatedAt != nil {
+			if !middleware.AuthorizeUserlevel(c, "admin") {
+				return
+			}
+			break
+		}
+	}
+
+	res := models.ImportObligationsResponse{
+		Status: http.StatusOK,
+	}
+
+	for _, obligation := range obligations {
+		if !utils.IsValidObligationType(obligation.Type) {
+			res.Data = append(res.Data, models.LicenseError{
+				Status: http.StatusBadRequest,
+				Message: fmt.Sprintf("Unknown obligation type '%s', must be one of [%s]", obligation.Type,
+					strings.Join(utils.ObligationTypes(), " ")),
+				Error:     obligation.Topic,
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			continue
+		}
+		if utils.IsReservedObligationTopic(obligation.Topic) {
+			res.Data = append(res.Data, models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   utils.ReservedObligationTopicMessage("topic"),
+				Error:     obligation.Topic,
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			continue
+		}
+		if strings.HasPrefix(obligation.Comment, models.ENCRYPTED_FIELD_PREFIX) {
+			res.Data = append(res.Data, models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   models.ErrEncryptedFieldPrefix.Error(),
+				Error:     obligation.Topic,
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			continue
+		}
+		recordedAt := time.Now()
+		if obligation.CreatedAt != nil && obligation.CreatedAt.After(recordedAt) {
+			res.Data = append(res.Data, models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   fmt.Sprintf("created_at '%s' is in the future", obligation.CreatedAt.Format(time.RFC3339)),
+				Error:     obligation.Topic,
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			continue
+		}
+
+		_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+			ob := models.Obligation{
+				Topic:          obligation.Topic,
+				Type:           obligation.Type,
+				Text:           obligation.Text,
+				Classification: obligation.Classification,
+				Modifications:  obligation.Modifications,
+				Comment:        obligation.Comment,
+				Active:         obligation.Active,
+				TextUpdatable:  obligation.TextUpdatable,
+				Sensitive:      obligation.Sensitive,
+				EffectiveFrom:  obligation.EffectiveFrom,
+				EffectiveUntil: obligation.EffectiveUntil,
+			}
+
+			ob.NormalizedText = utils.NormalizeObligationText(ob.Text)
+			ob.TextHash = utils.NormalizedObligationTextHash(ob.NormalizedText)
+
+			// Former topics of renamed obligations are reserved
+			reservedFor, err := topicReservedFor(tx, ob.Topic)
+			if err != nil {
+				res.Data = append(res.Data, models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   fmt.Sprintf("Failed to create obligation: %s", err.Error()),
+					Error:     ob.Topic,
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				})
+				return err
+			} else if reservedFor != 0 {
+				res.Data = append(res.Data, models.LicenseError{
+					Status:    http.StatusConflict,
+					Message:   "Topic is reserved as the former topic of a renamed obligation",
+					Error:     ob.Topic,
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				})
+				return errors.New("obligation topic reserved")
+			}
+
+			oldObligation := ob
+			// New obligations are drafts, as the ones created one by one
+			oldObligation.Status = models.OBLIGATION_STATUS_DRAFT
+			// The creation date is only overridden for new obligations
+			if obligation.CreatedAt != nil {
+				oldObligation.CreatedAt = *obligation.CreatedAt
+			}
+			result := tx.
+				Where(&models.Obligation{Topic: ob.Topic}).
+				Or(&models.Obligation{TextHash: ob.TextHash}).
+				FirstOrCreate(&oldObligation)
+			if result.Error != nil {
+				res.Data = append(res.Data, models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   fmt.Sprintf("Failed to create obligation: %s", result.Error.Error()),
+					Error:     ob.Topic,
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				})
+				return result.Error
+			} else if result.RowsAffected == 0 && obligationTextCollides(&oldObligation, ob.Text) {
+				res.Data = append(res.Data, models.LicenseError{
+					Status:    http.StatusConflict,
+					Message:   fmt.Sprintf("Text has the same hash as obligation '%s' but a different content", oldObligation.Topic),
+					Error:     ob.Topic,
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				})
+				return errors.New("obligation text hash collision")
+			} else if result.RowsAffected == 0 && strategy == models.IMPORT_STRATEGY_SKIP {
+				// case when obligation exists in database and is left untouched
+				res.Data = append(res.Data, models.ObligationImportStatus{
+					Data:   models.ObligationId{Id: oldObligation.Id, Topic: oldObligation.Topic},
+					Status: http.StatusOK,
+					Action: models.IMPORT_ACTION_SKIPPED,
+				})
+			} else if result.RowsAffected == 0 {
+				// case when obligation exists in database and is updated, unless another
+				// user holds its lock
+				lock, err := obligationLockedByOther(tx, oldObligation.Id, username)
+				if err != nil {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusInternalServerError,
+						Message:   fmt.Sprintf("Unable to fetch the lock of the obligation: %s", err.Error()),
+						Error:     ob.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return err
+				}
+				if lock != nil {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusLocked,
+						Message:   obligationLockedMessage(ob.Topic, lock),
+						Error:     ob.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return errObligationLocked
+				}
+
+				action := models.IMPORT_ACTION_OVERWRITTEN
+				if strategy == models.IMPORT_STRATEGY_MERGE {
+					action = models.IMPORT_ACTION_MERGED
+					ob = mergeObligation(oldObligation, ob)
+				} else if !oldObligation.TextUpdatable && oldObligation.TextHash != ob.TextHash {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusBadRequest,
+						Message:   "Can not update obligation text",
+						Error:     ob.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return errors.New("invalid request")
+				} else if oldObligation.TextHash != ob.TextHash && changeReason == "" {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusUnprocessableEntity,
+						Message:   "change_reason is required when text is changed",
+						Error:     ob.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return errors.New("change reason missing")
+				}
+
+				// The existing obligation keeps its topic, it may have been found by its text
+				if ob.TextHash != oldObligation.TextHash {
+					var count int64
+					if err := tx.Model(&models.Obligation{}).Where("md5 = ? AND id <> ?", ob.TextHash, oldObligation.Id).
+						Count(&count).Error; err != nil {
+						res.Data = append(res.Data, models.LicenseError{
+							Status:    http.StatusInternalServerError,
+							Message:   fmt.Sprintf("Failed to update obligation: %s", err.Error()),
+							Error:     ob.Topic,
+							Path:      c.Request.URL.Path,
+							Timestamp: time.Now().Format(time.RFC3339),
+						})
+						return err
+					} else if count != 0 {
+						res.Data = append(res.Data, models.LicenseError{
+							Status:    http.StatusConflict,
+							Message:   "Another obligation with the same text exists",
+							Error:     ob.Topic,
+							Path:      c.Request.URL.Path,
+							Timestamp: time.Now().Format(time.RFC3339),
+						})
+						return errors.New("obligation text exists")
+					}
+				}
+
+				// Updated with a map, as the zero values of a struct, e.g. active false, are skipped
+				updates := map[string]interface{}{
+					"type":            ob.Type,
+					"text":            ob.Text,
+					"normalized_text": ob.NormalizedText,
+					"md5":             ob.TextHash,
+					"classification":  ob.Classification,
+					"modifications":   ob.Modifications,
+					"comment":         ob.Comment,
+					"active":          ob.Active,
+					"text_updatable":  ob.TextUpdatable,
+					"sensitive":       ob.Sensitive,
+					"effective_from":  ob.EffectiveFrom,
+					"effective_until": ob.EffectiveUntil,
+				}
+				ob = models.Obligation{Id: oldObligation.Id}
+				if err := tx.Model(&ob).Updates(updates).Error; err != nil {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusInternalServerError,
+						Message:   fmt.Sprintf("Failed to update obligation: %s", err.Error()),
+						Error:     oldObligation.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return err
+				}
+				if err := tx.Where(models.Obligation{Id: oldObligation.Id}).First(&ob).Error; err != nil {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusInternalServerError,
+						Message:   fmt.Sprintf("Failed to update obligation: %s", err.Error()),
+						Error:     oldObligation.Topic,
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return err
+				}
+
+				if err := addChangelogsForObligationUpdate(tx, username, &ob, &oldObligation, changeReason); err != nil {
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    http.StatusInternalServerError,
+						Message:   "Failed to update license",
+						Error:     err.Error(),
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return err
+				}
+
+				res.Data = append(res.Data, models.ObligationImportStatus{
+					Data:   models.ObligationId{Id: ob.Id, Topic: ob.Topic},
+					Status: http.StatusOK,
+					Action: action,
+				})
+
+			} else {
+				// case when obligation doesn't exist in database and is inserted
+				if err := consumeObligationCreationQuota(tx, username, 1); err != nil {
+					status := http.StatusInternalServerError
+					message := "Failed to create obligation"
+					if errors.Is(err, errObligationQuotaExceeded) {
+						status = http.StatusTooManyRequests
+						message = "Daily obligation creation quota exceeded"
+					}
+					res.Data = append(res.Data, models.LicenseError{
+						Status:    status,
+						Message:   message,
+						Error:     err.Error(),
+						Path:      c.Request.URL.Path,
+						Timestamp: time.Now().Format(time.RFC3339),
+					})
+					return err
+				}
+				if obligation.CreatedAt != nil {
+					if err := addChangelogForCreatedAtOverride(tx, username, &oldObligation, recordedAt); err != nil {
+						res.Data = append(res.Data, models.LicenseError{
+							Status:    http.StatusInternalServerError,
+							Message:   "Failed to audit the overridden creation date",
+							Error:     err.Error(),
+							Path:      c.Request.URL.Path,
+							Timestamp: time.Now().Format(time.RFC3339),
+						})
+						return err
+					}
+				}
+				res.Data = append(res.Data, models.ObligationImportStatus{
+					Data:   models.ObligationId{Id: oldObligation.Id, Topic: oldObligation.Topic},
+					Status: http.StatusCreated,
+					Action: models.IMPORT_ACTION_CREATED,
+				})
+			}
+
+			return nil
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// mergeObligation fills the empty fields of the existing obligation with the ones
+// of the imported obligation and returns the result.
+func mergeObligation(existing, imported models.Obligation) models.Obligation {
+	merged := existing
+	if merged.Type == "" {
+		merged.Type = imported.Type
+	}
+	if merged.Classification == "" {
+		merged.Classification = imported.Classification
+	}
+	if merged.Comment == "" {
+		merged.Comment = imported.Comment
+	}
+	if merged.EffectiveFrom == nil {
+		merged.EffectiveFrom = imported.EffectiveFrom
+	}
+	if merged.EffectiveUntil == nil {
+		merged.EffectiveUntil = imported.EffectiveUntil
+	}
+	return merged
+}
+
+// ExportObligations gives users all obligations as a json file.
+//
+//	@Summary		Export all obligations as a json file
+//	@Description	Export all obligations as a json file, in an envelope with the schema version of the
+//	@Description	export to be checked on import. The obligations are streamed from a read only
+//	@Description	snapshot, limited by EXPORT_STATEMENT_TIMEOUT_SECONDS. Exports are rate limited per user
+//	@Description	by EXPORT_RATE_LIMIT per minute. The number of exported obligations is sent in the
+//	@Description	X-Total-Count header. The manifest at the end of the export holds the number of exported
+//	@Description	obligations and the sha256 over them, by which the import verifies the export. With
+//	@Description	schemaVersion 1, the obligations are exported as a bare array, without envelope and manifest.
+//	@Id				ExportObligations
+//	@Tags			Obligations
+//	@Produce		json
+//	@Param			status			query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Param			schemaVersion	query		int		false	"Schema version of the export"	Enums(1, 3)	default(3)
+//	@Success		200				{object}	models.ObligationExportEnvelope
+//	@Header			200				{integer}	X-Total-Count		"Number of exported obligations"
+//	@Failure		400				{object}	models.LicenseError	"Invalid status or schemaVersion value"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		429		{object}	models.LicenseError	"Too many export requests"
+//	@Failure		500		{object}	models.LicenseError	"Failed to fetch obligations"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/export [get]
+func ExportObligations(c *gin.Context) {
+	schemaVersion := models.OBLIGATION_EXPORT_SCHEMA_VERSION
+	if value := c.Query("schemaVersion"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || (parsed != 1 && parsed != models.OBLIGATION_EXPORT_SCHEMA_VERSION) {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid schemaVersion value",
+				Error:     fmt.Sprintf("schemaVersion must be 1 or %d, got '%s'", models.OBLIGATION_EXPORT_SCHEMA_VERSION, value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+		schemaVersion = parsed
+	}
+
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := setExportStatementTimeout(tx); err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to fetch obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		obligations := tx.Model(&models.Obligation{})
+		if !filterObligationStatus(c, obligations) {
+			return errors.New("invalid status")
+		}
+		obligations = obligations.Session(&gorm.Session{})
+
+		var count int64
+		if err := obligations.Count(&count).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to fetch obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		var obligationMaps []struct {
+			ObligationPk int64
+			Shortname    string
+		}
+		if err := tx.Model(&models.ObligationMap{}).
+			Select("obligation_maps.obligation_pk, license_dbs.rf_shortname AS shortname").
+			Joins("JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk").
+			Scan(&obligationMaps).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to fetch obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		shortnamesByObligation := make(map[int64][]string)
+		for _, obMap := range obligationMaps {
+			shortnamesByObligation[obMap.ObligationPk] = append(shortnamesByObligation[obMap.ObligationPk], obMap.Shortname)
+		}
+
+		rows, err := obligations.Order("id").Rows()
+		if err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Failed to fetch obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+		defer rows.Close()
+
+		fileName := strings.Map(func(r rune) rune {
+			if r == '+' || r == ':' {
+				return '_'
+			}
+			return r
+		}, fmt.Sprintf("obligations-export-%s.json", time.Now().Format(time.RFC3339)))
+
+		middleware.StreamResponse(c)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Header("X-Total-Count", strconv.FormatInt(count, 10))
+		c.Status(http.StatusOK)
+
+		// The obligations are hashed as they are written, see models.ObligationExportManifest
+		checksum := sha256.New()
+		encoder := json.NewEncoder(io.MultiWriter(c.Writer, checksum))
+		if schemaVersion == 1 {
+			_, err = c.Writer.WriteString("[")
+		} else {
+			exportedAt, _ := json.Marshal(time.Now())
+			_, err = fmt.Fprintf(c.Writer, `{"schema_version":%d,"exported_at":%s,"obligations":[`,
+				schemaVersion, exportedAt)
+		}
+		if err != nil {
+			return err
+		}
+		exported := 0
+		for ; rows.Next(); exported++ {
+			var obligation models.Obligation
+			if err := tx.ScanRows(rows, &obligation); err != nil {
+				// The status is already sent, so the client sees a truncated file
+				_ = c.Error(err)
+				return err
+			}
+			// ScanRows does not run the hooks decrypting the obligation
+			if err := obligation.AfterFind(tx); err != nil {
+				_ = c.Error(err)
+				return err
+			}
+
+			obJSONFileFormat := models.ObligationJSONFileFormat{
+				Topic:          obligation.Topic,
+				Type:           obligation.Type,
+				Text:           obligation.Text,
+				Shortnames:     shortnamesByObligation[obligation.Id],
+				TextUpdatable:  obligation.TextUpdatable,
+				Sensitive:      obligation.Sensitive,
+				Active:         obligation.Active,
+				Modifications:  obligation.Modifications,
+				Comment:        obligation.Comment,
+				Classification: obligation.Classification,
+				EffectiveFrom:  obligation.EffectiveFrom,
+				EffectiveUntil: obligation.EffectiveUntil,
+			}
+
+			if exported > 0 {
+				if _, err := c.Writer.WriteString(","); err != nil {
+					return err
+				}
+			}
+			if err := encoder.Encode(&obJSONFileFormat); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		if err := rows.Err(); err != nil {
+			_ = c.Error(err)
+			return err
+		}
+		if schemaVersion == 1 {
+			_, err = c.Writer.WriteString("]")
+			return err
+		}
+		manifest, _ := json.Marshal(models.ObligationExportManifest{
+			Count:  exported,
+			Sha256: hex.EncodeToString(checksum.Sum(nil)),
+		})
+		_, err = fmt.Fprintf(c.Writer, `],"manifest":%s}`, manifest)
+		return err
+	}, exportTxOptions()...)
+}
+
+// exportTxOptions returns the options of the transactions the exports are read in, which
+// are read only snapshots on Postgres.
+func exportTxOptions() []*sql.TxOptions {
+	if !db.IsPostgres() {
+		return nil
+	}
+	return []*sql.TxOptions{{Isolation: sql.LevelRepeatableRead, ReadOnly: true}}
+}
+
+// setExportStatementTimeout limits the statements of the export transaction on Postgres
+// by EXPORT_STATEMENT_TIMEOUT_SECONDS, as the exports are not limited by the request timeout.
+func setExportStatementTimeout(tx *gorm.DB) error {
+	if !db.IsPostgres() {
+		return nil
+	}
+	timeout, err := strconv.Atoi(os.Getenv("EXPORT_STATEMENT_TIMEOUT_SECONDS"))
+	if err != nil || timeout <= 0 {
+		timeout = DEFAULT_EXPORT_STATEMENT_TIMEOUT_SECONDS
+	}
+	return tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout*1000)).Error
+}
+
+// GetSimilarObligations lists the obligations with texts similar to the text of an obligation
+//
+//	@Summary		Get similar obligations
+//	@Description	Get the obligations whose texts are at least threshold similar to the text of the
+//	@Description	given obligation, most similar first. The similarity is computed on the trigrams of the
+//	@Description	texts, by pg_trgm on Postgres. The defaults are set by OBLIGATION_SIMILARITY_THRESHOLD and
+//	@Description	OBLIGATION_SIMILARITY_MATCH_COUNT.
+//	@Id				GetSimilarObligations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic		path		string	true	"Topic of the obligation"
+//	@Param			threshold	query		number	false	"Minimum similarity between 0 and 1"	default(0.95)
+//	@Param			count		query		int		false	"Maximum number of matches"				default(5)
+//	@Param			status		query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200			{object}	models.ObligationSimilarityResponse
+//	@Failure		400			{object}	models.LicenseError	"Invalid threshold, count or status"
+//	@Failure		401			{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403			{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404			{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500			{object}	models.LicenseError	"Unable to fetch obligations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/similar [get]
+func GetSimilarObligations(c *gin.Context) {
+	threshold, err := strconv.ParseFloat(os.Getenv("OBLIGATION_SIMILARITY_THRESHOLD"), 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		threshold = DEFAULT_SIMILARITY_THRESHOLD
+	}
+	count, err := strconv.Atoi(os.Getenv("OBLIGATION_SIMILARITY_MATCH_COUNT"))
+	if err != nil || count <= 0 {
+		count = DEFAULT_SIMILARITY_MATCH_COUNT
+	}
+
+	if value := c.Query("threshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid threshold value",
+				Error:     fmt.Sprintf("threshold must be a number between 0 and 1, got '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+	if value := c.Query("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid count value",
+				Error:     fmt.Sprintf("count must be a positive integer, got '%s'", value),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	var obligation models.Obligation
+	tp := c.Param("topic")
+	query := db.DB.WithContext(c).Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Where(models.Obligation{Topic: tp}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", tp),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
 
-				if result.RowsAffected == 0 {
-					res.Data = append(res.Data, models.LicenseError{
-						Status:    http.StatusConflict,
-						Message:   "Another obligation with the same text exists",
-						Error:     ob.Topic,
-						Path:      c.Request.URL.Path,
-						Timestamp: time.Now().Format(time.RFC3339),
-					})
-					return err
-				}
+	// Only published obligations are matched, drafts are not revealed through their similarity
+	var matches []models.ObligationSimilarity
+	published := db.DB.WithContext(c).Where(models.Obligation{Status: models.OBLIGATION_STATUS_PUBLISHED})
+	if db.IsPostgres() {
+		matches, err = findSimilarObligationsInDB(published, &obligation, threshold, count)
+	} else {
+		matches, err = findSimilarObligationsInBatches(published, &obligation, threshold, count)
+	}
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
 
-				if err := addChangelogsForObligationUpdate(tx, username, &ob, &oldObligation); err != nil {
-					res.Data = append(res.Data, models.LicenseError{
-						Status:    http.StatusInternalServerError,
-						Message:   "Failed to update license",
-						Error:     err.Error(),
-						Path:      c.Request.URL.Path,
-						Timestamp: time.Now().Format(time.RFC3339),
-					})
-					return err
-				}
+	res := models.ObligationSimilarityResponse{
+		Status: http.StatusOK,
+		Data:   matches,
+	}
+	c.JSON(http.StatusOK, res)
+}
 
-				res.Data = append(res.Data, models.ObligationImportStatus{
-					Data:   models.ObligationId{Id: ob.Id, Topic: ob.Topic},
-					Status: http.StatusOK,
-				})
+// findSimilarObligationsInDB finds the count obligations most similar to the obligation
+// by the similarity of pg_trgm.
+func findSimilarObligationsInDB(tx *gorm.DB, obligation *models.Obligation, threshold float64,
+	count int) ([]models.ObligationSimilarity, error) {
+	matches := []models.ObligationSimilarity{}
+	err := tx.Model(&models.Obligation{}).
+		Select("topic, type, similarity(text, ?) AS score", obligation.Text).
+		Where("id <> ? AND similarity(text, ?) >= ?", obligation.Id, obligation.Text, threshold).
+		Order("score DESC").Limit(count).Scan(&matches).Error
+	return matches, err
+}
 
-			} else {
-				// case when obligation doesn't exist in database and is inserted
-				res.Data = append(res.Data, models.ObligationImportStatus{
-					Data:   models.ObligationId{Id: oldObligation.Id, Topic: oldObligation.Topic},
-					Status: http.StatusCreated,
-				})
+// findSimilarObligationsInBatches finds the count obligations most similar to the
+// obligation by utils.TextSimilarity, for databases without pg_trgm. The other
+// obligations are read in batches, keeping only the best matches.
+func findSimilarObligationsInBatches(tx *gorm.DB, obligation *models.Obligation, threshold float64,
+	count int) ([]models.ObligationSimilarity, error) {
+	matches := []models.ObligationSimilarity{}
+	var others []models.Obligation
+	err := tx.Select("id", "topic", "type", "text").Where("id <> ?", obligation.Id).
+		FindInBatches(&others, SIMILARITY_BATCH_SIZE, func(_ *gorm.DB, _ int) error {
+			for _, other := range others {
+				score := utils.TextSimilarity(obligation.Text, other.Text)
+				if score >= threshold {
+					matches = append(matches, models.ObligationSimilarity{
+						Topic: other.Topic,
+						Type:  other.Type,
+						Score: score,
+					})
+				}
+			}
+			sort.SliceStable(matches, func(i, j int) bool {
+				return matches[i].Score > matches[j].Score
+			})
+			if len(matches) > count {
+				matches = matches[:count]
 			}
-
 			return nil
-		})
-	}
-
-	c.JSON(http.StatusOK, res)
+		}).Error
+	return matches, err
 }
 
-// ExportObligations gives users all obligations as a json file.
+// ExportLicenseObligations gives users the active obligations of a set of licenses as a file.
 //
-//	@Summary		Export all obligations as a json file
-//	@Description	Export all obligations as a json file
-//	@Id				ExportObligations
-//	@Tags			Obligations
-//	@Produce		json
-//	@Success		200	{array}		models.ObligationJSONFileFormat
-//	@Failure		500	{object}	models.LicenseError	"Failed to fetch obligations"
-//	@Security		ApiKeyAuth || {}
-//	@Router			/obligations/export [get]
-func ExportObligations(c *gin.Context) {
-	var obligations []models.Obligation
-	var obligationsJSONFileFormat []models.ObligationJSONFileFormat
+//	@Summary		Export the obligations of licenses
+//	@Description	Export the union of the active obligations of the given licenses as a json or csv file,
+//	@Description	to be attached to an SBOM. Each obligation appears once, annotated with the shortnames
+//	@Description	of the given licenses it applies to. The obligations are streamed from a read only
+//	@Description	snapshot, limited by EXPORT_STATEMENT_TIMEOUT_SECONDS. Exports are rate limited per user
+//	@Description	by EXPORT_RATE_LIMIT per minute.
+//	@Id				ExportLicenseObligations
+//	@Tags			Licenses
+//	@Accept			json
+//	@Produce		json,text/csv
+//	@Param			licenses	body		models.LicenseObligationsExportInput	true	"Shortnames of the licenses"
+//	@Param			format		query		string									false	"Format of the file"	Enums(json, csv)	default(json)
+//	@Success		200			{array}		models.LicenseObligationExport
+//	@Failure		400			{object}	models.ValidationError	"Bad request body or format"
+//	@Failure		429			{object}	models.LicenseError		"Too many export requests"
+//	@Failure		500			{object}	models.LicenseError		"Failed to fetch obligations"
+//	@Security		ApiKeyAuth
+//	@Router			/licenses/obligations/export [post]
+func ExportLicenseObligations(c *gin.Context) {
+	var input models.LicenseObligationsExportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
 
-	if err := db.DB.Model(&models.Obligation{}).Find(&obligations).Error; err != nil {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
 		er := models.LicenseError{
-			Status:    http.StatusInternalServerError,
-			Message:   "Failed to fetch obligations",
-			Error:     err.Error(),
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid format value",
+			Error:     fmt.Sprintf("format must be json or csv, got '%s'", format),
 			Path:      c.Request.URL.Path,
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		c.JSON(http.StatusInternalServerError, er)
+		c.JSON(http.StatusBadRequest, er)
 		return
 	}
 
-	for _, obligation := range obligations {
-		var obligationMaps []models.ObligationMap
-		if err := db.DB.Model(&obligationMaps).Preload("LicenseDB").Where(models.ObligationMap{ObligationPk: obligation.Id}).Find(&obligationMaps).Error; err != nil {
+	_ = db.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Only the shortnames are read ahead, the obligations are streamed as they are read
+		err := setExportStatementTimeout(tx)
+		var obligationIds []int64
+		var shortnamesByObligation map[int64][]string
+		if err == nil {
+			obligationIds, shortnamesByObligation, err = fetchLicenseObligationShortnames(tx, input.Shortnames)
+		}
+		var rows *sql.Rows
+		if err == nil {
+			rows, err = tx.Model(&models.Obligation{}).Where("id IN ?", obligationIds).Order("topic").Rows()
+		}
+		if err != nil {
 			er := models.LicenseError{
 				Status:    http.StatusInternalServerError,
 				Message:   "Failed to fetch obligations",
@@ -675,47 +3822,199 @@ func ExportObligations(c *gin.Context) {
 				Timestamp: time.Now().Format(time.RFC3339),
 			}
 			c.JSON(http.StatusInternalServerError, er)
-			return
+			return err
 		}
+		defer rows.Close()
 
-		var shortnames []string
-		for _, obMap := range obligationMaps {
-			shortnames = append(shortnames, *obMap.LicenseDB.Shortname)
+		fileName := strings.Map(func(r rune) rune {
+			if r == '+' || r == ':' {
+				return '_'
+			}
+			return r
+		}, fmt.Sprintf("license-obligations-export-%s.%s", time.Now().Format(time.RFC3339), format))
+
+		middleware.StreamResponse(c)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Status(http.StatusOK)
+			err = writeLicenseObligationsCSV(c, tx, rows, shortnamesByObligation)
+		} else {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Status(http.StatusOK)
+			err = writeLicenseObligationsJSON(c, tx, rows, shortnamesByObligation)
 		}
+		if err != nil {
+			// The status is already sent, so the client sees a truncated file
+			_ = c.Error(err)
+		}
+		return err
+	}, exportTxOptions()...)
+}
+
+// fetchLicenseObligations returns the active published obligations of the licenses with the
+// given shortnames, ordered by topic, and the shortnames of those licenses each
+// obligation applies to.
+func fetchLicenseObligations(tx *gorm.DB, shortnames []string) ([]models.Obligation, map[int64][]string, error) {
+	obligationIds, shortnamesByObligation, err := fetchLicenseObligationShortnames(tx, shortnames)
+	if err != nil {
+		return nil, nil, err
+	}
+	obligations := []models.Obligation{}
+	if len(obligationIds) != 0 {
+		if err := tx.Where("id IN ?", obligationIds).Order("topic").Find(&obligations).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	return obligations, shortnamesByObligation, nil
+}
+
+// fetchLicenseObligationShortnames returns the ids of the active published obligations of
+// the licenses with the given shortnames, and the shortnames of those licenses each
+// obligation applies to.
+func fetchLicenseObligationShortnames(tx *gorm.DB, shortnames []string) ([]int64, map[int64][]string, error) {
+	var obligationMaps []struct {
+		ObligationPk int64
+		Shortname    string
+	}
+	query := tx.Model(&models.ObligationMap{}).
+		Select("obligation_maps.obligation_pk, license_dbs.rf_shortname AS shortname").
+		Joins("JOIN license_dbs ON license_dbs.rf_id = obligation_maps.rf_pk").
+		Joins("JOIN obligations ON obligations.id = obligation_maps.obligation_pk").
+		Where("license_dbs.rf_shortname IN ?", shortnames).
+		Where("obligations.status = ?", models.OBLIGATION_STATUS_PUBLISHED)
+	filterActiveObligations(query, true)
+	if err := query.Order("license_dbs.rf_shortname").Scan(&obligationMaps).Error; err != nil {
+		return nil, nil, err
+	}
 
-		obJSONFileFormat := models.ObligationJSONFileFormat{
-			Topic:          obligation.Topic,
-			Type:           obligation.Type,
-			Text:           obligation.Text,
-			Shortnames:     shortnames,
-			TextUpdatable:  obligation.TextUpdatable,
-			Active:         obligation.Active,
-			Modifications:  obligation.Modifications,
-			Comment:        obligation.Comment,
-			Classification: obligation.Classification,
+	shortnamesByObligation := make(map[int64][]string)
+	obligationIds := []int64{}
+	for _, obMap := range obligationMaps {
+		if _, ok := shortnamesByObligation[obMap.ObligationPk]; !ok {
+			obligationIds = append(obligationIds, obMap.ObligationPk)
 		}
+		if !slices.Contains(shortnamesByObligation[obMap.ObligationPk], obMap.Shortname) {
+			shortnamesByObligation[obMap.ObligationPk] = append(shortnamesByObligation[obMap.ObligationPk], obMap.Shortname)
+		}
+	}
+	return obligationIds, shortnamesByObligation, nil
+}
+
+// scanObligation scans the obligation of the current row
+func scanObligation(tx *gorm.DB, rows *sql.Rows) (models.Obligation, error) {
+	var obligation models.Obligation
+	if err := tx.ScanRows(rows, &obligation); err != nil {
+		return obligation, err
+	}
+	// ScanRows does not run the hooks decrypting the obligation
+	return obligation, obligation.AfterFind(tx)
+}
 
-		obligationsJSONFileFormat = append(obligationsJSONFileFormat, obJSONFileFormat)
+// writeLicenseObligationsJSON streams the obligations of the rows as a json array,
+// flushing after every obligation.
+func writeLicenseObligationsJSON(c *gin.Context, tx *gorm.DB, rows *sql.Rows,
+	shortnamesByObligation map[int64][]string) error {
+	encoder := json.NewEncoder(c.Writer)
+	if _, err := c.Writer.WriteString("["); err != nil {
+		return err
+	}
+	for i := 0; rows.Next(); i++ {
+		obligation, err := scanObligation(tx, rows)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(licenseObligationExport(obligation, shortnamesByObligation[obligation.Id])); err != nil {
+			return err
+		}
+		c.Writer.Flush()
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err := c.Writer.WriteString("]")
+	return err
+}
 
-	fileName := strings.Map(func(r rune) rune {
-		if r == '+' || r == ':' {
-			return '_'
+// writeLicenseObligationsCSV streams the obligations of the rows as csv rows, flushing
+// after every obligation. Shortnames are joined with ";".
+func writeLicenseObligationsCSV(c *gin.Context, tx *gorm.DB, rows *sql.Rows,
+	shortnamesByObligation map[int64][]string) error {
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write([]string{"topic", "type", "text", "classification", "modifications",
+		"comment", "shortnames"}); err != nil {
+		return err
+	}
+	for rows.Next() {
+		obligation, err := scanObligation(tx, rows)
+		if err != nil {
+			return err
+		}
+		export := licenseObligationExport(obligation, shortnamesByObligation[obligation.Id])
+		if err := writer.Write([]string{export.Topic, export.Type, export.Text, export.Classification,
+			strconv.FormatBool(export.Modifications), export.Comment,
+			strings.Join(export.Shortnames, ";")}); err != nil {
+			return err
 		}
-		return r
-	}, fmt.Sprintf("obligations-export-%s.json", time.Now().Format(time.RFC3339)))
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+	}
+	return rows.Err()
+}
 
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
-	c.JSON(http.StatusOK, &obligationsJSONFileFormat)
+// licenseObligationExport converts an obligation to its export format
+func licenseObligationExport(obligation models.Obligation, shortnames []string) models.LicenseObligationExport {
+	return models.LicenseObligationExport{
+		Topic:          obligation.Topic,
+		Type:           obligation.Type,
+		Text:           obligation.Text,
+		Classification: obligation.Classification,
+		Modifications:  obligation.Modifications,
+		Comment:        obligation.Comment,
+		Shortnames:     shortnames,
+	}
 }
 
-// addChangelogsForObligationUpdate adds changelogs for the updated fields on obligation update
+// addChangelogsForObligationUpdate adds changelogs for the updated fields on obligation update,
+// with the reason of the change on their audit
 func addChangelogsForObligationUpdate(tx *gorm.DB, username string,
-	newObligation, oldObligation *models.Obligation) error {
+	newObligation, oldObligation *models.Obligation, changeReason string) error {
 	var user models.User
 	if err := tx.Where(models.User{Username: username}).First(&user).Error; err != nil {
 		return err
 	}
+
+	changes := obligationChangeLogs(newObligation, oldObligation)
+	if len(changes) != 0 {
+		audit := models.Audit{
+			UserId:       user.Id,
+			TypeId:       newObligation.Id,
+			Timestamp:    time.Now(),
+			Type:         "Obligation",
+			ChangeReason: changeReason,
+			ChangeLogs:   changes,
+		}
+
+		if err := createAudit(tx, &audit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// obligationChangeLogs returns the changelogs of the fields which differ between the
+// old and the new obligation.
+func obligationChangeLogs(newObligation, oldObligation *models.Obligation) []models.ChangeLog {
 	var changes []models.ChangeLog
 
 	if oldObligation.Topic != newObligation.Topic {
@@ -732,7 +4031,7 @@ func addChangelogsForObligationUpdate(tx *gorm.DB, username string,
 			UpdatedValue: &newObligation.Type,
 		})
 	}
-	if oldObligation.Md5 != newObligation.Md5 {
+	if oldObligation.TextHash != newObligation.TextHash {
 		changes = append(changes, models.ChangeLog{
 			Field:        "Text",
 			OldValue:     &oldObligation.Text,
@@ -756,11 +4055,15 @@ func addChangelogsForObligationUpdate(tx *gorm.DB, username string,
 		})
 	}
 	if oldObligation.Comment != newObligation.Comment {
-		changes = append(changes, models.ChangeLog{
-			Field:        "Comment",
-			OldValue:     &oldObligation.Comment,
-			UpdatedValue: &newObligation.Comment,
-		})
+		// An empty comment is logged as a cleared comment
+		change := models.ChangeLog{Field: "Comment"}
+		if oldObligation.Comment != "" {
+			change.OldValue = &oldObligation.Comment
+		}
+		if newObligation.Comment != "" {
+			change.UpdatedValue = &newObligation.Comment
+		}
+		changes = append(changes, change)
 	}
 	if oldObligation.Active != newObligation.Active {
 		oldVal := strconv.FormatBool(oldObligation.Active)
@@ -780,24 +4083,333 @@ func addChangelogsForObligationUpdate(tx *gorm.DB, username string,
 			UpdatedValue: &newVal,
 		})
 	}
+	if oldObligation.Sensitive != newObligation.Sensitive {
+		oldVal := strconv.FormatBool(oldObligation.Sensitive)
+		newVal := strconv.FormatBool(newObligation.Sensitive)
+		changes = append(changes, models.ChangeLog{
+			Field:        "Sensitive",
+			OldValue:     &oldVal,
+			UpdatedValue: &newVal,
+		})
+	}
+	if oldObligation.Status != newObligation.Status {
+		changes = append(changes, models.ChangeLog{
+			Field:        "Status",
+			OldValue:     &oldObligation.Status,
+			UpdatedValue: &newObligation.Status,
+		})
+	}
 
-	if len(changes) != 0 {
-		audit := models.Audit{
-			UserId:     user.Id,
-			TypeId:     newObligation.Id,
-			Timestamp:  time.Now(),
-			Type:       "Obligation",
-			ChangeLogs: changes,
+	if !equalTimes(oldObligation.EffectiveFrom, newObligation.EffectiveFrom) {
+		changes = append(changes, models.ChangeLog{
+			Field:        "EffectiveFrom",
+			OldValue:     formatOptionalTime(oldObligation.EffectiveFrom),
+			UpdatedValue: formatOptionalTime(newObligation.EffectiveFrom),
+		})
+	}
+	if !equalTimes(oldObligation.EffectiveUntil, newObligation.EffectiveUntil) {
+		changes = append(changes, models.ChangeLog{
+			Field:        "EffectiveUntil",
+			OldValue:     formatOptionalTime(oldObligation.EffectiveUntil),
+			UpdatedValue: formatOptionalTime(newObligation.EffectiveUntil),
+		})
+	}
+
+	return changes
+}
+
+// addChangelogForCreatedAtOverride audits that the creation date of a new obligation was
+// overridden by the user, with the time it was actually created at as the old value
+func addChangelogForCreatedAtOverride(tx *gorm.DB, username string, obligation *models.Obligation,
+	recordedAt time.Time) error {
+	var user models.User
+	if err := tx.Where(models.User{Username: username}).First(&user).Error; err != nil {
+		return err
+	}
+
+	oldVal := recordedAt.Format(time.RFC3339)
+	newVal := obligation.CreatedAt.Format(time.RFC3339)
+	audit := models.Audit{
+		UserId:       user.Id,
+		TypeId:       obligation.Id,
+		Timestamp:    time.Now(),
+		Type:         "Obligation",
+		ChangeReason: "Creation date overridden",
+		ChangeLogs: []models.ChangeLog{
+			{
+				Field:        "CreatedAt",
+				OldValue:     &oldVal,
+				UpdatedValue: &newVal,
+			},
+		},
+	}
+	return tx.Create(&audit).Error
+}
+
+// ObligationFieldPolicy returns the obligation fields, by json key, which users of
+// the userlevel may update, or nil if they may update all fields. By default it
+// reads OBLIGATION_FIELD_PERMISSIONS, and it can be replaced in tests.
+var ObligationFieldPolicy = func(userlevel string) []string {
+	return parseObligationFieldPolicy(os.Getenv("OBLIGATION_FIELD_PERMISSIONS"))[userlevel]
+}
+
+// ObligationReviewerUserlevels returns the userlevels which may review and publish
+// obligations. By default it reads the comma separated OBLIGATION_REVIEWER_USERLEVELS,
+// falling back to admin, and it can be replaced in tests.
+var ObligationReviewerUserlevels = func() []string {
+	var userlevels []string
+	for _, userlevel := range strings.Split(os.Getenv("OBLIGATION_REVIEWER_USERLEVELS"), ",") {
+		if userlevel = strings.TrimSpace(userlevel); userlevel != "" {
+			userlevels = append(userlevels, userlevel)
 		}
+	}
+	if len(userlevels) == 0 {
+		return []string{"admin"}
+	}
+	return userlevels
+}
 
-		if err := tx.Create(&audit).Error; err != nil {
-			return err
+// parseObligationFieldPolicy parses a policy of the form
+// "participant:comment,classification;reviewer:comment" to the allowed fields of
+// each userlevel. Userlevels not in the policy are allowed all fields.
+func parseObligationFieldPolicy(policy string) map[string][]string {
+	allowedFields := make(map[string][]string)
+	for _, entry := range strings.Split(policy, ";") {
+		userlevel, fields, found := strings.Cut(entry, ":")
+		userlevel = strings.TrimSpace(userlevel)
+		if !found || userlevel == "" {
+			continue
 		}
+		allowedFields[userlevel] = []string{}
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				allowedFields[userlevel] = append(allowedFields[userlevel], field)
+			}
+		}
+	}
+	return allowedFields
+}
+
+// obligationPatchFields are the json keys of the fields which can be updated with a
+// PATCH request
+var obligationPatchFields = []string{"topic", "type", "text", "classification", "modifications", "comment",
+	"active", "text_updatable", "sensitive", "status", "effective_from", "effective_until"}
+
+// updatedObligationFields returns the json keys of the fields set in the PATCH request
+func updatedObligationFields(updates *models.ObligationPATCHRequestJSONSchema) []string {
+	var fields []string
+	if updates.Topic.IsDefined {
+		fields = append(fields, "topic")
+	}
+	if updates.Type.IsDefined {
+		fields = append(fields, "type")
+	}
+	if updates.Text.IsDefined {
+		fields = append(fields, "text")
+	}
+	if updates.Classification.IsDefined {
+		fields = append(fields, "classification")
+	}
+	if updates.Modifications.IsDefined {
+		fields = append(fields, "modifications")
+	}
+	if updates.Comment.IsDefinedAndNotNull || updates.Comment.IsNull {
+		fields = append(fields, "comment")
+	}
+	if updates.Active.IsDefined {
+		fields = append(fields, "active")
 	}
+	if updates.TextUpdatable.IsDefined {
+		fields = append(fields, "text_updatable")
+	}
+	if updates.Sensitive.IsDefined {
+		fields = append(fields, "sensitive")
+	}
+	if updates.Status.IsDefined {
+		fields = append(fields, "status")
+	}
+	if updates.EffectiveFrom.IsDefinedAndNotNull || updates.EffectiveFrom.IsNull {
+		fields = append(fields, "effective_from")
+	}
+	if updates.EffectiveUntil.IsDefinedAndNotNull || updates.EffectiveUntil.IsNull {
+		fields = append(fields, "effective_until")
+	}
+	return fields
+}
+
+// validateObligationTextUpdate checks that the text of the obligation may be changed
+// by the PATCH request. The change is checked against the text_updatable of the request
+// if it is sent, else against the stored one. So a request may unlock the text and
+// change it, but not change it while locking it. Sending the unchanged text is always
+// allowed.
+func validateObligationTextUpdate(oldObligation *models.Obligation,
+	updates *models.ObligationPATCHRequestJSONSchema) error {
+	if !updates.Text.IsDefined {
+		return nil
+	}
+	if utils.ObligationTextHash(updates.Text.Value) == oldObligation.TextHash {
+		return nil
+	}
+
+	if updates.TextUpdatable.IsDefined && !updates.TextUpdatable.Value {
+		return errors.New("text can not be changed while setting text_updatable to false")
+	}
+	if !updates.TextUpdatable.IsDefined && !oldObligation.TextUpdatable {
+		return errors.New("text of the obligation is not updatable")
+	}
+	return nil
+}
 
+// invalidObligationCommentError writes a 422 ValidationError for a comment starting
+// with models.ENCRYPTED_FIELD_PREFIX, see models.EncryptField.
+func invalidObligationCommentError(c *gin.Context) {
+	er := models.ValidationError{
+		Status:  http.StatusUnprocessableEntity,
+		Message: "invalid json body",
+		Error:   models.ErrEncryptedFieldPrefix.Error(),
+		Errors: []models.FieldError{
+			{
+				Field:   "comment",
+				Rule:    "not_encrypted",
+				Message: fmt.Sprintf("comment must not start with '%s'", models.ENCRYPTED_FIELD_PREFIX),
+			},
+		},
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.JSON(http.StatusUnprocessableEntity, er)
+}
+
+// invalidEffectiveWindowError responds with the validation error of an effective
+// window which ends before it starts.
+func invalidEffectiveWindowError(c *gin.Context, err error) {
+	er := models.ValidationError{
+		Status:  http.StatusUnprocessableEntity,
+		Message: "invalid effective dates",
+		Error:   err.Error(),
+		Errors: []models.FieldError{
+			{
+				Field:   "effective_until",
+				Rule:    "gtfield",
+				Message: err.Error(),
+			},
+		},
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.JSON(http.StatusUnprocessableEntity, er)
+}
+
+// validateEffectiveWindow checks that the obligation does not stop being effective
+// before it starts being effective.
+func validateEffectiveWindow(effectiveFrom, effectiveUntil *time.Time) error {
+	if effectiveFrom != nil && effectiveUntil != nil && !effectiveUntil.After(*effectiveFrom) {
+		return errors.New("effective_until must be after effective_from")
+	}
 	return nil
 }
 
+// obligationReadableBy reports whether the user may read the obligation, which is
+// published or the user is a reviewer.
+func obligationReadableBy(tx *gorm.DB, obligation *models.Obligation, username string) bool {
+	return obligation.Status == models.OBLIGATION_STATUS_PUBLISHED || isObligationReviewer(tx, username)
+}
+
+// isObligationReviewer reports whether the user is a reviewer of obligations. Requests
+// without credentials have no user, which is no reviewer.
+func isObligationReviewer(tx *gorm.DB, username string) bool {
+	if username == "" {
+		return false
+	}
+	var user models.User
+	return tx.Where(models.User{Username: username}).First(&user).Error == nil &&
+		slices.Contains(ObligationReviewerUserlevels(), user.Userlevel)
+}
+
+// includesInactiveObligations matches the requests listing the inactive obligations
+// too, which is reserved to admins on the routes.
+func includesInactiveObligations(c *gin.Context) bool {
+	includeInactive, err := strconv.ParseBool(c.Query("includeInactive"))
+	return err == nil && includeInactive
+}
+
+// filterActiveObligations filters the obligations on the active flag combined with
+// their effective window. An obligation is active only if its active flag is set
+// and the current time is within its effective window.
+func filterActiveObligations(query *gorm.DB, active bool) {
+	now := time.Now()
+	if active {
+		query.Where("active = ?", true).
+			Where("effective_from IS NULL OR effective_from <= ?", now).
+			Where("effective_until IS NULL OR effective_until > ?", now)
+	} else {
+		query.Where("active = ? OR effective_from > ? OR effective_until <= ?", false, now, now)
+	}
+}
+
+// filterObligationStatus restricts the query to published obligations, unless another
+// status is asked for by the status query parameter, which is reserved to reviewers on
+// the routes. Else it writes the error response.
+func filterObligationStatus(c *gin.Context, query *gorm.DB) bool {
+	status := c.Query("status")
+	if status == "" {
+		query.Where("obligations.status = ?", models.OBLIGATION_STATUS_PUBLISHED)
+		return true
+	}
+	if !slices.Contains(models.ObligationStatuses, status) {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid status value",
+			Error:     fmt.Sprintf("status must be one of [%s]", strings.Join(models.ObligationStatuses, " ")),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return false
+	}
+	query.Where("obligations.status = ?", status)
+	return true
+}
+
+// topicReservedFor returns the id of the renamed obligation the topic is a former topic
+// of, or 0 if it is none.
+func topicReservedFor(tx *gorm.DB, topic string) (int64, error) {
+	var redirect models.ObligationTopicRedirect
+	err := tx.Where(models.ObligationTopicRedirect{Topic: topic}).First(&redirect).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return redirect.ObligationPk, err
+}
+
+// renameObligationTopic reserves the former topic of the renamed obligation, redirecting
+// to the obligation. If the obligation took back one of its own former topics, the
+// topic is no longer reserved.
+func renameObligationTopic(tx *gorm.DB, oldObligation *models.Obligation, topic string) error {
+	if err := tx.Where(models.ObligationTopicRedirect{Topic: topic}).
+		Delete(&models.ObligationTopicRedirect{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.ObligationTopicRedirect{Topic: oldObligation.Topic, ObligationPk: oldObligation.Id}).Error
+}
+
+// equalTimes compares two optional timestamps
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// formatOptionalTime formats an optional timestamp for a changelog
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
 // GetAllObligationPreviews retrieves a list of topics and types of all obligations
 //
 //	@Summary		Get topic and types of all active obligations
@@ -806,8 +4418,12 @@ func addChangelogsForObligationUpdate(tx *gorm.DB, username string,
 //	@Tags			Obligations
 //	@Accept			json
 //	@Produce		json
-//	@Param			active	query		bool	true	"Active obligation only"
+//	@Param			active	query		bool	true	"Active obligation only, considering the effective window"
+//	@Param			status	query		string	false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
 //	@Success		200		{object}	models.ObligationPreviewResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid active or status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
 //	@Security		ApiKeyAuth || {}
 //	@Router			/obligations/preview [get]
 func GetAllObligationPreviews(c *gin.Context) {
@@ -830,8 +4446,11 @@ func GetAllObligationPreviews(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, er)
 		return
 	}
-	query := db.DB.Model(&models.Obligation{})
-	query.Where("active = ?", parsedActive)
+	query := db.DB.WithContext(c).Model(&models.Obligation{})
+	filterActiveObligations(query, parsedActive)
+	if !filterObligationStatus(c, query) {
+		return
+	}
 
 	if err = query.Find(&obligations).Error; err != nil {
 		er := models.LicenseError{
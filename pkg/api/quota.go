@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/fossology/LicenseDb/pkg/models"
+)
+
+// errObligationQuotaExceeded is returned when a user created more obligations today
+// than allowed by the daily obligation creation quota.
+var errObligationQuotaExceeded = errors.New("daily obligation creation quota exceeded")
+
+// ObligationCreationQuota returns the number of obligations a user of the userlevel may
+// create per day, or 0 if the user is not limited. By default it reads
+// OBLIGATION_CREATION_QUOTA, or OBLIGATION_CREATION_QUOTA_ADMIN for admins, falling back
+// to DEFAULT_OBLIGATION_CREATION_QUOTA and DEFAULT_OBLIGATION_CREATION_QUOTA_ADMIN, and
+// it can be replaced in tests.
+var ObligationCreationQuota = func(userlevel string) int {
+	env, fallback := "OBLIGATION_CREATION_QUOTA", DEFAULT_OBLIGATION_CREATION_QUOTA
+	if userlevel == "admin" {
+		env, fallback = "OBLIGATION_CREATION_QUOTA_ADMIN", DEFAULT_OBLIGATION_CREATION_QUOTA_ADMIN
+	}
+	quota, err := strconv.Atoi(os.Getenv(env))
+	if err != nil || quota < 0 {
+		return fallback
+	}
+	return quota
+}
+
+// consumeObligationCreationQuota counts created obligations against the daily quota of
+// the user. The count is kept per user and day (UTC) in the obligation_creation_counts
+// table, so it resets at midnight UTC. It returns an error wrapping
+// errObligationQuotaExceeded if the user is over the quota, in which case the caller
+// rolls back the transaction, and with it the count.
+func consumeObligationCreationQuota(tx *gorm.DB, username string, created int) error {
+	var user models.User
+	if err := tx.Where(models.User{Username: username}).First(&user).Error; err != nil {
+		return fmt.Errorf("unable to find user '%s': %w", username, err)
+	}
+	quota := ObligationCreationQuota(user.Userlevel)
+	if quota == 0 {
+		return nil
+	}
+
+	count := models.ObligationCreationCount{
+		UserId:  user.Id,
+		Day:     time.Now().UTC().Format(time.DateOnly),
+		Created: int64(created),
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"created": gorm.Expr("obligation_creation_counts.created + ?", created),
+		}),
+	}).Create(&count).Error; err != nil {
+		return err
+	}
+	if err := tx.Where(models.ObligationCreationCount{UserId: count.UserId, Day: count.Day}).
+		First(&count).Error; err != nil {
+		return err
+	}
+	if count.Created > int64(quota) {
+		return fmt.Errorf("%w: user '%s' may create at most %d obligations per day, the quota resets at midnight UTC",
+			errObligationQuotaExceeded, username, quota)
+	}
+	return nil
+}
+
+// writeObligationQuotaExceeded writes the 429 response of a request over the daily
+// obligation creation quota, with a Retry-After header of the seconds until it resets.
+func writeObligationQuotaExceeded(c *gin.Context, err error) {
+	now := time.Now().UTC()
+	reset := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	er := models.LicenseError{
+		Status:    http.StatusTooManyRequests,
+		Message:   "Daily obligation creation quota exceeded, please try again tomorrow",
+		Error:     err.Error(),
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(reset.Sub(now).Seconds()))))
+	c.JSON(http.StatusTooManyRequests, er)
+}
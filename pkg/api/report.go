@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+)
+
+// REPORT_SIGNATURE_ALGORITHM is the algorithm by which the reports are signed
+const REPORT_SIGNATURE_ALGORITHM = "HMAC-SHA256"
+
+// signReport signs the json encoding of the report with the key, which is the canonical
+// content recipients verify the signature against.
+func signReport(report interface{}, key []byte) (models.ReportSignature, error) {
+	content, err := json.Marshal(report)
+	if err != nil {
+		return models.ReportSignature{}, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return models.ReportSignature{
+		Algorithm: REPORT_SIGNATURE_ALGORITHM,
+		Value:     hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// GetObligationAuditReport compiles the signed audit report of an obligation
+//
+//	@Summary		Get the signed audit report of an obligation
+//	@Description	Compile the full change history of an obligation, its audits and their changelogs
+//	@Description	including the archived ones, oldest first, into a report along with when and by whom it
+//	@Description	was generated. The report is signed by an HMAC-SHA256, hex encoded, over its compact json
+//	@Description	encoding as returned in data, with the key AUDIT_REPORT_SIGNING_KEY. Recipients holding
+//	@Description	the key can so verify that the report was not altered.
+//	@Id				GetObligationAuditReport
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationAuditReportResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to compile the audit report"
+//	@Failure		503		{object}	models.LicenseError	"AUDIT_REPORT_SIGNING_KEY is not set"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/audit-report [get]
+func GetObligationAuditReport(c *gin.Context) {
+	key := os.Getenv("AUDIT_REPORT_SIGNING_KEY")
+	if key == "" {
+		er := models.LicenseError{
+			Status:    http.StatusServiceUnavailable,
+			Message:   "Audit reports are not enabled",
+			Error:     "AUDIT_REPORT_SIGNING_KEY is not set",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusServiceUnavailable, er)
+		return
+	}
+
+	var obligation models.Obligation
+	if !findReadObligation(c, &obligation) {
+		return
+	}
+
+	internalError := func(err error) {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to compile the audit report",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+	}
+
+	var audits []models.Audit
+	if err := db.DB.WithContext(c).Preload("User").Preload("ChangeLogs").
+		Where(models.Audit{Type: "Obligation", TypeId: obligation.Id}).Find(&audits).Error; err != nil {
+		internalError(err)
+		return
+	}
+	var archivedAudits []models.ArchivedAudit
+	if err := db.DB.WithContext(c).Where("LOWER(type) = ? AND type_id = ?", "obligation", obligation.Id).
+		Find(&archivedAudits).Error; err != nil {
+		internalError(err)
+		return
+	}
+
+	// The users of the archived audits may no longer exist, then they are left unnamed
+	var userIds []int64
+	for _, audit := range archivedAudits {
+		userIds = append(userIds, audit.UserId)
+	}
+	var users []models.User
+	if len(userIds) != 0 {
+		if err := db.DB.WithContext(c).Where("id IN ?", userIds).Find(&users).Error; err != nil {
+			internalError(err)
+			return
+		}
+	}
+	usernames := make(map[int64]string, len(users))
+	for _, user := range users {
+		usernames[user.Id] = user.Username
+	}
+
+	entries := make([]models.ObligationAuditReportEntry, 0, len(audits)+len(archivedAudits))
+	for _, audit := range audits {
+		entries = append(entries, models.ObligationAuditReportEntry{
+			AuditId:      audit.Id,
+			Timestamp:    audit.Timestamp,
+			Username:     audit.User.Username,
+			ChangeReason: audit.ChangeReason,
+			Truncated:    audit.Truncated,
+			ChangeLogs:   audit.ChangeLogs,
+		})
+	}
+	for _, audit := range archivedAudits {
+		entries = append(entries, models.ObligationAuditReportEntry{
+			AuditId:      audit.Id,
+			Timestamp:    audit.Timestamp,
+			Username:     usernames[audit.UserId],
+			ChangeReason: audit.ChangeReason,
+			Truncated:    audit.Truncated,
+			Archived:     true,
+			ChangeLogs:   audit.ChangeLogs.Data(),
+		})
+	}
+	for i := range entries {
+		if entries[i].ChangeLogs == nil {
+			entries[i].ChangeLogs = []models.ChangeLog{}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		}
+		return entries[i].AuditId < entries[j].AuditId
+	})
+
+	report := models.ObligationAuditReport{
+		Topic:        obligation.Topic,
+		ObligationId: obligation.Id,
+		GeneratedAt:  time.Now().UTC(),
+		GeneratedBy:  c.GetString("username"),
+		Audits:       entries,
+	}
+	signature, err := signReport(report, []byte(key))
+	if err != nil {
+		internalError(err)
+		return
+	}
+
+	res := models.ObligationAuditReportResponse{
+		Data:      report,
+		Signature: signature,
+		Status:    http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// HIGHLIGHT_RADIUS is the number of bytes of context kept on each side of the
+// first match in a highlight snippet.
+const HIGHLIGHT_RADIUS = 100
+
+// FIND_SNIPPET_RADIUS is the number of characters of context kept on each side of a
+// match found in an obligation.
+const FIND_SNIPPET_RADIUS = 40
+
+// SearchInObligation Search for obligations based on user-provided search criteria.
+//
+//	@Summary		Search obligations
+//	@Description	Search obligations on different filters and algorithms. Each result has a highlight
+//	@Description	field with a snippet of the searched field and the matches wrapped in <mark> tags.
+//	@Description	The snippet is HTML-escaped, so it can be rendered as HTML.
+//	@Description	Comments can not be searched while field encryption is enabled.
+//	@Id				SearchInObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			search	body		models.SearchObligation			true	"Search criteria"
+//	@Param			status	query		string							false	"Reviewers only, obligations of this review status instead of the published ones"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationSearchResponse	"Obligations matched"
+//	@Failure		400		{object}	models.ValidationError			"Invalid request or status, or comment search while field encryption is enabled"
+//	@Failure		401		{object}	models.LicenseError				"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError				"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError				"Search algorithm doesn't exist"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/search [post]
+func SearchInObligation(c *gin.Context) {
+	var input models.SearchObligation
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	// Encrypted comments can not be matched in the database
+	if input.Field == "comment" && models.FieldEncryptionKeyId() != "" {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Comments can not be searched while field encryption is enabled",
+			Error:     "comments are encrypted",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var results []models.ObligationSearchResult
+	query := db.DB.WithContext(c).Model(&models.Obligation{})
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	highlightInDB := false
+
+	if input.Search == "fuzzy" {
+		likeOperator := "LIKE"
+		if db.IsPostgres() {
+			likeOperator = "ILIKE"
+		}
+		query = query.Where(fmt.Sprintf("%s %s ?", input.Field, likeOperator),
+			fmt.Sprintf("%%%s%%", input.SearchTerm))
+	} else if input.Search == "" || input.Search == "full_text_search" {
+		if db.IsPostgres() {
+			query = query.
+				// The field is HTML-escaped before the matches are wrapped in tags
+				Select(fmt.Sprintf("obligations.*, ts_headline(replace(replace(replace(%s, '&', '&amp;'), "+
+					"'<', '&lt;'), '>', '&gt;'), plainto_tsquery(?), ?) AS highlight", input.Field),
+					input.SearchTerm, "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15").
+				Where(input.Field+" @@ plainto_tsquery(?)", input.SearchTerm)
+			highlightInDB = true
+		} else {
+			// Portable fallback matching all the words of the search term
+			for _, word := range strings.Fields(input.SearchTerm) {
+				query = query.Where(fmt.Sprintf("LOWER(%s) LIKE ?", input.Field),
+					fmt.Sprintf("%%%s%%", strings.ToLower(word)))
+			}
+		}
+	} else {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   "search algorithm doesn't exist",
+			Error:     "search algorithm with such name doesn't exists",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	if !highlightInDB {
+		// The highlight is not a column and is added below
+		query = query.Select("obligations.*")
+	}
+	if err := query.Find(&results).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Query failed because of error",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	if !highlightInDB {
+		terms := strings.Fields(input.SearchTerm)
+		if input.Search == "fuzzy" {
+			terms = []string{input.SearchTerm}
+		}
+		for i := range results {
+			results[i].Highlight = utils.HighlightSnippet(searchedObligationField(&results[i].Obligation, input.Field),
+				terms, HIGHLIGHT_RADIUS)
+		}
+	}
+
+	res := models.ObligationSearchResponse{
+		Data:   results,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(results),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// searchedObligationField returns the value of the obligation field which was searched
+func searchedObligationField(obligation *models.Obligation, field string) string {
+	switch field {
+	case "topic":
+		return obligation.Topic
+	case "comment":
+		return obligation.Comment
+	default:
+		return obligation.Text
+	}
+}
+
+// FindInObligation finds a phrase in the text of an obligation
+//
+//	@Summary		Find in an obligation
+//	@Description	Find the occurrences of a phrase in the text of an obligation, compared case-insensitively.
+//	@Description	Each match has its character offset and length in the text, and a snippet of the text
+//	@Description	around it. Reads of sensitive obligations are logged.
+//	@Id				FindInObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			q		query		string	true	"Phrase to find"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationTextMatchesResponse
+//	@Failure		400		{object}	models.LicenseError	"Missing q or invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to log the access"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/find [get]
+func FindInObligation(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Missing q value",
+			Error:     "q must be the phrase to find",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var obligation models.Obligation
+	if !findReadObligation(c, &obligation) {
+		return
+	}
+
+	text := []rune(obligation.Text)
+	length := utf8.RuneCountInString(query)
+	matches := []models.ObligationTextMatch{}
+	for _, offset := range utils.FindAllFold(obligation.Text, query) {
+		start := offset - FIND_SNIPPET_RADIUS
+		if start < 0 {
+			start = 0
+		}
+		end := offset + length + FIND_SNIPPET_RADIUS
+		if end > len(text) {
+			end = len(text)
+		}
+		snippet := string(text[start:end])
+		snippetOffset := offset - start
+		if start > 0 {
+			snippet = "..." + snippet
+			snippetOffset += len("...")
+		}
+		if end < len(text) {
+			snippet += "..."
+		}
+		matches = append(matches, models.ObligationTextMatch{
+			Offset:        offset,
+			Length:        length,
+			Snippet:       snippet,
+			SnippetOffset: snippetOffset,
+		})
+	}
+
+	res := models.ObligationTextMatchesResponse{
+		Data: models.ObligationTextMatches{
+			Topic:   obligation.Topic,
+			Query:   query,
+			Matches: matches,
+		},
+		Status: http.StatusOK,
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// DefaultObligationFixture is the bundled fixture of representative licenses and
+// obligations, for development and CI databases.
+//
+//go:embed fixtures/obligations.json
+var DefaultObligationFixture []byte
+
+// SeedObligations loads the fixture into the database. The licenses are inserted or
+// updated by shortname and the obligations by topic, with their maps replaced by the
+// licenses of their shortnames, so seeding twice gives the same database. If reset
+// is set, all the obligations are deleted first. The fixture is applied in a single
+// transaction, so nothing is seeded if any of it is invalid.
+func SeedObligations(fixture []byte, reset bool) error {
+	var seed models.ObligationFixture
+	if err := json.Unmarshal(fixture, &seed); err != nil {
+		return fmt.Errorf("invalid fixture: %w", err)
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if reset {
+			if err := deleteObligations(tx); err != nil {
+				return fmt.Errorf("unable to delete obligations: %w", err)
+			}
+		}
+
+		for _, license := range seed.Licenses {
+			result := utils.Converter(license)
+			errMessage, importStatus, _, _ := utils.InsertOrUpdateLicenseOnImport(tx, &result,
+				&models.UpdateExternalRefsJSONPayload{ExternalRef: make(map[string]interface{})})
+			if importStatus == utils.IMPORT_FAILED {
+				return fmt.Errorf("license '%s': %s", license.Shortname, errMessage)
+			}
+		}
+
+		for i := range seed.Obligations {
+			if err := seedObligation(tx, &seed.Obligations[i]); err != nil {
+				return fmt.Errorf("obligation '%s': %w", seed.Obligations[i].Topic, err)
+			}
+		}
+
+		log.Printf("Seeded %d licenses and %d obligations", len(seed.Licenses), len(seed.Obligations))
+		return nil
+	})
+}
+
+// seedObligation inserts or updates the obligation of the fixture by topic, with the
+// checks of the obligation import, and maps it to the licenses of its shortnames.
+func seedObligation(tx *gorm.DB, obligation *models.ObligationJSONFileFormat) error {
+	if !utils.IsValidObligationType(obligation.Type) {
+		return fmt.Errorf("unknown obligation type '%s', must be one of [%s]", obligation.Type,
+			strings.Join(utils.ObligationTypes(), " "))
+	}
+	if utils.IsReservedObligationTopic(obligation.Topic) {
+		return errors.New(utils.ReservedObligationTopicMessage("topic"))
+	}
+	if _, ok := classificationSeverity[obligation.Classification]; !ok {
+		return fmt.Errorf("classification must be one of green, white, yellow or red, got '%s'", obligation.Classification)
+	}
+	if err := validateEffectiveWindow(obligation.EffectiveFrom, obligation.EffectiveUntil); err != nil {
+		return err
+	}
+	unknown, err := unknownShortnames(tx, obligation.Shortnames)
+	if err != nil {
+		return err
+	}
+	if len(unknown) != 0 {
+		return fmt.Errorf("unknown license shortnames [%s]", strings.Join(unknown, " "))
+	}
+
+	// Former topics of renamed obligations are reserved
+	reservedFor, err := topicReservedFor(tx, obligation.Topic)
+	if err != nil {
+		return err
+	}
+	if reservedFor != 0 {
+		return errors.New("topic is reserved as the former topic of a renamed obligation")
+	}
+
+	ob := models.Obligation{
+		Topic:          obligation.Topic,
+		Type:           obligation.Type,
+		Text:           obligation.Text,
+		Classification: obligation.Classification,
+		Modifications:  obligation.Modifications,
+		Comment:        obligation.Comment,
+		Active:         obligation.Active,
+		TextUpdatable:  obligation.TextUpdatable,
+		Sensitive:      obligation.Sensitive,
+		Status:         models.OBLIGATION_STATUS_PUBLISHED,
+		EffectiveFrom:  obligation.EffectiveFrom,
+		EffectiveUntil: obligation.EffectiveUntil,
+		NormalizedText: utils.NormalizeObligationText(obligation.Text),
+	}
+	ob.TextHash = utils.NormalizedObligationTextHash(ob.NormalizedText)
+	if obligation.CreatedAt != nil {
+		ob.CreatedAt = *obligation.CreatedAt
+	}
+
+	var sameText models.Obligation
+	err = tx.Where(models.Obligation{TextHash: ob.TextHash}).First(&sameText).Error
+	if err == nil && (sameText.Topic != ob.Topic || obligationTextCollides(&sameText, ob.Text)) {
+		return fmt.Errorf("text is the same as of obligation '%s'", sameText.Topic)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	var existing models.Obligation
+	err = tx.Where(models.Obligation{Topic: ob.Topic}).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Create(&ob).Error; err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		ob.Id = existing.Id
+		if err := tx.Model(&ob).Select("*").Omit("Id", "CreatedAt", "LicenseCount").Updates(&ob).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(models.ObligationMap{ObligationPk: ob.Id}).Delete(&models.ObligationMap{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Obligation{}).Where(models.Obligation{Id: ob.Id}).
+			UpdateColumn("license_count", 0).Error; err != nil {
+			return err
+		}
+	}
+
+	return createObligationMaps(tx, ob.Id, obligation.Shortnames)
+}
+
+// deleteObligations deletes all the obligations along with their maps, translations,
+// notes, tags, watches, redirects, access logs, snapshots, locks and audits.
+func deleteObligations(tx *gorm.DB) error {
+	all := tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+	for _, dependent := range []interface{}{
+		&models.ObligationMap{},
+		&models.ObligationTranslation{},
+		&models.ObligationNote{},
+		&models.ObligationTag{},
+		&models.ObligationWatch{},
+		&models.ObligationTopicRedirect{},
+		&models.ObligationAccessLog{},
+		&models.ObligationSnapshot{},
+		&models.ObligationLock{},
+	} {
+		if err := all.Delete(dependent).Error; err != nil {
+			return err
+		}
+	}
+
+	audits := tx.Model(&models.Audit{}).Select("id").Where(models.Audit{Type: "Obligation"})
+	if err := tx.Where("audit_id IN (?)", audits).Delete(&models.ChangeLog{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where(models.Audit{Type: "Obligation"}).Delete(&models.Audit{}).Error; err != nil {
+		return err
+	}
+	return all.Delete(&models.Obligation{}).Error
+}
@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// createObligationSnapshot takes a snapshot of the obligation for the reason, as the
+// user with the given username.
+func createObligationSnapshot(tx *gorm.DB, username string, obligation *models.Obligation,
+	reason string) (*models.ObligationSnapshot, error) {
+	var user models.User
+	if err := tx.Where(models.User{Username: username}).First(&user).Error; err != nil {
+		return nil, err
+	}
+	snapshot := models.ObligationSnapshot{
+		ObligationPk: obligation.Id,
+		Reason:       reason,
+		UserId:       user.Id,
+		Obligation:   *obligation,
+	}
+	if err := tx.Omit("User").Create(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	snapshot.User = user
+	return &snapshot, nil
+}
+
+// CreateObligationSnapshot takes a snapshot of an obligation
+//
+//	@Summary		Take a snapshot of an obligation
+//	@Description	Take an immutable snapshot of the obligation as it is now, for point in time records.
+//	@Description	Snapshots are also taken on every publication of the obligation.
+//	@Id				CreateObligationSnapshot
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Success		201		{object}	models.ObligationSnapshotResponse
+//	@Failure		404		{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to take snapshot"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/snapshot [post]
+func CreateObligationSnapshot(c *gin.Context) {
+	var obligation models.Obligation
+	topic := c.Param("topic")
+
+	if err := db.DB.WithContext(c).Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	snapshot, err := createObligationSnapshot(db.DB.WithContext(c), c.GetString("username"), &obligation,
+		models.OBLIGATION_SNAPSHOT_REASON_MANUAL)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to take snapshot",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationSnapshotResponse{
+		Data:   []models.ObligationSnapshot{*snapshot},
+		Status: http.StatusCreated,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// GetObligationSnapshots retrieves the snapshots of an obligation
+//
+//	@Summary		Get snapshots of an obligation
+//	@Description	Get the snapshots of an obligation, newest first. Reads of the snapshots of sensitive
+//	@Description	obligations are logged as reads of the obligation.
+//	@Id				GetObligationSnapshots
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of records per page"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationSnapshotResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch snapshots"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/snapshots [get]
+func GetObligationSnapshots(c *gin.Context) {
+	var obligation models.Obligation
+	var snapshots []models.ObligationSnapshot
+	if !findReadObligation(c, &obligation) {
+		return
+	}
+
+	query := db.DB.WithContext(c).Model(&models.ObligationSnapshot{}).
+		Where(models.ObligationSnapshot{ObligationPk: obligation.Id})
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationSnapshotResponse{})
+
+	if err := query.Preload("User").Order("created_at desc").Order("id desc").Find(&snapshots).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch snapshots",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationSnapshotResponse{
+		Data:   snapshots,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(snapshots),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetObligationSnapshot retrieves a snapshot of an obligation
+//
+//	@Summary		Get a snapshot of an obligation
+//	@Description	Get a snapshot of an obligation by its id. Reads of the snapshots of sensitive obligations
+//	@Description	are logged as reads of the obligation.
+//	@Id				GetObligationSnapshot
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			id		path		int		true	"Id of the snapshot"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationSnapshotResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid id or status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic or snapshot with given id found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch snapshot"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/snapshots/{id} [get]
+func GetObligationSnapshot(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "Invalid id value",
+			Error:     fmt.Sprintf("Parsing failed for value '%s'", c.Param("id")),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	var obligation models.Obligation
+	if !findReadObligation(c, &obligation) {
+		return
+	}
+
+	var snapshot models.ObligationSnapshot
+	if err := db.DB.WithContext(c).Preload("User").
+		Where(models.ObligationSnapshot{Id: id, ObligationPk: obligation.Id}).First(&snapshot).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("snapshot with id %d of obligation '%s' not found", id, obligation.Topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	res := models.ObligationSnapshotResponse{
+		Data:   []models.ObligationSnapshot{snapshot},
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// findReadObligation looks up the obligation of the topic of the request, published
+// unless reviewers ask for another status, and logs the read of it, else it writes the
+// error response and returns false.
+func findReadObligation(c *gin.Context, obligation *models.Obligation) bool {
+	topic := c.Param("topic")
+	query := db.DB.WithContext(c).Model(obligation)
+	if !filterObligationStatus(c, query) {
+		return false
+	}
+	if err := query.Where(models.Obligation{Topic: topic}).First(obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return false
+	}
+
+	if err := logObligationAccess(c, obligation); err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to log access to the obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// AssignObligationTag adds a tag to many obligations
+//
+//	@Summary		Assign a tag to obligations
+//	@Description	Add a tag to all the obligations with the given topics, or matching the given filter,
+//	@Description	in one transaction. An audit is written for every obligation which gets the tag.
+//	@Id				AssignObligationTag
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag	body		models.ObligationTagAssignInput	true	"Tag and the obligations to assign it to"
+//	@Success		200	{object}	models.ObligationTagAssignResponse
+//	@Failure		400	{object}	models.ValidationError	"Invalid request body"
+//	@Failure		500	{object}	models.LicenseError		"Unable to assign tag"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/tags/assign [post]
+func AssignObligationTag(c *gin.Context) {
+	updateObligationTag(c, true)
+}
+
+// UnassignObligationTag removes a tag from many obligations
+//
+//	@Summary		Unassign a tag from obligations
+//	@Description	Remove a tag from all the obligations with the given topics, or matching the given filter,
+//	@Description	in one transaction. An audit is written for every obligation which loses the tag.
+//	@Id				UnassignObligationTag
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag	body		models.ObligationTagAssignInput	true	"Tag and the obligations to unassign it from"
+//	@Success		200	{object}	models.ObligationTagAssignResponse
+//	@Failure		400	{object}	models.ValidationError	"Invalid request body"
+//	@Failure		500	{object}	models.LicenseError		"Unable to unassign tag"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/tags/unassign [post]
+func UnassignObligationTag(c *gin.Context) {
+	updateObligationTag(c, false)
+}
+
+// updateObligationTag assigns or unassigns the tag of the request on the selected
+// obligations and responds with the number of obligations changed.
+func updateObligationTag(c *gin.Context, assign bool) {
+	var input models.ObligationTagAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	input.Tag = strings.TrimSpace(input.Tag)
+	if input.Tag == "" || (len(input.Topics) == 0) == (input.Filter == nil) {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     "a non empty tag and either topics or filter must be given",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	action := "assign"
+	if !assign {
+		action = "unassign"
+	}
+	result := models.ObligationTagAssignResult{Tag: input.Tag}
+
+	_ = db.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to " + action + " tag",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		query := tx.Model(&models.Obligation{})
+		if len(input.Topics) != 0 {
+			query.Where("topic IN ?", input.Topics)
+		} else {
+			if input.Filter.Active != nil {
+				filterActiveObligations(query, *input.Filter.Active)
+			}
+			if input.Filter.Type != "" {
+				query.Where(models.Obligation{Type: input.Filter.Type})
+			}
+			if input.Filter.Classification != "" {
+				query.Where(models.Obligation{Classification: input.Filter.Classification})
+			}
+		}
+		var obligationIds []int64
+		if err := query.Pluck("id", &obligationIds).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to " + action + " tag",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return err
+		}
+
+		for _, obligationId := range obligationIds {
+			changed, err := updateTagOfObligation(tx, obligationId, input.Tag, assign, user.Id)
+			if err != nil {
+				er := models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   "Unable to " + action + " tag",
+					Error:     err.Error(),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.JSON(http.StatusInternalServerError, er)
+				return err
+			}
+			if changed {
+				result.Count++
+			}
+		}
+
+		res := models.ObligationTagAssignResponse{
+			Data:   result,
+			Status: http.StatusOK,
+		}
+		c.JSON(http.StatusOK, res)
+		return nil
+	})
+}
+
+// updateTagOfObligation assigns or unassigns the tag on an obligation and writes an
+// audit if it changed. Returns whether the obligation changed.
+func updateTagOfObligation(tx *gorm.DB, obligationId int64, tag string, assign bool, userId int64) (bool, error) {
+	change := models.ChangeLog{Field: "Tags"}
+	if assign {
+		var existing models.ObligationTag
+		err := tx.Where(models.ObligationTag{ObligationPk: obligationId, Tag: tag}).First(&existing).Error
+		if err == nil {
+			return false, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+		if err := tx.Create(&models.ObligationTag{ObligationPk: obligationId, Tag: tag}).Error; err != nil {
+			return false, err
+		}
+		change.UpdatedValue = &tag
+	} else {
+		result := tx.Where(models.ObligationTag{ObligationPk: obligationId, Tag: tag}).Delete(&models.ObligationTag{})
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, nil
+		}
+		change.OldValue = &tag
+	}
+
+	audit := models.Audit{
+		UserId:     userId,
+		TypeId:     obligationId,
+		Timestamp:  time.Now(),
+		Type:       "Obligation",
+		ChangeLogs: []models.ChangeLog{change},
+	}
+	if err := tx.Create(&audit).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
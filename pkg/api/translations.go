@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// GetObligationTranslations retrieves all the translations of an obligation
+//
+//	@Summary		Get translations of an obligation
+//	@Description	Get the translated texts of an obligation in all available languages
+//	@Id				GetObligationTranslations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path		string	true	"Topic of the obligation"
+//	@Param			status	query		string	false	"Reviewers only, the obligation of this review status instead of the published one"	Enums(DRAFT, IN_REVIEW, PUBLISHED)
+//	@Success		200		{object}	models.ObligationTranslationResponse
+//	@Failure		400		{object}	models.LicenseError	"Invalid status value"
+//	@Failure		401		{object}	models.LicenseError	"status without valid credentials"
+//	@Failure		403		{object}	models.LicenseError	"status by a non reviewer"
+//	@Failure		404		{object}	models.LicenseError	"No published obligation with given topic found"
+//	@Failure		500		{object}	models.LicenseError	"Unable to fetch translations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/{topic}/translations [get]
+func GetObligationTranslations(c *gin.Context) {
+	var obligation models.Obligation
+	var translations []models.ObligationTranslation
+	topic := c.Param("topic")
+
+	query := db.DB.Model(&obligation)
+	if !filterObligationStatus(c, query) {
+		return
+	}
+	if err := query.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	if err := db.DB.Where(models.ObligationTranslation{ObligationPk: obligation.Id}).
+		Order("language").Find(&translations).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch translations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationTranslationResponse{
+		Data:   translations,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(translations),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// UpdateObligationTranslation adds or updates the translation of an obligation in a language
+//
+//	@Summary		Add or update a translation of an obligation
+//	@Description	Add or update the text of an obligation in the given language
+//	@Id				UpdateObligationTranslation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic		path		string								true	"Topic of the obligation"
+//	@Param			lang		path		string								true	"Language tag of the translation"	example(de)
+//	@Param			translation	body		models.ObligationTranslationInput	true	"Translated text"
+//	@Success		200			{object}	models.ObligationTranslationResponse
+//	@Failure		400			{object}	models.ValidationError	"Invalid language or request body"
+//	@Failure		404			{object}	models.LicenseError		"No obligation with given topic found"
+//	@Failure		500			{object}	models.LicenseError		"Unable to save translation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/translations/{lang} [put]
+func UpdateObligationTranslation(c *gin.Context) {
+	var obligation models.Obligation
+	var input models.ObligationTranslationInput
+	topic := c.Param("topic")
+
+	language, err := utils.NormalizeLanguage(c.Param("lang"))
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid language",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	translation := models.ObligationTranslation{
+		ObligationPk: obligation.Id,
+		Language:     language,
+	}
+	if err := db.DB.
+		Where(models.ObligationTranslation{ObligationPk: obligation.Id, Language: language}).
+		Assign(models.ObligationTranslation{Text: input.Text}).
+		FirstOrCreate(&translation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to save translation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationTranslationResponse{
+		Data:   []models.ObligationTranslation{translation},
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: 1,
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// translateObligation replaces the text of the obligation with its translation in
+// the most preferred of the given languages which has one. A language with a region
+// falls back to its base language. Returns the language used, or an empty string
+// if no translation is available and the canonical text is kept.
+func translateObligation(obligation *models.Obligation, languages []string) (string, error) {
+	var candidates []string
+	for _, language := range languages {
+		candidates = append(candidates, language)
+		if base, _, found := strings.Cut(language, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	var translations []models.ObligationTranslation
+	if err := db.DB.Where("obligation_pk = ? AND language IN ?", obligation.Id, candidates).
+		Find(&translations).Error; err != nil {
+		return "", err
+	}
+
+	for _, candidate := range candidates {
+		for _, translation := range translations {
+			if translation.Language == candidate {
+				obligation.Text = translation.Text
+				obligation.NormalizedText = utils.NormalizeObligationText(translation.Text)
+				return candidate, nil
+			}
+		}
+	}
+	return "", nil
+}
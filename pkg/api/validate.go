@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// topicSlug matches topics made of lowercase letters and digits, separated by single hyphens.
+var topicSlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateObligations validates a batch of obligations without creating them
+//
+//	@Summary		Validate obligations
+//	@Description	Validate, for each given obligation, that it could be created: the required fields, the
+//	@Description	classification and type, that the topic is a slug of lowercase letters, digits and hyphens,
+//	@Description	that the text has at least minTextLength characters, and that neither the topic nor the
+//	@Description	text already exists, in the database or earlier in the batch, and that the text matches
+//	@Description	expected_md5 if given. All the errors of each obligation are reported. Nothing is
+//	@Description	written, so no admin rights are needed.
+//	@Id				ValidateObligations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			minTextLength	query		int											false	"Texts shorter than this many characters are invalid"	default(20)
+//	@Param			obligations		body		[]models.ObligationPOSTRequestJSONSchema	true	"Obligations to validate"
+//	@Success		200				{object}	models.ObligationValidationResponse
+//	@Failure		400				{object}	models.ValidationError	"Bad request body, invalid minTextLength value or too many obligations"
+//	@Failure		500				{object}	models.LicenseError		"Unable to validate obligations"
+//	@Security		ApiKeyAuth || {}
+//	@Router			/obligations/validate [post]
+func ValidateObligations(c *gin.Context) {
+	minTextLength := DEFAULT_LINT_MIN_TEXT_LENGTH
+	if m := c.Query("minTextLength"); m != "" {
+		var err error
+		if minTextLength, err = strconv.Atoi(m); err != nil || minTextLength < 0 {
+			er := models.LicenseError{
+				Status:    http.StatusBadRequest,
+				Message:   "Invalid minTextLength value",
+				Error:     fmt.Sprintf("Parsing failed for value '%s'", m),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusBadRequest, er)
+			return
+		}
+	}
+
+	// The obligations are decoded without binding, so that the validation errors are
+	// reported per obligation instead of failing the whole batch
+	var input []models.ObligationPOSTRequestJSONSchema
+	if err := json.NewDecoder(c.Request.Body).Decode(&input); err != nil {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     err.Error(),
+			Errors:    utils.GetValidationErrors(err),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+	if len(input) > MAX_DUPLICATE_CHECK_BATCH_SIZE {
+		er := models.ValidationError{
+			Status:    http.StatusBadRequest,
+			Message:   "invalid json body",
+			Error:     fmt.Sprintf("at most %d obligations can be validated at once", MAX_DUPLICATE_CHECK_BATCH_SIZE),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusBadRequest, er)
+		return
+	}
+
+	topics := make([]string, 0, len(input))
+	textHashes := make([]string, 0, len(input))
+	for _, ob := range input {
+		topics = append(topics, ob.Topic)
+		textHashes = append(textHashes, utils.ObligationTextHash(ob.Text))
+	}
+
+	var existing []models.Obligation
+	if len(input) != 0 {
+		if err := db.DB.WithContext(c).Select("topic", "text", "normalized_text", "md5").
+			Where("topic IN ?", topics).Or("md5 IN ?", textHashes).Find(&existing).Error; err != nil {
+			er := models.LicenseError{
+				Status:    http.StatusInternalServerError,
+				Message:   "Unable to validate obligations",
+				Error:     err.Error(),
+				Path:      c.Request.URL.Path,
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			c.JSON(http.StatusInternalServerError, er)
+			return
+		}
+	}
+
+	existingTopics := make(map[string]bool, len(existing))
+	obligationsByHash := make(map[string]models.Obligation, len(existing))
+	for _, ob := range existing {
+		existingTopics[ob.Topic] = true
+		obligationsByHash[ob.TextHash] = ob
+	}
+
+	batchTopics := make(map[string]int, len(input))
+	batchTextHashes := make(map[string]int, len(input))
+	valid := true
+	results := make([]models.ObligationValidationResult, 0, len(input))
+	for i, ob := range input {
+		fieldErrors := []models.FieldError{}
+		if err := binding.Validator.ValidateStruct(&ob); err != nil {
+			fieldErrors = append(fieldErrors, utils.GetValidationErrors(err)...)
+		}
+
+		if ob.Topic != "" {
+			if !topicSlug.MatchString(ob.Topic) {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "topic",
+					Rule:    "slug",
+					Message: "topic must only have lowercase letters, digits and single hyphens between them",
+				})
+			}
+			if existingTopics[ob.Topic] {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "topic",
+					Rule:    "unique",
+					Message: fmt.Sprintf("an obligation with topic '%s' already exists", ob.Topic),
+				})
+			} else if index, ok := batchTopics[ob.Topic]; ok {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "topic",
+					Rule:    "unique",
+					Message: fmt.Sprintf("topic '%s' is already used by obligation %d of the batch", ob.Topic, index),
+				})
+			} else {
+				batchTopics[ob.Topic] = i
+			}
+		}
+
+		if ob.Text != "" {
+			if utf8.RuneCountInString(strings.TrimSpace(ob.Text)) < minTextLength {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "text",
+					Rule:    "min",
+					Message: fmt.Sprintf("text must be at least %d characters", minTextLength),
+				})
+			}
+			if existingObligation, ok := obligationsByHash[textHashes[i]]; ok &&
+				!obligationTextCollides(&existingObligation, ob.Text) {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "text",
+					Rule:    "unique",
+					Message: fmt.Sprintf("text is already the text of obligation '%s'", existingObligation.Topic),
+				})
+			} else if index, ok := batchTextHashes[textHashes[i]]; ok {
+				fieldErrors = append(fieldErrors, models.FieldError{
+					Field:   "text",
+					Rule:    "unique",
+					Message: fmt.Sprintf("text is already the text of obligation %d of the batch", index),
+				})
+			} else {
+				batchTextHashes[textHashes[i]] = i
+			}
+		}
+
+		if ob.ExpectedMd5 != "" && !strings.EqualFold(ob.ExpectedMd5, utils.ObligationTextMD5(ob.Text)) {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "expected_md5",
+				Rule:    "md5",
+				Message: "expected_md5 must be the md5 of the canonical text",
+			})
+		}
+
+		if err := validateEffectiveWindow(ob.EffectiveFrom, ob.EffectiveUntil); err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "effective_until",
+				Rule:    "gtfield",
+				Message: err.Error(),
+			})
+		}
+
+		valid = valid && len(fieldErrors) == 0
+		results = append(results, models.ObligationValidationResult{
+			Index:  i,
+			Topic:  ob.Topic,
+			Valid:  len(fieldErrors) == 0,
+			Errors: fieldErrors,
+		})
+	}
+
+	res := models.ObligationValidationResponse{
+		Data:   results,
+		Status: http.StatusOK,
+		Valid:  valid,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(results),
+		},
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fossology/LicenseDb/pkg/db"
+	"github.com/fossology/LicenseDb/pkg/models"
+	"github.com/fossology/LicenseDb/pkg/utils"
+)
+
+// WatchObligation makes the user watch an obligation
+//
+//	@Summary		Watch an obligation
+//	@Description	Watch an obligation, so it is listed in the obligations watched by the user and its
+//	@Description	changes are notified in the feed /obligations/watched/changes.atom
+//	@Id				WatchObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path	string	true	"Topic of the obligation"
+//	@Success		204
+//	@Failure		404	{object}	models.LicenseError	"No obligation with given topic found"
+//	@Failure		500	{object}	models.LicenseError	"Unable to watch obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/watch [post]
+func WatchObligation(c *gin.Context) {
+	obligation, user, ok := getWatchObligationAndUser(c)
+	if !ok {
+		return
+	}
+
+	watch := models.ObligationWatch{UserId: user.Id, ObligationPk: obligation.Id}
+	if err := db.DB.Omit("User", "Obligation").Where(watch).FirstOrCreate(&watch).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to watch obligation",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnwatchObligation makes the user stop watching an obligation
+//
+//	@Summary		Unwatch an obligation
+//	@Description	Stop watching an obligation
+//	@Id				UnwatchObligation
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	path	string	true	"Topic of the obligation"
+//	@Success		204
+//	@Failure		404	{object}	models.LicenseError	"No obligation with given topic found or not watched"
+//	@Failure		500	{object}	models.LicenseError	"Unable to unwatch obligation"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/{topic}/watch [delete]
+func UnwatchObligation(c *gin.Context) {
+	obligation, user, ok := getWatchObligationAndUser(c)
+	if !ok {
+		return
+	}
+
+	result := db.DB.Where(models.ObligationWatch{UserId: user.Id, ObligationPk: obligation.Id}).
+		Delete(&models.ObligationWatch{})
+	if result.Error != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to unwatch obligation",
+			Error:     result.Error.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	} else if result.RowsAffected == 0 {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' is not watched", obligation.Topic),
+			Error:     "watch not found",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetWatchedObligations retrieves the obligations watched by the user
+//
+//	@Summary		Get watched obligations
+//	@Description	Get the obligations watched by the logged in user
+//	@Id				GetWatchedObligations
+//	@Tags			Obligations
+//	@Accept			json
+//	@Produce		json
+//	@Param			page				query		int		false	"Page number"
+//	@Param			limit				query		int		false	"Number of records per page"
+//	@Param			raw					query		bool	false	"false for the normalized text instead of the text as sent"	default(true)
+//	@Param			X-Response-Envelope	header		string	false	"none for the obligations without the response envelope"	Enums(none)
+//	@Success		200					{object}	models.ObligationResponse
+//	@Failure		400					{object}	models.LicenseError	"Invalid raw value"
+//	@Failure		401					{object}	models.LicenseError	"User not found"
+//	@Failure		500					{object}	models.LicenseError	"Unable to fetch obligations"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/watched [get]
+func GetWatchedObligations(c *gin.Context) {
+	var user models.User
+	var obligations []models.Obligation
+	if err := db.DB.Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnauthorized, er)
+		return
+	}
+
+	query := db.DB.Model(&models.Obligation{}).
+		Joins("JOIN obligation_watches ON obligation_watches.obligation_pk = obligations.id").
+		Where("obligation_watches.user_id = ?", user.Id)
+	_ = utils.PreparePaginateResponse(c, query, &models.ObligationResponse{})
+
+	if err := query.Order("obligations.topic").Find(&obligations).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusInternalServerError,
+			Message:   "Unable to fetch obligations",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusInternalServerError, er)
+		return
+	}
+
+	res := models.ObligationResponse{
+		Data:   obligations,
+		Status: http.StatusOK,
+		Meta: &models.PaginationMeta{
+			ResourceCount: len(obligations),
+		},
+	}
+	writeObligationResponse(c, res, false)
+}
+
+// GetWatchedObligationChangesFeed gives the latest changes of the obligations watched by
+// the user as an Atom feed
+//
+//	@Summary		Get the changes of watched obligations as an Atom feed
+//	@Description	Get the latest changes of the published obligations watched by the logged in user as
+//	@Description	an Atom feed, which notifies the user of the changes of those obligations in a feed
+//	@Description	reader. The entries are those of /obligations/changes.atom.
+//	@Id				GetWatchedObligationChangesFeed
+//	@Tags			Obligations
+//	@Produce		application/atom+xml
+//	@Param			since			query		string				false	"Only the changes after this RFC3339 timestamp"
+//	@Param			classification	query		string				false	"Only the changes of obligations of this classification"	Enums(green, white, yellow, red)
+//	@Success		200				{string}	string				"Atom feed"
+//	@Failure		400				{object}	models.LicenseError	"Invalid since or classification value"
+//	@Failure		401				{object}	models.LicenseError	"User not found"
+//	@Failure		500				{object}	models.LicenseError	"Unable to fetch changes"
+//	@Security		ApiKeyAuth
+//	@Router			/obligations/watched/changes.atom [get]
+func GetWatchedObligationChangesFeed(c *gin.Context) {
+	var user models.User
+	if err := db.DB.Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnauthorized, er)
+		return
+	}
+
+	writeObligationChangesFeed(c, fmt.Sprintf("urn:licensedb:users:%d:watched:changes", user.Id),
+		fmt.Sprintf("LicenseDB changes of the obligations watched by %s", user.Username),
+		path.Dir(path.Dir(c.Request.URL.Path)), user.Id)
+}
+
+// getWatchObligationAndUser finds the obligation of the topic param and the logged
+// in user. It responds with an error and returns false if either is not found.
+func getWatchObligationAndUser(c *gin.Context) (models.Obligation, models.User, bool) {
+	var obligation models.Obligation
+	var user models.User
+	topic := c.Param("topic")
+
+	if err := db.DB.Where(models.Obligation{Topic: topic}).First(&obligation).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusNotFound,
+			Message:   fmt.Sprintf("obligation with topic '%s' not found", topic),
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusNotFound, er)
+		return obligation, user, false
+	}
+
+	if err := db.DB.Where(models.User{Username: c.GetString("username")}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusUnauthorized, er)
+		return obligation, user, false
+	}
+
+	return obligation, user, true
+}
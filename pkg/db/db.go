@@ -4,13 +4,17 @@
 package db
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"github.com/fossology/LicenseDb/pkg/models"
@@ -20,12 +24,29 @@ import (
 // DB is a global variable to store the GORM database connection.
 var DB *gorm.DB
 
+// Supported values of the DB_DRIVER environment variable
+const (
+	DRIVER_POSTGRES = "postgres"
+	DRIVER_SQLITE   = "sqlite"
+)
+
 // Connect establishes a connection to the database using the provided parameters.
+// The driver is selected by the DB_DRIVER environment variable and defaults to
+// postgres. For sqlite, only dbname is used as the path of the database file.
 func Connect(dbhost, port, user, dbname, password *string) {
+	var dialector gorm.Dialector
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", DRIVER_POSTGRES:
+		dburi := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s", *dbhost, *port, *user, *dbname, *password)
+		dialector = postgres.Open(dburi)
+	case DRIVER_SQLITE:
+		dialector = sqlite.Open(*dbname)
+	default:
+		log.Fatalf("Unsupported database driver: %s", driver)
+	}
 
-	dburi := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s", *dbhost, *port, *user, *dbname, *password)
 	gormConfig := &gorm.Config{}
-	database, err := gorm.Open(postgres.Open(dburi), gormConfig)
+	database, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -33,6 +54,279 @@ func Connect(dbhost, port, user, dbname, password *string) {
 	DB = database
 }
 
+// IsPostgres reports whether the database connection uses the postgres driver.
+// Postgres specific queries must be guarded by it and have a portable fallback.
+func IsPostgres() bool {
+	return DB.Dialector.Name() == DRIVER_POSTGRES
+}
+
+// NormalizeObligationTypes lower cases and trims the types of the existing
+// obligations, and logs the obligations whose type is still not allowed so they
+// can be fixed by hand.
+func NormalizeObligationTypes(tx *gorm.DB) error {
+	result := tx.Model(&models.Obligation{}).
+		Where("type <> LOWER(TRIM(type))").
+		Update("type", gorm.Expr("LOWER(TRIM(type))"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Normalized types of %d obligations", result.RowsAffected)
+	}
+
+	var topics []string
+	if err := tx.Model(&models.Obligation{}).Where("type NOT IN ?", utils.ObligationTypes()).
+		Pluck("topic", &topics).Error; err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		log.Printf("Obligation %s has a type which is not one of %v", topic, utils.ObligationTypes())
+	}
+	return nil
+}
+
+// BackfillNormalizedObligationTexts stores the normalized form of the texts of the
+// obligations created before it was stored. Only obligations without a normalized
+// text are updated.
+func BackfillNormalizedObligationTexts(tx *gorm.DB) error {
+	var obligations []models.Obligation
+	count := 0
+	result := tx.Select("id", "text").Where("normalized_text = ''").
+		FindInBatches(&obligations, 100, func(_ *gorm.DB, batch int) error {
+			for _, obligation := range obligations {
+				if err := tx.Model(&models.Obligation{}).Where(models.Obligation{Id: obligation.Id}).
+					UpdateColumn("normalized_text", utils.NormalizeObligationText(obligation.Text)).Error; err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if count > 0 {
+		log.Printf("Backfilled normalized texts of %d obligations", count)
+	}
+	return nil
+}
+
+// RehashObligationTexts replaces the md5 text hashes of the obligations created before
+// the switch to SHA-256. Only obligations whose hash is not of the length of a hex
+// encoded SHA-256 are updated. Texts which only differed by what the normalization
+// removes, like a BOM or CRLF line endings, now get the same hash, which the unique
+// column can not hold: such obligations keep their md5 and are logged, to be merged
+// by hand, instead of failing the migration.
+func RehashObligationTexts(tx *gorm.DB) error {
+	var obligations []models.Obligation
+	count := 0
+	duplicates := 0
+	result := tx.Select("id", "topic", "text").Where("md5 IS NULL OR LENGTH(md5) <> ?", 2*sha256.Size).
+		FindInBatches(&obligations, 100, func(_ *gorm.DB, batch int) error {
+			for _, obligation := range obligations {
+				hash := utils.ObligationTextHash(obligation.Text)
+				var duplicate models.Obligation
+				found := tx.Select("topic").Where("md5 = ? AND id <> ?", hash, obligation.Id).Limit(1).Find(&duplicate)
+				if found.Error != nil {
+					return fmt.Errorf("obligation %d: %w", obligation.Id, found.Error)
+				}
+				if found.RowsAffected > 0 {
+					log.Printf("Obligation %s has the same normalized text as %s, its md5 is kept until they are merged",
+						obligation.Topic, duplicate.Topic)
+					duplicates++
+					continue
+				}
+				if err := tx.Model(&models.Obligation{}).Where(models.Obligation{Id: obligation.Id}).
+					UpdateColumn("md5", hash).Error; err != nil {
+					return fmt.Errorf("obligation %d: %w", obligation.Id, err)
+				}
+				count++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if count > 0 {
+		log.Printf("Rehashed texts of %d obligations", count)
+	}
+	if duplicates > 0 {
+		log.Printf("Kept the md5 of %d obligations duplicating the text of another one", duplicates)
+	}
+	return nil
+}
+
+// RecountObligationLicenses sets the license count of every obligation to the number
+// of its obligation maps, for the obligations created before the count was kept.
+func RecountObligationLicenses(tx *gorm.DB) error {
+	count := tx.Model(&models.ObligationMap{}).Select("COUNT(*)").
+		Where("obligation_maps.obligation_pk = obligations.id")
+	result := tx.Model(&models.Obligation{}).Where("license_count <> (?)", count).
+		UpdateColumn("license_count", count)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Recounted licenses of %d obligations", result.RowsAffected)
+	}
+	return nil
+}
+
+// EnableTrigramSimilarity creates the pg_trgm extension, by which the similarity of
+// obligation texts is computed in the database. Other databases compute it in Go.
+func EnableTrigramSimilarity(tx *gorm.DB) error {
+	if tx.Dialector.Name() != DRIVER_POSTGRES {
+		return nil
+	}
+	return tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error
+}
+
+// BackfillObligationTimestamps sets the creation and update times of the obligations
+// created before they were kept, which are otherwise left out by the filters on them.
+// The creation time is taken from the first audit of the obligation and the update
+// time from the last one, else from each other, else the time of the migration.
+func BackfillObligationTimestamps(tx *gorm.DB) error {
+	audits := func(aggregate string) *gorm.DB {
+		return tx.Model(&models.Audit{}).Select(aggregate+"(audits.timestamp)").
+			Where("audits.type = ? AND audits.type_id = obligations.id", "Obligation")
+	}
+	now := time.Now()
+	created := tx.Model(&models.Obligation{}).Where("created_at IS NULL").
+		UpdateColumn("created_at", gorm.Expr("COALESCE((?), updated_at, ?)", audits("MIN"), now))
+	if created.Error != nil {
+		return created.Error
+	}
+	updated := tx.Model(&models.Obligation{}).Where("updated_at IS NULL").
+		UpdateColumn("updated_at", gorm.Expr("COALESCE((?), created_at)", audits("MAX")))
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if created.RowsAffected > 0 || updated.RowsAffected > 0 {
+		log.Printf("Backfilled creation times of %d and update times of %d obligations",
+			created.RowsAffected, updated.RowsAffected)
+	}
+	return nil
+}
+
+// OBLIGATION_MAP_INDEX is the unique index of the obligation maps, by which an
+// obligation is mapped to a license at most once.
+const OBLIGATION_MAP_INDEX = "idx_obligation_map_license"
+
+// CreateUniqueObligationMapIndex removes the duplicate maps of an obligation to the
+// same license, keeping the first one, and creates the unique index which prevents
+// them. The license counts of the obligations are recounted without the duplicates.
+func CreateUniqueObligationMapIndex(tx *gorm.DB) error {
+	first := tx.Model(&models.ObligationMap{}).Select("MIN(om_pk)").Group("obligation_pk, rf_pk")
+	result := tx.Where("om_pk NOT IN (?)", first).Delete(&models.ObligationMap{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d duplicate obligation maps", result.RowsAffected)
+		if err := RecountObligationLicenses(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + OBLIGATION_MAP_INDEX +
+		" ON obligation_maps (obligation_pk, rf_pk)").Error
+}
+
+// EncryptObligationComments encrypts the obligation comments, along with the changelogs
+// of their changes and the obligation snapshots, which are not encrypted with the key of FIELD_ENCRYPTION_KEY_ID yet.
+// This covers the comments saved before field encryption was enabled as well as the
+// ones encrypted with a rotated key. It is safe to run on every start.
+func EncryptObligationComments() {
+	keyId := models.FieldEncryptionKeyId()
+	if keyId == "" {
+		return
+	}
+	encryptedPrefix := models.ENCRYPTED_FIELD_PREFIX + keyId + ":"
+	reencrypt := func(value *string) (*string, error) {
+		if value == nil || *value == "" || strings.HasPrefix(*value, encryptedPrefix) {
+			return value, nil
+		}
+		plain, err := models.DecryptField(*value)
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := models.EncryptField(plain)
+		return &encrypted, err
+	}
+
+	// The columns are scanned into plain structs so the hooks do not decrypt them
+	var obligations []struct {
+		Id      int64
+		Comment string
+	}
+	if err := DB.Model(&models.Obligation{}).Select("id", "comment").
+		Where("comment <> '' AND comment NOT LIKE ?", encryptedPrefix+"%").Find(&obligations).Error; err != nil {
+		log.Fatalf("Failed to fetch obligation comments: %v", err)
+	}
+	for _, obligation := range obligations {
+		comment, err := reencrypt(&obligation.Comment)
+		if err == nil {
+			err = DB.Model(&models.Obligation{}).Where(models.Obligation{Id: obligation.Id}).
+				UpdateColumn("comment", *comment).Error
+		}
+		if err != nil {
+			log.Fatalf("Failed to encrypt comment of obligation %d: %v", obligation.Id, err)
+		}
+	}
+
+	var changes []struct {
+		Id           int64
+		OldValue     *string
+		UpdatedValue *string
+	}
+	if err := DB.Model(&models.ChangeLog{}).Select("id", "old_value", "updated_value").
+		Where(models.ChangeLog{Field: "Comment"}).Find(&changes).Error; err != nil {
+		log.Fatalf("Failed to fetch changelogs of obligation comments: %v", err)
+	}
+	count := 0
+	for _, change := range changes {
+		oldValue, err := reencrypt(change.OldValue)
+		if err != nil {
+			log.Fatalf("Failed to encrypt changelog %d: %v", change.Id, err)
+		}
+		updatedValue, err := reencrypt(change.UpdatedValue)
+		if err != nil {
+			log.Fatalf("Failed to encrypt changelog %d: %v", change.Id, err)
+		}
+		if oldValue == change.OldValue && updatedValue == change.UpdatedValue {
+			continue
+		}
+		if err := DB.Model(&models.ChangeLog{}).Where(models.ChangeLog{Id: change.Id}).
+			UpdateColumns(map[string]interface{}{"old_value": oldValue, "updated_value": updatedValue}).Error; err != nil {
+			log.Fatalf("Failed to encrypt changelog %d: %v", change.Id, err)
+		}
+		count++
+	}
+
+	var snapshots []struct {
+		Id   int64
+		Data string
+	}
+	if err := DB.Model(&models.ObligationSnapshot{}).Select("id", "data").
+		Where("data NOT LIKE ?", encryptedPrefix+"%").Find(&snapshots).Error; err != nil {
+		log.Fatalf("Failed to fetch obligation snapshots: %v", err)
+	}
+	for _, snapshot := range snapshots {
+		data, err := reencrypt(&snapshot.Data)
+		if err == nil {
+			err = DB.Model(&models.ObligationSnapshot{}).Where(models.ObligationSnapshot{Id: snapshot.Id}).
+				UpdateColumn("data", *data).Error
+		}
+		if err != nil {
+			log.Fatalf("Failed to encrypt obligation snapshot %d: %v", snapshot.Id, err)
+		}
+	}
+
+	if len(obligations) > 0 || count > 0 || len(snapshots) > 0 {
+		log.Printf("Encrypted comments of %d obligations, %d changelogs and %d snapshots with key %s",
+			len(obligations), count, len(snapshots), keyId)
+	}
+}
+
 // Populatedb populates the database with license data from a JSON file.
 func Populatedb(datafile string) {
 	var licenses []models.LicenseJson
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fossology/LicenseDb/pkg/models"
+)
+
+// Migration is a versioned change of the data of the database, for what AutoMigrate
+// can not do, like backfills of new columns. The migrations are applied once each, in
+// the order of their versions, and recorded in the schema_migrations table.
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(tx *gorm.DB) error
+}
+
+// Migrations are the migrations of the database ordered by version. A new migration
+// is appended with the next version; applied migrations must not be changed, as they
+// are not applied again. Migrations must be safe to apply on a database which does
+// not need them, as they are applied to new databases too.
+var Migrations = []Migration{
+	{Version: 1, Description: "Normalize obligation types", Migrate: NormalizeObligationTypes},
+	{Version: 2, Description: "Backfill normalized obligation texts", Migrate: BackfillNormalizedObligationTexts},
+	{Version: 3, Description: "Rehash obligation texts with SHA-256", Migrate: RehashObligationTexts},
+	{Version: 4, Description: "Recount obligation licenses", Migrate: RecountObligationLicenses},
+	{Version: 5, Description: "Enable trigram similarity", Migrate: EnableTrigramSimilarity},
+	{Version: 6, Description: "Backfill obligation timestamps", Migrate: BackfillObligationTimestamps},
+	{Version: 7, Description: "Create unique index of obligation maps", Migrate: CreateUniqueObligationMapIndex},
+}
+
+// PendingMigrations returns the migrations which are not applied yet, in order.
+func PendingMigrations() ([]Migration, error) {
+	var applied []int
+	if DB.Migrator().HasTable(&models.SchemaMigration{}) {
+		if err := DB.Model(&models.SchemaMigration{}).Pluck("version", &applied).Error; err != nil {
+			return nil, err
+		}
+	}
+	appliedVersions := make(map[int]bool, len(applied))
+	for _, version := range applied {
+		appliedVersions[version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range Migrations {
+		if !appliedVersions[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// RunMigrations applies the pending migrations in order. Each migration is applied in
+// a transaction along with its record, so a failed migration is rolled back and applied
+// again on the next run, and the later migrations are not applied before it.
+func RunMigrations() error {
+	if err := DB.AutoMigrate(&models.SchemaMigration{}); err != nil {
+		return err
+	}
+	pending, err := PendingMigrations()
+	if err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&models.SchemaMigration{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		log.Printf("Applied migration %d: %s", migration.Version, migration.Description)
+	}
+	return nil
+}
@@ -8,13 +8,21 @@ package middleware
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fossology/LicenseDb/pkg/db"
@@ -22,92 +30,394 @@ import (
 	"github.com/fossology/LicenseDb/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/exp/slices"
 )
 
 // AuthenticationMiddleware is a middleware function for user authentication.
 func AuthenticationMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
+		if authenticate(c) {
+			c.Next()
+		}
+	}
+}
 
-		if tokenString == "" {
-			er := models.LicenseError{
-				Status:    http.StatusUnauthorized,
-				Message:   "Please check your credentials and try again",
-				Error:     "no credentials were passed",
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
+// authenticate sets the username of the user the request is made by, else it
+// writes the error response and aborts the request.
+func authenticate(c *gin.Context) bool {
+	username, er := authenticatedUsername(c)
+	if er != nil {
+		c.JSON(er.Status, er)
+		c.Abort()
+		return false
+	}
+	c.Set("username", username)
+	return true
+}
 
-			c.JSON(http.StatusUnauthorized, er)
-			c.Abort()
+// authenticatedUsername gives the username of the user the request is made by, else
+// the error to respond with.
+func authenticatedUsername(c *gin.Context) (string, *models.LicenseError) {
+	tokenString := c.GetHeader("Authorization")
+
+	if tokenString == "" {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "Please check your credentials and try again",
+			Error:     "no credentials were passed",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		return "", &er
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("API_SECRET")), nil
+	})
+
+	if err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "Please check your credentials and try again",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		return "", &er
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "Invalid token",
+			Error:     "Invalid token",
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		return "", &er
+	}
+
+	userId := int64(claims["user"].(map[string]interface{})["id"].(float64))
+
+	var user models.User
+	if err := db.DB.Where(models.User{Id: userId}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		return "", &er
+	}
+
+	return user.Username, nil
+}
+
+// AdminMiddleware restricts the route to users with admin userlevel. It must
+// be used after AuthenticationMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return UserlevelMiddleware(func() []string { return []string{"admin"} })
+}
+
+// UserlevelMiddleware restricts the route to users whose userlevel is one of the
+// userlevels. They are read on every request, so that they follow the configuration.
+// It must be used after AuthenticationMiddleware.
+func UserlevelMiddleware(userlevels func() []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if AuthorizeUserlevel(c, userlevels()...) {
+			c.Next()
+		}
+	}
+}
+
+// AuthorizeIfMiddleware runs the authorization middleware only for the requests
+// matching the condition, e.g. the ones setting a query parameter which is reserved
+// to some users. Read routes may be served without authentication, in which case the
+// credentials of the request are checked first.
+func AuthorizeIfMiddleware(condition func(c *gin.Context) bool, authorization gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !condition(c) {
+			c.Next()
 			return
 		}
+		if c.GetString("username") == "" && !authenticate(c) {
+			return
+		}
+		authorization(c)
+	}
+}
+
+// HasQueryCondition is a condition of AuthorizeIfMiddleware matching the requests
+// which set the query parameter.
+func HasQueryCondition(key string) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		return c.Query(key) != ""
+	}
+}
+
+// AuthorizeUserlevel checks that the request is made by a user whose userlevel is
+// one of the userlevels, else it writes the error response and aborts the request.
+// It is meant for the restrictions depending on the request body, which the route
+// middlewares can not see.
+func AuthorizeUserlevel(c *gin.Context, userlevels ...string) bool {
+	var user models.User
+	username := c.GetString("username")
+	if err := db.DB.Where(models.User{Username: username}).First(&user).Error; err != nil {
+		er := models.LicenseError{
+			Status:    http.StatusUnauthorized,
+			Message:   "User not found",
+			Error:     err.Error(),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+
+		c.JSON(http.StatusUnauthorized, er)
+		c.Abort()
+		return false
+	}
+
+	if !slices.Contains(userlevels, user.Userlevel) {
+		er := models.LicenseError{
+			Status:    http.StatusForbidden,
+			Message:   fmt.Sprintf("Users with userlevel '%s' can not access this resource", user.Userlevel),
+			Error:     fmt.Sprintf("userlevel of user '%s' must be one of [%s]", username, strings.Join(userlevels, " ")),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+
+		c.JSON(http.StatusForbidden, er)
+		c.Abort()
+		return false
+	}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	return true
+}
+
+// RateLimitNow gives the current time by which the windows of the rate limits are
+// counted. Tests replace it to move through the windows without waiting.
+var RateLimitNow = time.Now
+
+// RateLimitMiddleware limits each client to limit requests per window on the
+// routes it is used on. Clients are identified by their username if logged in,
+// else by their IP address. Requests over the limit get a 429 response. Every
+// middleware counts the requests of its routes on its own.
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	type clientWindow struct {
+		start time.Time
+		count int
+	}
+	var mu sync.Mutex
+	clients := make(map[string]*clientWindow)
+
+	return func(c *gin.Context) {
+		client := c.GetString("username")
+		if client == "" {
+			client = c.ClientIP()
+		}
+		now := RateLimitNow()
+
+		mu.Lock()
+		for key, cw := range clients {
+			if now.Sub(cw.start) >= window {
+				delete(clients, key)
 			}
-			return []byte(os.Getenv("API_SECRET")), nil
-		})
+		}
+		cw, ok := clients[client]
+		if !ok {
+			cw = &clientWindow{start: now}
+			clients[client] = cw
+		}
+		cw.count++
+		count, retryAfter := cw.count, cw.start.Add(window).Sub(now)
+		mu.Unlock()
 
-		if err != nil {
+		if count > limit {
 			er := models.LicenseError{
-				Status:    http.StatusUnauthorized,
-				Message:   "Please check your credentials and try again",
-				Error:     err.Error(),
+				Status:    http.StatusTooManyRequests,
+				Message:   "Too many requests, please try again later",
+				Error:     fmt.Sprintf("at most %d requests are allowed per %s", limit, window),
 				Path:      c.Request.URL.Path,
 				Timestamp: time.Now().Format(time.RFC3339),
 			}
 
-			c.JSON(http.StatusUnauthorized, er)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, er)
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			er := models.LicenseError{
-				Status:    http.StatusUnauthorized,
-				Message:   "Invalid token",
-				Error:     "Invalid token",
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
+		c.Next()
+	}
+}
+
+// RecoveryMiddleware recovers from panics in the handlers. The panic and its stack
+// are logged with a random request id, and the client gets a LicenseError with the
+// same id and without any internal details.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Later middlewares may replace the writer with one that buffers the body
+		writer := c.Writer
+		defer func() {
+			if r := recover(); r != nil {
+				c.Writer = writer
+				id := newRequestId()
+
+				log.Printf("[Recovery] request %s %s %s panicked: %v\n%s",
+					id, c.Request.Method, c.Request.URL.Path, r, debug.Stack())
+
+				er := models.LicenseError{
+					Status:    http.StatusInternalServerError,
+					Message:   "Something went wrong, please report the request id to the administrator",
+					Error:     fmt.Sprintf("internal server error, request id %s", id),
+					Path:      c.Request.URL.Path,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+
+				c.Header("X-Request-Id", id)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, er)
 			}
+		}()
 
-			c.JSON(http.StatusUnauthorized, er)
-			c.Abort()
+		c.Next()
+	}
+}
+
+// newRequestId returns a random id to correlate a response with the server logs.
+func newRequestId() string {
+	requestId := make([]byte, 8)
+	_, _ = rand.Read(requestId)
+	return hex.EncodeToString(requestId)
+}
+
+// ErrorVerbosityMiddleware hides the internal errors of the server from the clients
+// unless debug is set. The error of a json error response with a 5xx status, which
+// may carry raw database errors, is logged with a random request id and replaced
+// with a generic error with the same id. It must be used after PaginationMiddleware
+// and before TimeoutMiddleware.
+func ErrorVerbosityMiddleware(debug bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if debug || c.Writer.Status() < http.StatusInternalServerError {
+			return
+		}
+		writer, ok := c.Writer.(*bodyWriter)
+		if !ok || writer.stream || !strings.HasPrefix(c.Writer.Header().Get("Content-Type"), gin.MIMEJSON) {
+			return
+		}
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(writer.body.Bytes(), &body); err != nil {
+			return
+		}
+		var internalError string
+		if err := json.Unmarshal(body["error"], &internalError); err != nil || internalError == "" {
 			return
 		}
 
-		userId := int64(claims["user"].(map[string]interface{})["id"].(float64))
+		id := newRequestId()
+		log.Printf("[Error] request %s %s %s failed with status %d: %s",
+			id, c.Request.Method, c.Request.URL.Path, c.Writer.Status(), internalError)
+		body["error"], _ = json.Marshal(fmt.Sprintf("internal server error, request id %s", id))
+		newBody, err := json.Marshal(body)
+		if err != nil {
+			return
+		}
+		writer.body.Reset()
+		writer.body.Write(newBody)
+		c.Header("X-Request-Id", id)
+	}
+}
 
-		var user models.User
-		if err := db.DB.Where(models.User{Id: userId}).First(&user).Error; err != nil {
-			er := models.LicenseError{
-				Status:    http.StatusUnauthorized,
-				Message:   "User not found",
-				Error:     err.Error(),
-				Path:      c.Request.URL.Path,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
+// TimeoutMiddleware cancels the context of the request after timeout, so the
+// database queries run with it are cancelled, and replaces the response of the
+// handler with a 504 LicenseError. Used in a nested route group, it overrides the
+// timeout of the outer group, where a timeout of 0 disables it. Streamed responses
+// are cancelled but not replaced. It must be used after PaginationMiddleware.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := c.Request.Context()
+		if outer, ok := c.Get("requestContext"); ok {
+			parent = outer.(context.Context)
+		} else {
+			c.Set("requestContext", parent)
+		}
 
-			c.JSON(http.StatusUnauthorized, er)
-			c.Abort()
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, timeout)
+		}
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// Only the innermost timeout applies
+		if c.Request.Context() != ctx || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			return
 		}
+		writer, ok := c.Writer.(*bodyWriter)
+		if !ok || writer.stream {
+			return
+		}
+		writer.body.Reset()
+		delete(c.Keys, "paginationMeta")
+		er := models.LicenseError{
+			Status:    http.StatusGatewayTimeout,
+			Message:   "The request took too long, please try again later",
+			Error:     fmt.Sprintf("request exceeded the timeout of %s", timeout),
+			Path:      c.Request.URL.Path,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		c.JSON(http.StatusGatewayTimeout, er)
+	}
+}
 
-		c.Set("username", user.Username)
+// PublicRoutesMiddleware serves the routes matching the public routes without
+// authentication and authenticates the requests of the other routes. The requests of
+// the public routes passing valid credentials are authenticated too, so the user is
+// known to the handlers, while those passing invalid ones are served anonymously. The
+// routes are matched without the base path.
+func PublicRoutesMiddleware(basePath string, publicRoutes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsPublicRoute(strings.TrimPrefix(c.FullPath(), basePath), publicRoutes) {
+			if authenticate(c) {
+				c.Next()
+			}
+			return
+		}
+		if c.GetHeader("Authorization") != "" {
+			if username, er := authenticatedUsername(c); er == nil {
+				c.Set("username", username)
+			}
+		}
 		c.Next()
 	}
 }
 
+// IsPublicRoute reports whether the route is one of the public routes or below one of
+// them, e.g. /obligations/:topic below /obligations. The public route * matches all
+// the routes.
+func IsPublicRoute(route string, publicRoutes []string) bool {
+	for _, public := range publicRoutes {
+		if public == "*" || route == public || strings.HasPrefix(route, strings.TrimSuffix(public, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // CORSMiddleware is a middleware function for CORS.
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Disposition, Link")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -149,50 +459,30 @@ func PaginationMiddleware() gin.HandlerFunc {
 		c.Set("page", page)
 		c.Next()
 
+		// Streamed responses are already written to the client
+		if writer.stream {
+			return
+		}
+
 		// Get the pagination information from route after processing
 		metaValue, paginationExists := c.Get("paginationMeta")
 
 		// Handle only 200 responses with paginationMeta
 		if paginationExists && c.Writer.Status() == 200 {
-			originalBody := writer.body.Bytes()
-			var metaObject *models.PaginationMeta
-
-			// Try the body with different possible unmarshalling before failing
-			var licenseRes models.LicenseResponse
-			var obligationRes models.ObligationResponse
-			var auditRes models.AuditResponse
-			var userRes models.UserResponse
-			isLicenseRes := false
-			isObligationRes := false
-			isAuditRes := false
-			isUserRes := false
-			responseModel, _ := c.Get("responseModel")
-			switch responseModel.(type) {
-			case *models.LicenseResponse:
-				err = json.Unmarshal(originalBody, &licenseRes)
-				isLicenseRes = true
-				metaObject = licenseRes.Meta
-			case *models.ObligationResponse:
-				err = json.Unmarshal(originalBody, &obligationRes)
-				isObligationRes = true
-				metaObject = obligationRes.Meta
-			case *models.AuditResponse:
-				err = json.Unmarshal(originalBody, &auditRes)
-				isAuditRes = true
-				metaObject = auditRes.Meta
-			case *models.UserResponse:
-				err = json.Unmarshal(originalBody, &userRes)
-				isUserRes = true
-				metaObject = userRes.Meta
-			default:
-				err = fmt.Errorf("unknown response model type")
+			paginationMeta := metaValue.(models.PaginationMeta)
+
+			// Only the pagination meta of the body is rewritten, whatever the response model
+			var body map[string]json.RawMessage
+			metaObject := &models.PaginationMeta{}
+			err := json.Unmarshal(writer.body.Bytes(), &body)
+			if err == nil && body["paginationmeta"] != nil {
+				err = json.Unmarshal(body["paginationmeta"], metaObject)
 			}
 			if err != nil {
-				log.Fatalf("Error marshalling new body: %s", err)
+				paginationError(c, writer, err)
+				return
 			}
 
-			paginationMeta := metaValue.(models.PaginationMeta)
-
 			// Get the query params from the request
 			params := c.Request.URL.Query()
 
@@ -216,41 +506,222 @@ func PaginationMiddleware() gin.HandlerFunc {
 			}
 
 			// Marshal the new body
+			body["paginationmeta"], err = json.Marshal(metaObject)
 			var newBody []byte
-			var err error
-			if isLicenseRes {
-				newBody, err = json.Marshal(licenseRes)
-			} else if isObligationRes {
-				newBody, err = json.Marshal(obligationRes)
-			} else if isAuditRes {
-				newBody, err = json.Marshal(auditRes)
-			} else if isUserRes {
-				newBody, err = json.Marshal(userRes)
+			if err == nil {
+				newBody, err = json.Marshal(body)
 			}
 			if err != nil {
-				log.Fatalf("Error marshalling new body: %s", err.Error())
+				paginationError(c, writer, err)
+				return
 			}
-			_, err = c.Writer.WriteString(string(newBody))
-			if err != nil {
-				log.Fatalf("Error writing new body: %s", err.Error())
+			if _, err := writer.ResponseWriter.Write(newBody); err != nil {
+				log.Printf("Error writing new body: %s", err.Error())
 			}
-		} else {
-			// Write the original body for non-paginated responses
-			_, err := c.Writer.WriteString(writer.body.String())
-			if err != nil {
-				log.Fatalf("Error writing new body: %s", err.Error())
+		} else if writer.body.Len() > 0 {
+			// Write the original body for non-paginated responses, responses like
+			// 204 No Content have none and must not be written to
+			if _, err := writer.ResponseWriter.Write(writer.body.Bytes()); err != nil {
+				log.Printf("Error writing body: %s", err.Error())
 			}
 		}
 	}
 }
 
+// paginationError replaces the buffered response, which could not be paginated, by
+// an internal server error.
+func paginationError(c *gin.Context, writer *bodyWriter, err error) {
+	er := models.LicenseError{
+		Status:    http.StatusInternalServerError,
+		Message:   "Unable to paginate the response",
+		Error:     err.Error(),
+		Path:      c.Request.URL.Path,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	body, _ := json.Marshal(er)
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+	if _, err := writer.ResponseWriter.Write(body); err != nil {
+		log.Printf("Error writing body: %s", err.Error())
+	}
+}
+
+// GzipMiddleware compresses json responses of at least minSize bytes with gzip for
+// the clients accepting it. Smaller responses are sent as they are. Streamed
+// responses are compressed as soon as they are flushed, with every flush sending
+// the compressed data written so far.
+func GzipMiddleware(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = writer
+		c.Header("Vary", "Accept-Encoding")
+
+		c.Next()
+
+		if err := writer.Close(); err != nil {
+			log.Printf("Error writing compressed response: %s", err)
+		}
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(encoding, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if len(parts) > 1 && strings.ReplaceAll(strings.TrimSpace(parts[1]), " ", "") == "q=0" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter buffers the response until it reaches minSize bytes, and then
+// compresses it if it is json.
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buffer  bytes.Buffer
+	gzip    *gzip.Writer
+	decided bool
+}
+
+// Write buffers the data until it is decided whether to compress the response.
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.gzip != nil {
+			return w.gzip.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buffer.Write(b)
+	if w.buffer.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// WriteString buffers the string like Write.
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends the data written so far, compressed if the response is json.
+func (w *gzipWriter) Flush() {
+	if err := w.decide(true); err != nil {
+		log.Printf("Error writing compressed response: %s", err)
+		return
+	}
+	if w.gzip != nil {
+		if err := w.gzip.Flush(); err != nil {
+			log.Printf("Error writing compressed response: %s", err)
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Close writes the rest of the response.
+func (w *gzipWriter) Close() error {
+	if err := w.decide(false); err != nil {
+		return err
+	}
+	if w.gzip != nil {
+		return w.gzip.Close()
+	}
+	return nil
+}
+
+// decide decides whether to compress the response, once, and writes the
+// buffered data.
+func (w *gzipWriter) decide(compress bool) error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	header := w.Header()
+	if compress && header.Get("Content-Encoding") == "" && strings.Contains(header.Get("Content-Type"), "json") &&
+		w.Status() != http.StatusNoContent && w.Status() != http.StatusNotModified {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gzip = gzip.NewWriter(w.ResponseWriter)
+	}
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if w.gzip != nil {
+		_, err = w.gzip.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+// StreamResponse makes the writes of the route go directly to the client instead
+// of being captured by PaginationMiddleware, so large responses can be streamed.
+// It must be called before anything is written.
+func StreamResponse(c *gin.Context) {
+	if writer, ok := c.Writer.(*bodyWriter); ok {
+		writer.stream = true
+	}
+}
+
+// HeadMiddleware serves HEAD requests with the handler of the GET request of the
+// route, sending its status and headers without the body.
+func HeadMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer
+		c.Writer = &headWriter{ResponseWriter: writer}
+
+		c.Next()
+
+		c.Writer = writer
+		c.Writer.WriteHeaderNow()
+	}
+}
+
+// headWriter sends the headers of the response on the first write and discards the body.
+type headWriter struct {
+	gin.ResponseWriter
+}
+
+// Write sends the headers and discards the data.
+func (w *headWriter) Write(b []byte) (int, error) {
+	w.ResponseWriter.WriteHeaderNow()
+	return len(b), nil
+}
+
+// WriteString discards the string like Write.
+func (w *headWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
 // bodyWriter is a custom writer to capture and process response body.
 type bodyWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body   *bytes.Buffer
+	stream bool
 }
 
 // Write is a custom write function to capture and process response body.
-func (w bodyWriter) Write(b []byte) (int, error) {
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	if w.stream {
+		return w.ResponseWriter.Write(b)
+	}
 	return w.body.Write(b)
 }
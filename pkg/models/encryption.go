@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ENCRYPTED_FIELD_PREFIX tags the values encrypted by EncryptField. It is followed by
+// the id of the key and the base64 encoded nonce and ciphertext, separated by ':'.
+const ENCRYPTED_FIELD_PREFIX = "enc:"
+
+// ErrEncryptedFieldPrefix is returned for a plaintext value starting with
+// ENCRYPTED_FIELD_PREFIX, which would be taken for an encrypted value when it is read.
+var ErrEncryptedFieldPrefix = fmt.Errorf("value must not start with '%s', which tags encrypted values",
+	ENCRYPTED_FIELD_PREFIX)
+
+// FieldEncryptionKeyId returns the id of the key new values are encrypted with, or an
+// empty string if field encryption is disabled.
+func FieldEncryptionKeyId() string {
+	return strings.TrimSpace(os.Getenv("FIELD_ENCRYPTION_KEY_ID"))
+}
+
+// fieldEncryptionKeys parses FIELD_ENCRYPTION_KEYS, a comma separated list of
+// <key id>:<base64 encoded AES key> pairs, to the keys by their id.
+func fieldEncryptionKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(os.Getenv("FIELD_ENCRYPTION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encodedKey, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, errors.New("field encryption keys must be of the form <key id>:<key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("field encryption key '%s' is not base64 encoded", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// fieldCipher returns the cipher of the key with the given id.
+func fieldCipher(id string) (cipher.AEAD, error) {
+	keys, err := fieldEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown field encryption key '%s'", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field encryption key '%s': %w", id, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptField encrypts the value with AES-GCM using the key of FIELD_ENCRYPTION_KEY_ID
+// and tags the result with the id of the key. Empty values and all values while field
+// encryption is disabled are returned as is. Values starting with
+// ENCRYPTED_FIELD_PREFIX are rejected with ErrEncryptedFieldPrefix, whether field
+// encryption is enabled or not, as they could not be told apart from encrypted ones.
+func EncryptField(value string) (string, error) {
+	if strings.HasPrefix(value, ENCRYPTED_FIELD_PREFIX) {
+		return "", ErrEncryptedFieldPrefix
+	}
+	id := FieldEncryptionKeyId()
+	if id == "" || value == "" {
+		return value, nil
+	}
+	aead, err := fieldCipher(id)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// The key id is authenticated along with the value, so it can not be swapped
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(id))
+	return ENCRYPTED_FIELD_PREFIX + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptField decrypts a value encrypted by EncryptField with the key it is tagged
+// with. All keys of FIELD_ENCRYPTION_KEYS are used, so values encrypted before a key
+// rotation remain readable. Values which are not encrypted are returned as is.
+func DecryptField(value string) (string, error) {
+	if !strings.HasPrefix(value, ENCRYPTED_FIELD_PREFIX) {
+		return value, nil
+	}
+	id, encoded, found := strings.Cut(strings.TrimPrefix(value, ENCRYPTED_FIELD_PREFIX), ":")
+	if !found {
+		return "", errors.New("encrypted field is missing the key id")
+	}
+	aead, err := fieldCipher(id)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed field encrypted with key '%s'", id)
+	}
+	nonceSize := aead.NonceSize()
+	plain, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt field encrypted with key '%s': %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// transformOptionalField returns a pointer to the transformed value. The value the
+// given pointer points to is left untouched, as it may be shared, e.g. with the
+// obligation a changelog is created for.
+func transformOptionalField(value *string, transform func(string) (string, error)) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	transformed, err := transform(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &transformed, nil
+}
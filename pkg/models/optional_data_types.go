@@ -6,14 +6,20 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 )
 
+// ErrNullValue is returned when a null value is unmarshalled into an OptionalData. The
+// json decoder does not tell which key was null, see utils.GetNullFieldErrors for that.
+var ErrNullValue = errors.New("value can not be null")
+
 // When we unmarshal json, the undefined keys take zero values in structs. So, there
 // is no way to differentiate between an undefined value and an actual zero value when
 // it is passed. OptionalData is a generic for differentiating between undefined and
-// zero valued keys in json.
+// zero valued keys in json. A key may be left undefined but can not be null, for
+// fields which must always have a value, see NullableAndOptionalData otherwise.
 type OptionalData[T any] struct {
 	// This is set to true if corresponding key is present in json object
 	IsDefined bool
@@ -25,11 +31,11 @@ func (v *OptionalData[T]) UnmarshalJSON(data []byte) error {
 	v.rawJson = append((v.rawJson)[0:0], data...)
 	if len(v.rawJson) != 0 {
 		var x *T
-		if err := json.Unmarshal(data, &x); err != nil {
+		if err := unmarshalValue(data, &x); err != nil {
 			return err
 		}
 		if x == nil {
-			return errors.New("field value cannot be null")
+			return ErrNullValue
 		}
 		v.Value = *x
 		v.IsDefined = true
@@ -37,24 +43,39 @@ func (v *OptionalData[T]) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// NullableAndOptionalData is same as OptionalData, but also accepts a null value.
+// This allows differentiating between undefined, null and valued keys in json.
 type NullableAndOptionalData[T any] struct {
 	// This is set to true if corresponding key is present in json object
 	IsDefinedAndNotNull bool
-	rawJson             json.RawMessage
-	Value               T
+	// This is set to true if corresponding key is present in json object with null value
+	IsNull  bool
+	rawJson json.RawMessage
+	Value   T
 }
 
 func (v *NullableAndOptionalData[T]) UnmarshalJSON(data []byte) error {
 	v.rawJson = append((v.rawJson)[0:0], data...)
 	if len(v.rawJson) != 0 {
 		var x *T
-		if err := json.Unmarshal(data, &x); err != nil {
+		if err := unmarshalValue(data, &x); err != nil {
 			return err
 		}
 		if x != nil {
 			v.Value = *x
 			v.IsDefinedAndNotNull = true
+		} else {
+			v.IsNull = true
 		}
 	}
 	return nil
 }
+
+// unmarshalValue unmarshals the json value into v. Numbers are decoded into typed
+// fields directly and into interface values as json.Number, never through float64,
+// so large integers keep their exact value.
+func unmarshalValue(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -7,6 +7,8 @@
 package models
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"time"
 
@@ -39,6 +41,7 @@ type LicenseDB struct {
 	Risk            *int64                                       `json:"risk" gorm:"column:rf_risk;not null;default:0" validate:"omitempty,min=0,max=5"`
 	Flag            *int64                                       `json:"flag" gorm:"default:1;column:rf_flag;not null;default:0" validate:"omitempty,min=0,max=2" example:"1"`
 	Marydone        *bool                                        `json:"marydone" gorm:"column:marydone;not null;default:false"`
+	AutoCreated     *bool                                        `json:"auto_created" gorm:"column:rf_auto_created;not null;default:false"`
 	ExternalRef     datatypes.JSONType[LicenseDBSchemaExtension] `json:"external_ref"`
 }
 
@@ -90,6 +93,7 @@ type LicenseUpdateJSONSchema struct {
 	Risk            *int64                                       `json:"risk" validate:"omitempty,min=0,max=5" example:"1"`
 	Flag            *int64                                       `json:"flag" validate:"omitempty,min=0,max=2" example:"1"`
 	Marydone        *bool                                        `json:"marydone" example:"false"`
+	AutoCreated     *bool                                        `json:"auto_created" example:"false"`
 	ExternalRef     datatypes.JSONType[LicenseDBSchemaExtension] `json:"external_ref"`
 }
 
@@ -201,6 +205,63 @@ type LicenseError struct {
 	Timestamp string `json:"timestamp" example:"2023-12-01T10:00:51+05:30"`
 }
 
+// FieldError describes a single field of the request body which failed validation.
+type FieldError struct {
+	Field   string `json:"field" example:"topic"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"topic is required"`
+}
+
+// ValidationError is the error response returned when the request body fails
+// validation. Along with the fields of LicenseError, it lists the offending fields.
+type ValidationError struct {
+	Status    int          `json:"status" example:"400"`
+	Message   string       `json:"message" example:"invalid json body"`
+	Error     string       `json:"error" example:"Key: 'ObligationPOSTRequestJSONSchema.Topic' Error:Field validation for 'Topic' failed on the 'required' tag"`
+	Errors    []FieldError `json:"errors"`
+	Path      string       `json:"path" example:"/api/v1/obligations"`
+	Timestamp string       `json:"timestamp" example:"2023-12-01T10:00:51+05:30"`
+}
+
+// ObligationNotFoundError is the error response returned when no obligation has the
+// requested topic. Along with the fields of LicenseError, it suggests the existing
+// topics closest to the requested one, e.g. for a typo.
+type ObligationNotFoundError struct {
+	Status      int      `json:"status" example:"404"`
+	Message     string   `json:"message" example:"obligation with topic 'coplyeft' not found"`
+	Error       string   `json:"error" example:"record not found"`
+	Suggestions []string `json:"suggestions" example:"copyleft"`
+	Path        string   `json:"path" example:"/api/v1/obligations/coplyeft"`
+	Timestamp   string   `json:"timestamp" example:"2023-12-01T10:00:51+05:30"`
+}
+
+// ObligationConflictError is the error response returned when an obligation can not be
+// created as it conflicts with an existing one. Along with the fields of LicenseError,
+// it carries the id and topic of the existing obligation, so clients can link to it.
+type ObligationConflictError struct {
+	Status    int                        `json:"status" example:"409"`
+	Message   string                     `json:"message" example:"can not create obligation with same topic or text"`
+	Error     string                     `json:"error" example:"Error: Obligation with topic 'copyleft' or Text 'Source cod'... already exists"`
+	Existing  ObligationConflictExisting `json:"existing"`
+	Diff      []ObligationFieldDiff      `json:"diff,omitempty"` // only if the topic exists with a different text, which the user may read
+	Path      string                     `json:"path" example:"/api/v1/obligations"`
+	Timestamp string                     `json:"timestamp" example:"2023-12-01T10:00:51+05:30"`
+}
+
+// ObligationConflictExisting identifies the existing obligation of a conflict.
+type ObligationConflictExisting struct {
+	Id    int64  `json:"id" example:"1"`
+	Topic string `json:"topic" example:"copyleft"`
+}
+
+// ObligationFieldDiff is a field which differs between an existing obligation and the
+// one sent to create.
+type ObligationFieldDiff struct {
+	Field    string  `json:"field" example:"Classification"`
+	OldValue *string `json:"old_value" example:"green"`
+	NewValue *string `json:"new_value" example:"red"`
+}
+
 // User struct is representation of user information.
 type User struct {
 	Id           int64   `json:"id" gorm:"primary_key" example:"123"`
@@ -237,14 +298,46 @@ type SearchLicense struct {
 // Audit struct represents an audit entity with certain attributes and properties
 // It has user id as a foreign key
 type Audit struct {
-	Id         int64       `json:"id" gorm:"primary_key" example:"456"`
-	UserId     int64       `json:"user_id" example:"123"`
-	User       User        `gorm:"foreignKey:UserId;references:Id" json:"user"`
-	Timestamp  time.Time   `json:"timestamp" example:"2023-12-01T18:10:25.00+05:30"`
-	Type       string      `json:"type" enums:"obligation,license" example:"license"`
-	TypeId     int64       `json:"type_id" example:"34"`
-	Entity     interface{} `json:"entity" gorm:"-" swaggertype:"object"`
-	ChangeLogs []ChangeLog `json:"-"`
+	Id           int64       `json:"id" gorm:"primary_key" example:"456"`
+	UserId       int64       `json:"user_id" example:"123"`
+	User         User        `gorm:"foreignKey:UserId;references:Id" json:"user"`
+	Timestamp    time.Time   `json:"timestamp" example:"2023-12-01T18:10:25.00+05:30"`
+	Type         string      `json:"type" enums:"obligation,license" example:"license"`
+	TypeId       int64       `json:"type_id" example:"34"`
+	ChangeReason string      `json:"change_reason" example:"Aligned the text with the license"`
+	Truncated    bool        `json:"truncated" gorm:"not null;default:false" example:"false"` // changelogs over the maximum are summarized in one
+	Entity       interface{} `json:"entity" gorm:"-" swaggertype:"object"`
+	ChangeLogs   []ChangeLog `json:"-"`
+}
+
+// ObligationAuditSummary summarizes the change history of an obligation.
+type ObligationAuditSummary struct {
+	TotalAudits       int64            `json:"total_audits" example:"12"`
+	LastChangedAt     *time.Time       `json:"last_changed_at" example:"2023-12-01T18:10:25.00+05:30"`
+	LastChangedBy     *string          `json:"last_changed_by" example:"fossy"`
+	FieldChangeCounts map[string]int64 `json:"field_change_counts"`
+}
+
+// ObligationAuditSummaryResponse represents the response format for an obligation audit summary.
+type ObligationAuditSummaryResponse struct {
+	Status int                    `json:"status" example:"200"`
+	Data   ObligationAuditSummary `json:"data"`
+}
+
+// ObligationRecentChange is an obligation changed within a window, with the time of its
+// last change and the fields changed by its audits within the window.
+type ObligationRecentChange struct {
+	Obligation    Obligation `json:"obligation"`
+	LastChangedAt time.Time  `json:"last_changed_at" example:"2024-05-01T10:00:00Z"`
+	ChangedFields []string   `json:"changed_fields" example:"Text,Classification"`
+	Audits        int        `json:"audits" example:"2"`
+}
+
+// ObligationRecentChangesResponse represents the response format for recently changed obligations.
+type ObligationRecentChangesResponse struct {
+	Status int                      `json:"status" example:"200"`
+	Data   []ObligationRecentChange `json:"data"`
+	Meta   *PaginationMeta          `json:"paginationmeta"`
 }
 
 // ChangeLog struct represents a change entity with certain attributes and properties
@@ -257,6 +350,42 @@ type ChangeLog struct {
 	Audit        Audit   `gorm:"foreignKey:AuditId;references:Id" json:"-"`
 }
 
+// BeforeSave encrypts the values of the changes of obligation comments, as the
+// comments themselves, see EncryptField.
+func (c *ChangeLog) BeforeSave(tx *gorm.DB) (err error) {
+	if c.Field != "Comment" {
+		return
+	}
+	if c.OldValue, err = transformOptionalField(c.OldValue, EncryptField); err != nil {
+		return
+	}
+	c.UpdatedValue, err = transformOptionalField(c.UpdatedValue, EncryptField)
+	return
+}
+
+// AfterSave decrypts the values again, so the saved changelog can be used as before.
+func (c *ChangeLog) AfterSave(tx *gorm.DB) error {
+	return c.decrypt()
+}
+
+// AfterFind decrypts the values of the changes of obligation comments.
+func (c *ChangeLog) AfterFind(tx *gorm.DB) error {
+	return c.decrypt()
+}
+
+// decrypt decrypts the values of the change if it is a change of an obligation
+// comment. The values of the other fields are never encrypted.
+func (c *ChangeLog) decrypt() (err error) {
+	if c.Field != "Comment" {
+		return
+	}
+	if c.OldValue, err = transformOptionalField(c.OldValue, DecryptField); err != nil {
+		return
+	}
+	c.UpdatedValue, err = transformOptionalField(c.UpdatedValue, DecryptField)
+	return
+}
+
 // ChangeLogResponse represents the design of API response of change log
 type ChangeLogResponse struct {
 	Status int            `json:"status" example:"200"`
@@ -271,18 +400,211 @@ type AuditResponse struct {
 	Meta   *PaginationMeta `json:"paginationmeta"`
 }
 
+// ObligationAuditBatchRequest is the request body to fetch the audits of several obligations.
+type ObligationAuditBatchRequest struct {
+	Topics []string `json:"topics" binding:"required,min=1" example:"copyleft,patent-grant"`
+}
+
+// ObligationAudits are the audits of an obligation.
+type ObligationAudits struct {
+	Topic  string  `json:"topic" example:"copyleft"`
+	Audits []Audit `json:"audits"`
+}
+
+// ObligationAuditBatchResponse represents the response format for the audits of several
+// obligations, grouped by obligation. The pagination is over the audits.
+type ObligationAuditBatchResponse struct {
+	Status        int                `json:"status" example:"200"`
+	Data          []ObligationAudits `json:"data"`
+	UnknownTopics []string           `json:"unknown_topics" example:"no-such-topic"`
+	Meta          *PaginationMeta    `json:"paginationmeta"`
+}
+
+// ObligationAuditReportEntry is an audit of an obligation in its audit report, along with
+// its changelogs.
+type ObligationAuditReportEntry struct {
+	AuditId      int64       `json:"audit_id" example:"456"`
+	Timestamp    time.Time   `json:"timestamp" example:"2023-12-01T18:10:25.00+05:30"`
+	Username     string      `json:"username" example:"fossy"`
+	ChangeReason string      `json:"change_reason" example:"Aligned the text with the license"`
+	Truncated    bool        `json:"truncated" example:"false"`
+	Archived     bool        `json:"archived" example:"false"` // moved to the archive by the retention job
+	ChangeLogs   []ChangeLog `json:"change_logs"`
+}
+
+// ObligationAuditReport is the full change history of an obligation.
+type ObligationAuditReport struct {
+	Topic        string                       `json:"topic" example:"copyleft"`
+	ObligationId int64                        `json:"obligation_id" example:"147"`
+	GeneratedAt  time.Time                    `json:"generated_at" example:"2024-12-01T18:10:25Z"`
+	GeneratedBy  string                       `json:"generated_by" example:"fossy"`
+	Audits       []ObligationAuditReportEntry `json:"audits"`
+}
+
+// ReportSignature is the signature of a report, over its json encoding.
+type ReportSignature struct {
+	Algorithm string `json:"algorithm" example:"HMAC-SHA256"`
+	Value     string `json:"value" example:"3f0b6c2e..."` // hex encoded
+}
+
+// ObligationAuditReportResponse represents the response format for the signed audit report
+// of an obligation.
+type ObligationAuditReportResponse struct {
+	Status    int                   `json:"status" example:"200"`
+	Data      ObligationAuditReport `json:"data"`
+	Signature ReportSignature       `json:"signature"`
+}
+
+// ArchivedAudit is an audit moved out of the audits table by the retention job.
+// Its change logs are kept alongside it as json so that the history of an entity
+// is not lost once the original rows are deleted.
+type ArchivedAudit struct {
+	Id           int64                           `json:"id" gorm:"primary_key" example:"456"`
+	UserId       int64                           `json:"user_id" example:"123"`
+	Timestamp    time.Time                       `json:"timestamp" example:"2023-12-01T18:10:25.00+05:30"`
+	Type         string                          `json:"type" enums:"obligation,license" example:"license"`
+	TypeId       int64                           `json:"type_id" example:"34"`
+	ChangeReason string                          `json:"change_reason" example:"Aligned the text with the license"`
+	Truncated    bool                            `json:"truncated" gorm:"not null;default:false" example:"false"`
+	ChangeLogs   datatypes.JSONType[[]ChangeLog] `json:"change_logs" swaggertype:"array,object"`
+	ArchivedAt   time.Time                       `json:"archived_at" example:"2024-12-01T18:10:25.00+05:30"`
+}
+
+// ArchivedAuditResponse represents the response format for archived audit data.
+type ArchivedAuditResponse struct {
+	Status int             `json:"status" example:"200"`
+	Data   []ArchivedAudit `json:"data"`
+	Meta   *PaginationMeta `json:"paginationmeta"`
+}
+
+// AuditArchiveResult is the outcome of an audit archive request.
+type AuditArchiveResult struct {
+	Before time.Time `json:"before" example:"2023-12-01T00:00:00Z"`
+	Count  int64     `json:"count" example:"42"`
+	DryRun bool      `json:"dry_run" example:"false"`
+}
+
+// AuditArchiveResponse represents the response format for audit archive requests.
+type AuditArchiveResponse struct {
+	Status int                `json:"status" example:"200"`
+	Data   AuditArchiveResult `json:"data"`
+}
+
+// AuditUserActivity is the number of obligation audits of a user and the time of the
+// last one, within the requested range.
+type AuditUserActivity struct {
+	UserId         int64      `json:"user_id" example:"123"`
+	Username       string     `json:"username" example:"fossy"`
+	Audits         int64      `json:"audits" example:"42"`
+	LastActivityAt *time.Time `json:"last_activity_at" example:"2024-05-01T10:00:00Z"` // null if the user has no audits
+}
+
+// AuditUserActivityResponse represents the response format for the obligation audits per user.
+type AuditUserActivityResponse struct {
+	Status int                 `json:"status" example:"200"`
+	Data   []AuditUserActivity `json:"data"`
+	Meta   *PaginationMeta     `json:"paginationmeta"`
+}
+
+// Default types of an obligation
+const (
+	OBLIGATION_TYPE_OBLIGATION  = "obligation"
+	OBLIGATION_TYPE_RESTRICTION = "restriction"
+	OBLIGATION_TYPE_RISK        = "risk"
+	OBLIGATION_TYPE_RIGHT       = "right"
+)
+
+// Review statuses of an obligation. New obligations are drafts, which are submitted
+// for review and only listed to consumers once published by a reviewer.
+const (
+	OBLIGATION_STATUS_DRAFT     = "DRAFT"
+	OBLIGATION_STATUS_IN_REVIEW = "IN_REVIEW"
+	OBLIGATION_STATUS_PUBLISHED = "PUBLISHED"
+)
+
+// ObligationStatuses are the review statuses of an obligation.
+var ObligationStatuses = []string{OBLIGATION_STATUS_DRAFT, OBLIGATION_STATUS_IN_REVIEW, OBLIGATION_STATUS_PUBLISHED}
+
+// ObligationClassifications are the allowed obligation classifications.
+var ObligationClassifications = []string{"green", "white", "yellow", "red"}
+
+// DefaultObligationTypes are the allowed obligation types unless configured otherwise.
+var DefaultObligationTypes = []string{
+	OBLIGATION_TYPE_OBLIGATION,
+	OBLIGATION_TYPE_RESTRICTION,
+	OBLIGATION_TYPE_RISK,
+	OBLIGATION_TYPE_RIGHT,
+}
+
+// ObligationTypesResponse represents the response format for the allowed obligation types.
+type ObligationTypesResponse struct {
+	Status int      `json:"status" example:"200"`
+	Data   []string `json:"data" example:"obligation,restriction,risk,right"`
+}
+
 // Obligation represents an obligation record in the database.
 type Obligation struct {
-	Id             int64  `gorm:"primary_key" json:"id" example:"147"`
-	Topic          string `gorm:"unique" json:"topic" example:"copyleft"`
-	Type           string `json:"type" enums:"obligation,restriction,risk,right" example:"risk"`
-	Text           string `json:"text" example:"Source code be made available when distributing the software."`
-	Classification string `json:"classification" enums:"green,white,yellow,red" example:"green"`
-	Modifications  bool   `json:"modifications" example:"true"`
-	Comment        string `json:"comment"`
-	Active         bool   `json:"active"`
-	TextUpdatable  bool   `json:"text_updatable" example:"true"`
-	Md5            string `gorm:"unique" json:"-"`
+	Id             int64               `gorm:"primary_key" json:"id" example:"147"`
+	Topic          string              `gorm:"unique" json:"topic" example:"copyleft"`
+	Type           string              `json:"type" enums:"obligation,restriction,risk,right" example:"risk"`
+	Text           string              `json:"text" example:"Source code be made available when distributing the software."`
+	NormalizedText string              `gorm:"not null;default:''" json:"-"`
+	Classification string              `json:"classification" enums:"green,white,yellow,red" example:"green"`
+	Modifications  bool                `json:"modifications" example:"true"`
+	Comment        string              `json:"comment"`
+	Active         bool                `json:"active"`
+	TextUpdatable  bool                `json:"text_updatable" example:"true"`
+	Sensitive      bool                `gorm:"not null;default:false" json:"sensitive"` // reads are logged when the read audit is enabled
+	LicenseCount   int64               `gorm:"not null;default:0" json:"license_count" example:"3"`
+	Status         string              `gorm:"not null;default:'PUBLISHED'" json:"status" enums:"DRAFT,IN_REVIEW,PUBLISHED" example:"PUBLISHED"`
+	TextHash       string              `gorm:"column:md5;unique" json:"-"` // SHA-256 of the normalized text, the column predates the switch from md5
+	CreatedAt      time.Time           `json:"created_at" example:"2023-12-01T18:10:25.00+05:30"`
+	UpdatedAt      time.Time           `json:"updated_at" example:"2023-12-01T18:10:25.00+05:30"`
+	EffectiveFrom  *time.Time          `json:"effective_from" example:"2024-01-01T00:00:00Z"`
+	EffectiveUntil *time.Time          `json:"effective_until" example:"2024-12-31T23:59:59Z"`
+	Lock           *ObligationLock     `gorm:"-" json:"lock,omitempty"`        // only set when a single obligation is read
+	Licenses       []ObligationLicense `gorm:"-" json:"licenses,omitempty"`    // only set when the licenses are expanded
+	LicenseIds     []int64             `gorm:"-" json:"license_ids,omitempty"` // only set when the expanded licenses are deduplicated
+}
+
+// ObligationLicense is a license an obligation is mapped to, expanded in the obligation list.
+type ObligationLicense struct {
+	Id        int64  `json:"id" example:"123"`
+	Shortname string `json:"shortname" example:"MIT"`
+	Fullname  string `json:"fullname" example:"MIT License"`
+	SpdxId    string `json:"spdx_id" example:"MIT"`
+	Url       string `json:"url" example:"https://opensource.org/licenses/MIT"`
+}
+
+// BeforeSave encrypts the comment of the obligation, see EncryptField. On updates with
+// a map, the comment in the map is encrypted instead.
+func (o *Obligation) BeforeSave(tx *gorm.DB) (err error) {
+	comment := o.Comment
+	if values, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		if comment, ok = values["comment"].(string); !ok {
+			return
+		}
+	}
+	encrypted, err := EncryptField(comment)
+	if err != nil {
+		return err
+	}
+	if encrypted != comment {
+		tx.Statement.SetColumn("comment", encrypted)
+	}
+	return
+}
+
+// AfterSave decrypts the comment again, so the saved obligation can be used as before.
+func (o *Obligation) AfterSave(tx *gorm.DB) (err error) {
+	o.Comment, err = DecryptField(o.Comment)
+	return
+}
+
+// AfterFind decrypts the comment of the obligation, see DecryptField.
+func (o *Obligation) AfterFind(tx *gorm.DB) (err error) {
+	o.Comment, err = DecryptField(o.Comment)
+	return
 }
 
 // ObligationPreview is just the Type and Topic of Obligation
@@ -299,32 +621,492 @@ type ObligationPreviewResponse struct {
 
 // ObligationPOSTRequestJSONSchema represents the data format of POST request for obligation
 type ObligationPOSTRequestJSONSchema struct {
-	Topic          string   `json:"topic" binding:"required" example:"copyleft"`
-	Type           string   `json:"type" enums:"obligation,restriction,risk,right" binding:"required"`
-	Text           string   `json:"text" binding:"required" example:"Source code be made available when distributing the software."`
-	Classification string   `json:"classification" enums:"green,white,yellow,red" binding:"required"`
-	Modifications  bool     `json:"modifications" binding:"required"`
-	Comment        string   `json:"comment" binding:"required"`
-	Shortnames     []string `json:"shortnames" binding:"required" example:"GPL-2.0-only,GPL-2.0-or-later"`
-	Active         bool     `json:"active" binding:"required" example:"true"`
+	Topic          string     `json:"topic" binding:"required,obligation_topic" example:"copyleft"`
+	Type           string     `json:"type" enums:"obligation,restriction,risk,right" binding:"required,obligation_type"`
+	Text           string     `json:"text" binding:"required" example:"Source code be made available when distributing the software."`
+	Classification string     `json:"classification" enums:"green,white,yellow,red" binding:"required,oneof=green white yellow red"`
+	Modifications  bool       `json:"modifications" binding:"required"`
+	Comment        string     `json:"comment" binding:"required"`
+	Shortnames     []string   `json:"shortnames" example:"GPL-2.0-only,GPL-2.0-or-later"`
+	Active         bool       `json:"active" binding:"required" example:"true"`
+	Sensitive      bool       `json:"sensitive"`
+	EffectiveFrom  *time.Time `json:"effective_from" example:"2024-01-01T00:00:00Z"`
+	EffectiveUntil *time.Time `json:"effective_until" example:"2024-12-31T23:59:59Z"`
+	CreatedAt      *time.Time `json:"created_at" example:"2019-06-01T00:00:00Z"`               // admin only, to keep the creation date of migrated obligations
+	ExpectedMd5    string     `json:"expected_md5" example:"5d41402abc4b2a76b9719d911017c592"` // md5 of the canonical text, verified by the server
 }
 
 // ObligationPATCHRequestJSONSchema represents the data format of PATCH request for obligation
 type ObligationPATCHRequestJSONSchema struct {
-	Type           OptionalData[string] `json:"type" swaggertype:"string" enums:"obligation,restriction,risk,right"`
-	Text           OptionalData[string] `json:"text" swaggertype:"string" example:"Source code be made available when distributing the software."`
-	Classification OptionalData[string] `json:"classification" swaggertype:"string" enums:"green,white,yellow,red"`
-	Modifications  OptionalData[bool]   `json:"modifications" swaggertype:"boolean"`
-	Comment        OptionalData[string] `json:"comment" swaggertype:"string" example:"This is a comment."`
-	Active         OptionalData[bool]   `json:"active" swaggertype:"boolean" example:"true"`
-	TextUpdatable  OptionalData[bool]   `json:"text_updatable" swaggertype:"boolean"`
+	Topic          OptionalData[string]               `json:"topic" swaggertype:"string" example:"copyleft"`
+	Type           OptionalData[string]               `json:"type" swaggertype:"string" enums:"obligation,restriction,risk,right"`
+	Text           OptionalData[string]               `json:"text" swaggertype:"string" example:"Source code be made available when distributing the software."`
+	Classification OptionalData[string]               `json:"classification" swaggertype:"string" enums:"green,white,yellow,red"`
+	Modifications  OptionalData[bool]                 `json:"modifications" swaggertype:"boolean"`
+	Comment        NullableAndOptionalData[string]    `json:"comment" swaggertype:"string" example:"This is a comment."`
+	Active         OptionalData[bool]                 `json:"active" swaggertype:"boolean" example:"true"`
+	TextUpdatable  OptionalData[bool]                 `json:"text_updatable" swaggertype:"boolean"`
+	Sensitive      OptionalData[bool]                 `json:"sensitive" swaggertype:"boolean"`
+	Status         OptionalData[string]               `json:"status" swaggertype:"string" enums:"DRAFT,IN_REVIEW"` // publishing is done by a reviewer with the publish endpoint
+	EffectiveFrom  NullableAndOptionalData[time.Time] `json:"effective_from" swaggertype:"string" format:"date-time" example:"2024-01-01T00:00:00Z"`
+	EffectiveUntil NullableAndOptionalData[time.Time] `json:"effective_until" swaggertype:"string" format:"date-time" example:"2024-12-31T23:59:59Z"`
+	ChangeReason   string                             `json:"change_reason" example:"Aligned the text with the license"`
 }
 
 // ObligationResponse represents the response format for obligation data.
 type ObligationResponse struct {
+	Status   int                         `json:"status" example:"200"`
+	Data     []Obligation                `json:"data"`
+	Included map[int64]ObligationLicense `json:"included,omitempty"` // the deduplicated licenses by id
+	Meta     *PaginationMeta             `json:"paginationmeta"`
+}
+
+// ObligationProjectionResponse represents the response format for the obligations with
+// only the fields selected by the fields parameter.
+type ObligationProjectionResponse struct {
+	Status int                      `json:"status" example:"200"`
+	Data   []map[string]interface{} `json:"data" swaggertype:"array,object"`
+	Meta   *PaginationMeta          `json:"paginationmeta"`
+}
+
+// Outcomes of a dry run obligation create
+const (
+	DRY_RUN_CREATED            = "created"
+	DRY_RUN_CONFLICT           = "conflict"
+	DRY_RUN_UNKNOWN_SHORTNAMES = "unknown_shortnames"
+)
+
+// ObligationDryRunResponse is the response of a dry run obligation create. It
+// mirrors ObligationResponse and reports what the real create would do. On
+// conflict, data holds the existing obligation.
+type ObligationDryRunResponse struct {
+	Status            int             `json:"status" example:"200"`
+	Data              []Obligation    `json:"data"`
+	Meta              *PaginationMeta `json:"paginationmeta"`
+	Outcome           string          `json:"outcome" enums:"created,conflict,unknown_shortnames" example:"created"`
+	UnknownShortnames []string        `json:"unknown_shortnames,omitempty" example:"GPL-2.0-only"`
+}
+
+// ObligationDuplicateCheckInput is an incoming obligation to check for duplicates.
+type ObligationDuplicateCheckInput struct {
+	Topic string `json:"topic" binding:"required" example:"copyleft"`
+	Text  string `json:"text" binding:"required" example:"Source code be made available when distributing the software."`
+}
+
+// ObligationDuplicateCheckResult reports whether an incoming obligation collides
+// with an existing one by topic or by the hash of its text.
+type ObligationDuplicateCheckResult struct {
+	Topic         string `json:"topic" example:"copyleft"`
+	TopicExists   bool   `json:"topic_exists" example:"true"`
+	TextExists    bool   `json:"text_exists" example:"false"`
+	ExistingTopic string `json:"existing_topic,omitempty" example:"copyleft"`
+}
+
+// ObligationDuplicateCheckResponse represents the response format for obligation duplicate checks.
+type ObligationDuplicateCheckResponse struct {
+	Status int                              `json:"status" example:"200"`
+	Data   []ObligationDuplicateCheckResult `json:"data"`
+	Meta   *PaginationMeta                  `json:"paginationmeta"`
+}
+
+// ObligationValidationResult reports the validation errors of an obligation of a batch,
+// which is valid if there are none.
+type ObligationValidationResult struct {
+	Index  int          `json:"index" example:"0"`
+	Topic  string       `json:"topic" example:"copyleft"`
+	Valid  bool         `json:"valid" example:"false"`
+	Errors []FieldError `json:"errors"`
+}
+
+// ObligationValidationResponse represents the response format for obligation batch validation.
+type ObligationValidationResponse struct {
+	Status int                          `json:"status" example:"200"`
+	Valid  bool                         `json:"valid" example:"false"`
+	Data   []ObligationValidationResult `json:"data"`
+	Meta   *PaginationMeta              `json:"paginationmeta"`
+}
+
+// ObligationTextMatch is an occurrence of a phrase in the text of an obligation. The
+// offsets count characters, not bytes.
+type ObligationTextMatch struct {
+	Offset        int    `json:"offset" example:"42"`
+	Length        int    `json:"length" example:"11"`
+	Snippet       string `json:"snippet" example:"...be made available when distributing..."`
+	SnippetOffset int    `json:"snippet_offset" example:"18"` // offset of the match in the snippet
+}
+
+// ObligationTextMatches are the occurrences of a phrase in the text of an obligation.
+type ObligationTextMatches struct {
+	Topic   string                `json:"topic" example:"copyleft"`
+	Query   string                `json:"query" example:"distributing"`
+	Matches []ObligationTextMatch `json:"matches"`
+}
+
+// ObligationTextMatchesResponse represents the response format for the search within an obligation.
+type ObligationTextMatchesResponse struct {
+	Status int                   `json:"status" example:"200"`
+	Data   ObligationTextMatches `json:"data"`
+}
+
+// ObligationSimilarity is an obligation with the similarity of its text to the
+// text of another obligation.
+type ObligationSimilarity struct {
+	Topic string  `json:"topic" example:"copyleft"`
+	Type  string  `json:"type" enums:"obligation,restriction,risk,right"`
+	Score float64 `json:"score" example:"0.97"`
+}
+
+// ObligationSimilarityResponse represents the response format for similar obligations.
+type ObligationSimilarityResponse struct {
+	Status int                    `json:"status" example:"200"`
+	Data   []ObligationSimilarity `json:"data"`
+}
+
+// SearchObligation struct represents the input needed to search in obligations.
+type SearchObligation struct {
+	Field      string `json:"field" binding:"required,oneof=topic text comment" enums:"topic,text,comment" example:"text"`
+	SearchTerm string `json:"search_term" binding:"required" example:"source code"`
+	Search     string `json:"search" enums:"fuzzy,full_text_search"`
+}
+
+// ObligationSearchResult is an obligation matched by a search along with a
+// snippet of the searched field with the matches highlighted.
+type ObligationSearchResult struct {
+	Obligation
+	Highlight string `json:"highlight" example:"... <mark>source</mark> <mark>code</mark> be made available ..."`
+}
+
+// ObligationSearchResponse represents the response format for obligation search.
+type ObligationSearchResponse struct {
+	Status int                      `json:"status" example:"200"`
+	Data   []ObligationSearchResult `json:"data"`
+	Meta   *PaginationMeta          `json:"paginationmeta"`
+}
+
+// ObligationTranslation is the text of an obligation in another language. It does not
+// take part in the hash deduplication of the canonical obligation text.
+type ObligationTranslation struct {
+	Id           int64      `json:"-" gorm:"primary_key"`
+	ObligationPk int64      `json:"-" gorm:"uniqueIndex:idx_obligation_translation_lang;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	Language     string     `json:"language" gorm:"uniqueIndex:idx_obligation_translation_lang;not null" example:"de"`
+	Text         string     `json:"text" gorm:"not null" example:"Der Quellcode muss bei der Weitergabe der Software zur Verfügung gestellt werden."`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-12-01T18:10:25.00+05:30"`
+	UpdatedAt    time.Time  `json:"updated_at" example:"2023-12-01T18:10:25.00+05:30"`
+}
+
+// ObligationTranslationInput represents the input format for adding or updating a translation.
+type ObligationTranslationInput struct {
+	Text string `json:"text" binding:"required" example:"Der Quellcode muss bei der Weitergabe der Software zur Verfügung gestellt werden."`
+}
+
+// ObligationTranslationResponse represents the response format for obligation translations.
+type ObligationTranslationResponse struct {
+	Status int                     `json:"status" example:"200"`
+	Data   []ObligationTranslation `json:"data"`
+	Meta   *PaginationMeta         `json:"paginationmeta"`
+}
+
+// ObligationNote is a dated note left by a user on an obligation during review.
+type ObligationNote struct {
+	Id           int64      `json:"id" gorm:"primary_key" example:"12"`
+	ObligationPk int64      `json:"-" gorm:"index;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	UserId       int64      `json:"user_id" example:"123"`
+	User         User       `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	Text         string     `json:"text" gorm:"not null" example:"Checked with legal, applies to binaries as well."`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-12-01T18:10:25.00+05:30"`
+}
+
+// ObligationNoteInput represents the input format for adding a note to an obligation.
+type ObligationNoteInput struct {
+	Text string `json:"text" binding:"required" example:"Checked with legal, applies to binaries as well."`
+}
+
+// ObligationNoteResponse represents the response format for obligation notes.
+type ObligationNoteResponse struct {
+	Status int              `json:"status" example:"200"`
+	Data   []ObligationNote `json:"data"`
+	Meta   *PaginationMeta  `json:"paginationmeta"`
+}
+
+// Reasons an obligation snapshot is taken for.
+const (
+	OBLIGATION_SNAPSHOT_REASON_PUBLISH = "publish"
+	OBLIGATION_SNAPSHOT_REASON_MANUAL  = "manual"
+)
+
+// ObligationSnapshot is an immutable copy of an obligation at a point in time, taken on
+// every publication and on demand. The obligation is stored serialized as json in Data,
+// which like the comments is encrypted at rest when field encryption is enabled.
+type ObligationSnapshot struct {
+	Id           int64      `json:"id" gorm:"primary_key" example:"7"`
+	ObligationPk int64      `json:"obligation_id" gorm:"index;not null" example:"147"`
+	Reason       string     `json:"reason" gorm:"not null" enums:"publish,manual" example:"publish"`
+	UserId       int64      `json:"user_id" example:"123"`
+	User         User       `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	Data         string     `json:"-" gorm:"type:text;not null"`
+	Obligation   Obligation `json:"obligation" gorm:"-"`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-12-01T18:10:25.00+05:30"`
+}
+
+// BeforeCreate serializes and encrypts the obligation of the snapshot, see EncryptField.
+func (s *ObligationSnapshot) BeforeCreate(tx *gorm.DB) error {
+	data, err := json.Marshal(s.Obligation)
+	if err != nil {
+		return err
+	}
+	s.Data, err = EncryptField(string(data))
+	return err
+}
+
+// AfterFind decrypts and deserializes the obligation of the snapshot.
+func (s *ObligationSnapshot) AfterFind(tx *gorm.DB) error {
+	data, err := DecryptField(s.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), &s.Obligation)
+}
+
+// ObligationSnapshotResponse represents the response format for obligation snapshots.
+type ObligationSnapshotResponse struct {
+	Status int                  `json:"status" example:"200"`
+	Data   []ObligationSnapshot `json:"data"`
+	Meta   *PaginationMeta      `json:"paginationmeta"`
+}
+
+// ObligationTag is a label attached to an obligation for triage.
+type ObligationTag struct {
+	Id           int64      `json:"-" gorm:"primary_key"`
+	ObligationPk int64      `json:"-" gorm:"uniqueIndex:idx_obligation_tag;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	Tag          string     `json:"tag" gorm:"uniqueIndex:idx_obligation_tag;not null" example:"needs-review"`
+}
+
+// ObligationTagFilter selects obligations by their fields for bulk tagging.
+type ObligationTagFilter struct {
+	Active         *bool  `json:"active" example:"true"`
+	Type           string `json:"type" enums:"obligation,restriction,risk,right" example:"risk"`
+	Classification string `json:"classification" enums:"green,white,yellow,red" example:"green"`
+}
+
+// ObligationTagAssignInput represents the input format for assigning a tag to or
+// unassigning it from many obligations, selected by topics or by a filter.
+type ObligationTagAssignInput struct {
+	Tag    string               `json:"tag" binding:"required" example:"needs-review"`
+	Topics []string             `json:"topics" example:"copyleft,patent-grant"`
+	Filter *ObligationTagFilter `json:"filter"`
+}
+
+// ObligationTagAssignResult is the outcome of a bulk tag assign or unassign.
+type ObligationTagAssignResult struct {
+	Tag   string `json:"tag" example:"needs-review"`
+	Count int64  `json:"count" example:"12"`
+}
+
+// ObligationTagAssignResponse represents the response format for bulk tag assign and unassign.
+type ObligationTagAssignResponse struct {
+	Status int                       `json:"status" example:"200"`
+	Data   ObligationTagAssignResult `json:"data"`
+}
+
+// ObligationReclassifyFilter selects obligations by their fields for bulk reclassification.
+type ObligationReclassifyFilter struct {
+	Classification string `json:"classification" enums:"green,white,yellow,red" binding:"omitempty,oneof=green white yellow red" example:"red"`
+	Modifications  *bool  `json:"modifications" example:"false"`
+	Type           string `json:"type" enums:"obligation,restriction,risk,right" binding:"omitempty,obligation_type" example:"risk"`
+	Active         *bool  `json:"active" example:"true"`
+}
+
+// ObligationReclassifyInput represents the input format for setting the classification
+// of all the obligations matching a filter.
+type ObligationReclassifyInput struct {
+	Filter         ObligationReclassifyFilter `json:"filter"`
+	Classification string                     `json:"classification" enums:"green,white,yellow,red" binding:"required,oneof=green white yellow red" example:"yellow"`
+	ChangeReason   string                     `json:"change_reason" example:"Re-leveled the risk of unmodified red obligations"`
+}
+
+// ObligationReclassifyResult is the outcome of a bulk reclassification, with the
+// topics of the obligations which are, or with a dry run would be, reclassified.
+type ObligationReclassifyResult struct {
+	Classification string   `json:"classification" example:"yellow"`
+	Count          int      `json:"count" example:"2"`
+	Topics         []string `json:"topics" example:"copyleft,patent-grant"`
+	DryRun         bool     `json:"dry_run" example:"false"`
+}
+
+// ObligationReclassifyResponse represents the response format for bulk reclassification.
+type ObligationReclassifyResponse struct {
+	Status int                        `json:"status" example:"200"`
+	Data   ObligationReclassifyResult `json:"data"`
+}
+
+// ObligationEditableFieldsResponse represents the response format for the fields of an
+// obligation the user may update, by json key.
+type ObligationEditableFieldsResponse struct {
 	Status int             `json:"status" example:"200"`
-	Data   []Obligation    `json:"data"`
-	Meta   *PaginationMeta `json:"paginationmeta"`
+	Data   map[string]bool `json:"data" swaggertype:"object,boolean" example:"text:false,comment:true"`
+}
+
+// ObligationDeactivateInput represents the input format for deactivating the obligations
+// of an explicit list of topics.
+type ObligationDeactivateInput struct {
+	Topics       []string `json:"topics" binding:"required,min=1" example:"copyleft,patent-grant"`
+	ChangeReason string   `json:"change_reason" example:"Retired with the license cleanup"`
+}
+
+// ObligationDeactivateResult is the outcome of deactivating obligations by topic.
+type ObligationDeactivateResult struct {
+	Deactivated     []string `json:"deactivated" example:"copyleft"`
+	AlreadyInactive []string `json:"already_inactive" example:"patent-grant"`
+	NotFound        []string `json:"not_found" example:"unknown-topic"`
+	Locked          []string `json:"locked" example:"under-review"` // locked by another user, left active
+}
+
+// ObligationDeactivateResponse represents the response format for deactivating
+// obligations by topic.
+type ObligationDeactivateResponse struct {
+	Status int                        `json:"status" example:"200"`
+	Data   ObligationDeactivateResult `json:"data"`
+}
+
+// AtomFeed is an Atom feed (RFC 4287) of the changes of obligations.
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    AtomLink    `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+// AtomEntry is a change of an obligation in an AtomFeed.
+type AtomEntry struct {
+	Id      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  AtomAuthor `xml:"author"`
+	Link    AtomLink   `xml:"link"`
+	Summary string     `xml:"summary"`
+}
+
+// AtomAuthor is the author of an AtomEntry.
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// AtomLink links an AtomFeed or an AtomEntry to a resource.
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// ClassificationRule maps a keyword in obligation text to a classification. The
+// rules are used to suggest a classification for new obligations.
+type ClassificationRule struct {
+	Id             int64  `json:"id" gorm:"primary_key" example:"5"`
+	Keyword        string `json:"keyword" gorm:"unique;not null" binding:"required" example:"patent"`
+	Classification string `json:"classification" gorm:"not null" enums:"green,white,yellow,red" binding:"required,oneof=green white yellow red" example:"red"`
+	Weight         int    `json:"weight" gorm:"not null;default:1" binding:"omitempty,min=1" example:"1"`
+}
+
+// ClassificationRuleResponse represents the response format for classification rules.
+type ClassificationRuleResponse struct {
+	Status int                  `json:"status" example:"200"`
+	Data   []ClassificationRule `json:"data"`
+	Meta   *PaginationMeta      `json:"paginationmeta"`
+}
+
+// ObligationClassifyInput represents the input format for suggesting a classification.
+type ObligationClassifyInput struct {
+	Text string `json:"text" binding:"required" example:"Source code be made available when distributing the software."`
+}
+
+// ObligationClassification is a suggested classification of an obligation text
+// along with the keywords which led to it. The classification is empty if no
+// keyword matched.
+type ObligationClassification struct {
+	Classification  string         `json:"classification" enums:"green,white,yellow,red" example:"red"`
+	MatchedKeywords []string       `json:"matched_keywords" example:"patent"`
+	Scores          map[string]int `json:"scores"`
+}
+
+// ObligationClassificationResponse represents the response format for classification suggestions.
+type ObligationClassificationResponse struct {
+	Status int                      `json:"status" example:"200"`
+	Data   ObligationClassification `json:"data"`
+}
+
+// ObligationTopicRedirect is a former topic of a renamed obligation. Requests for the
+// former topic are redirected to the obligation, and the topic is reserved so no other
+// obligation can take it.
+type ObligationTopicRedirect struct {
+	Id           int64      `json:"-" gorm:"primary_key"`
+	Topic        string     `json:"topic" gorm:"unique;not null"`
+	ObligationPk int64      `json:"-" gorm:"index;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	CreatedAt    time.Time  `json:"-"`
+}
+
+// ObligationWatch is a user watching an obligation.
+type ObligationWatch struct {
+	Id           int64      `json:"-" gorm:"primary_key"`
+	UserId       int64      `json:"-" gorm:"uniqueIndex:idx_obligation_watch;not null"`
+	User         User       `json:"-" gorm:"foreignKey:UserId;references:Id"`
+	ObligationPk int64      `json:"-" gorm:"uniqueIndex:idx_obligation_watch;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	CreatedAt    time.Time  `json:"-"`
+}
+
+// ObligationLock keeps the other users from updating an obligation under review until it
+// is released by its holder or expires.
+type ObligationLock struct {
+	Id           int64     `json:"-" gorm:"primary_key"`
+	ObligationPk int64     `json:"-" gorm:"uniqueIndex;not null"`
+	UserId       int64     `json:"user_id" example:"123"`
+	User         User      `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	LockedAt     time.Time `json:"locked_at" gorm:"not null" example:"2023-12-01T18:10:25.00+05:30"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null" example:"2023-12-01T18:40:25.00+05:30"`
+}
+
+// ObligationLockResponse represents the response format for the lock of an obligation.
+type ObligationLockResponse struct {
+	Status int            `json:"status" example:"200"`
+	Data   ObligationLock `json:"data"`
+}
+
+// SchemaMigration records a database migration which was applied, see db.Migrations.
+type SchemaMigration struct {
+	Version     int       `json:"version" gorm:"primary_key;autoIncrement:false" example:"3"`
+	Description string    `json:"description" example:"Rehash obligation texts with SHA-256"`
+	AppliedAt   time.Time `json:"applied_at" example:"2023-12-01T18:10:25.00+05:30"`
+}
+
+// ObligationAccessLog is a read of a sensitive obligation, recorded when the read
+// audit is enabled. The reads are kept apart from the audits of the changes.
+type ObligationAccessLog struct {
+	Id           int64      `json:"id" gorm:"primary_key" example:"12"`
+	ObligationPk int64      `json:"-" gorm:"index;not null"`
+	Obligation   Obligation `json:"-" gorm:"foreignKey:ObligationPk;references:Id"`
+	Topic        string     `json:"topic" example:"copyleft"`
+	Username     string     `json:"username" example:"fossy"` // empty for reads without authentication
+	Timestamp    time.Time  `json:"timestamp" example:"2023-12-01T18:10:25.00+05:30"`
+}
+
+// ObligationCreationCount is the number of obligations a user created on a day (UTC),
+// counted against the daily obligation creation quota.
+type ObligationCreationCount struct {
+	UserId  int64  `json:"user_id" gorm:"primary_key;autoIncrement:false" example:"123"`
+	Day     string `json:"day" gorm:"primary_key;size:10" example:"2023-12-01"`
+	Created int64  `json:"created" gorm:"not null" example:"12"`
+}
+
+// ObligationAccessLogResponse represents the response format for the access log of an obligation.
+type ObligationAccessLogResponse struct {
+	Status int                   `json:"status" example:"200"`
+	Data   []ObligationAccessLog `json:"data"`
+	Meta   *PaginationMeta       `json:"paginationmeta"`
 }
 
 // ObligationMap represents the mapping between an obligation and a license.
@@ -366,6 +1148,132 @@ type ObligationMapResponse struct {
 	Meta   PaginationMeta      `json:"paginationmeta"`
 }
 
+// ObligationMapPair is a map of an obligation to a license by their topic and shortname.
+type ObligationMapPair struct {
+	Topic     string `json:"topic" binding:"required" example:"copyleft"`
+	Shortname string `json:"shortname" binding:"required" example:"GPL-2.0-only"`
+}
+
+// ObligationMapImportInput represents the input format for importing obligation maps.
+type ObligationMapImportInput struct {
+	Maps []ObligationMapPair `json:"maps" binding:"required,min=1,dive"`
+}
+
+// ObligationMapImportResult is the outcome of an import of obligation maps. Maps with
+// an unresolved topic or shortname are not imported.
+type ObligationMapImportResult struct {
+	Created              int      `json:"created" example:"12"`
+	Existing             int      `json:"existing" example:"3"`
+	UnresolvedTopics     []string `json:"unresolved_topics" example:"unknown-topic"`
+	UnresolvedShortnames []string `json:"unresolved_shortnames" example:"Unknown-1.0"`
+}
+
+// ObligationMapImportResponse represents the response format for an import of obligation maps.
+type ObligationMapImportResponse struct {
+	Status int                       `json:"status" example:"200"`
+	Data   ObligationMapImportResult `json:"data"`
+}
+
+// ObligationGraphNode is an obligation or a license in the obligation graph.
+type ObligationGraphNode struct {
+	Id             string `json:"id" example:"obligation:147"`
+	Kind           string `json:"kind" enums:"obligation,license" example:"obligation"`
+	Label          string `json:"label" example:"copyleft"`
+	Type           string `json:"type,omitempty" enums:"obligation,restriction,risk,right"`
+	Classification string `json:"classification,omitempty" enums:"green,white,yellow,red"`
+}
+
+// ObligationGraphEdge links an obligation to a license it is mapped to.
+type ObligationGraphEdge struct {
+	Source string `json:"source" example:"obligation:147"`
+	Target string `json:"target" example:"license:123"`
+}
+
+// ObligationGraph is the graph of the obligations and the licenses they are mapped to.
+type ObligationGraph struct {
+	Nodes []ObligationGraphNode `json:"nodes"`
+	Edges []ObligationGraphEdge `json:"edges"`
+}
+
+// ObligationGraphResponse represents the response format for the obligation graph.
+type ObligationGraphResponse struct {
+	Status int             `json:"status" example:"200"`
+	Data   ObligationGraph `json:"data"`
+}
+
+// ObligationMappingHealthCategory lists the obligations of a mapping health category.
+type ObligationMappingHealthCategory struct {
+	Count  int      `json:"count" example:"1"`
+	Topics []string `json:"topics" example:"copyleft"`
+}
+
+// ObligationMappingHealth classifies the obligations by the licenses they are mapped to.
+// An obligation mapped to both missing and inactive licenses is counted as missing.
+type ObligationMappingHealth struct {
+	FullyMapped      ObligationMappingHealthCategory `json:"fully_mapped"`
+	InactiveLicenses ObligationMappingHealthCategory `json:"inactive_licenses"`
+	MissingLicenses  ObligationMappingHealthCategory `json:"missing_licenses"`
+	Unmapped         ObligationMappingHealthCategory `json:"unmapped"`
+}
+
+// ObligationMappingHealthResponse represents the response format for the obligation mapping health.
+type ObligationMappingHealthResponse struct {
+	Status int                     `json:"status" example:"200"`
+	Data   ObligationMappingHealth `json:"data"`
+}
+
+// ObligationLengthBucket is the number of obligations whose text length is at least Min
+// and below Max. The last bucket has no Max.
+type ObligationLengthBucket struct {
+	Min   int   `json:"min" example:"50"`
+	Max   *int  `json:"max" example:"500"`
+	Count int64 `json:"count" example:"12"`
+}
+
+// ObligationLengthDistributionResponse represents the response format for the
+// distribution of obligation text lengths.
+type ObligationLengthDistributionResponse struct {
+	Status int                      `json:"status" example:"200"`
+	Data   []ObligationLengthBucket `json:"data"`
+}
+
+// ObligationLintCategory lists the obligations flagged by a lint check.
+type ObligationLintCategory struct {
+	Count  int      `json:"count" example:"1"`
+	Topics []string `json:"topics" example:"copyleft"`
+}
+
+// ObligationLint lists the suspicious obligations by the check flagging them. An
+// obligation may be flagged by several checks.
+type ObligationLint struct {
+	TopicIsTextHash                ObligationLintCategory `json:"topic_is_text_hash"`
+	EmptyClassification            ObligationLintCategory `json:"empty_classification"`
+	ShortText                      ObligationLintCategory `json:"short_text"`
+	Unmapped                       ObligationLintCategory `json:"unmapped"`
+	InactiveMappedToActiveLicenses ObligationLintCategory `json:"inactive_mapped_to_active_licenses"`
+}
+
+// ObligationLintResponse represents the response format for the obligation lint.
+type ObligationLintResponse struct {
+	Status int            `json:"status" example:"200"`
+	Data   ObligationLint `json:"data"`
+}
+
+// ObligationUsage is an obligation with the number of distinct active licenses mapped to it.
+type ObligationUsage struct {
+	Topic          string `json:"topic" example:"copyleft"`
+	Type           string `json:"type" enums:"obligation,restriction,risk,right" example:"risk"`
+	Classification string `json:"classification" enums:"green,white,yellow,red" example:"green"`
+	ActiveLicenses int64  `json:"active_licenses" example:"12"`
+}
+
+// ObligationUsageResponse represents the response format for the obligation usage.
+type ObligationUsageResponse struct {
+	Status int               `json:"status" example:"200"`
+	Data   []ObligationUsage `json:"data"`
+	Meta   *PaginationMeta   `json:"paginationmeta"`
+}
+
 // ObligationImportRequest represents the request body structure for import obligation
 type ObligationImportRequest struct {
 	ObligationFile string `form:"file"`
@@ -373,15 +1281,94 @@ type ObligationImportRequest struct {
 
 // ObligationJSONFileFormat represents an obligation record in the import/export json file.
 type ObligationJSONFileFormat struct {
-	Topic          string   `json:"topic" example:"copyleft" validate:"required"` // binding:"required" tag cannot be used as is works only for request body
-	Type           string   `json:"type" enums:"obligation,restriction,risk,right" validate:"required"`
-	Text           string   `json:"text" example:"Source code be made available when distributing the software." validate:"required"`
-	Classification string   `json:"classification" enums:"green,white,yellow,red" validate:"required"`
-	Modifications  bool     `json:"modifications" validate:"required"`
-	Comment        string   `json:"comment" example:"This is a comment." validate:"required"`
-	Active         bool     `json:"active" validate:"required"`
-	TextUpdatable  bool     `json:"text_updatable" validate:"required"`
-	Shortnames     []string `json:"shortnames" example:"GPL-2.0-only,GPL-2.0-or-later" validate:"required"`
+	Topic          string     `json:"topic" example:"copyleft" validate:"required"` // binding:"required" tag cannot be used as is works only for request body
+	Type           string     `json:"type" enums:"obligation,restriction,risk,right" validate:"required"`
+	Text           string     `json:"text" example:"Source code be made available when distributing the software." validate:"required"`
+	Classification string     `json:"classification" enums:"green,white,yellow,red" validate:"required"`
+	Modifications  bool       `json:"modifications" validate:"required"`
+	Comment        string     `json:"comment" example:"This is a comment." validate:"required"`
+	Active         bool       `json:"active" validate:"required"`
+	TextUpdatable  bool       `json:"text_updatable" validate:"required"`
+	Sensitive      bool       `json:"sensitive,omitempty"`
+	Shortnames     []string   `json:"shortnames" example:"GPL-2.0-only,GPL-2.0-or-later" validate:"required"`
+	EffectiveFrom  *time.Time `json:"effective_from,omitempty" example:"2024-01-01T00:00:00Z"`
+	EffectiveUntil *time.Time `json:"effective_until,omitempty" example:"2024-12-31T23:59:59Z"`
+	CreatedAt      *time.Time `json:"created_at,omitempty" example:"2019-06-01T00:00:00Z"` // admin only, to keep the creation date of migrated obligations
+}
+
+// ObligationFixture is the format of the fixtures seeding a database with licenses and
+// obligations. The obligations are mapped to the licenses of their shortnames.
+type ObligationFixture struct {
+	Licenses    []LicenseJson              `json:"licenses"`
+	Obligations []ObligationJSONFileFormat `json:"obligations"`
+}
+
+// LicenseExpressionInput represents the input format for resolving the obligations
+// of an SPDX license expression.
+type LicenseExpressionInput struct {
+	Expression string `json:"expression" binding:"required" example:"(MIT OR Apache-2.0) AND GPL-2.0-only"`
+}
+
+// LicenseExpressionNode is a node of a parsed SPDX license expression. It is either
+// a license with the topics of its obligations, or an AND or OR of its children.
+type LicenseExpressionNode struct {
+	Operator    string                  `json:"operator,omitempty" enums:"AND,OR" example:"AND"`
+	License     string                  `json:"license,omitempty" example:"MIT"`
+	Obligations []string                `json:"obligations,omitempty" example:"copyleft"`
+	Children    []LicenseExpressionNode `json:"children,omitempty"`
+}
+
+// ResolvedLicenseExpression is a license expression annotated with the obligations
+// of its licenses, along with the combined obligations of all the licenses.
+type ResolvedLicenseExpression struct {
+	Expression      LicenseExpressionNode     `json:"expression"`
+	Obligations     []LicenseObligationExport `json:"obligations"`
+	UnknownLicenses []string                  `json:"unknown_licenses" example:"GPL-2.0-only"`
+}
+
+// ResolvedLicenseExpressionResponse represents the response format for a resolved license expression.
+type ResolvedLicenseExpressionResponse struct {
+	Status int                       `json:"status" example:"200"`
+	Data   ResolvedLicenseExpression `json:"data"`
+}
+
+// OBLIGATION_EXPORT_SCHEMA_VERSION is the schema version of obligation exports.
+// Version 1 exports are a bare array of ObligationJSONFileFormat, version 2 added
+// the envelope and version 3 the manifest.
+const OBLIGATION_EXPORT_SCHEMA_VERSION = 3
+
+// ObligationExportEnvelope is the versioned format of obligation exports.
+type ObligationExportEnvelope struct {
+	SchemaVersion int                        `json:"schema_version" example:"3"`
+	ExportedAt    time.Time                  `json:"exported_at" example:"2024-01-01T00:00:00Z"`
+	Obligations   []ObligationJSONFileFormat `json:"obligations"`
+	Manifest      ObligationExportManifest   `json:"manifest"`
+}
+
+// ObligationExportManifest lets an import verify that an export was neither truncated
+// nor corrupted. The checksum is taken over the obligations in their order, each
+// serialized as by a json.Encoder, i.e. compact json followed by a newline.
+type ObligationExportManifest struct {
+	Count  int    `json:"count" example:"2"`
+	Sha256 string `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"` // hex encoded
+}
+
+// LicenseObligationsExportInput represents the input format for exporting the
+// obligations of a set of licenses.
+type LicenseObligationsExportInput struct {
+	Shortnames []string `json:"shortnames" binding:"required,min=1" example:"GPL-2.0-only,MIT"`
+}
+
+// LicenseObligationExport is an obligation in the export of the obligations of a
+// set of licenses, annotated with the shortnames of the licenses it applies to.
+type LicenseObligationExport struct {
+	Topic          string   `json:"topic" example:"copyleft"`
+	Type           string   `json:"type" enums:"obligation,restriction,risk,right"`
+	Text           string   `json:"text" example:"Source code be made available when distributing the software."`
+	Classification string   `json:"classification" enums:"green,white,yellow,red"`
+	Modifications  bool     `json:"modifications"`
+	Comment        string   `json:"comment" example:"This is a comment."`
+	Shortnames     []string `json:"shortnames" example:"GPL-2.0-only,GPL-2.0-or-later"`
 }
 
 // ObligationId is the id of successfully imported obligation
@@ -390,9 +1377,25 @@ type ObligationId struct {
 	Topic string `json:"topic" example:"copyleft"`
 }
 
+// Conflict resolution strategies of obligation import
+const (
+	IMPORT_STRATEGY_SKIP      = "skip"
+	IMPORT_STRATEGY_OVERWRITE = "overwrite"
+	IMPORT_STRATEGY_MERGE     = "merge"
+)
+
+// Actions taken on an obligation record during import
+const (
+	IMPORT_ACTION_CREATED     = "created"
+	IMPORT_ACTION_SKIPPED     = "skipped"
+	IMPORT_ACTION_OVERWRITTEN = "overwritten"
+	IMPORT_ACTION_MERGED      = "merged"
+)
+
 // ObligationImportStatus is the status of obligation records successfully inserted in the database during import
 type ObligationImportStatus struct {
 	Status int          `json:"status" example:"200"`
+	Action string       `json:"action" enums:"created,skipped,overwritten,merged" example:"created"`
 	Data   ObligationId `json:"data"`
 }
 
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2024 Siemens AG
+//
+// SPDX-License-Identifier: GPL-2.0-only
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FilterColumnType is the type of the values of a column in a filter expression
+type FilterColumnType int
+
+const (
+	FILTER_STRING FilterColumnType = iota
+	FILTER_BOOL
+	FILTER_INT
+)
+
+// FilterColumn is a column which may be used in a filter expression
+type FilterColumn struct {
+	Name string
+	Type FilterColumnType
+}
+
+// filterOperators maps the operators of a filter expression to their sql form
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"in":   "IN",
+	"like": "LIKE",
+}
+
+var (
+	filterConjunctionRegex = regexp.MustCompile(`(?i)\s+AND\s+`)
+	filterListRegex        = regexp.MustCompile(`,`)
+	namedConditionRegex    = regexp.MustCompile(`^(\w+)\s+(?i:(eq|ne|in|like))\s+(.+)$`)
+	symbolicConditionRegex = regexp.MustCompile(`^(\w+)\s*(!=|=)\s*(.+)$`)
+)
+
+// filterCondition is a single condition of a filter expression, with its values
+// already converted to the type of the column
+type filterCondition struct {
+	column   string
+	operator string
+	value    interface{}
+}
+
+// ApplyFilter adds the conditions of the filter expression to the query. A filter
+// expression is a list of conditions joined by AND, e.g.
+// "classification=red AND modifications=true". A condition is either
+// "<field>=<value>", "<field>!=<value>" or "<field> <op> <value>" where op is one of
+// eq, ne, in and like. The values of in are comma separated, optionally wrapped in
+// parentheses, and values may be wrapped in single or double quotes, e.g.
+// text="A AND B", in which case AND and commas are part of the value. Only the given
+// columns can be filtered on. Nothing is added to the query if the expression is invalid.
+func ApplyFilter(query *gorm.DB, filter string, columns map[string]FilterColumn) error {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil
+	}
+
+	var conditions []filterCondition
+	for _, expression := range splitUnquoted(filter, filterConjunctionRegex) {
+		condition, err := parseFilterCondition(strings.TrimSpace(expression), columns)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, condition := range conditions {
+		query.Where(fmt.Sprintf("%s %s ?", condition.column, condition.operator), condition.value)
+	}
+	return nil
+}
+
+// parseFilterCondition parses a single condition of a filter expression.
+func parseFilterCondition(expression string, columns map[string]FilterColumn) (filterCondition, error) {
+	var field, operator, value string
+	if match := namedConditionRegex.FindStringSubmatch(expression); match != nil {
+		field, operator, value = match[1], strings.ToLower(match[2]), match[3]
+	} else if match := symbolicConditionRegex.FindStringSubmatch(expression); match != nil {
+		field, value = match[1], match[3]
+		operator = "eq"
+		if match[2] == "!=" {
+			operator = "ne"
+		}
+	} else {
+		return filterCondition{}, fmt.Errorf("invalid condition '%s'", expression)
+	}
+
+	column, ok := columns[field]
+	if !ok {
+		return filterCondition{}, fmt.Errorf("can not filter on field '%s'", field)
+	}
+	if operator == "like" && column.Type != FILTER_STRING {
+		return filterCondition{}, fmt.Errorf("operator 'like' is not supported on field '%s'", field)
+	}
+
+	condition := filterCondition{column: column.Name, operator: filterOperators[operator]}
+	if operator != "in" {
+		parsed, err := parseFilterValue(unquoteFilterValue(value), column.Type)
+		if err != nil {
+			return filterCondition{}, fmt.Errorf("invalid value for field '%s': %s", field, err.Error())
+		}
+		condition.value = parsed
+		return condition, nil
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = value[1 : len(value)-1]
+	}
+	var values []interface{}
+	for _, item := range splitUnquoted(value, filterListRegex) {
+		parsed, err := parseFilterValue(unquoteFilterValue(item), column.Type)
+		if err != nil {
+			return filterCondition{}, fmt.Errorf("invalid value for field '%s': %s", field, err.Error())
+		}
+		values = append(values, parsed)
+	}
+	condition.value = values
+	return condition, nil
+}
+
+// splitUnquoted splits the value around the matches of the separator which are not
+// inside a quoted value. A quote only opens a quoted value at the start of a value,
+// so apostrophes within words are kept as they are.
+func splitUnquoted(value string, separator *regexp.Regexp) []string {
+	var parts []string
+	start, scanned := 0, 0
+	var quote byte
+	for _, loc := range separator.FindAllStringIndex(value, -1) {
+		for ; scanned < loc[0]; scanned++ {
+			char := value[scanned]
+			if quote != 0 {
+				if char == quote {
+					quote = 0
+				}
+			} else if (char == '\'' || char == '"') &&
+				(scanned == 0 || strings.ContainsRune(" \t=(,", rune(value[scanned-1]))) {
+				quote = char
+			}
+		}
+		if quote == 0 {
+			parts = append(parts, value[start:loc[0]])
+			start, scanned = loc[1], loc[1]
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// unquoteFilterValue trims the value and removes the single or double quotes around it.
+func unquoteFilterValue(value string) string {
+	value = strings.TrimSpace(value)
+	for _, quote := range []string{"'", "\""} {
+		if len(value) >= 2 && strings.HasPrefix(value, quote) && strings.HasSuffix(value, quote) {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// parseFilterValue converts the value to the type of the column.
+func parseFilterValue(value string, columnType FilterColumnType) (interface{}, error) {
+	switch columnType {
+	case FILTER_BOOL:
+		return strconv.ParseBool(value)
+	case FILTER_INT:
+		return strconv.ParseInt(value, 10, 64)
+	default:
+		return value, nil
+	}
+}
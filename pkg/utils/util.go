@@ -7,12 +7,24 @@
 package utils
 
 import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -137,6 +149,400 @@ func ParseIdToInt(c *gin.Context, id string, idType string) (int64, error) {
 	return parsedId, nil
 }
 
+// JSONTagName returns the json key of a struct field, to be used as the field name
+// in validation errors. It is meant to be registered with validator.RegisterTagNameFunc.
+func JSONTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// GetValidationErrors translates the error returned while binding a request body
+// into a list of field level errors which can be mapped back to the input.
+func GetValidationErrors(err error) []models.FieldError {
+	var fieldErrors []models.FieldError
+
+	var validationErrors validator.ValidationErrors
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrors):
+		for _, fe := range validationErrors {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: validationErrorMessage(fe),
+			})
+		}
+	case errors.As(err, &unmarshalTypeError):
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   unmarshalTypeError.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%s must be of type %s", unmarshalTypeError.Field, unmarshalTypeError.Type.String()),
+		})
+	case errors.As(err, &syntaxError):
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Rule:    "syntax",
+			Message: fmt.Sprintf("malformed json at offset %d", syntaxError.Offset),
+		})
+	default:
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Rule:    "invalid",
+			Message: err.Error(),
+		})
+	}
+
+	return fieldErrors
+}
+
+// GetNullFieldErrors returns an error for every key of the json body which is null,
+// but is bound to a field of the schema which can not be null, like an OptionalData.
+func GetNullFieldErrors(body []byte, schema interface{}) []models.FieldError {
+	var fieldErrors []models.FieldError
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return fieldErrors
+	}
+	schemaType := reflect.Indirect(reflect.ValueOf(schema)).Type()
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		name := JSONTagName(field)
+		if value, ok := keys[name]; !ok || string(value) != "null" {
+			continue
+		}
+		if err := json.Unmarshal(keys[name], reflect.New(field.Type).Interface()); errors.Is(err, models.ErrNullValue) {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   name,
+				Rule:    "not_null",
+				Message: fmt.Sprintf("%s cannot be null", name),
+			})
+		}
+	}
+	return fieldErrors
+}
+
+// BindErrorStatus returns the status code for an error returned while binding a
+// request body. A body which parses but fails validation is unprocessable, while
+// malformed json is a bad request.
+func BindErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// validationErrorMessage returns a human readable message for a failed validation rule.
+func validationErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", fe.Field(), fe.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "obligation_type":
+		return fmt.Sprintf("%s must be one of [%s]", fe.Field(), strings.Join(ObligationTypes(), " "))
+	case "obligation_topic":
+		return ReservedObligationTopicMessage(fe.Field())
+	default:
+		return fmt.Sprintf("%s failed on the '%s' rule", fe.Field(), fe.Tag())
+	}
+}
+
+// ObligationTypes returns the allowed obligation types, read from the comma
+// separated OBLIGATION_TYPES environment variable. Falls back to
+// models.DefaultObligationTypes if the variable is not set.
+func ObligationTypes() []string {
+	var types []string
+	for _, obligationType := range strings.Split(os.Getenv("OBLIGATION_TYPES"), ",") {
+		if obligationType = strings.ToLower(strings.TrimSpace(obligationType)); obligationType != "" {
+			types = append(types, obligationType)
+		}
+	}
+	if len(types) == 0 {
+		return models.DefaultObligationTypes
+	}
+	return types
+}
+
+// IsValidObligationType reports whether the obligation type is allowed.
+func IsValidObligationType(obligationType string) bool {
+	for _, allowed := range ObligationTypes() {
+		if obligationType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateObligationType is a validator.Func for the "obligation_type" tag which
+// checks that a field holds an allowed obligation type.
+func ValidateObligationType(fl validator.FieldLevel) bool {
+	return IsValidObligationType(fl.Field().String())
+}
+
+// ReservedObligationTopics are the words of the static obligation routes, such as
+// /obligations/types, which would shadow the obligations with these topics.
+var ReservedObligationTopics = []string{"audits", "changes.atom", "check-duplicates", "classify",
+	"deactivate-by-topics", "export", "graph", "id", "import", "length-distribution", "lint",
+	"mapping-health", "preview", "recent", "reclassify", "resolve-expression", "search", "tags",
+	"types", "usage", "validate", "watched"}
+
+// IsReservedObligationTopic reports whether the topic is the word of a static
+// obligation route.
+func IsReservedObligationTopic(topic string) bool {
+	for _, reserved := range ReservedObligationTopics {
+		if topic == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// ReservedObligationTopicMessage returns the message of a field holding a reserved
+// obligation topic.
+func ReservedObligationTopicMessage(field string) string {
+	return fmt.Sprintf("%s must not be one of the reserved words [%s]", field,
+		strings.Join(ReservedObligationTopics, " "))
+}
+
+// ValidateObligationTopic is a validator.Func for the "obligation_topic" tag which
+// checks that a field does not hold a reserved obligation topic.
+func ValidateObligationTopic(fl validator.FieldLevel) bool {
+	return !IsReservedObligationTopic(fl.Field().String())
+}
+
+// HighlightSnippet returns a snippet of the text around the first match of any of
+// the terms, with all the matches in the snippet wrapped in <mark> tags. Matching is
+// case-insensitive. The snippet extends to radius bytes on both sides of the match.
+// The text is HTML-escaped, so the snippet can be rendered as HTML.
+func HighlightSnippet(text string, terms []string, radius int) string {
+	var patterns []string
+	for _, term := range terms {
+		if term != "" {
+			patterns = append(patterns, regexp.QuoteMeta(term))
+		}
+	}
+	if len(patterns) == 0 {
+		return ""
+	}
+	re := regexp.MustCompile("(?i)(" + strings.Join(patterns, "|") + ")")
+
+	start, end := 0, len(text)
+	if loc := re.FindStringIndex(text); loc != nil {
+		if loc[0]-radius > start {
+			start = loc[0] - radius
+		}
+		if loc[1]+radius < end {
+			end = loc[1] + radius
+		}
+	} else if 2*radius < end {
+		end = 2 * radius
+	}
+	// Do not cut through multibyte characters
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	var builder strings.Builder
+	previous := 0
+	for _, loc := range re.FindAllStringIndex(text[start:end], -1) {
+		builder.WriteString(html.EscapeString(text[start+previous : start+loc[0]]))
+		builder.WriteString("<mark>" + html.EscapeString(text[start+loc[0]:start+loc[1]]) + "</mark>")
+		previous = loc[1]
+	}
+	builder.WriteString(html.EscapeString(text[start+previous : end]))
+	snippet := builder.String()
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(text) {
+		snippet = snippet + "..."
+	}
+	return snippet
+}
+
+// FindAllFold returns the character offsets of the non-overlapping occurrences of the
+// query in the text, compared case-insensitively.
+func FindAllFold(text, query string) []int {
+	offsets := []int{}
+	if query == "" {
+		return offsets
+	}
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	offset, previous := 0, 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		offset += utf8.RuneCountInString(text[previous:loc[0]])
+		previous = loc[0]
+		offsets = append(offsets, offset)
+	}
+	return offsets
+}
+
+// languageTagRegex matches BCP 47 like language tags such as "de" or "pt-BR"
+var languageTagRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// NormalizeLanguage validates the language tag and returns it in lower case.
+func NormalizeLanguage(language string) (string, error) {
+	if !languageTagRegex.MatchString(language) {
+		return "", fmt.Errorf("invalid language tag '%s'", language)
+	}
+	return strings.ToLower(language), nil
+}
+
+// ParseAcceptLanguage parses the value of an Accept-Language header and returns the
+// valid language tags ordered by preference. Wildcards are ignored.
+func ParseAcceptLanguage(header string) []string {
+	type weightedLanguage struct {
+		language string
+		quality  float64
+	}
+	var weighted []weightedLanguage
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language, err := NormalizeLanguage(strings.TrimSpace(fields[0]))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			weighted = append(weighted, weightedLanguage{language: language, quality: quality})
+		}
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	languages := make([]string, 0, len(weighted))
+	for _, wl := range weighted {
+		languages = append(languages, wl.language)
+	}
+	return languages
+}
+
+// TextSimilarity returns the similarity of two texts between 0 and 1, as the
+// Jaccard index of the sets of their trigrams. Like pg_trgm, the texts are
+// lower cased and split into words, and each word is padded with two spaces in
+// front and one at the end.
+func TextSimilarity(a, b string) float64 {
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	if len(trigramsA) == 0 && len(trigramsB) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(trigramsA)+len(trigramsB)-common)
+}
+
+// trigrams returns the set of trigrams of the words of the text
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// LevenshteinDistance returns the number of single character insertions, deletions
+// and substitutions needed to turn a into b.
+func LevenshteinDistance(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runesB)]
+}
+
+// obligationTextReplacer canonicalizes obligation texts before hashing. It drops the
+// byte order mark and the zero width characters, and turns \r\n and \r line endings
+// into \n.
+var obligationTextReplacer = strings.NewReplacer(
+	"\ufeff", "",
+	"\u200b", "",
+	"\u200c", "",
+	"\u200d", "",
+	"\u2060", "",
+	"\r\n", "\n",
+	"\r", "\n",
+)
+
+// NormalizeObligationText returns the canonical form of an obligation text, which is
+// stored next to the text as sent.
+func NormalizeObligationText(text string) string {
+	return obligationTextReplacer.Replace(text)
+}
+
+// ObligationTextHash returns the hex encoded SHA-256 of the canonical form of an
+// obligation text, by which duplicate obligations are detected. JSON escapes are already
+// resolved when the request is decoded, so the same text sent with different escapes,
+// e.g. \n or \u000a, gets the same hash. The text itself is stored as sent.
+func ObligationTextHash(text string) string {
+	return NormalizedObligationTextHash(NormalizeObligationText(text))
+}
+
+// NormalizedObligationTextHash returns the hash of ObligationTextHash from the already
+// normalized text, so that a text being stored is only normalized once.
+func NormalizedObligationTextHash(normalizedText string) string {
+	hash := sha256.Sum256([]byte(normalizedText))
+	return hex.EncodeToString(hash[:])
+}
+
+// ObligationTextMD5 returns the hex encoded MD5 of the canonical form of an obligation
+// text, by which clients can verify that the text was not altered in transit.
+func ObligationTextMD5(text string) string {
+	hash := md5.Sum([]byte(NormalizeObligationText(text)))
+	return hex.EncodeToString(hash[:])
+}
+
 // HashPassword hashes the password of the user using bcrypt. It also trims the
 // username and escapes the HTML characters.
 func HashPassword(user *models.User) error {
@@ -161,7 +567,8 @@ func VerifyPassword(inputPassword, dbPassword string) error {
 // PreparePaginateResponse prepares the pagination response for the API.
 // It gets the count of total rows and sets the pagination parameters, also
 // updates the query limit and offset and update the "paginationMeta" and
-// "responseModel" in gin.Context for middleware to process.
+// "responseModel" in gin.Context for middleware to process. If there are
+// records after the page, the X-Result-Truncated and Warning headers are set.
 func PreparePaginateResponse(c *gin.Context, query *gorm.DB,
 	responseModel interface{}) models.PaginationInput {
 	var totalRows int64
@@ -181,11 +588,78 @@ func PreparePaginateResponse(c *gin.Context, query *gorm.DB,
 	var paginationMeta models.PaginationMeta
 	paginationMeta.ResourceCount = int(totalRows)
 
+	if enabled, err := strconv.ParseBool(os.Getenv("PAGINATION_LINK_HEADERS")); err != nil || enabled {
+		if link := PaginationLinkHeader(*c.Request.URL, pagination, totalRows); link != "" {
+			c.Writer.Header().Add("Link", link)
+		}
+	}
+
+	// Warn the clients ignoring the pagination meta that the page does not hold all the
+	// records, as they would else not notice
+	if enabled, err := strconv.ParseBool(os.Getenv("PAGINATION_TRUNCATION_WARNING")); err != nil || enabled {
+		if pagination.Limit > 0 && pagination.GetOffset()+pagination.Limit < totalRows {
+			c.Header("X-Result-Truncated", "true")
+			c.Header("Warning", fmt.Sprintf("199 - \"Result truncated at limit %d of %d records, request the next page for more\"",
+				pagination.Limit, totalRows))
+		}
+	}
+
 	c.Set("paginationMeta", paginationMeta)
 	c.Set("responseModel", responseModel)
 	return pagination
 }
 
+// PaginationLinkHeader returns the value of an RFC 5988 Link header with the first,
+// last, next and previous pages of the paginated resource at the url. Other query
+// parameters of the url are kept.
+func PaginationLinkHeader(u url.URL, pagination models.PaginationInput, totalRows int64) string {
+	if pagination.Limit <= 0 {
+		return ""
+	}
+	lastPage := (totalRows + pagination.Limit - 1) / pagination.Limit
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	params := u.Query()
+	pageLink := func(page int64, rel string) string {
+		params.Set("page", strconv.FormatInt(page, 10))
+		params.Set("limit", strconv.FormatInt(pagination.Limit, 10))
+		u.RawQuery = params.Encode()
+		return fmt.Sprintf("<%s>; rel=\"%s\"", u.String(), rel)
+	}
+
+	var links []string
+	if pagination.Page < lastPage {
+		links = append(links, pageLink(pagination.Page+1, "next"))
+	}
+	if pagination.Page > 1 {
+		// Past the end, the previous page is the last one
+		prevPage := pagination.Page - 1
+		if prevPage > lastPage {
+			prevPage = lastPage
+		}
+		links = append(links, pageLink(prevPage, "prev"))
+	}
+	links = append(links, pageLink(1, "first"), pageLink(lastPage, "last"))
+	return strings.Join(links, ", ")
+}
+
+// MergeJSONExpr returns an expression which overwrites values of existing keys in
+// the json column, adds new key value pairs and removes keys with null values.
+func MergeJSONExpr(tx *gorm.DB, column string, value interface{}) (clause.Expr, error) {
+	if tx.Dialector.Name() == "postgres" {
+		return gorm.Expr(fmt.Sprintf("jsonb_strip_nulls(COALESCE(%s, '{}'::jsonb) || ?)", column), value), nil
+	}
+	// json_patch implements RFC 7396 merge patch which has the same semantics. The sqlite
+	// driver cannot bind maps, so the patch is bound as its json encoding.
+	patch, err := json.Marshal(value)
+	if err != nil {
+		return clause.Expr{}, err
+	}
+	return gorm.Expr(fmt.Sprintf("json_patch(COALESCE(%s, '{}'), ?)", column), string(patch)), nil
+}
+
 // LicenseImportStatusCode is internally used for checking status of a license import
 type LicenseImportStatusCode int
 
@@ -222,7 +696,11 @@ func InsertOrUpdateLicenseOnImport(tx *gorm.DB, license *models.LicenseDB, exter
 		// case when license exists in database and is updated
 
 		// Overwrite values of existing keys, add new key value pairs and remove keys with null values.
-		if err := tx.Model(&models.LicenseDB{}).Where(models.LicenseDB{Id: oldLicense.Id}).UpdateColumn("external_ref", gorm.Expr("jsonb_strip_nulls(COALESCE(external_ref, '{}'::jsonb) || ?)", &externalRefs.ExternalRef)).Error; err != nil {
+		mergeExpr, err := MergeJSONExpr(tx, "external_ref", &externalRefs.ExternalRef)
+		if err == nil {
+			err = tx.Model(&models.LicenseDB{}).Where(models.LicenseDB{Id: oldLicense.Id}).UpdateColumn("external_ref", mergeExpr).Error
+		}
+		if err != nil {
 			message = fmt.Sprintf("failed to update license: %s", err.Error())
 			importStatus = IMPORT_FAILED
 			return message, importStatus, &oldLicense, &newLicense